@@ -0,0 +1,45 @@
+package models
+
+// dipakai utk POST /achievements/bulk/verify
+type BulkVerifyItem struct {
+	ID            string `json:"id"`
+	Points        int    `json:"points"`
+	Justification string `json:"justification"`
+}
+
+type BulkVerifyRequest struct {
+	Items []BulkVerifyItem `json:"items"`
+}
+
+// dipakai utk POST /achievements/bulk/reject
+type BulkRejectItem struct {
+	ID   string `json:"id"`
+	Note string `json:"note"`
+}
+
+type BulkRejectRequest struct {
+	Items []BulkRejectItem `json:"items"`
+}
+
+// Hasil per item bulk verify / reject
+const (
+	BulkResultOK          = "ok"
+	BulkResultForbidden   = "forbidden"
+	BulkResultWrongStatus = "wrong_status"
+	BulkResultNotFound    = "not_found"
+	BulkResultInvalid     = "invalid"
+	BulkResultError       = "error"
+)
+
+type BulkItemResult struct {
+	ID                string   `json:"id"`
+	Result            string   `json:"result"`
+	Message           string   `json:"message,omitempty"`
+	CurrentStatus     string   `json:"current_status,omitempty"`
+	AllowedNextStates []string `json:"allowed_next_states,omitempty"`
+}
+
+type BulkResponse struct {
+	Summary map[string]int   `json:"summary"`
+	Results []BulkItemResult `json:"results"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// Jenis event di achievement_events
+const (
+	AchievementEventCreated         = "created"
+	AchievementEventUpdated         = "updated"
+	AchievementEventAttachmentAdded = "attachment_added"
+	AchievementEventSubmitted       = "submitted"
+	AchievementEventVerified        = "verified"
+	AchievementEventRejected        = "rejected"
+	AchievementEventDeleted         = "deleted"
+	// kompensasi: event sebelumnya dibatalkan karena langkah penulisan berikutnya gagal
+	AchievementEventReverted = "reverted"
+)
+
+// FieldChange: nilai field sebelum dan sesudah event
+type FieldChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+type AchievementEvent struct {
+	ID                 int64                  `json:"id"`
+	MongoAchievementID string                 `json:"mongo_achievement_id"`
+	EventType          string                 `json:"event"`
+	ActorUserID        *string                `json:"actor_user_id"`
+	ActorRole          *string                `json:"actor_role"`
+	FromStatus         *string                `json:"from_status"`
+	ToStatus           *string                `json:"to_status"`
+	Changes            map[string]FieldChange `json:"changes,omitempty"`
+	Note               *string                `json:"note"`
+	CreatedAt          time.Time              `json:"created_at"`
+}
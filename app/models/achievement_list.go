@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// Field urutan yang didukung GET /achievements (nama field di MongoDB)
+const (
+	AchievementSortCreatedAt = "createdAt"
+	AchievementSortUpdatedAt = "updatedAt"
+	AchievementSortPoints    = "points"
+)
+
+// AchievementListQuery: filter, urutan, dan halaman untuk repository.GetAllAchievements.
+// Filter kosong berarti tidak dibatasi.
+type AchievementListQuery struct {
+	StudentIDs    []string // nil = semua mahasiswa (admin); kosong = tidak ada yang boleh dilihat
+	Types         []string
+	Statuses      []string   // status di achievement_references
+	Tags          []string   // dokumen harus punya semua tag
+	CreatedFrom   *time.Time // createdAt >= CreatedFrom
+	CreatedBefore *time.Time // createdAt < CreatedBefore
+	SortBy        string
+	Desc          bool
+	Skip          int64
+	Limit         int64
+	After         *AchievementCursor // keyset pagination; Skip diabaikan
+}
+
+// AchievementCursor: posisi item terakhir halaman sebelumnya (nilai field urutan + _id)
+type AchievementCursor struct {
+	SortBy string    `json:"s"`
+	Desc   bool      `json:"d"`
+	Time   time.Time `json:"t,omitempty"` // createdAt / updatedAt
+	Points int       `json:"p,omitempty"`
+	ID     string    `json:"id"`
+}
+
+// AchievementPage: data envelope GET /achievements
+type AchievementPage struct {
+	Page       int           `json:"page,omitempty"` // kosong pada mode cursor
+	Limit      int           `json:"limit"`
+	Total      int64         `json:"total"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+	Results    []Achievement `json:"results"`
+}
@@ -15,3 +15,12 @@ type AchievementReference struct {
     UpdatedAt          time.Time   `json:"updated_at"`
 }
 
+
+// Status prestasi di achievement_references
+const (
+	AchievementStatusDraft     = "draft"
+	AchievementStatusSubmitted = "submitted"
+	AchievementStatusVerified  = "verified"
+	AchievementStatusRejected  = "rejected"
+	AchievementStatusDeleted   = "deleted"
+)
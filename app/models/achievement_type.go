@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// AchievementType: satu entri katalog achievementType.
+// Schema diisi dari achievement_type_schemas (nil jika tipe belum punya schema).
+type AchievementType struct {
+	Code          string            `json:"code"`
+	Labels        map[string]string `json:"labels"` // per bahasa, mis. {"id": "Kompetisi", "en": "Competition"}
+	IsActive      bool              `json:"is_active"`
+	DefaultPoints *int              `json:"default_points"`
+	Schema        map[string]any    `json:"schema"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+}
+
+// dipakai utk POST /admin/achievement-types dan PUT /admin/achievement-types/{code}
+// (code diabaikan pada PUT; IsActive nil = aktif / tidak berubah)
+type AchievementTypeRequest struct {
+	Code          string            `json:"code"`
+	Labels        map[string]string `json:"labels"`
+	IsActive      *bool             `json:"is_active"`
+	DefaultPoints *int              `json:"default_points"`
+}
+
+// AchievementTypeSchema: JSON Schema untuk details satu achievementType
+type AchievementTypeSchema struct {
+	AchievementType string         `json:"achievement_type"`
+	Schema          map[string]any `json:"schema"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+}
+
+// dipakai utk PUT /admin/achievement-types/{type}/schema
+type AchievementTypeSchemaRequest struct {
+	Schema map[string]any `json:"schema"`
+}
+
+// dipakai utk POST /admin/achievement-types/remap
+// Mappings: nilai lama -> kode katalog. Nilai yang tidak disebut dicocokkan otomatis
+// (kode atau label tanpa membedakan huruf besar/kecil).
+type TypeRemapRequest struct {
+	Mappings map[string]string `json:"mappings"`
+}
+
+// TypeRemapEntry: satu nilai achievementType di Mongo yang tidak ada di katalog
+type TypeRemapEntry struct {
+	From   string `json:"from"`
+	To     string `json:"to,omitempty"`     // kosong = tidak ditemukan padanannya
+	Source string `json:"source,omitempty"` // "explicit" atau "auto"
+	Count  int64  `json:"count"`
+}
+
+type TypeRemapReport struct {
+	DryRun   bool             `json:"dry_run"`
+	Remapped []TypeRemapEntry `json:"remapped"`
+	Unmapped []TypeRemapEntry `json:"unmapped"`
+}
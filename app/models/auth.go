@@ -13,7 +13,8 @@ type User struct {
 	Email        string    `json:"email"`
 	PasswordHash string    `json:"password_hash"`
 	FullName     string    `json:"full_name"`
-	RoleID       string    `json:"role_id"`
+	RoleID       string    `json:"role_id"`            // role utama
+	RoleIDs      []string  `json:"role_ids,omitempty"` // semua role (utama + tambahan); hanya diisi jika dimuat
 	IsActive     bool      `json:"is_active"`
 	CreatedAt    time.Time `json:"created_at"`
 	UpdatedAt    time.Time `json:"updated_at"`
@@ -24,13 +25,18 @@ type LoginRequest struct {
     Email    string `json:"email"`
     NIM      string `json:"nim"`
     Password string `json:"password"`
+    Device   string `json:"device,omitempty"` // nama perangkat (opsional, default User-Agent)
 }
 
-// Response login (user info + token)
+// Response login (user info + access token + refresh token)
 type LoginResponse struct {
-    User        User     `json:"user"`
-    Token       string   `json:"token"`
-    Permissions []string `json:"permissions"` // <- tambahkan ini jika belum ada
+    User                  User      `json:"user"`
+    TokenType             string    `json:"token_type"`
+    AccessToken           string    `json:"access_token"`
+    AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+    RefreshToken          string    `json:"refresh_token"`
+    RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+    Permissions           []string  `json:"permissions"` // <- tambahkan ini jika belum ada
 }
 
 
@@ -39,18 +45,37 @@ type JWTClaims struct {
 	UserID string `json:"user_id"`
 	Email  string `json:"email"`
 	Role   string `json:"role"`
+	// semua role ID user (termasuk Role); kosong pada token lama = hanya Role
+	Roles     []string `json:"roles,omitempty"`
+	SessionID string   `json:"sid,omitempty"` // family id refresh token (per perangkat)
+	// permission role saat token dibuat; hanya diisi jika JWT_EMBED_PERMISSIONS=true
+	Permissions []string `json:"perms,omitempty"`
 	jwt.RegisteredClaims
 }
 
 type RefreshTokenRequest struct {
-	Token string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// Refresh token opaque yang disimpan per perangkat (hanya hash-nya yang disimpan).
+// Semua token hasil rotasi dari satu login berbagi FamilyID yang sama.
+type RefreshToken struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"user_id"`
+	FamilyID   string     `json:"family_id"`
+	TokenHash  string     `json:"-"`
+	Device     string     `json:"device"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	ReplacedBy *string    `json:"replaced_by"`
 }
 
 type CreateUserRequest struct {
 	Username string `json:"username" validate:"required"`
 	FullName string `json:"full_name" validate:"required"`
 	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required,min=6"`
+	Password string `json:"password" validate:"required"` // aturan lain: helper.ValidatePassword
 	RoleID   string `json:"role_id" validate:"required"`
 	IsActive bool   `json:"is_active" validate:"required"`
 }
@@ -64,6 +89,9 @@ type UpdateUserRequest struct {
 	Password string `json:"password"`
 }
 
+// UpdateUserRoleRequest: role_id mengganti role utama (bentuk lama); add/remove mengatur role tambahan.
 type UpdateUserRoleRequest struct {
-	RoleID string `json:"role_id" validate:"required"`
+	RoleID        string   `json:"role_id"`
+	AddRoleIDs    []string `json:"add_role_ids"`
+	RemoveRoleIDs []string `json:"remove_role_ids"`
 }
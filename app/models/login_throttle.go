@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Scope pembatasan login (dan permintaan reset password)
+const (
+	LoginThrottleAccount = "account"
+	LoginThrottleIP      = "ip"
+	LoginThrottleReset   = "reset"
+	LoginThrottleResetIP = "reset_ip"
+)
+
+// LoginThrottle: jumlah login gagal berturut-turut untuk satu akun / IP.
+type LoginThrottle struct {
+	Scope         string     `json:"scope"`
+	Key           string     `json:"key"`
+	Failures      int        `json:"failures"`
+	LastFailureAt time.Time  `json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Request body ganti password (user yang sedang login)
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required"`
+}
+
+// Request body lupa password: email atau NIM
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+	NIM   string `json:"nim"`
+}
+
+// Request body reset password memakai token dari email
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required"`
+}
+
+// Token reset password sekali pakai (hanya hash-nya yang disimpan).
+type PasswordResetToken struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	UsedAt    *time.Time `json:"used_at"`
+}
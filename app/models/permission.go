@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type Permission struct {
     ID          string `json:"id"`
     Name        string `json:"name"`
@@ -11,4 +13,38 @@ type Permission struct {
 type RolePermission struct {
     RoleID       string `json:"role_id"`
     PermissionID string `json:"permission_id"`
-}
\ No newline at end of file
+}
+
+// Effect override permission per user
+const (
+    PermissionEffectGrant = "grant"
+    PermissionEffectDeny  = "deny"
+)
+
+// UserPermission: override satu permission untuk satu user (deny > grant > role)
+type UserPermission struct {
+    UserID     string    `json:"user_id"`
+    Permission string    `json:"permission"`
+    Effect     string    `json:"effect"`
+    Reason     *string   `json:"reason"`
+    GrantedBy  *string   `json:"granted_by"`
+    CreatedAt  time.Time `json:"created_at"`
+    UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// dipakai utk PUT /users/{id}/permissions/{permission}
+type UserPermissionRequest struct {
+    Effect string `json:"effect"` // "grant" atau "deny"
+    Reason string `json:"reason"`
+}
+
+// UserPermissionsView: permission role, override, dan hasil akhirnya untuk satu user
+type UserPermissionsView struct {
+    UserID          string           `json:"user_id"`
+    RoleID          string           `json:"role_id"`
+    Role            string           `json:"role"`
+    Roles           []string         `json:"roles"`            // semua role (utama + tambahan)
+    RolePermissions []string         `json:"role_permissions"` // gabungan permission semua role
+    Overrides       []UserPermission `json:"overrides"`
+    Effective       []string         `json:"effective"`
+}
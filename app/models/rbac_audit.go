@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Jenis aksi di rbac_audit_log
+const (
+	RBACAuditRoleCreated           = "role_created"
+	RBACAuditRoleUpdated           = "role_updated"
+	RBACAuditRoleDeleted           = "role_deleted"
+	RBACAuditPermissionGranted     = "permission_granted"
+	RBACAuditPermissionRevoked     = "permission_revoked"
+	RBACAuditUserRoleAssigned      = "user_role_assigned"
+	RBACAuditUserRoleAdded         = "user_role_added"
+	RBACAuditUserRoleRemoved       = "user_role_removed"
+	RBACAuditUserPermissionSet     = "user_permission_set"
+	RBACAuditUserPermissionRemoved = "user_permission_removed"
+)
+
+type RBACAuditEntry struct {
+	ID           int64                  `json:"id"`
+	Action       string                 `json:"action"`
+	RoleID       *string                `json:"role_id"`
+	RoleName     *string                `json:"role_name"`
+	Permission   *string                `json:"permission"`
+	TargetUserID *string                `json:"target_user_id"`
+	ActorUserID  *string                `json:"actor_user_id"`
+	ActorRole    *string                `json:"actor_role"`
+	Changes      map[string]FieldChange `json:"changes,omitempty"`
+	CreatedAt    time.Time              `json:"created_at"`
+}
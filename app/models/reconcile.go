@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Kategori selisih antara dokumen Mongo dan achievement_references
+const (
+	ReconcileMongoOrphan          = "mongo_without_reference"   // dokumen Mongo tanpa row reference
+	ReconcileReferenceOrphan      = "reference_without_mongo"   // reference menunjuk dokumen yang tidak ada
+	ReconcileDeletedOnlyInMongo   = "deleted_in_mongo_only"     // Mongo status deleted, reference belum
+	ReconcileDeletedOnlyReference = "deleted_in_reference_only" // reference deleted, Mongo belum
+)
+
+// AchievementMongoState: field dokumen Mongo yang dibutuhkan untuk rekonsiliasi
+type AchievementMongoState struct {
+	MongoID   string    `json:"mongo_id"`
+	StudentID string    `json:"student_id"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ReconcileIssue struct {
+	Category        string `json:"category"`
+	MongoID         string `json:"mongo_id"`
+	ReferenceID     string `json:"reference_id,omitempty"`
+	StudentID       string `json:"student_id,omitempty"`
+	MongoStatus     string `json:"mongo_status,omitempty"`
+	ReferenceStatus string `json:"reference_status,omitempty"`
+	Action          string `json:"action"`
+	Repaired        bool   `json:"repaired"`
+	Stale           bool   `json:"stale,omitempty"` // selisih sudah tidak ada saat dicek ulang sebelum diperbaiki
+	Error           string `json:"error,omitempty"`
+}
+
+type ReconcileReport struct {
+	DryRun            bool             `json:"dry_run"`
+	ScannedMongo      int              `json:"scanned_mongo"`
+	ScannedReferences int              `json:"scanned_references"`
+	SkippedRecent     int              `json:"skipped_recent"` // prestasi yang baru dibuat saat scan, tidak diperiksa
+	Counts            map[string]int   `json:"counts"`
+	Issues            []ReconcileIssue `json:"issues"`
+}
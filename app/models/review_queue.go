@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// ReviewQueueItem: prestasi submitted milik mahasiswa bimbingan yang menunggu verifikasi dosen wali
+type ReviewQueueItem struct {
+	ReferenceID        string    `json:"reference_id"`
+	MongoAchievementID string    `json:"mongo_achievement_id"`
+	StudentID          string    `json:"student_id"`
+	StudentName        string    `json:"student_name"`
+	NIM                string    `json:"nim"`
+	ProgramStudy       string    `json:"program_study"`
+	Title              string    `json:"title"`
+	AchievementType    string    `json:"achievement_type"`
+	AttachmentCount    int       `json:"attachment_count"`
+	SubmittedAt        time.Time `json:"submitted_at"`
+	AgeSeconds         int64     `json:"age_seconds"`      // lama menunggu di antrian
+	SuggestedPoints    *int      `json:"suggested_points"` // poin rubrik, null jika tidak ada aturan yang cocok
+
+	Details map[string]any `json:"-"` // dipakai untuk menghitung SuggestedPoints
+}
@@ -8,5 +8,20 @@ type Role struct {
     ID          string    `json:"id"`
     Name        string    `json:"name"`
     Description string    `json:"description"`
+    IsSystem    bool      `json:"is_system"` // role bawaan: tidak bisa diganti nama / dihapus
+    Permissions []string  `json:"permissions"`
+    UserCount   int       `json:"user_count"`
     CreatedAt   time.Time `json:"created_at"`
 }
+
+// dipakai utk POST /roles dan PUT /roles/{id} (Permissions hanya dipakai saat create)
+type RoleRequest struct {
+    Name        string   `json:"name"`
+    Description string   `json:"description"`
+    Permissions []string `json:"permissions"`
+}
+
+// dipakai utk POST /roles/{id}/permissions
+type RolePermissionsRequest struct {
+    Permissions []string `json:"permissions"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+// mode penerapan rubrik poin
+const (
+	ScoringModeEnforce  = "enforce"
+	ScoringModeOverride = "override"
+)
+
+// nilai details.participation
+const (
+	ParticipationIndividual = "individual"
+	ParticipationTeam       = "team"
+)
+
+// ScoringRule: satu baris rubrik. Kriteria nil = berlaku untuk semua nilai.
+type ScoringRule struct {
+	ID               string    `json:"id"`
+	AchievementType  string    `json:"achievement_type"`
+	CompetitionLevel *string   `json:"competition_level"`
+	Rank             *int      `json:"rank"`
+	Participation    *string   `json:"participation"`
+	Points           int       `json:"points"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// dipakai utk POST/PUT /admin/scoring-rules
+type ScoringRuleRequest struct {
+	AchievementType  string  `json:"achievement_type"`
+	CompetitionLevel *string `json:"competition_level"`
+	Rank             *int    `json:"rank"`
+	Participation    *string `json:"participation"`
+	Points           int     `json:"points"`
+}
+
+type ScoringPolicy struct {
+	Mode               string    `json:"mode"`
+	MaxOverridePercent int       `json:"max_override_percent"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// dipakai utk PUT /admin/scoring-policy
+type ScoringPolicyRequest struct {
+	Mode               string `json:"mode"`
+	MaxOverridePercent int    `json:"max_override_percent"`
+}
+
+// ScoreSuggestion: poin yang disarankan rubrik untuk satu prestasi.
+// SuggestedPoints nil jika tidak ada aturan yang cocok (poin diisi bebas oleh dosen).
+type ScoreSuggestion struct {
+	SuggestedPoints *int   `json:"suggested_points"`
+	RuleID          string `json:"rule_id,omitempty"`
+	Mode            string `json:"mode"`
+	MinPoints       *int   `json:"min_points"`
+	MaxPoints       *int   `json:"max_points"`
+}
@@ -20,25 +20,28 @@ type UpdateStudentAdvisorRequest struct {
 
 // dipakai utk POST /achievements
 type CreateAchievementRequest struct {
-	Title           string   `json:"title"`
-	Description     string   `json:"description"`
-	AchievementType string   `json:"achievementType"`
-	Details         string   `json:"details,omitempty"`
-	Tags            []string `json:"tags,omitempty"`
+	Title           string         `json:"title"`
+	Description     string         `json:"description"`
+	AchievementType string         `json:"achievementType"`
+	Details         map[string]any `json:"details,omitempty"` // divalidasi dengan schema achievementType (GET /achievement-types)
+	Tags            []string       `json:"tags,omitempty"`
 }
 
 // dipakai utk PATCH /achievements/{id}
 type UpdateAchievementRequest struct {
-	Title           *string   `json:"title,omitempty"`
-	Description     *string   `json:"description,omitempty"`
-	AchievementType *string   `json:"achievementType,omitempty"`
-	Details         *string   `json:"details,omitempty"`
-	Tags            *[]string `json:"tags,omitempty"`
+	Title           *string         `json:"title,omitempty"`
+	Description     *string         `json:"description,omitempty"`
+	AchievementType *string         `json:"achievementType,omitempty"`
+	Details         *map[string]any `json:"details,omitempty"`
+	Tags            *[]string       `json:"tags,omitempty"`
 }
 
 // dipakai utk POST /achievements/{id}/verify
+// Points boleh kosong jika ada aturan rubrik yang cocok (poin rubrik dipakai).
+// Justification wajib jika poin menyimpang dari rubrik (mode override).
 type VerifyAchievementRequest struct {
-	Points int `json:"points"`
+	Points        int    `json:"points"`
+	Justification string `json:"justification"`
 }
 
 // dipakai utk POST /achievements/{id}/reject
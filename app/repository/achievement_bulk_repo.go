@@ -0,0 +1,227 @@
+package repository
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/database"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetAchievementReferencesByMongoIDs mengambil banyak reference sekaligus.
+//
+//go:noinline
+func GetAchievementReferencesByMongoIDs(mongoIDs []string) ([]models.AchievementReference, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := database.PSQL.QueryContext(ctx, `
+		SELECT id, student_id, mongo_achievement_id, status,
+		       submitted_at, verified_at, verified_by,
+		       rejection_note, created_at, updated_at
+		FROM achievement_references
+		WHERE mongo_achievement_id = ANY($1)
+	`, pq.Array(mongoIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.AchievementReference
+	for rows.Next() {
+		var r models.AchievementReference
+		if err := rows.Scan(
+			&r.ID, &r.StudentID, &r.MongoAchievementID, &r.Status,
+			&r.SubmittedAt, &r.VerifiedAt, &r.VerifiedBy,
+			&r.RejectionNote, &r.CreatedAt, &r.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// GetAdviseeStudentSet mengembalikan studentIDs yang merupakan bimbingan lecturerID.
+//
+//go:noinline
+func GetAdviseeStudentSet(lecturerID string, studentIDs []string) (map[string]bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := database.PSQL.QueryContext(ctx,
+		`SELECT id FROM students WHERE advisor_id = $1 AND id = ANY($2)`,
+		lecturerID, pq.Array(studentIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[string]bool{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out[id] = true
+	}
+	return out, rows.Err()
+}
+
+// BulkVerifyAchievementReferences memverifikasi banyak reference dalam satu query.
+// Hanya reference yang masih submitted yang diubah; id yang benar-benar berubah dikembalikan.
+//
+//go:noinline
+func BulkVerifyAchievementReferences(refIDs []string, dosenID string) (map[string]bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := database.PSQL.QueryContext(ctx, `
+		UPDATE achievement_references
+		SET status = 'verified',
+		    verified_at = NOW(),
+		    verified_by = $2,
+		    updated_at = NOW()
+		WHERE id = ANY($1::uuid[]) AND status = 'submitted'
+		RETURNING id
+	`, pq.Array(refIDs), dosenID)
+	if err != nil {
+		return nil, err
+	}
+	return scanIDSet(rows)
+}
+
+// BulkRejectAchievementReferences menolak banyak reference (catatan per reference) dalam satu query.
+//
+//go:noinline
+func BulkRejectAchievementReferences(notes map[string]string, dosenID string) (map[string]bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ids := make([]string, 0, len(notes))
+	texts := make([]string, 0, len(notes))
+	for id, note := range notes {
+		ids = append(ids, id)
+		texts = append(texts, note)
+	}
+
+	rows, err := database.PSQL.QueryContext(ctx, `
+		UPDATE achievement_references ar
+		SET status = 'rejected',
+		    rejection_note = v.note,
+		    verified_by = $3,
+		    verified_at = NOW(),
+		    updated_at = NOW()
+		FROM unnest($1::uuid[], $2::text[]) AS v(id, note)
+		WHERE ar.id = v.id AND ar.status = 'submitted'
+		RETURNING ar.id
+	`, pq.Array(ids), pq.Array(texts), dosenID)
+	if err != nil {
+		return nil, err
+	}
+	return scanIDSet(rows)
+}
+
+func scanIDSet(rows *sql.Rows) (map[string]bool, error) {
+	defer rows.Close()
+
+	out := map[string]bool{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out[id] = true
+	}
+	return out, rows.Err()
+}
+
+// BulkUpdateAchievementsMongo menjalankan $set per dokumen dalam satu BulkWrite (unordered).
+// Mengembalikan error per mongoID yang gagal. Jika seluruh batch gagal, semua id dianggap gagal.
+//
+//go:noinline
+func BulkUpdateAchievementsMongo(sets map[string]bson.M) map[string]error {
+	collection := database.MongoDB.Collection("achievements")
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	failed := map[string]error{}
+	ids := make([]string, 0, len(sets))
+	writes := make([]mongo.WriteModel, 0, len(sets))
+	for id, set := range sets {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			failed[id] = err
+			continue
+		}
+		ids = append(ids, id)
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": objID}).
+			SetUpdate(bson.M{"$set": set}))
+	}
+	if len(writes) == 0 {
+		return failed
+	}
+
+	_, err := collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	if err == nil {
+		return failed
+	}
+
+	var bwe mongo.BulkWriteException
+	if errors.As(err, &bwe) && bwe.WriteConcernError == nil {
+		for _, we := range bwe.WriteErrors {
+			failed[ids[we.Index]] = errors.New(we.Message)
+		}
+		return failed
+	}
+
+	for _, id := range ids {
+		failed[id] = err
+	}
+	return failed
+}
+
+// GetAchievementsByMongoIDs mengambil tipe dan details banyak dokumen sekaligus (untuk penilaian rubrik).
+// Id yang tidak valid atau tidak ditemukan tidak ada di hasil.
+//
+//go:noinline
+func GetAchievementsByMongoIDs(mongoIDs []string) (map[string]models.Achievement, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objIDs := make([]primitive.ObjectID, 0, len(mongoIDs))
+	for _, id := range mongoIDs {
+		if objID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objIDs = append(objIDs, objID)
+		}
+	}
+
+	out := map[string]models.Achievement{}
+	if len(objIDs) == 0 {
+		return out, nil
+	}
+
+	opts := options.Find().SetProjection(bson.M{"_id": 1, "studentId": 1, "achievementType": 1, "details": 1})
+	cursor, err := database.MongoDB.Collection("achievements").Find(ctx, bson.M{"_id": bson.M{"$in": objIDs}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var a models.Achievement
+		if err := cursor.Decode(&a); err != nil {
+			return nil, err
+		}
+		out[a.ID.Hex()] = a
+	}
+	return out, cursor.Err()
+}
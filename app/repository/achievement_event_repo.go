@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/database"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// InsertAchievementEvent menambah satu baris ke log audit (tabel append-only).
+//
+//go:noinline
+func InsertAchievementEvent(e *models.AchievementEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// dikirim sebagai string (bukan []byte) agar lib/pq tidak meng-encode-nya sebagai bytea
+	var changes any
+	if len(e.Changes) > 0 {
+		b, err := json.Marshal(e.Changes)
+		if err != nil {
+			return err
+		}
+		changes = string(b)
+	}
+
+	return database.PSQL.QueryRowContext(ctx, `
+		INSERT INTO achievement_events
+		(mongo_achievement_id, event_type, actor_user_id, actor_role, from_status, to_status, changes, note)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`, e.MongoAchievementID, e.EventType, e.ActorUserID, e.ActorRole, e.FromStatus, e.ToStatus, changes, e.Note).
+		Scan(&e.ID, &e.CreatedAt)
+}
+
+// GetAchievementEvents mengambil riwayat event prestasi, urut dari yang paling lama.
+//
+//go:noinline
+func GetAchievementEvents(mongoID string) ([]models.AchievementEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := database.PSQL.QueryContext(ctx, `
+		SELECT id, mongo_achievement_id, event_type, actor_user_id, actor_role,
+		       from_status, to_status, changes, note, created_at
+		FROM achievement_events
+		WHERE mongo_achievement_id = $1
+		ORDER BY id
+	`, mongoID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.AchievementEvent{}
+	for rows.Next() {
+		var e models.AchievementEvent
+		var changes []byte
+		if err := rows.Scan(&e.ID, &e.MongoAchievementID, &e.EventType, &e.ActorUserID, &e.ActorRole,
+			&e.FromStatus, &e.ToStatus, &changes, &e.Note, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if len(changes) > 0 {
+			if err := json.Unmarshal(changes, &e.Changes); err != nil {
+				return nil, err
+			}
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// InsertAchievementEvents menambah banyak event dalam satu INSERT (dipakai operasi bulk).
+//
+//go:noinline
+func InsertAchievementEvents(events []models.AchievementEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	const cols = 8
+	values := make([]string, 0, len(events))
+	args := make([]any, 0, len(events)*cols)
+	for i, e := range events {
+		var changes any
+		if len(e.Changes) > 0 {
+			b, err := json.Marshal(e.Changes)
+			if err != nil {
+				return err
+			}
+			changes = string(b)
+		}
+
+		n := i * cols
+		values = append(values, fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)",
+			n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8))
+		args = append(args, e.MongoAchievementID, e.EventType, e.ActorUserID, e.ActorRole,
+			e.FromStatus, e.ToStatus, changes, e.Note)
+	}
+
+	_, err := database.PSQL.ExecContext(ctx, `
+		INSERT INTO achievement_events
+		(mongo_achievement_id, event_type, actor_user_id, actor_role, from_status, to_status, changes, note)
+		VALUES `+strings.Join(values, ", "), args...)
+	return err
+}
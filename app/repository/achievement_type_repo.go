@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/database"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var (
+	ErrAchievementTypeNotFound = errors.New("achievement type not found")
+	ErrAchievementTypeExists   = errors.New("achievement type already exists")
+)
+
+func scanAchievementType(row interface{ Scan(...any) error }) (models.AchievementType, error) {
+	var t models.AchievementType
+	var labels, schema []byte
+	if err := row.Scan(&t.Code, &labels, &t.IsActive, &t.DefaultPoints, &schema, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return t, err
+	}
+	if err := json.Unmarshal(labels, &t.Labels); err != nil {
+		return t, err
+	}
+	if schema != nil {
+		if err := json.Unmarshal(schema, &t.Schema); err != nil {
+			return t, err
+		}
+	}
+	return t, nil
+}
+
+const achievementTypeSelect = `
+	SELECT t.code, t.labels, t.is_active, t.default_points, s.schema, t.created_at, t.updated_at
+	FROM achievement_types t
+	LEFT JOIN achievement_type_schemas s ON s.achievement_type = t.code
+`
+
+// ListAchievementTypes mengambil katalog achievementType beserta schema details-nya.
+//
+//go:noinline
+func ListAchievementTypes(activeOnly bool) ([]models.AchievementType, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := database.PSQL.QueryContext(ctx,
+		achievementTypeSelect+` WHERE ($1 = FALSE OR t.is_active) ORDER BY t.code`, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.AchievementType{}
+	for rows.Next() {
+		t, err := scanAchievementType(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// GetAchievementTypeDefaultPoints: poin default per kode (hanya tipe yang punya poin default).
+//
+//go:noinline
+func GetAchievementTypeDefaultPoints() (map[string]int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := database.PSQL.QueryContext(ctx,
+		`SELECT code, default_points FROM achievement_types WHERE default_points IS NOT NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[string]int{}
+	for rows.Next() {
+		var code string
+		var points int
+		if err := rows.Scan(&code, &points); err != nil {
+			return nil, err
+		}
+		out[code] = points
+	}
+	return out, rows.Err()
+}
+
+// achievementTypeError menerjemahkan duplicate key / baris tidak ditemukan.
+func achievementTypeError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrAchievementTypeExists
+	}
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		// schema / rubrik untuk kode yang tidak ada di katalog
+		return ErrAchievementTypeNotFound
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrAchievementTypeNotFound
+	}
+	return err
+}
+
+//go:noinline
+func CreateAchievementType(req models.AchievementTypeRequest) (*models.AchievementType, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	labels, err := json.Marshal(req.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	t := models.AchievementType{Code: req.Code, Labels: req.Labels, IsActive: *req.IsActive, DefaultPoints: req.DefaultPoints}
+	err = database.PSQL.QueryRowContext(ctx, `
+		INSERT INTO achievement_types (code, labels, is_active, default_points)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at, updated_at
+	`, req.Code, string(labels), *req.IsActive, req.DefaultPoints).Scan(&t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return nil, achievementTypeError(err)
+	}
+	return &t, nil
+}
+
+//go:noinline
+func UpdateAchievementType(code string, req models.AchievementTypeRequest) (*models.AchievementType, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	labels, err := json.Marshal(req.Labels)
+	if err != nil {
+		return nil, err
+	}
+
+	row := database.PSQL.QueryRowContext(ctx, `
+		WITH t AS (
+			UPDATE achievement_types
+			SET labels = $2,
+			    is_active = COALESCE($3, is_active),
+			    default_points = $4,
+			    updated_at = NOW()
+			WHERE code = $1
+			RETURNING *
+		)
+		SELECT t.code, t.labels, t.is_active, t.default_points, s.schema, t.created_at, t.updated_at
+		FROM t
+		LEFT JOIN achievement_type_schemas s ON s.achievement_type = t.code
+	`, code, string(labels), req.IsActive, req.DefaultPoints)
+
+	t, err := scanAchievementType(row)
+	if err != nil {
+		return nil, achievementTypeError(err)
+	}
+	return &t, nil
+}
+
+// DeleteAchievementType menghapus tipe dari katalog (schema & rubrik ikut terhapus lewat FK).
+//
+//go:noinline
+func DeleteAchievementType(code string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := database.PSQL.ExecContext(ctx, `DELETE FROM achievement_types WHERE code = $1`, code)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrAchievementTypeNotFound
+	}
+	return nil
+}
+
+// CountAchievementTypeValues menghitung dokumen achievements per nilai achievementType (apa adanya).
+//
+//go:noinline
+func CountAchievementTypeValues() (map[string]int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	cursor, err := database.MongoDB.Collection("achievements").Aggregate(ctx, []bson.M{
+		{"$group": bson.M{"_id": "$achievementType", "count": bson.M{"$sum": 1}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	out := map[string]int64{}
+	for cursor.Next(ctx) {
+		var row struct {
+			Value any   `bson:"_id"`
+			Count int64 `bson:"count"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return nil, err
+		}
+		// null / field tidak ada dihitung sebagai string kosong
+		value, _ := row.Value.(string)
+		out[value] += row.Count
+	}
+	return out, cursor.Err()
+}
+
+// RemapAchievementTypeMongo mengganti achievementType from -> to di semua dokumen.
+//
+//go:noinline
+func RemapAchievementTypeMongo(from, to string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	res, err := database.MongoDB.Collection("achievements").UpdateMany(ctx,
+		bson.M{"achievementType": from},
+		bson.M{"$set": bson.M{"achievementType": to, "updatedAt": time.Now()}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
+
+// GetAchievementTypeSchema mengambil schema details satu tipe; nil jika tipe belum punya schema.
+//
+//go:noinline
+func GetAchievementTypeSchema(achType string) (map[string]any, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var raw []byte
+	err := database.PSQL.QueryRowContext(ctx,
+		`SELECT schema FROM achievement_type_schemas WHERE achievement_type = $1`, achType,
+	).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, err
+	}
+	return schema, nil
+}
+
+//go:noinline
+func UpsertAchievementTypeSchema(achType string, schema map[string]any) (*models.AchievementTypeSchema, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return nil, err
+	}
+
+	out := models.AchievementTypeSchema{AchievementType: achType, Schema: schema}
+	// JSONB dikirim sebagai string; []byte akan dikirim lib/pq sebagai bytea
+	err = database.PSQL.QueryRowContext(ctx, `
+		INSERT INTO achievement_type_schemas (achievement_type, schema, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (achievement_type) DO UPDATE
+		SET schema = EXCLUDED.schema, updated_at = NOW()
+		RETURNING updated_at
+	`, achType, string(raw)).Scan(&out.UpdatedAt)
+	if err != nil {
+		return nil, achievementTypeError(err)
+	}
+	return &out, nil
+}
+
+// DeleteAchievementTypeSchema menghapus schema; false jika tipe tidak punya schema.
+//
+//go:noinline
+func DeleteAchievementTypeSchema(achType string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := database.PSQL.ExecContext(ctx,
+		`DELETE FROM achievement_type_schemas WHERE achievement_type = $1`, achType)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	return n > 0, nil
+}
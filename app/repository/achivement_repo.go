@@ -12,64 +12,189 @@ import (
 	"errors"
 	"time"
 
+	"github.com/lib/pq"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	// "database/sql"
 	// "errors"
 	// "github.com/google/uuid"
 )
 
-// Get all achievements with optional filters and status from PostgreSQL
-func GetAllAchievements(studentId string, achType string) ([]models.Achievement, error) {
+// GetAllAchievements mengambil satu halaman prestasi sesuai filter beserta total yang cocok.
+// Filter status diterjemahkan ke daftar _id dari achievement_references; status setiap
+// hasil diambil dalam satu query dan dimasukkan ke Details["status"].
+//
+//go:noinline
+func GetAllAchievements(q models.AchievementListQuery) ([]models.Achievement, int64, error) {
 	collection := database.MongoDB.Collection("achievements")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
 	filter := bson.M{}
+	if q.StudentIDs != nil {
+		if len(q.StudentIDs) == 0 {
+			return []models.Achievement{}, 0, nil
+		}
+		filter["studentId"] = bson.M{"$in": q.StudentIDs}
+	}
+	if len(q.Types) > 0 {
+		filter["achievementType"] = bson.M{"$in": q.Types}
+	}
+	if len(q.Tags) > 0 {
+		filter["tags"] = bson.M{"$all": q.Tags}
+	}
+	if q.CreatedFrom != nil || q.CreatedBefore != nil {
+		created := bson.M{}
+		if q.CreatedFrom != nil {
+			created["$gte"] = *q.CreatedFrom
+		}
+		if q.CreatedBefore != nil {
+			created["$lt"] = *q.CreatedBefore
+		}
+		filter["createdAt"] = created
+	}
+	if len(q.Statuses) > 0 {
+		ids, err := getMongoIDsByStatus(ctx, q.StudentIDs, q.Statuses)
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(ids) == 0 {
+			return []models.Achievement{}, 0, nil
+		}
+		filter["_id"] = bson.M{"$in": ids}
+	}
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	dir := 1
+	if q.Desc {
+		dir = -1
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: q.SortBy, Value: dir}, {Key: "_id", Value: dir}}).
+		SetLimit(q.Limit)
+
+	if q.After != nil {
+		after, err := cursorFilter(q.After)
+		if err != nil {
+			return nil, 0, err
+		}
+		filter = bson.M{"$and": []bson.M{filter, after}}
+	} else if q.Skip > 0 {
+		opts.SetSkip(q.Skip)
+	}
 
-	if studentId != "" {
-		filter["studentId"] = studentId
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	results := []models.Achievement{}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, 0, err
 	}
-	fmt.Println(studentId,"student id")
-	if achType != "" {
-		filter["achievementType"] = achType
+	if len(results) == 0 {
+		return results, total, nil
+	}
+
+	mongoIDs := make([]string, len(results))
+	for i := range results {
+		mongoIDs[i] = results[i].ID.Hex()
 	}
-	fmt.Println(achType,"achievement type")
-	cursor, err := collection.Find(context.Background(), filter)
+	statuses, err := GetAchievementStatusesByMongoIDs(mongoIDs)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error fetching achievement statuses: %w", err)
+	}
+	for i := range results {
+		status, ok := statuses[mongoIDs[i]]
+		if !ok {
+			continue
+		}
+		if results[i].Details == nil {
+			results[i].Details = make(map[string]any)
+		}
+		results[i].Details["status"] = status
+	}
+
+	return results, total, nil
+}
+
+// cursorFilter: dokumen sesudah cursor menurut (field urutan, _id)
+func cursorFilter(c *models.AchievementCursor) (bson.M, error) {
+	objID, err := primitive.ObjectIDFromHex(c.ID)
 	if err != nil {
 		return nil, err
 	}
+	var value any = c.Time
+	if c.SortBy == models.AchievementSortPoints {
+		value = c.Points
+	}
+	op := "$gt"
+	if c.Desc {
+		op = "$lt"
+	}
+	return bson.M{"$or": []bson.M{
+		{c.SortBy: bson.M{op: value}},
+		{c.SortBy: value, "_id": bson.M{op: objID}},
+	}}, nil
+}
 
-	var results []models.Achievement
-	if err := cursor.All(context.Background(), &results); err != nil {
+func getMongoIDsByStatus(ctx context.Context, studentIDs []string, statuses []string) ([]primitive.ObjectID, error) {
+	// studentIDs nil dikirim sebagai NULL = semua mahasiswa
+	rows, err := database.PSQL.QueryContext(ctx, `
+		SELECT mongo_achievement_id
+		FROM achievement_references
+		WHERE status = ANY($1) AND ($2::text[] IS NULL OR student_id::text = ANY($2::text[]))
+	`, pq.Array(statuses), pq.Array(studentIDs))
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	// Fetch status from PostgreSQL achievement_references for each achievement
-	for i := range results {
-		mongoID := results[i].ID.Hex()
-
-		// Query PostgreSQL to get the status from achievement_references
-		var status sql.NullString
-		query := `
-			SELECT status 
-			FROM achievement_references 
-			WHERE mongo_achievement_id = $1
-		`
-		err := database.PSQL.QueryRow(query, mongoID).Scan(&status)
-
-		// If reference exists, add status to the Details map
-		if err == nil && status.Valid {
-			if results[i].Details == nil {
-				results[i].Details = make(map[string]any)
-			}
-			results[i].Details["status"] = status.String
-		} else if err != nil && err != sql.ErrNoRows {
-			// Only return error for actual errors, not for missing references
-			return nil, fmt.Errorf("error fetching status for achievement %s: %w", mongoID, err)
+	ids := []primitive.ObjectID{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		if objID, err := primitive.ObjectIDFromHex(id); err == nil {
+			ids = append(ids, objID)
 		}
 	}
+	return ids, rows.Err()
+}
+
+// GetAchievementStatusesByMongoIDs: status reference per mongoID dalam satu query.
+// mongoID tanpa reference tidak ada di hasil.
+//
+//go:noinline
+func GetAchievementStatusesByMongoIDs(mongoIDs []string) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
-	return results, nil
+	rows, err := database.PSQL.QueryContext(ctx, `
+		SELECT mongo_achievement_id, status
+		FROM achievement_references
+		WHERE mongo_achievement_id = ANY($1)
+	`, pq.Array(mongoIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := map[string]string{}
+	for rows.Next() {
+		var id, status string
+		if err := rows.Scan(&id, &status); err != nil {
+			return nil, err
+		}
+		out[id] = status
+	}
+	return out, rows.Err()
 }
 
 // Get achievement by its MongoDB ID
@@ -211,8 +336,32 @@ func GetAchievementReferenceByMongoID(mongoID string) (*models.AchievementRefere
 	return &r, nil
 }
 
-// Hard delete reference by its reference UUID (NOT by mongoID)
-func AchievementSoftDeleteReference(referenceID string) error {
+// ReferenceStatusError: UPDATE transisi tidak mengenai baris karena status reference
+// sudah bukan salah satu status asal yang diharapkan (berubah sejak dibaca).
+type ReferenceStatusError struct {
+	Current string
+}
+
+func (e *ReferenceStatusError) Error() string {
+	return "achievement reference status changed to " + e.Current
+}
+
+// referenceTransitionMiss: penyebab UPDATE transisi tidak mengenai baris —
+// sql.ErrNoRows jika reference tidak ada, *ReferenceStatusError jika statusnya sudah berubah.
+func referenceTransitionMiss(ctx context.Context, column, key string) error {
+	var current string
+	err := database.PSQL.QueryRowContext(ctx,
+		"SELECT status FROM achievement_references WHERE "+column+" = $1", key).Scan(&current)
+	if err != nil {
+		return err
+	}
+	return &ReferenceStatusError{Current: current}
+}
+
+// Soft delete reference by its reference UUID (NOT by mongoID), hanya jika statusnya masih salah satu expectedFrom
+//
+//go:noinline
+func AchievementSoftDeleteReference(referenceID string, expectedFrom []string) error {
 	if referenceID == "" {
 		return errors.New("reference id required")
 	}
@@ -239,7 +388,7 @@ func AchievementSoftDeleteReference(referenceID string) error {
 		 SET status = 'deleted',
 		     deleted_at = NOW(),
 		     updated_at = NOW()
-		 WHERE id = $1`, referenceID)
+		 WHERE id = $1 AND status = ANY($2)`, referenceID, pq.Array(expectedFrom))
 	if err != nil {
 		return err
 	}
@@ -249,7 +398,7 @@ func AchievementSoftDeleteReference(referenceID string) error {
 		return err
 	}
 	if ra == 0 {
-		return sql.ErrNoRows
+		return referenceTransitionMiss(ctx, "id", referenceID)
 	}
 
 	return nil
@@ -302,17 +451,33 @@ func SubmitAchievement(referenceID string, studentID string) error {
 	return nil
 }
 
-func UpdateReferenceStatusSubmitted(mongoID string) error {
+// UpdateReferenceStatusSubmitted: status -> submitted, hanya jika statusnya masih salah satu expectedFrom.
+//
+//go:noinline
+func UpdateReferenceStatusSubmitted(mongoID string, expectedFrom []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
 	query := `
 		UPDATE achievement_references
 		SET status = 'submitted',
 		    submitted_at = NOW(),
 		    updated_at = NOW()
-		WHERE mongo_achievement_id = $1
+		WHERE mongo_achievement_id = $1 AND status = ANY($2)
 	`
 
-	_, err := database.PSQL.Exec(query, mongoID)
-	return err
+	res, err := database.PSQL.ExecContext(ctx, query, mongoID, pq.Array(expectedFrom))
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return referenceTransitionMiss(ctx, "mongo_achievement_id", mongoID)
+	}
+	return nil
 }
 
 func VerifyAchievementMongo(id string, points int, dosenID string) error {
@@ -334,18 +499,27 @@ func VerifyAchievementMongo(id string, points int, dosenID string) error {
 		},
 	}
 
-	_, err = collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
-	return err
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
 }
 
-func VerifyAchievementReference(refID string, dosenID string) error {
+// VerifyAchievementReference: status -> verified, hanya jika statusnya masih salah satu expectedFrom.
+//
+//go:noinline
+func VerifyAchievementReference(refID string, dosenID string, expectedFrom []string) error {
 	query := `
         UPDATE achievement_references
         SET status = 'verified',
             verified_at = NOW(),
             verified_by = $2,
             updated_at = NOW()
-        WHERE id = $1
+        WHERE id = $1 AND status = ANY($3)
     `
 
 	fmt.Println("DEBUG VerifyAchievementReference")
@@ -353,7 +527,7 @@ func VerifyAchievementReference(refID string, dosenID string) error {
 	fmt.Println("refID:", refID)
 	fmt.Println("dosenID:", dosenID)
 
-	res, err := database.PSQL.Exec(query, refID, dosenID)
+	res, err := database.PSQL.Exec(query, refID, dosenID, pq.Array(expectedFrom))
 	if err != nil {
 		fmt.Println("Postgres ERROR:", err.Error()) // ERROR ASLI di log
 		return err
@@ -361,9 +535,12 @@ func VerifyAchievementReference(refID string, dosenID string) error {
 
 	rows, err := res.RowsAffected()
 	fmt.Println("RowsAffected:", rows, "err:", err)
+	if err != nil {
+		return err
+	}
 
 	if rows == 0 {
-		return errors.New("ERROR: no rows affected — reference ID not found OR lecturer not authorized")
+		return referenceTransitionMiss(context.Background(), "id", refID)
 	}
 
 	return nil
@@ -388,11 +565,20 @@ func RejectAchievementMongo(id, note, dosenID string) error {
 		},
 	}
 
-	_, err = collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
-	return err
+	result, err := collection.UpdateOne(ctx, bson.M{"_id": objID}, update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
 }
 
-func RejectAchievementReference(refID string, note, dosenID string) error {
+// RejectAchievementReference: status -> rejected, hanya jika statusnya masih salah satu expectedFrom.
+//
+//go:noinline
+func RejectAchievementReference(refID string, note, dosenID string, expectedFrom []string) error {
 	query := `
         UPDATE achievement_references
         SET status = 'rejected',
@@ -400,16 +586,19 @@ func RejectAchievementReference(refID string, note, dosenID string) error {
             verified_by = $3,
             verified_at = NOW(),
             updated_at = NOW()
-        WHERE id = $1
+        WHERE id = $1 AND status = ANY($4)
     `
-	res, err := database.PSQL.Exec(query, refID, note, dosenID)
+	res, err := database.PSQL.Exec(query, refID, note, dosenID, pq.Array(expectedFrom))
 	if err != nil {
 		return err
 	}
 
-	rows, _ := res.RowsAffected()
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
 	if rows == 0 {
-		return errors.New("reference not found or not updated")
+		return referenceTransitionMiss(context.Background(), "id", refID)
 	}
 
 	return nil
@@ -489,3 +678,51 @@ func AddAchievementAttachment(mongoID string, att models.Attachment) error {
 
 	return err
 }
+
+// AchievementDeleteMongo menghapus permanen dokumen Mongo.
+// Hanya dipakai sebagai kompensasi saat insert reference Postgres gagal.
+//
+//go:noinline
+func AchievementDeleteMongo(mongoID primitive.ObjectID) error {
+	collection := database.MongoDB.Collection("achievements")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := collection.DeleteOne(ctx, bson.M{"_id": mongoID})
+	return err
+}
+
+// RestoreReferenceState mengembalikan kolom status reference ke snapshot sebelumnya.
+// Dipakai sebagai kompensasi saat langkah MongoDB gagal setelah Postgres ter-update.
+// Hanya berlaku jika status masih writtenStatus (status yang ditulis langkah yang dibatalkan);
+// jika transisi lain sudah masuk, tidak ditimpa dan *ReferenceStatusError dikembalikan.
+//
+//go:noinline
+func RestoreReferenceState(ref *models.AchievementReference, writtenStatus string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := database.PSQL.ExecContext(ctx, `
+		UPDATE achievement_references
+		SET status = $2,
+		    submitted_at = $3,
+		    verified_at = $4,
+		    verified_by = $5,
+		    rejection_note = $6,
+		    updated_at = $7,
+		    deleted_at = NULL
+		WHERE id = $1 AND status = $8
+	`, ref.ID, ref.Status, ref.SubmittedAt, ref.VerifiedAt, ref.VerifiedBy, ref.RejectionNote, ref.UpdatedAt, writtenStatus)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return referenceTransitionMiss(ctx, "id", ref.ID)
+	}
+	return nil
+}
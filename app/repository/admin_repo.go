@@ -133,43 +133,32 @@ func CreateUser(user *models.User) (*models.User, error) {
 }
 
 func UpdateUser(id string, user *models.User) (*models.User, error) {
-	var row *sql.Row
+	tx, err := database.PSQL.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
 
-	if user.PasswordHash != "" {
-		// update termasuk password
-		query := `UPDATE users 
-                  SET email = $1, username = $2, full_name = $3, role_id = $4, is_active = $5, 
-                      password_hash = $6, updated_at = NOW()
-                  WHERE id = $7
-                  RETURNING id, email, username, full_name, role_id, is_active, created_at, updated_at`
+	admins, err := lockActiveAdmins(tx)
+	if err != nil {
+		return nil, err
+	}
 
-		row = database.PSQL.QueryRow(query,
-			user.Email,
-			user.Username,
-			user.FullName,
-			user.RoleID,
-			user.IsActive,
-			user.PasswordHash,
-			id,
-		)
-
-	} else {
-		// update tanpa password
-		query := `UPDATE users 
+	// password (opsional) diganti lewat setPassword di bawah supaya sesi ikut dicabut
+	query := `UPDATE users 
                   SET email = $1, username = $2, full_name = $3, role_id = $4, is_active = $5,
                       updated_at = NOW()
                   WHERE id = $6
                   RETURNING id, email, username, full_name, role_id, is_active, created_at, updated_at`
 
-		row = database.PSQL.QueryRow(query,
-			user.Email,
-			user.Username,
-			user.FullName,
-			user.RoleID,
-			user.IsActive,
-			id,
-		)
-	}
+	row := tx.QueryRow(query,
+		user.Email,
+		user.Username,
+		user.FullName,
+		user.RoleID,
+		user.IsActive,
+		id,
+	)
 
 	if err := row.Scan(
 		&user.ID,
@@ -188,7 +177,16 @@ func UpdateUser(id string, user *models.User) (*models.User, error) {
 		return nil, err
 	}
 
-	return user, nil
+	if user.PasswordHash != "" {
+		if err := setPassword(tx, id, user.PasswordHash, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ensureAdminRemains(tx, admins); err != nil {
+		return nil, err
+	}
+	return user, tx.Commit()
 }
 
 func DeleteUser(id string) error {
@@ -197,6 +195,12 @@ func DeleteUser(id string) error {
 		return err
 	}
 
+	admins, err := lockActiveAdmins(tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// Delete from students if exists
 	_, err = tx.Exec(`DELETE FROM students WHERE user_id = $1::uuid`, id)
 	if err != nil {
@@ -230,24 +234,69 @@ func DeleteUser(id string) error {
 		return errors.New("user not found")
 	}
 
-	return tx.Commit()
+	if err := ensureAdminRemains(tx, admins); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	// override permission ikut terhapus (ON DELETE CASCADE)
+	InvalidateUserPermissionCache(id)
+	return nil
 }
 
+// UpdateUserRole mengganti role utama saja (bentuk lama PUT /users/:id/role); lihat UpdateUserRoles.
 func UpdateUserRole(id string, roleID string) (*models.User, error) {
-	query := `UPDATE users SET role_id = $1, updated_at = NOW() 
-			  WHERE id = $2 
-			  RETURNING id, email, role_id, is_active, created_at, updated_at`
-
-	row := database.PSQL.QueryRow(query, roleID, id)
-	var user models.User
-	if err := row.Scan(&user.ID, &user.Email, &user.RoleID, &user.IsActive, &user.CreatedAt, &user.UpdatedAt); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, errors.New("user not found")
-		}
+	change, err := UpdateUserRoles(id, roleID, nil, nil)
+	if err != nil {
 		return nil, err
 	}
+	return change.User, nil
+}
 
-	return &user, nil
+// ErrLastAdmin: perubahan akan menyisakan nol admin aktif
+var ErrLastAdmin = errors.New("cannot remove the last active admin")
+
+// activeAdminFilter: user aktif yang memegang role admin (utama maupun tambahan)
+const activeAdminFilter = `
+	u.is_active AND EXISTS (
+		SELECT 1 FROM user_roles ur JOIN roles r ON r.id = ur.role_id
+		WHERE ur.user_id = u.id AND r.name = 'admin'
+	)`
+
+// lockActiveAdmins mengunci baris admin aktif (FOR UPDATE) sampai transaksi selesai agar dua
+// admin tidak bisa saling menurunkan secara bersamaan; mengembalikan jumlahnya.
+func lockActiveAdmins(tx *sql.Tx) (int, error) {
+	rows, err := tx.Query(`SELECT u.id::text FROM users u WHERE` + activeAdminFilter + `
+		FOR UPDATE OF u`)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		n++
+	}
+	return n, rows.Err()
+}
+
+// ensureAdminRemains dipanggil setelah perubahan, di transaksi yang sama dengan lockActiveAdmins:
+// ErrLastAdmin jika sebelumnya ada admin aktif dan sekarang tidak tersisa satu pun.
+func ensureAdminRemains(tx *sql.Tx, adminsBefore int) error {
+	if adminsBefore == 0 {
+		return nil
+	}
+	var remaining int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM users u WHERE` + activeAdminFilter).Scan(&remaining); err != nil {
+		return err
+	}
+	if remaining == 0 {
+		return ErrLastAdmin
+	}
+	return nil
 }
 
 func IsEmailExistsForOtherUser(id, email string) (bool, error) {
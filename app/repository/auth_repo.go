@@ -95,10 +95,48 @@ func GetPermissionsByRoleID(roleID string) ([]string, error) {
 	}
 	return perms, nil
 }
-func LogoutUser(userID string) error {
-	// Token invalidation logic can be implemented here
-	// For now, logout is handled client-side by removing the token
-	return nil
+// LogoutUser mencabut token (berdasarkan jti) sehingga tidak bisa dipakai lagi
+// walaupun belum expired.
+func LogoutUser(userID string, jti string, expiresAt time.Time) error {
+	return RevokeToken(jti, userID, expiresAt)
+}
+
+// RevokeToken memasukkan jti ke blacklist sampai token tersebut expired.
+func RevokeToken(jti string, userID string, expiresAt time.Time) error {
+	if jti == "" {
+		return errors.New("token id is empty")
+	}
+
+	_, err := database.PSQL.Exec(`
+		INSERT INTO revoked_tokens (jti, user_id, expires_at, revoked_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (jti) DO NOTHING
+	`, jti, userID, expiresAt)
+	return err
+}
+
+// IsTokenRevoked mengecek apakah jti ada di blacklist.
+func IsTokenRevoked(jti string) (bool, error) {
+	var exists bool
+	err := database.PSQL.QueryRow(`
+		SELECT EXISTS(
+			SELECT 1 FROM revoked_tokens WHERE jti = $1
+		)
+	`, jti).Scan(&exists)
+	if err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// DeleteExpiredRevokedTokens menghapus entri blacklist yang token-nya sudah expired
+// (token tersebut sudah ditolak oleh validasi exp, jadi tidak perlu disimpan lagi).
+func DeleteExpiredRevokedTokens() (int64, error) {
+	res, err := database.PSQL.Exec(`DELETE FROM revoked_tokens WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
 }
 
 func GetStudentIDByUserID(userID string) (string, error) {
@@ -189,27 +227,32 @@ func RoleHasPermission(roleID string, permissionName string) (bool, error) {
     return exists, nil
 }
 
-// UserHasPermission: opsi jika kamu punya mekanisme permission per-user
+// UserHasPermission: permission efektif user dalam satu query.
+// Urutan resolusi: deny di user_permissions > grant di user_permissions > permission salah satu role user.
 func UserHasPermission(userID string, permissionName string) (bool, error) {
     if userID == "" || permissionName == "" {
         return false, errors.New("invalid args")
     }
 
-    var exists bool
-    // contoh query: ambil role_id dari users lalu cek role_permissions
+    var allowed bool
     query := `
-        SELECT EXISTS(
-            SELECT 1
-            FROM users u
-            JOIN roles r ON u.role_id = r.id
-            JOIN role_permissions rp ON rp.role_id = r.id
-            JOIN permissions p ON p.id = rp.permission_id
-            WHERE u.id = $1 AND p.name = $2
+        SELECT COALESCE(
+            (SELECT up.effect = 'grant'
+             FROM user_permissions up
+             JOIN permissions p ON p.id = up.permission_id
+             WHERE up.user_id = $1 AND p.name = $2),
+            EXISTS(
+                SELECT 1
+                FROM user_roles ur
+                JOIN role_permissions rp ON rp.role_id = ur.role_id
+                JOIN permissions p ON p.id = rp.permission_id
+                WHERE ur.user_id = $1 AND p.name = $2
+            )
         )
     `
-    err := database.PSQL.QueryRow(query, userID, permissionName).Scan(&exists)
+    err := database.PSQL.QueryRow(query, userID, permissionName).Scan(&allowed)
     if err != nil {
         return false, err
     }
-    return exists, nil
+    return allowed, nil
 }
\ No newline at end of file
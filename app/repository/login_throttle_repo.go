@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/database"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// LoginAccountKey: kunci throttle per akun dari identifier yang diketik (email / NIM),
+// dipakai sama persis untuk akun yang ada maupun tidak.
+func LoginAccountKey(byNIM bool, identifier string) string {
+	prefix := "email:"
+	if byNIM {
+		prefix = "nim:"
+	}
+	return prefix + strings.ToLower(strings.TrimSpace(identifier))
+}
+
+// GetLoginThrottle mengambil status throttle; nil jika belum pernah gagal.
+//
+//go:noinline
+func GetLoginThrottle(scope, key string) (*models.LoginThrottle, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t := models.LoginThrottle{Scope: scope, Key: key}
+	err := database.PSQL.QueryRowContext(ctx, `
+		SELECT failures, last_failure_at, locked_until
+		FROM login_throttles
+		WHERE scope = $1 AND key = $2
+	`, scope, key).Scan(&t.Failures, &t.LastFailureAt, &t.LockedUntil)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// RecordLoginFailure menambah hitungan gagal secara atomik. Hitungan mulai dari 1 lagi jika
+// kegagalan terakhir lebih lama dari lockout; mencapai maxAttempts berarti dikunci selama lockout.
+//
+//go:noinline
+func RecordLoginFailure(scope, key string, maxAttempts int, lockout time.Duration) (*models.LoginThrottle, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	t := models.LoginThrottle{Scope: scope, Key: key}
+	err := database.PSQL.QueryRowContext(ctx, `
+		INSERT INTO login_throttles AS t (scope, key, failures, last_failure_at, locked_until)
+		VALUES ($1, $2, 1, NOW(), CASE WHEN 1 >= $3 THEN NOW() + make_interval(secs => $4::float8) END)
+		ON CONFLICT (scope, key) DO UPDATE
+		SET failures = CASE WHEN t.last_failure_at >= NOW() - make_interval(secs => $4::float8)
+		                    THEN t.failures + 1 ELSE 1 END,
+		    last_failure_at = NOW(),
+		    locked_until = CASE
+		        WHEN (CASE WHEN t.last_failure_at >= NOW() - make_interval(secs => $4::float8)
+		                   THEN t.failures + 1 ELSE 1 END) >= $3
+		        THEN NOW() + make_interval(secs => $4::float8)
+		        ELSE t.locked_until END
+		RETURNING failures, last_failure_at, locked_until
+	`, scope, key, maxAttempts, lockout.Seconds()).Scan(&t.Failures, &t.LastFailureAt, &t.LockedUntil)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ClearLoginThrottle menghapus hitungan gagal (mis. setelah login berhasil).
+//
+//go:noinline
+func ClearLoginThrottle(scope, key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := database.PSQL.ExecContext(ctx, `DELETE FROM login_throttles WHERE scope = $1 AND key = $2`, scope, key)
+	return err
+}
+
+// ClearAccountLockout membuka kunci semua identifier milik user (email dan NIM); mengembalikan jumlah baris yang dihapus.
+//
+//go:noinline
+func ClearAccountLockout(userID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := database.PSQL.ExecContext(ctx, `
+		DELETE FROM login_throttles t
+		USING users u
+		LEFT JOIN students s ON s.user_id = u.id
+		WHERE u.id::text = $1 AND t.scope = 'account'
+		  AND t.key IN ('email:' || LOWER(u.email), 'nim:' || LOWER(s.student_id))
+	`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// DeleteStaleLoginThrottles menghapus entri yang sudah tidak dikunci dan kegagalan terakhirnya lebih lama dari window.
+//
+//go:noinline
+func DeleteStaleLoginThrottles(window time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := database.PSQL.ExecContext(ctx, `
+		DELETE FROM login_throttles
+		WHERE last_failure_at < NOW() - make_interval(secs => $1::float8)
+		  AND (locked_until IS NULL OR locked_until < NOW())
+	`, window.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
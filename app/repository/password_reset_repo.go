@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/database"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// ErrResetTokenInvalid: token reset tidak ada, sudah dipakai, atau expired
+var ErrResetTokenInvalid = errors.New("invalid or expired reset token")
+
+// FindUserForPasswordReset mencari user berdasarkan email atau NIM; nil jika tidak ada.
+//
+//go:noinline
+func FindUserForPasswordReset(identifier string, byNIM bool) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	query := `SELECT id, email, is_active FROM users WHERE LOWER(email) = LOWER($1)`
+	if byNIM {
+		query = `
+			SELECT u.id, u.email, u.is_active
+			FROM users u
+			JOIN students s ON s.user_id = u.id
+			WHERE s.student_id = $1
+		`
+	}
+
+	var user models.User
+	err := database.PSQL.QueryRowContext(ctx, query, identifier).Scan(&user.ID, &user.Email, &user.IsActive)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreatePasswordResetToken menyimpan token baru; token lama user yang belum dipakai ikut dibatalkan.
+//
+//go:noinline
+func CreatePasswordResetToken(rt *models.PasswordResetToken) error {
+	tx, err := database.PSQL.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		UPDATE password_reset_tokens SET used_at = NOW()
+		WHERE user_id = $1 AND used_at IS NULL
+	`, rt.UserID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO password_reset_tokens (id, user_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+	`, rt.ID, rt.UserID, rt.TokenHash, rt.ExpiresAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// ResetPasswordWithToken memakai token (sekali pakai), mengganti password, dan mencabut semua sesi user
+// dalam satu transaksi. Mengembalikan user ID pemilik token.
+//
+//go:noinline
+func ResetPasswordWithToken(tokenHash, passwordHash string) (string, error) {
+	tx, err := database.PSQL.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var userID string
+	err = tx.QueryRow(`
+		UPDATE password_reset_tokens SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+		RETURNING user_id::text
+	`, tokenHash).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrResetTokenInvalid
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := setPassword(tx, userID, passwordHash, ""); err != nil {
+		return "", err
+	}
+	return userID, tx.Commit()
+}
+
+// ChangeUserPassword mengganti password dan mencabut sesi lain milik user; sesi keepFamilyID
+// (perangkat yang sedang dipakai, boleh kosong) tetap berlaku.
+//
+//go:noinline
+func ChangeUserPassword(userID, passwordHash, keepFamilyID string) error {
+	tx, err := database.PSQL.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := setPassword(tx, userID, passwordHash, keepFamilyID); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// setPassword: password baru membatalkan refresh token (kecuali keepFamilyID) dan token reset yang masih terbuka.
+// password_changed_at ikut diperbarui sehingga access token yang sudah terbit ditolak AuthRequired.
+func setPassword(tx *sql.Tx, userID, passwordHash, keepFamilyID string) error {
+	res, err := tx.Exec(`
+		UPDATE users SET password_hash = $1, password_changed_at = NOW(), updated_at = NOW()
+		WHERE id::text = $2
+	`, passwordHash, userID)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return errors.New("user not found")
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE refresh_tokens SET revoked_at = NOW()
+		WHERE user_id::text = $1 AND revoked_at IS NULL AND family_id::text <> $2
+	`, userID, keepFamilyID); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		UPDATE password_reset_tokens SET used_at = NOW()
+		WHERE user_id::text = $1 AND used_at IS NULL
+	`, userID)
+	return err
+}
+
+// GetPasswordChangedAt: kapan password user terakhir diganti / direset; zero time jika belum pernah.
+//
+//go:noinline
+func GetPasswordChangedAt(userID string) (time.Time, error) {
+	var changedAt sql.NullTime
+	err := database.PSQL.QueryRow(`SELECT password_changed_at FROM users WHERE id::text = $1`, userID).Scan(&changedAt)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return changedAt.Time, nil
+}
+
+// DeleteExpiredPasswordResetTokens menghapus token reset yang sudah expired.
+func DeleteExpiredPasswordResetTokens() (int64, error) {
+	res, err := database.PSQL.Exec(`DELETE FROM password_reset_tokens WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// GetResetTokenUserID mengembalikan pemilik token reset yang masih berlaku (belum dipakai, belum expired).
+//
+//go:noinline
+func GetResetTokenUserID(tokenHash string) (string, error) {
+	var userID string
+	err := database.PSQL.QueryRow(`
+		SELECT user_id::text FROM password_reset_tokens
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > NOW()
+	`, tokenHash).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", ErrResetTokenInvalid
+	}
+	return userID, err
+}
+
+// GetUserPasswordIdentifiers: username, email, dan NIM (jika mahasiswa) yang tidak boleh dipakai sebagai password.
+//
+//go:noinline
+func GetUserPasswordIdentifiers(userID string) ([]string, error) {
+	var username, email string
+	var nim sql.NullString
+	err := database.PSQL.QueryRow(`
+		SELECT u.username, u.email, s.student_id
+		FROM users u
+		LEFT JOIN students s ON s.user_id = u.id
+		WHERE u.id::text = $1
+	`, userID).Scan(&username, &email, &nim)
+	if err != nil {
+		return nil, err
+	}
+	return []string{username, email, nim.String}, nil
+}
+
+// RehashUserPassword mengganti hash dengan cost baru tanpa mencabut sesi; tidak menimpa jika
+// password sudah diganti sejak oldHash dibaca.
+//
+//go:noinline
+func RehashUserPassword(userID, oldHash, newHash string) error {
+	_, err := database.PSQL.Exec(`
+		UPDATE users SET password_hash = $1
+		WHERE id::text = $2 AND password_hash = $3
+	`, newHash, userID, oldHash)
+	return err
+}
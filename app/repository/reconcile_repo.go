@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/database"
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// field dokumen achievements yang dibaca rekonsiliasi
+type achievementMongoStateDoc struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	StudentID string             `bson:"studentId"`
+	Status    string             `bson:"status"`
+	CreatedAt time.Time          `bson:"createdAt"`
+}
+
+var achievementMongoStateProjection = bson.M{"_id": 1, "studentId": 1, "status": 1, "createdAt": 1}
+
+func (d achievementMongoStateDoc) state() models.AchievementMongoState {
+	return models.AchievementMongoState{
+		MongoID:   d.ID.Hex(),
+		StudentID: d.StudentID,
+		Status:    d.Status,
+		CreatedAt: d.CreatedAt,
+	}
+}
+
+// ListAchievementMongoStates mengambil id, studentId, status dan createdAt semua dokumen achievements.
+//
+//go:noinline
+func ListAchievementMongoStates() ([]models.AchievementMongoState, error) {
+	collection := database.MongoDB.Collection("achievements")
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	opts := options.Find().SetProjection(achievementMongoStateProjection)
+	cursor, err := collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var out []models.AchievementMongoState
+	for cursor.Next(ctx) {
+		var doc achievementMongoStateDoc
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+		out = append(out, doc.state())
+	}
+	return out, cursor.Err()
+}
+
+// GetAchievementMongoState: seperti ListAchievementMongoStates untuk satu dokumen; nil jika tidak ada.
+//
+//go:noinline
+func GetAchievementMongoState(mongoID string) (*models.AchievementMongoState, error) {
+	objID, err := primitive.ObjectIDFromHex(mongoID)
+	if err != nil {
+		return nil, err
+	}
+
+	collection := database.MongoDB.Collection("achievements")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var doc achievementMongoStateDoc
+	opts := options.FindOne().SetProjection(achievementMongoStateProjection)
+	err = collection.FindOne(ctx, bson.M{"_id": objID}, opts).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state := doc.state()
+	return &state, nil
+}
+
+// ListAchievementReferences mengambil semua row achievement_references (termasuk yang deleted).
+//
+//go:noinline
+func ListAchievementReferences() ([]models.AchievementReference, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	rows, err := database.PSQL.QueryContext(ctx, `
+		SELECT id, student_id, mongo_achievement_id, status, created_at, updated_at
+		FROM achievement_references
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []models.AchievementReference
+	for rows.Next() {
+		var r models.AchievementReference
+		if err := rows.Scan(&r.ID, &r.StudentID, &r.MongoAchievementID, &r.Status, &r.CreatedAt, &r.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// InsertMissingReference membuat reference untuk dokumen Mongo yang belum punya row di Postgres.
+//
+//go:noinline
+func InsertMissingReference(studentID, mongoID, status string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := database.PSQL.ExecContext(ctx, `
+		INSERT INTO achievement_references
+		(id, student_id, mongo_achievement_id, status, created_at, updated_at, deleted_at)
+		VALUES (uuid_generate_v4(), $1, $2, $3, NOW(), NOW(), CASE WHEN $4 THEN NOW() END)
+		ON CONFLICT (mongo_achievement_id) DO NOTHING
+	`, studentID, mongoID, status, status == "deleted")
+	return err
+}
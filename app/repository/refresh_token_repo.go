@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/database"
+	"errors"
+)
+
+// ErrRefreshTokenReused: token sudah pernah dirotasi / dicabut tapi dipakai lagi.
+var ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+
+// CreateRefreshToken menyimpan refresh token baru (hash saja).
+func CreateRefreshToken(rt *models.RefreshToken) error {
+	_, err := database.PSQL.Exec(`
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, device, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+	`, rt.ID, rt.UserID, rt.FamilyID, rt.TokenHash, rt.Device, rt.ExpiresAt)
+	return err
+}
+
+// GetRefreshTokenByHash mengambil refresh token berdasarkan hash-nya.
+func GetRefreshTokenByHash(tokenHash string) (*models.RefreshToken, error) {
+	var rt models.RefreshToken
+	err := database.PSQL.QueryRow(`
+		SELECT id, user_id, family_id, token_hash, device, expires_at, created_at, revoked_at, replaced_by
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`, tokenHash).Scan(
+		&rt.ID, &rt.UserID, &rt.FamilyID, &rt.TokenHash, &rt.Device,
+		&rt.ExpiresAt, &rt.CreatedAt, &rt.RevokedAt, &rt.ReplacedBy,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rt, nil
+}
+
+// RotateRefreshToken menandai token lama sebagai sudah dipakai (replaced_by = token baru)
+// dan menyimpan token baru dalam satu transaksi. Jika token lama ternyata sudah
+// dirotasi/dicabut (request paralel), dikembalikan ErrRefreshTokenReused.
+func RotateRefreshToken(oldID string, next *models.RefreshToken) error {
+	tx, err := database.PSQL.Begin()
+	if err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(`
+		UPDATE refresh_tokens
+		SET revoked_at = NOW(), replaced_by = $2
+		WHERE id = $1 AND revoked_at IS NULL
+	`, oldID, next.ID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if rows == 0 {
+		tx.Rollback()
+		return ErrRefreshTokenReused
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, device, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+	`, next.ID, next.UserID, next.FamilyID, next.TokenHash, next.Device, next.ExpiresAt)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RevokeRefreshTokenFamily mencabut semua refresh token dalam satu family (satu sesi perangkat).
+func RevokeRefreshTokenFamily(familyID string) error {
+	if familyID == "" {
+		return errors.New("family id is empty")
+	}
+
+	_, err := database.PSQL.Exec(`
+		UPDATE refresh_tokens
+		SET revoked_at = NOW()
+		WHERE family_id = $1 AND revoked_at IS NULL
+	`, familyID)
+	return err
+}
+
+// DeleteExpiredRefreshTokens menghapus refresh token yang sudah expired.
+func DeleteExpiredRefreshTokens() (int64, error) {
+	res, err := database.PSQL.Exec(`DELETE FROM refresh_tokens WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
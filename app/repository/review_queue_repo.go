@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/database"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// GetReviewQueue mengambil prestasi berstatus submitted milik mahasiswa bimbingan lecturerID,
+// urut dari yang paling lama diajukan. programStudy dan achType opsional ("" = semua).
+// Dokumen Mongo diambil sekali untuk semua item (bukan per item).
+//
+//go:noinline
+func GetReviewQueue(lecturerID, programStudy, achType string) ([]models.ReviewQueueItem, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := database.PSQL.QueryContext(ctx, `
+		SELECT ar.id, ar.mongo_achievement_id, s.id, u.full_name, s.student_id,
+		       COALESCE(s.program_study, ''), COALESCE(ar.submitted_at, ar.updated_at)
+		FROM achievement_references ar
+		JOIN students s ON s.id = ar.student_id
+		JOIN users u ON u.id = s.user_id
+		WHERE s.advisor_id = $1
+		  AND ar.status = 'submitted'
+		  AND ($2::text = '' OR s.program_study = $2::text)
+		ORDER BY COALESCE(ar.submitted_at, ar.updated_at) ASC, ar.id
+	`, lecturerID, programStudy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []models.ReviewQueueItem
+	var objIDs []primitive.ObjectID
+	for rows.Next() {
+		var it models.ReviewQueueItem
+		if err := rows.Scan(&it.ReferenceID, &it.MongoAchievementID, &it.StudentID, &it.StudentName,
+			&it.NIM, &it.ProgramStudy, &it.SubmittedAt); err != nil {
+			return nil, err
+		}
+		objID, err := primitive.ObjectIDFromHex(it.MongoAchievementID)
+		if err != nil {
+			continue // reference rusak, dilaporkan oleh reconcile
+		}
+		items = append(items, it)
+		objIDs = append(objIDs, objID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return []models.ReviewQueueItem{}, nil
+	}
+
+	match := bson.M{"_id": bson.M{"$in": objIDs}}
+	if achType != "" {
+		match["achievementType"] = achType
+	}
+	cursor, err := database.MongoDB.Collection("achievements").Aggregate(ctx, []bson.M{
+		{"$match": match},
+		{"$project": bson.M{
+			"title":           1,
+			"achievementType": 1,
+			"details":         1,
+			"attachmentCount": bson.M{"$size": bson.M{"$ifNull": []any{"$attachments", []any{}}}},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	type docSummary struct {
+		ID              primitive.ObjectID `bson:"_id"`
+		Title           string             `bson:"title"`
+		AchievementType string             `bson:"achievementType"`
+		Details         map[string]any     `bson:"details"`
+		AttachmentCount int                `bson:"attachmentCount"`
+	}
+	docs := map[string]docSummary{}
+	for cursor.Next(ctx) {
+		var d docSummary
+		if err := cursor.Decode(&d); err != nil {
+			return nil, err
+		}
+		docs[d.ID.Hex()] = d
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	// pertahankan urutan dari Postgres; item tanpa dokumen (atau tidak lolos filter tipe) dibuang
+	now := time.Now()
+	out := make([]models.ReviewQueueItem, 0, len(items))
+	for _, it := range items {
+		d, ok := docs[it.MongoAchievementID]
+		if !ok {
+			continue
+		}
+		it.Title = d.Title
+		it.AchievementType = d.AchievementType
+		it.Details = d.Details
+		it.AttachmentCount = d.AttachmentCount
+		it.AgeSeconds = int64(now.Sub(it.SubmittedAt).Seconds())
+		out = append(out, it)
+	}
+	return out, nil
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"UAS_GO/config"
+	"sync"
+	"time"
+)
+
+// Cache nama role + permission per role ID, dan override permission per user ID, di memori
+// proses supaya AuthRequired dan Authorize tidak menjalankan query ke Postgres di setiap request.
+//
+//	PERMISSION_CACHE_TTL  umur entri (Go duration, default 1m; "0" = tanpa cache)
+//
+// Perubahan lewat API role / override user langsung meng-invalidate entri terkait di proses
+// ini; instance lain mengikuti paling lambat setelah TTL.
+const defaultPermissionCacheTTL = time.Minute
+
+// generations: nomor versi per key (dan untuk seluruh cache). Invalidasi menaikkan nomornya;
+// hasil load yang dimulai sebelum invalidasi tidak disimpan karena datanya bisa sudah basi.
+// Dipakai di bawah mutex cache pemiliknya.
+type generations struct {
+	all  uint64
+	keys map[string]uint64
+}
+
+type generation struct{ all, key uint64 }
+
+func (g *generations) current(key string) generation {
+	return generation{all: g.all, key: g.keys[key]}
+}
+
+// bump: tanpa argumen = semua key.
+func (g *generations) bump(keys ...string) {
+	if len(keys) == 0 || g.keys == nil {
+		g.all++
+		g.keys = map[string]uint64{}
+	}
+	for _, k := range keys {
+		g.keys[k]++
+	}
+}
+
+type roleAccess struct {
+	name     string
+	perms    map[string]bool
+	loadedAt time.Time
+}
+
+var roleCache = struct {
+	sync.RWMutex
+	entries map[string]*roleAccess
+	gen     generations
+}{entries: map[string]*roleAccess{}}
+
+// PermissionCacheTTL membaca PERMISSION_CACHE_TTL; nilai tidak valid memakai default.
+func PermissionCacheTTL() time.Duration {
+	raw := config.GetEnv("PERMISSION_CACHE_TTL", "")
+	if raw == "" {
+		return defaultPermissionCacheTTL
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 {
+		return defaultPermissionCacheTTL
+	}
+	return d
+}
+
+func getRoleAccess(roleID string) (*roleAccess, error) {
+	ttl := PermissionCacheTTL()
+
+	roleCache.RLock()
+	entry, ok := roleCache.entries[roleID]
+	gen := roleCache.gen.current(roleID)
+	roleCache.RUnlock()
+	if ok && time.Since(entry.loadedAt) < ttl {
+		return entry, nil
+	}
+
+	name, err := GetRoleNameByID(roleID)
+	if err != nil {
+		return nil, err
+	}
+	perms, err := GetPermissionsByRoleID(roleID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry = &roleAccess{name: name, perms: make(map[string]bool, len(perms)), loadedAt: time.Now()}
+	for _, p := range perms {
+		entry.perms[p] = true
+	}
+	if ttl > 0 {
+		roleCache.Lock()
+		if roleCache.gen.current(roleID) == gen {
+			roleCache.entries[roleID] = entry
+		}
+		roleCache.Unlock()
+	}
+	return entry, nil
+}
+
+// CachedRoleName: seperti GetRoleNameByID, lewat cache.
+//
+//go:noinline
+func CachedRoleName(roleID string) (string, error) {
+	entry, err := getRoleAccess(roleID)
+	if err != nil {
+		return "", err
+	}
+	return entry.name, nil
+}
+
+// CachedRoleHasPermission: seperti RoleHasPermission, lewat cache.
+//
+//go:noinline
+func CachedRoleHasPermission(roleID, permission string) (bool, error) {
+	entry, err := getRoleAccess(roleID)
+	if err != nil {
+		return false, err
+	}
+	return entry.perms[permission], nil
+}
+
+// InvalidateRoleCache membuang entri cache untuk role yang disebut; tanpa argumen = semua role.
+func InvalidateRoleCache(roleIDs ...string) {
+	roleCache.Lock()
+	defer roleCache.Unlock()
+
+	roleCache.gen.bump(roleIDs...)
+	if len(roleIDs) == 0 {
+		roleCache.entries = map[string]*roleAccess{}
+		return
+	}
+	for _, id := range roleIDs {
+		delete(roleCache.entries, id)
+	}
+}
+
+type userOverrides struct {
+	effects  map[string]string // permission -> grant / deny
+	loadedAt time.Time
+}
+
+var userPermissionCache = struct {
+	sync.RWMutex
+	entries map[string]*userOverrides
+	gen     generations
+}{entries: map[string]*userOverrides{}}
+
+// CachedUserPermissionEffect: effect override permission untuk user ("grant", "deny", atau "" jika tidak ada).
+//
+//go:noinline
+func CachedUserPermissionEffect(userID, permission string) (string, error) {
+	ttl := PermissionCacheTTL()
+
+	userPermissionCache.RLock()
+	entry, ok := userPermissionCache.entries[userID]
+	gen := userPermissionCache.gen.current(userID)
+	userPermissionCache.RUnlock()
+	if ok && time.Since(entry.loadedAt) < ttl {
+		return entry.effects[permission], nil
+	}
+
+	overrides, err := GetUserPermissionOverrides(userID)
+	if err != nil {
+		return "", err
+	}
+	entry = &userOverrides{effects: make(map[string]string, len(overrides)), loadedAt: time.Now()}
+	for _, o := range overrides {
+		entry.effects[o.Permission] = o.Effect
+	}
+	if ttl > 0 {
+		userPermissionCache.Lock()
+		if userPermissionCache.gen.current(userID) == gen {
+			userPermissionCache.entries[userID] = entry
+		}
+		userPermissionCache.Unlock()
+	}
+	return entry.effects[permission], nil
+}
+
+// InvalidateUserPermissionCache membuang override user yang disebut; tanpa argumen = semua user.
+func InvalidateUserPermissionCache(userIDs ...string) {
+	userPermissionCache.Lock()
+	defer userPermissionCache.Unlock()
+
+	userPermissionCache.gen.bump(userIDs...)
+	if len(userIDs) == 0 {
+		userPermissionCache.entries = map[string]*userOverrides{}
+		return
+	}
+	for _, id := range userIDs {
+		delete(userPermissionCache.entries, id)
+	}
+}
@@ -0,0 +1,399 @@
+package repository
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/database"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrRoleNotFound = errors.New("role not found")
+	ErrRoleExists   = errors.New("role already exists")
+	ErrRoleInUse    = errors.New("role is still assigned to users")
+)
+
+// roleError menerjemahkan duplicate key / FK / id bukan UUID / baris tidak ditemukan.
+func roleError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code {
+		case "23505":
+			return ErrRoleExists
+		case "23503":
+			return ErrRoleInUse
+		case "22P02":
+			return ErrRoleNotFound
+		}
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrRoleNotFound
+	}
+	return err
+}
+
+const roleSelect = `
+	SELECT r.id, r.name, COALESCE(r.description, ''), r.is_system, r.created_at,
+	       ARRAY(SELECT p.name FROM role_permissions rp JOIN permissions p ON p.id = rp.permission_id
+	             WHERE rp.role_id = r.id ORDER BY p.name),
+	       (SELECT COUNT(*) FROM user_roles ur WHERE ur.role_id = r.id)
+	FROM roles r
+`
+
+func scanRole(row interface{ Scan(...any) error }) (models.Role, error) {
+	var r models.Role
+	err := row.Scan(&r.ID, &r.Name, &r.Description, &r.IsSystem, &r.CreatedAt, pq.Array(&r.Permissions), &r.UserCount)
+	if r.Permissions == nil {
+		r.Permissions = []string{}
+	}
+	return r, err
+}
+
+// ListRoles mengambil semua role beserta permission dan jumlah user-nya.
+//
+//go:noinline
+func ListRoles() ([]models.Role, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := database.PSQL.QueryContext(ctx, roleSelect+` ORDER BY r.name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.Role{}
+	for rows.Next() {
+		r, err := scanRole(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+//go:noinline
+func GetRoleByID(id string) (*models.Role, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	r, err := scanRole(database.PSQL.QueryRowContext(ctx, roleSelect+` WHERE r.id = $1`, id))
+	if err != nil {
+		return nil, roleError(err)
+	}
+	return &r, nil
+}
+
+// CreateRole membuat role baru (bukan role sistem) sekaligus memberi permission awalnya.
+// Baris audit ditulis di transaksi yang sama; audit.RoleID diisi id role baru.
+//
+//go:noinline
+func CreateRole(req models.RoleRequest, audit models.RBACAuditEntry) (*models.Role, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := database.PSQL.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id string
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO roles (name, description) VALUES ($1, $2) RETURNING id`,
+		req.Name, req.Description,
+	).Scan(&id)
+	if err != nil {
+		return nil, roleError(err)
+	}
+
+	if len(req.Permissions) > 0 {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO role_permissions (role_id, permission_id)
+			SELECT $1, id FROM permissions WHERE name = ANY($2)
+			ON CONFLICT DO NOTHING
+		`, id, pq.Array(req.Permissions))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	audit.RoleID = &id
+	if err := insertRBACAudit(ctx, tx, &audit); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return GetRoleByID(id)
+}
+
+// UpdateRole mengubah nama dan deskripsi role; audit nil = tidak ada perubahan yang dicatat.
+//
+//go:noinline
+func UpdateRole(id string, req models.RoleRequest, audit *models.RBACAuditEntry) (*models.Role, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := database.PSQL.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx,
+		`UPDATE roles SET name = $2, description = $3 WHERE id = $1 RETURNING id`,
+		id, req.Name, req.Description,
+	).Scan(&id)
+	if err != nil {
+		return nil, roleError(err)
+	}
+	if audit != nil {
+		if err := insertRBACAudit(ctx, tx, audit); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	InvalidateRoleCache(id)
+	return GetRoleByID(id)
+}
+
+// DeleteRole menghapus role non-sistem; role_permissions ikut terhapus lewat FK.
+// Role yang masih dipakai user ditolak oleh FK users.role_id / user_roles.role_id (ErrRoleInUse).
+//
+//go:noinline
+func DeleteRole(id string, audit models.RBACAuditEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := database.PSQL.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM roles WHERE id = $1 AND NOT is_system`, id)
+	if err != nil {
+		return roleError(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrRoleNotFound
+	}
+	if err := insertRBACAudit(ctx, tx, &audit); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	InvalidateRoleCache(id)
+	return nil
+}
+
+//go:noinline
+func ListPermissions() ([]models.Permission, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := database.PSQL.QueryContext(ctx, `
+		SELECT id, name, resource, action, COALESCE(description, '')
+		FROM permissions
+		ORDER BY name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.Permission{}
+	for rows.Next() {
+		var p models.Permission
+		if err := rows.Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, rows.Err()
+}
+
+// GrantRolePermissions memberi permission ke role; mengembalikan nama yang benar-benar baru ditambahkan.
+// Setiap nama baru dicatat sebagai satu baris audit (salinan audit dengan Permission terisi) di transaksi yang sama.
+//
+//go:noinline
+func GrantRolePermissions(roleID string, names []string, audit models.RBACAuditEntry) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := database.PSQL.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		WITH ins AS (
+			INSERT INTO role_permissions (role_id, permission_id)
+			SELECT $1, id FROM permissions WHERE name = ANY($2)
+			ON CONFLICT DO NOTHING
+			RETURNING permission_id
+		)
+		SELECT p.name FROM ins JOIN permissions p ON p.id = ins.permission_id
+		ORDER BY p.name
+	`, roleID, pq.Array(names))
+	if err != nil {
+		return nil, grantError(err)
+	}
+
+	added := []string{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		added = append(added, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, grantError(err)
+	}
+
+	for _, name := range added {
+		e := audit
+		e.Permission = &name
+		if err := insertRBACAudit(ctx, tx, &e); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	InvalidateRoleCache(roleID)
+	return added, nil
+}
+
+// grantError: pelanggaran FK role_id berarti role tidak ada
+func grantError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return ErrRoleNotFound
+	}
+	return roleError(err)
+}
+
+// RevokeRolePermission mencabut satu permission; false jika role memang tidak memilikinya.
+// Baris audit hanya ditulis (di transaksi yang sama) bila permission benar-benar dicabut.
+//
+//go:noinline
+func RevokeRolePermission(roleID, name string, audit models.RBACAuditEntry) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tx, err := database.PSQL.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		DELETE FROM role_permissions rp
+		USING permissions p
+		WHERE rp.permission_id = p.id AND rp.role_id = $1 AND p.name = $2
+	`, roleID, name)
+	if err != nil {
+		return false, roleError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n == 0 {
+		return false, nil
+	}
+	if err := insertRBACAudit(ctx, tx, &audit); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	InvalidateRoleCache(roleID)
+	return true, nil
+}
+
+// InsertRBACAudit menambah satu baris ke log audit RBAC (tabel append-only).
+//
+//go:noinline
+func InsertRBACAudit(e *models.RBACAuditEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return insertRBACAudit(ctx, database.PSQL, e)
+}
+
+// insertRBACAudit dipakai langsung oleh perubahan role agar baris audit ikut transaksinya.
+func insertRBACAudit(ctx context.Context, q interface {
+	QueryRowContext(context.Context, string, ...any) *sql.Row
+}, e *models.RBACAuditEntry) error {
+	var changes any
+	if len(e.Changes) > 0 {
+		b, err := json.Marshal(e.Changes)
+		if err != nil {
+			return err
+		}
+		changes = string(b)
+	}
+
+	return q.QueryRowContext(ctx, `
+		INSERT INTO rbac_audit_log
+		(action, role_id, role_name, permission, target_user_id, actor_user_id, actor_role, changes)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`, e.Action, e.RoleID, e.RoleName, e.Permission, e.TargetUserID, e.ActorUserID, e.ActorRole, changes).
+		Scan(&e.ID, &e.CreatedAt)
+}
+
+// ListRBACAudit mengambil log audit RBAC terbaru dulu; roleID kosong = semua role.
+//
+//go:noinline
+func ListRBACAudit(roleID string, limit int) ([]models.RBACAuditEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := database.PSQL.QueryContext(ctx, `
+		SELECT id, action, role_id, role_name, permission, target_user_id,
+		       actor_user_id, actor_role, changes, created_at
+		FROM rbac_audit_log
+		WHERE ($1 = '' OR role_id::text = $1)
+		ORDER BY id DESC
+		LIMIT $2
+	`, roleID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.RBACAuditEntry{}
+	for rows.Next() {
+		var e models.RBACAuditEntry
+		var changes []byte
+		if err := rows.Scan(&e.ID, &e.Action, &e.RoleID, &e.RoleName, &e.Permission, &e.TargetUserID,
+			&e.ActorUserID, &e.ActorRole, &changes, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		if len(changes) > 0 {
+			if err := json.Unmarshal(changes, &e.Changes); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
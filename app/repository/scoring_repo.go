@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/database"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrScoringRuleNotFound = errors.New("scoring rule not found")
+	ErrScoringRuleExists   = errors.New("scoring rule with the same criteria already exists")
+)
+
+const scoringRuleColumns = `id, achievement_type, competition_level, rank, participation, points, created_at, updated_at`
+
+func scanScoringRule(row interface{ Scan(...any) error }) (models.ScoringRule, error) {
+	var r models.ScoringRule
+	err := row.Scan(&r.ID, &r.AchievementType, &r.CompetitionLevel, &r.Rank, &r.Participation,
+		&r.Points, &r.CreatedAt, &r.UpdatedAt)
+	return r, err
+}
+
+// scoringRuleError menerjemahkan unique violation (kriteria sama) dan baris tidak ditemukan.
+func scoringRuleError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+		return ErrScoringRuleExists
+	}
+	if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+		return ErrAchievementTypeNotFound
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrScoringRuleNotFound
+	}
+	return err
+}
+
+// ListScoringRules mengambil seluruh rubrik (tabelnya kecil, pencocokan dilakukan di service).
+//
+//go:noinline
+func ListScoringRules() ([]models.ScoringRule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rows, err := database.PSQL.QueryContext(ctx, `
+		SELECT `+scoringRuleColumns+`
+		FROM scoring_rules
+		ORDER BY achievement_type, created_at, id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rules := []models.ScoringRule{}
+	for rows.Next() {
+		r, err := scanScoringRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+//go:noinline
+func CreateScoringRule(req models.ScoringRuleRequest) (*models.ScoringRule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	row := database.PSQL.QueryRowContext(ctx, `
+		INSERT INTO scoring_rules (achievement_type, competition_level, rank, participation, points)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+scoringRuleColumns,
+		req.AchievementType, req.CompetitionLevel, req.Rank, req.Participation, req.Points)
+
+	r, err := scanScoringRule(row)
+	if err != nil {
+		return nil, scoringRuleError(err)
+	}
+	return &r, nil
+}
+
+//go:noinline
+func UpdateScoringRule(id string, req models.ScoringRuleRequest) (*models.ScoringRule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	row := database.PSQL.QueryRowContext(ctx, `
+		UPDATE scoring_rules
+		SET achievement_type = $2,
+		    competition_level = $3,
+		    rank = $4,
+		    participation = $5,
+		    points = $6,
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+scoringRuleColumns,
+		id, req.AchievementType, req.CompetitionLevel, req.Rank, req.Participation, req.Points)
+
+	r, err := scanScoringRule(row)
+	if err != nil {
+		return nil, scoringRuleError(err)
+	}
+	return &r, nil
+}
+
+//go:noinline
+func DeleteScoringRule(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	res, err := database.PSQL.ExecContext(ctx, `DELETE FROM scoring_rules WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrScoringRuleNotFound
+	}
+	return nil
+}
+
+// GetScoringPolicy mengambil kebijakan rubrik (satu baris, dibuat oleh migrasi).
+//
+//go:noinline
+func GetScoringPolicy() (*models.ScoringPolicy, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var p models.ScoringPolicy
+	err := database.PSQL.QueryRowContext(ctx,
+		`SELECT mode, max_override_percent, updated_at FROM scoring_policy`,
+	).Scan(&p.Mode, &p.MaxOverridePercent, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+//go:noinline
+func UpdateScoringPolicy(req models.ScoringPolicyRequest) (*models.ScoringPolicy, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var p models.ScoringPolicy
+	err := database.PSQL.QueryRowContext(ctx, `
+		INSERT INTO scoring_policy (id, mode, max_override_percent, updated_at)
+		VALUES (TRUE, $1, $2, NOW())
+		ON CONFLICT (id) DO UPDATE
+		SET mode = EXCLUDED.mode,
+		    max_override_percent = EXCLUDED.max_override_percent,
+		    updated_at = NOW()
+		RETURNING mode, max_override_percent, updated_at
+	`, req.Mode, req.MaxOverridePercent).Scan(&p.Mode, &p.MaxOverridePercent, &p.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
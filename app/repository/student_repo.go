@@ -72,6 +72,36 @@ func GetAllStudents(advisorId string, q string) ([]map[string]any, error) {
 	return out, nil
 }
 
+// GetStudentIDsInScope: id mahasiswa bimbingan advisorID (kosong = semua dosen)
+// dan/atau dari programStudy tertentu (kosong = semua prodi).
+//
+//go:noinline
+func GetStudentIDsInScope(advisorID, programStudy string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := database.PSQL.QueryContext(ctx, `
+		SELECT id
+		FROM students
+		WHERE ($1::text = '' OR advisor_id::text = $1::text)
+		  AND ($2::text = '' OR program_study = $2::text)
+	`, advisorID, programStudy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
 // GetStudentByID returns a single student (raw json object) or nil if not found
 func GetStudentByID(id string) (map[string]any, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
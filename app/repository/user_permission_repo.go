@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/database"
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrUserNotFound       = errors.New("user not found")
+	ErrPermissionNotFound = errors.New("permission not found")
+)
+
+// GetUserPermissionOverrides mengambil semua override permission milik user, urut nama permission.
+//
+//go:noinline
+func GetUserPermissionOverrides(userID string) ([]models.UserPermission, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	rows, err := database.PSQL.QueryContext(ctx, `
+		SELECT up.user_id, p.name, up.effect, up.reason, up.granted_by, up.created_at, up.updated_at
+		FROM user_permissions up
+		JOIN permissions p ON p.id = up.permission_id
+		WHERE up.user_id::text = $1
+		ORDER BY p.name
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := []models.UserPermission{}
+	for rows.Next() {
+		var up models.UserPermission
+		if err := rows.Scan(&up.UserID, &up.Permission, &up.Effect, &up.Reason, &up.GrantedBy,
+			&up.CreatedAt, &up.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, up)
+	}
+	return out, rows.Err()
+}
+
+// SetUserPermission membuat / mengganti override satu permission untuk user.
+//
+//go:noinline
+func SetUserPermission(userID, permission, effect string, reason, grantedBy *string) (*models.UserPermission, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	up := models.UserPermission{UserID: userID, Permission: permission, Effect: effect, Reason: reason, GrantedBy: grantedBy}
+	err := database.PSQL.QueryRowContext(ctx, `
+		INSERT INTO user_permissions (user_id, permission_id, effect, reason, granted_by)
+		SELECT $1, p.id, $3, $4, $5 FROM permissions p WHERE p.name = $2
+		ON CONFLICT (user_id, permission_id) DO UPDATE
+		SET effect = EXCLUDED.effect, reason = EXCLUDED.reason,
+		    granted_by = EXCLUDED.granted_by, updated_at = NOW()
+		RETURNING created_at, updated_at
+	`, userID, permission, effect, reason, grantedBy).Scan(&up.CreatedAt, &up.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// SELECT kosong: nama permission tidak dikenal
+		return nil, ErrPermissionNotFound
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && (pqErr.Code == "23503" || pqErr.Code == "22P02") {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	InvalidateUserPermissionCache(userID)
+	return &up, nil
+}
+
+// DeleteUserPermission menghapus override; false jika user tidak punya override untuk permission tsb.
+//
+//go:noinline
+func DeleteUserPermission(userID, permission string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res, err := database.PSQL.ExecContext(ctx, `
+		DELETE FROM user_permissions up
+		USING permissions p
+		WHERE up.permission_id = p.id AND up.user_id::text = $1 AND p.name = $2
+	`, userID, permission)
+	if err != nil {
+		return false, err
+	}
+	n, _ := res.RowsAffected()
+	if n > 0 {
+		InvalidateUserPermissionCache(userID)
+	}
+	return n > 0, nil
+}
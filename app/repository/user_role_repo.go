@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/database"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// ErrPrimaryRoleRemoval: role utama (users.role_id) hanya bisa diganti lewat role_id, tidak dilepas
+var ErrPrimaryRoleRemoval = errors.New("the primary role cannot be removed; change role_id first")
+
+// UserRolesChange: hasil UpdateUserRoles. Added / Removed hanya berisi role tambahan yang benar-benar berubah.
+type UserRolesChange struct {
+	User    *models.User
+	Added   []string
+	Removed []string
+}
+
+type rowsQuerier interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+}
+
+func scanRoleIDs(rows *sql.Rows, err error) ([]string, error) {
+	if err != nil {
+		return nil, grantError(err)
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, grantError(err)
+	}
+	return ids, nil
+}
+
+func userRoleIDs(q rowsQuerier, userID string) ([]string, error) {
+	return scanRoleIDs(q.Query(`
+		SELECT ur.role_id::text
+		FROM user_roles ur
+		JOIN users u ON u.id = ur.user_id
+		WHERE ur.user_id::text = $1
+		ORDER BY ur.role_id = u.role_id DESC, ur.created_at, ur.role_id
+	`, userID))
+}
+
+// GetUserRoleIDs mengambil semua role ID user, role utama lebih dulu.
+//
+//go:noinline
+func GetUserRoleIDs(userID string) ([]string, error) {
+	return userRoleIDs(database.PSQL, userID)
+}
+
+// UpdateUserRoles mengganti role utama (roleID, kosong = tetap) lalu menambah / melepas role tambahan
+// dalam satu transaksi. Perubahan yang menyisakan nol admin aktif ditolak dengan ErrLastAdmin.
+//
+//go:noinline
+func UpdateUserRoles(id, roleID string, add, remove []string) (*UserRolesChange, error) {
+	tx, err := database.PSQL.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	admins, err := lockActiveAdmins(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// trigger users_sync_primary_role menukar role utama lama dengan yang baru di user_roles
+	var row *sql.Row
+	if roleID != "" {
+		row = tx.QueryRow(`UPDATE users SET role_id = $1, updated_at = NOW()
+			  WHERE id::text = $2
+			  RETURNING id, email, role_id, is_active, created_at, updated_at`, roleID, id)
+	} else {
+		row = tx.QueryRow(`UPDATE users SET updated_at = NOW()
+			  WHERE id::text = $1
+			  RETURNING id, email, role_id, is_active, created_at, updated_at`, id)
+	}
+	var user models.User
+	if err := row.Scan(&user.ID, &user.Email, &user.RoleID, &user.IsActive, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("user not found")
+		}
+		return nil, grantError(err)
+	}
+
+	change := &UserRolesChange{User: &user, Added: []string{}, Removed: []string{}}
+	if len(add) > 0 {
+		change.Added, err = scanRoleIDs(tx.Query(`
+			INSERT INTO user_roles (user_id, role_id)
+			SELECT $1, r::uuid FROM unnest($2::text[]) AS r
+			ON CONFLICT DO NOTHING
+			RETURNING role_id::text
+		`, user.ID, pq.Array(add)))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(remove) > 0 {
+		for _, r := range remove {
+			if r == user.RoleID {
+				return nil, ErrPrimaryRoleRemoval
+			}
+		}
+		change.Removed, err = scanRoleIDs(tx.Query(`
+			DELETE FROM user_roles
+			WHERE user_id = $1 AND role_id::text = ANY($2)
+			RETURNING role_id::text
+		`, user.ID, pq.Array(remove)))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := ensureAdminRemains(tx, admins); err != nil {
+		return nil, err
+	}
+	if user.RoleIDs, err = userRoleIDs(tx, user.ID); err != nil {
+		return nil, err
+	}
+	return change, tx.Commit()
+}
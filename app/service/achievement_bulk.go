@@ -0,0 +1,400 @@
+package service
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/helper"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// batas item per request bulk
+const maxBulkItems = 500
+
+// bulkTarget: item yang lolos validasi dan siap ditulis
+type bulkTarget struct {
+	idx  int // index di results
+	ref  models.AchievementReference
+	next string
+}
+
+// prepareBulk memeriksa semua item dengan query batch (reference, relasi dosen wali)
+// lalu workflow per item. validate(i) mengembalikan pesan jika isi item tidak valid.
+func prepareBulk(lecturerID string, roles []string, action string, ids []string, validate func(i int) string) ([]models.BulkItemResult, []bulkTarget, error) {
+	results := make([]models.BulkItemResult, len(ids))
+	seen := map[string]bool{}
+	var lookup []string
+
+	for i, id := range ids {
+		results[i].ID = id
+		switch {
+		case id == "":
+			setBulkResult(&results[i], models.BulkResultInvalid, "id is required")
+		case seen[id]:
+			setBulkResult(&results[i], models.BulkResultInvalid, "duplicate id")
+		default:
+			seen[id] = true
+			if msg := validate(i); msg != "" {
+				setBulkResult(&results[i], models.BulkResultInvalid, msg)
+				continue
+			}
+			lookup = append(lookup, id)
+		}
+	}
+	if len(lookup) == 0 {
+		return results, nil, nil
+	}
+
+	refs, err := repository.GetAchievementReferencesByMongoIDs(lookup)
+	if err != nil {
+		return nil, nil, err
+	}
+	refByMongoID := map[string]models.AchievementReference{}
+	var studentIDs []string
+	for _, r := range refs {
+		refByMongoID[r.MongoAchievementID] = r
+		studentIDs = append(studentIDs, r.StudentID)
+	}
+
+	advisees := map[string]bool{}
+	if len(studentIDs) > 0 {
+		advisees, err = repository.GetAdviseeStudentSet(lecturerID, studentIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var targets []bulkTarget
+	for i := range results {
+		if results[i].Result != "" {
+			continue
+		}
+		ref, ok := refByMongoID[results[i].ID]
+		if !ok {
+			setBulkResult(&results[i], models.BulkResultNotFound, "Achievement reference not found")
+			continue
+		}
+		if !advisees[ref.StudentID] {
+			setBulkResult(&results[i], models.BulkResultForbidden, "You are not the academic advisor for this student")
+			continue
+		}
+
+		next, err := NextAchievementStatus(action, ref.Status, roles...)
+		if err != nil {
+			te := err.(*TransitionError)
+			if te.RoleDenied {
+				setBulkResult(&results[i], models.BulkResultForbidden, fmt.Sprintf("You are not allowed to %s this achievement", action))
+				continue
+			}
+			setBulkResult(&results[i], models.BulkResultWrongStatus, fmt.Sprintf("Cannot %s achievement with status %s", action, ref.Status))
+			results[i].CurrentStatus = ref.Status
+			results[i].AllowedNextStates = te.AllowedNext
+			continue
+		}
+		targets = append(targets, bulkTarget{idx: i, ref: ref, next: next})
+	}
+
+	return results, targets, nil
+}
+
+// applyBulk menulis target ke Postgres (satu query), event audit (satu INSERT), lalu Mongo (satu BulkWrite).
+// Reference yang dokumen Mongo-nya gagal di-update dikembalikan ke status semula.
+func applyBulk(
+	c *fiber.Ctx,
+	results []models.BulkItemResult,
+	targets []bulkTarget,
+	updateRefs func(targets []bulkTarget) (map[string]bool, error),
+	mongoSet func(t bulkTarget) bson.M,
+	event func(t bulkTarget) models.AchievementEvent,
+) error {
+	if len(targets) == 0 {
+		return nil
+	}
+
+	updated, err := updateRefs(targets)
+	if err != nil {
+		return err
+	}
+
+	sets := map[string]bson.M{}
+	var written []bulkTarget
+	for _, t := range targets {
+		if !updated[t.ref.ID] {
+			// status berubah oleh request lain di antara pengecekan dan update
+			setBulkResult(&results[t.idx], models.BulkResultWrongStatus, "Achievement status changed, please reload")
+			continue
+		}
+		sets[t.ref.MongoAchievementID] = mongoSet(t)
+		written = append(written, t)
+	}
+
+	// event audit ditulis sebelum Mongo; jika gagal, semua reference dikembalikan
+	events := make([]models.AchievementEvent, 0, len(written))
+	for _, t := range written {
+		events = append(events, event(t))
+	}
+	if len(events) > 0 {
+		if err := recordAchievementEvents(c, events); err != nil {
+			for _, t := range written {
+				restoreBulkReference(t, err)
+				setBulkResult(&results[t.idx], models.BulkResultError, "Failed to record achievement history")
+			}
+			return nil
+		}
+	}
+
+	failed := repository.BulkUpdateAchievementsMongo(sets)
+
+	var reverted []models.AchievementEvent
+	for i, t := range written {
+		if mongoErr, ok := failed[t.ref.MongoAchievementID]; ok {
+			restoreBulkReference(t, mongoErr)
+			setBulkResult(&results[t.idx], models.BulkResultError, "Failed to update MongoDB")
+			reverted = append(reverted, revertedEvent(events[i]))
+			continue
+		}
+		setBulkResult(&results[t.idx], models.BulkResultOK, "")
+	}
+
+	if len(reverted) > 0 {
+		if err := recordAchievementEvents(c, reverted); err != nil {
+			log.Printf("CONSISTENCY: gagal mencatat %d event reverted setelah error Mongo: %v\n", len(reverted), err)
+		}
+	}
+	return nil
+}
+
+// restoreBulkReference mengembalikan reference target ke status sebelum bulk (kompensasi).
+func restoreBulkReference(t bulkTarget, cause error) {
+	ref := t.ref
+	if err := repository.RestoreReferenceState(&ref, t.next); err != nil {
+		log.Printf("CONSISTENCY: gagal mengembalikan reference %s setelah error (%v): %v\n", ref.ID, cause, err)
+	}
+}
+
+// bulkScore: poin akhir satu item bulk verify
+type bulkScore struct {
+	points     int
+	suggestion models.ScoreSuggestion
+	overridden bool
+}
+
+// scoreBulkTargets menilai target bulk verify dengan rubrik (dokumen dan rubrik diambil sekali).
+// Item yang poinnya tidak sesuai kebijakan ditandai invalid dan dikeluarkan dari target.
+func scoreBulkTargets(results []models.BulkItemResult, targets []bulkTarget, items []models.BulkVerifyItem) (map[int]bulkScore, []bulkTarget, error) {
+	scores := map[int]bulkScore{}
+	if len(targets) == 0 {
+		return scores, targets, nil
+	}
+
+	mongoIDs := make([]string, len(targets))
+	for i, t := range targets {
+		mongoIDs[i] = t.ref.MongoAchievementID
+	}
+	docs, err := repository.GetAchievementsByMongoIDs(mongoIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	rules, policy, err := loadScoring()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var kept []bulkTarget
+	for _, t := range targets {
+		doc, ok := docs[t.ref.MongoAchievementID]
+		if !ok {
+			setBulkResult(&results[t.idx], models.BulkResultNotFound, "Achievement not found")
+			continue
+		}
+		item := items[t.idx]
+		suggestion := suggestScore(rules, policy, doc.AchievementType, doc.Details)
+		points, overridden, err := resolvePoints(suggestion, item.Points, item.Justification)
+		if err != nil {
+			setBulkResult(&results[t.idx], models.BulkResultInvalid, err.Error())
+			continue
+		}
+		scores[t.idx] = bulkScore{points: points, suggestion: suggestion, overridden: overridden}
+		kept = append(kept, t)
+	}
+	return scores, kept, nil
+}
+
+func setBulkResult(r *models.BulkItemResult, result, message string) {
+	r.Result = result
+	r.Message = message
+}
+
+func bulkResponse(results []models.BulkItemResult) models.BulkResponse {
+	summary := map[string]int{}
+	for _, r := range results {
+		summary[r.Result]++
+	}
+	return models.BulkResponse{Summary: summary, Results: results}
+}
+
+// BulkVerifyAchievements godoc
+// @Summary      Bulk verify achievements
+// @Description  Dosen wali memverifikasi banyak prestasi sekaligus. Poin per item mengikuti rubrik (points kosong = poin rubrik, override dengan justification). Hasil dikembalikan per item: ok / forbidden / wrong_status / not_found / invalid / error.
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Param        body  body  models.BulkVerifyRequest  true  "Daftar id + points/justification (maks. 500)"
+// @Security     BearerAuth
+// @Success      200  {object}  models.BulkResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid JSON / jumlah item tidak valid"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (not a lecturer)"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /achievements/bulk/verify [post]
+func BulkVerifyAchievements(c *fiber.Ctx) error {
+	currentUserID := helper.GetUserID(c)
+	if currentUserID == "" {
+		return helper.Unauthorized(c, "Unauthorized")
+	}
+
+	lecturerID, err := repository.GetLecturerIDByUserID(currentUserID)
+	if err != nil {
+		return helper.Forbidden(c, "Lecturer profile not found")
+	}
+
+	var body models.BulkVerifyRequest
+	if err := c.BodyParser(&body); err != nil {
+		return helper.BadRequest(c, "Invalid JSON")
+	}
+	if len(body.Items) == 0 || len(body.Items) > maxBulkItems {
+		return helper.BadRequest(c, fmt.Sprintf("items must contain 1 to %d entries", maxBulkItems))
+	}
+
+	ids := make([]string, len(body.Items))
+	for i, it := range body.Items {
+		ids[i] = it.ID
+	}
+
+	results, targets, err := prepareBulk(lecturerID, currentRoles(c), AchievementActionVerify, ids, func(i int) string {
+		if body.Items[i].Points < 0 {
+			return "Points must be >= 0 (0 = use the scoring rubric)"
+		}
+		return ""
+	})
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+
+	scores, targets, err := scoreBulkTargets(results, targets, body.Items)
+	if err != nil {
+		return helper.InternalError(c, "Failed to load scoring rules")
+	}
+
+	now := time.Now()
+	err = applyBulk(c, results, targets,
+		func(targets []bulkTarget) (map[string]bool, error) {
+			refIDs := make([]string, len(targets))
+			for i, t := range targets {
+				refIDs[i] = t.ref.ID
+			}
+			return repository.BulkVerifyAchievementReferences(refIDs, currentUserID)
+		},
+		func(t bulkTarget) bson.M {
+			return bson.M{
+				"points":     scores[t.idx].points,
+				"verifiedAt": now,
+				"verifiedBy": currentUserID,
+				"updatedAt":  now,
+			}
+		},
+		func(t bulkTarget) models.AchievementEvent {
+			sc := scores[t.idx]
+			return verifiedEvent(t.ref.MongoAchievementID, t.ref.Status, t.next, sc.points, sc.suggestion, sc.overridden, body.Items[t.idx].Justification)
+		},
+	)
+	if err != nil {
+		return helper.InternalError(c, "Failed to update achievement references")
+	}
+
+	return helper.APIResponse(c, fiber.StatusOK, "Bulk verify finished", bulkResponse(results))
+}
+
+// BulkRejectAchievements godoc
+// @Summary      Bulk reject achievements
+// @Description  Dosen wali menolak banyak prestasi sekaligus (catatan per item). Hasil dikembalikan per item: ok / forbidden / wrong_status / not_found / invalid / error.
+// @Tags         Achievements
+// @Accept       json
+// @Produce      json
+// @Param        body  body  models.BulkRejectRequest  true  "Daftar id + note (maks. 500)"
+// @Security     BearerAuth
+// @Success      200  {object}  models.BulkResponse
+// @Failure      400  {object}  map[string]interface{}  "Invalid JSON / jumlah item tidak valid"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (not a lecturer)"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /achievements/bulk/reject [post]
+func BulkRejectAchievements(c *fiber.Ctx) error {
+	currentUserID := helper.GetUserID(c)
+	if currentUserID == "" {
+		return helper.Unauthorized(c, "Unauthorized")
+	}
+
+	lecturerID, err := repository.GetLecturerIDByUserID(currentUserID)
+	if err != nil {
+		return helper.Forbidden(c, "Lecturer profile not found")
+	}
+
+	var body models.BulkRejectRequest
+	if err := c.BodyParser(&body); err != nil {
+		return helper.BadRequest(c, "Invalid JSON")
+	}
+	if len(body.Items) == 0 || len(body.Items) > maxBulkItems {
+		return helper.BadRequest(c, fmt.Sprintf("items must contain 1 to %d entries", maxBulkItems))
+	}
+
+	ids := make([]string, len(body.Items))
+	for i, it := range body.Items {
+		ids[i] = it.ID
+		body.Items[i].Note = strings.TrimSpace(it.Note)
+	}
+
+	results, targets, err := prepareBulk(lecturerID, currentRoles(c), AchievementActionReject, ids, func(i int) string {
+		if body.Items[i].Note == "" {
+			return "Rejection note is required"
+		}
+		return ""
+	})
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+
+	now := time.Now()
+	err = applyBulk(c, results, targets,
+		func(targets []bulkTarget) (map[string]bool, error) {
+			notes := make(map[string]string, len(targets))
+			for _, t := range targets {
+				notes[t.ref.ID] = body.Items[t.idx].Note
+			}
+			return repository.BulkRejectAchievementReferences(notes, currentUserID)
+		},
+		func(t bulkTarget) bson.M {
+			return bson.M{
+				"rejectionNote": body.Items[t.idx].Note,
+				"verifiedAt":    now,
+				"verifiedBy":    currentUserID,
+				"updatedAt":     now,
+			}
+		},
+		func(t bulkTarget) models.AchievementEvent {
+			e := statusEvent(t.ref.MongoAchievementID, models.AchievementEventRejected, t.ref.Status, t.next)
+			e.Note = optionalString(body.Items[t.idx].Note)
+			return e
+		},
+	)
+	if err != nil {
+		return helper.InternalError(c, "Failed to update achievement references")
+	}
+
+	return helper.APIResponse(c, fiber.StatusOK, "Bulk reject finished", bulkResponse(results))
+}
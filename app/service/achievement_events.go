@@ -0,0 +1,137 @@
+package service
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/helper"
+	"log"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// aksi workflow di balik setiap jenis event; dipakai untuk mencatat role yang mengizinkannya
+var achievementEventActions = map[string]string{
+	models.AchievementEventUpdated:         AchievementActionUpdate,
+	models.AchievementEventAttachmentAdded: AchievementActionUpdate,
+	models.AchievementEventSubmitted:       AchievementActionSubmit,
+	models.AchievementEventVerified:        AchievementActionVerify,
+	models.AchievementEventRejected:        AchievementActionReject,
+	models.AchievementEventDeleted:         AchievementActionDelete,
+}
+
+// eventActorRole: role user yang mengizinkan aksi di balik event. Event tanpa aksi workflow
+// (mis. created) atau yang tidak cocok dengan role mana pun mencatat semua role user (dipisah koma).
+func eventActorRole(c *fiber.Ctx, eventType string) string {
+	roles := currentRoles(c)
+	if action, ok := achievementEventActions[eventType]; ok {
+		if role := AuthorizingRole(action, roles...); role != "" {
+			return role
+		}
+	}
+	return strings.Join(roles, ",")
+}
+
+// recordAchievementEvent menulis event audit; actor diambil dari JWT context.
+// ActorRole yang sudah diisi (mis. event reverted) tidak ditimpa.
+func recordAchievementEvent(c *fiber.Ctx, e models.AchievementEvent) error {
+	e.ActorUserID = optionalString(helper.GetUserID(c))
+	if e.ActorRole == nil {
+		e.ActorRole = optionalString(eventActorRole(c, e.EventType))
+	}
+
+	if err := repository.InsertAchievementEvent(&e); err != nil {
+		log.Printf("AUDIT: gagal mencatat event %s untuk prestasi %s: %v\n", e.EventType, e.MongoAchievementID, err)
+		return err
+	}
+	return nil
+}
+
+// recordAchievementEvents: seperti recordAchievementEvent, untuk banyak event dalam satu query.
+func recordAchievementEvents(c *fiber.Ctx, events []models.AchievementEvent) error {
+	actor := optionalString(helper.GetUserID(c))
+	for i := range events {
+		events[i].ActorUserID = actor
+		if events[i].ActorRole == nil {
+			events[i].ActorRole = optionalString(eventActorRole(c, events[i].EventType))
+		}
+	}
+
+	if err := repository.InsertAchievementEvents(events); err != nil {
+		log.Printf("AUDIT: gagal mencatat %d event bulk: %v\n", len(events), err)
+		return err
+	}
+	return nil
+}
+
+// achievementEventStep: langkah runWriteSteps yang menulis event audit, diletakkan sebelum langkah
+// terakhir. Event gagal ditulis berarti perubahan sebelumnya dikompensasi dan request gagal.
+// Log bersifat append-only, jadi jika langkah sesudahnya gagal kompensasinya berupa event "reverted".
+func achievementEventStep(c *fiber.Ctx, e models.AchievementEvent) writeStep {
+	e.ActorRole = optionalString(eventActorRole(c, e.EventType))
+	return writeStep{
+		name:    "audit event",
+		failMsg: "Failed to record achievement history",
+		do:      func() error { return recordAchievementEvent(c, e) },
+		undo:    func() error { return recordAchievementEvent(c, revertedEvent(e)) },
+	}
+}
+
+// revertedEvent: kompensasi event e (status dibalik)
+func revertedEvent(e models.AchievementEvent) models.AchievementEvent {
+	return models.AchievementEvent{
+		MongoAchievementID: e.MongoAchievementID,
+		EventType:          models.AchievementEventReverted,
+		ActorRole:          e.ActorRole,
+		FromStatus:         e.ToStatus,
+		ToStatus:           e.FromStatus,
+		Note:               optionalString(e.EventType + " dibatalkan"),
+	}
+}
+
+// statusEvent: event perubahan status from -> to
+func statusEvent(mongoID, eventType, from, to string) models.AchievementEvent {
+	return models.AchievementEvent{
+		MongoAchievementID: mongoID,
+		EventType:          eventType,
+		FromStatus:         optionalString(from),
+		ToStatus:           optionalString(to),
+	}
+}
+
+// diffAchievementFields: nilai lama vs baru untuk setiap field yang di-update.
+func diffAchievementFields(existing *models.Achievement, updates map[string]any) map[string]models.FieldChange {
+	changes := map[string]models.FieldChange{}
+	for field, value := range updates {
+		if field == "updatedAt" {
+			continue
+		}
+		changes[field] = models.FieldChange{From: achievementFieldValue(existing, field), To: value}
+	}
+	return changes
+}
+
+func achievementFieldValue(a *models.Achievement, field string) any {
+	switch field {
+	case "title":
+		return a.Title
+	case "description":
+		return a.Description
+	case "achievementType":
+		return a.AchievementType
+	case "details":
+		return a.Details
+	case "attachments":
+		return a.Attachments
+	case "tags":
+		return a.Tags
+	}
+	return nil
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
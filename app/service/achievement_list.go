@@ -0,0 +1,230 @@
+package service
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultAchievementLimit = 10
+	maxAchievementLimit     = 100
+)
+
+var achievementSortFields = []string{
+	models.AchievementSortCreatedAt,
+	models.AchievementSortUpdatedAt,
+	models.AchievementSortPoints,
+}
+
+var achievementStatuses = []string{
+	models.AchievementStatusDraft,
+	models.AchievementStatusSubmitted,
+	models.AchievementStatusVerified,
+	models.AchievementStatusRejected,
+	models.AchievementStatusDeleted,
+}
+
+// parseAchievementListQuery membaca query GET /achievements. Error berisi pesan untuk 400.
+func parseAchievementListQuery(c *fiber.Ctx) (models.AchievementListQuery, int, error) {
+	q := models.AchievementListQuery{
+		Types: queryList(c, "type"),
+		Tags:  queryList(c, "tags"),
+	}
+
+	q.SortBy = c.Query("sort", models.AchievementSortCreatedAt)
+	if !contains(achievementSortFields, q.SortBy) {
+		return q, 0, fmt.Errorf("sort must be one of: %s", strings.Join(achievementSortFields, ", "))
+	}
+	switch strings.ToLower(c.Query("order", "desc")) {
+	case "desc":
+		q.Desc = true
+	case "asc":
+	default:
+		return q, 0, errors.New("order must be asc or desc")
+	}
+
+	for _, s := range queryList(c, "status") {
+		s = strings.ToLower(s)
+		if !contains(achievementStatuses, s) {
+			return q, 0, fmt.Errorf("status must be one of: %s", strings.Join(achievementStatuses, ", "))
+		}
+		q.Statuses = append(q.Statuses, s)
+	}
+
+	from, err := parseDateQuery(c.Query("from"), false)
+	if err != nil {
+		return q, 0, fmt.Errorf("from: %w", err)
+	}
+	to, err := parseDateQuery(c.Query("to"), true)
+	if err != nil {
+		return q, 0, fmt.Errorf("to: %w", err)
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return q, 0, errors.New("from must be before to")
+	}
+	q.CreatedFrom, q.CreatedBefore = from, to
+
+	limit, err := positiveIntQuery(c, "limit", defaultAchievementLimit)
+	if err != nil {
+		return q, 0, err
+	}
+	q.Limit = int64(min(limit, maxAchievementLimit))
+
+	if raw := c.Query("cursor"); raw != "" {
+		cur, err := decodeAchievementCursor(raw)
+		if err != nil || cur.SortBy != q.SortBy || cur.Desc != q.Desc {
+			return q, 0, errors.New("invalid cursor for this sort order")
+		}
+		q.After = cur
+		return q, 0, nil
+	}
+
+	page, err := positiveIntQuery(c, "page", 1)
+	if err != nil {
+		return q, 0, err
+	}
+	q.Skip = int64(page-1) * q.Limit
+	return q, page, nil
+}
+
+// listScopeError: pemanggil tidak berhak atas cakupan yang diminta (403)
+type listScopeError struct{ msg string }
+
+func (e *listScopeError) Error() string { return e.msg }
+
+// achievementListScope menentukan mahasiswa yang prestasinya boleh dilihat pemanggil:
+// admin semua (nil), dosen_wali mahasiswa bimbingannya, selain itu hanya diri sendiri.
+// Filter studentId / programStudy hanya mempersempit cakupan tersebut.
+func achievementListScope(role, userID, studentID, programStudy string) ([]string, error) {
+	switch role {
+	case "admin":
+		if programStudy == "" {
+			if studentID == "" {
+				return nil, nil
+			}
+			return []string{studentID}, nil
+		}
+		ids, err := repository.GetStudentIDsInScope("", programStudy)
+		if err != nil {
+			return nil, err
+		}
+		return narrowStudentIDs(ids, studentID), nil
+
+	case "dosen_wali":
+		lecturerID, err := repository.GetLecturerIDByUserID(userID)
+		if err != nil {
+			return nil, &listScopeError{"Lecturer profile not found"}
+		}
+		ids, err := repository.GetStudentIDsInScope(lecturerID, programStudy)
+		if err != nil {
+			return nil, err
+		}
+		// tanpa filter prodi, studentId yang tidak ada di daftar pasti bukan bimbingan
+		if studentID != "" && programStudy == "" && !contains(ids, studentID) {
+			return nil, &listScopeError{"Student is not your advisee"}
+		}
+		return narrowStudentIDs(ids, studentID), nil
+	}
+
+	own, err := repository.GetStudentIDByUserID(userID)
+	if err != nil {
+		return nil, &listScopeError{"Student profile not found"}
+	}
+	if studentID != "" && studentID != own {
+		return nil, &listScopeError{"You can only list your own achievements"}
+	}
+	return []string{own}, nil
+}
+
+func narrowStudentIDs(ids []string, studentID string) []string {
+	if studentID == "" {
+		return ids
+	}
+	if contains(ids, studentID) {
+		return []string{studentID}
+	}
+	return []string{}
+}
+
+// queryList: nilai dipisah koma, boleh juga parameter berulang (?tags=a&tags=b)
+func queryList(c *fiber.Ctx, key string) []string {
+	var out []string
+	for _, raw := range c.Context().QueryArgs().PeekMulti(key) {
+		for _, v := range strings.Split(string(raw), ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				out = append(out, v)
+			}
+		}
+	}
+	return out
+}
+
+func positiveIntQuery(c *fiber.Ctx, key string, def int) (int, error) {
+	raw := c.Query(key)
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s must be a positive integer", key)
+	}
+	return n, nil
+}
+
+// parseDateQuery menerima YYYY-MM-DD atau RFC3339. Untuk batas akhir, tanggal saja berarti
+// sampai akhir hari tersebut (dikembalikan sebagai awal hari berikutnya, eksklusif).
+func parseDateQuery(raw string, end bool) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return nil, errors.New("must be a date (YYYY-MM-DD) or RFC3339 timestamp")
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+// encodeAchievementCursor membuat cursor dari item terakhir suatu halaman.
+func encodeAchievementCursor(q models.AchievementListQuery, last models.Achievement) string {
+	cur := models.AchievementCursor{SortBy: q.SortBy, Desc: q.Desc, ID: last.ID.Hex()}
+	switch q.SortBy {
+	case models.AchievementSortUpdatedAt:
+		cur.Time = last.UpdatedAt
+	case models.AchievementSortPoints:
+		cur.Points = last.Points
+	default:
+		cur.Time = last.CreatedAt
+	}
+	b, _ := json.Marshal(cur)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeAchievementCursor(raw string) (*models.AchievementCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	var cur models.AchievementCursor
+	if err := json.Unmarshal(b, &cur); err != nil {
+		return nil, err
+	}
+	if cur.ID == "" {
+		return nil, errors.New("cursor without id")
+	}
+	return &cur, nil
+}
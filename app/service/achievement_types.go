@@ -0,0 +1,443 @@
+package service
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/helper"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// validateAchievementDetails memvalidasi details terhadap schema achievementType.
+// Tipe tanpa schema tidak divalidasi; details kosong diperlakukan sebagai object kosong.
+func validateAchievementDetails(achType string, details any) ([]helper.FieldError, error) {
+	schema, err := repository.GetAchievementTypeSchema(achType)
+	if err != nil || schema == nil {
+		return nil, err
+	}
+	if details == nil {
+		details = map[string]any{}
+	}
+	return helper.ValidateJSONSchema(schema, normalizeBSON(details), "details"), nil
+}
+
+// fieldErrorResponse: 422 dengan daftar error per field
+func fieldErrorResponse(c *fiber.Ctx, message string, errs []helper.FieldError) error {
+	return helper.APIResponse(c, fiber.StatusUnprocessableEntity, message, fiber.Map{"errors": errs})
+}
+
+func detailsErrorResponse(c *fiber.Ctx, achType string, errs []helper.FieldError) error {
+	return fieldErrorResponse(c, fmt.Sprintf("details do not match the schema for achievement type %s", achType), errs)
+}
+
+// resolveAchievementType mencocokkan achievementType dengan katalog aktif (tanpa membedakan
+// huruf besar/kecil) dan mengembalikan kode bakunya.
+func resolveAchievementType(value any) (string, []helper.FieldError, error) {
+	s, ok := value.(string)
+	if !ok && value != nil {
+		return "", []helper.FieldError{{Field: "achievementType", Message: "must be of type string"}}, nil
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", []helper.FieldError{{Field: "achievementType", Message: "is required"}}, nil
+	}
+
+	types, err := repository.ListAchievementTypes(true)
+	if err != nil {
+		return "", nil, err
+	}
+	codes := make([]string, len(types))
+	for i, t := range types {
+		if strings.EqualFold(t.Code, s) {
+			return t.Code, nil, nil
+		}
+		codes[i] = t.Code
+	}
+	return "", []helper.FieldError{{Field: "achievementType", Message: "must be one of: " + strings.Join(codes, ", ")}}, nil
+}
+
+// normalizeBSON mengubah nilai hasil decode Mongo (primitive.D/M/A, DateTime)
+// ke bentuk JSON biasa agar bisa divalidasi dengan schema.
+func normalizeBSON(v any) any {
+	switch t := v.(type) {
+	case primitive.D:
+		m := make(map[string]any, len(t))
+		for _, e := range t {
+			m[e.Key] = normalizeBSON(e.Value)
+		}
+		return m
+	case primitive.M:
+		return normalizeBSON(map[string]any(t))
+	case map[string]any:
+		m := make(map[string]any, len(t))
+		for k, val := range t {
+			m[k] = normalizeBSON(val)
+		}
+		return m
+	case primitive.A:
+		return normalizeBSON([]any(t))
+	case []any:
+		out := make([]any, len(t))
+		for i, val := range t {
+			out[i] = normalizeBSON(val)
+		}
+		return out
+	case primitive.DateTime:
+		return t.Time().UTC().Format(time.RFC3339)
+	case time.Time:
+		return t.UTC().Format(time.RFC3339)
+	}
+	return v
+}
+
+// GetAchievementTypes godoc
+// @Summary      Achievement types & details schema
+// @Description  Katalog achievementType yang aktif (kode, label per bahasa, poin default) beserta JSON Schema field details, dipakai frontend untuk merender form
+// @Tags         Achievements
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{}  "envelope {status,message,data:[models.AchievementType]}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /achievement-types [get]
+func GetAchievementTypes(c *fiber.Ctx) error {
+	types, err := repository.ListAchievementTypes(true)
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "Success", types)
+}
+
+// AdminPutAchievementTypeSchema godoc
+// @Summary      Set details schema (admin)
+// @Description  Membuat / mengganti JSON Schema details untuk satu achievementType. Keyword yang didukung: type, properties, required, additionalProperties, enum, minLength, maxLength, pattern, format (date, date-time, email, uri), minimum, maximum, items, minItems, maxItems; anotasi title/description/default/examples/x-* disimpan untuk frontend.
+// @Tags         Admin - Achievement Types
+// @Accept       json
+// @Produce      json
+// @Param        type  path   string                               true  "Achievement type"
+// @Param        body  body   models.AchievementTypeSchemaRequest  true  "JSON Schema"
+// @Security     BearerAuth
+// @Success      200  {object}  models.AchievementTypeSchema
+// @Failure      400  {object}  map[string]interface{}  "Schema tidak valid / keyword tidak didukung"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh achievement-type:manage)"
+// @Failure      404  {object}  map[string]interface{}  "Achievement type tidak ada di katalog"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /admin/achievement-types/{type}/schema [put]
+func AdminPutAchievementTypeSchema(c *fiber.Ctx) error {
+	achType := strings.TrimSpace(c.Params("type"))
+	if achType == "" {
+		return helper.BadRequest(c, "achievement type is required")
+	}
+
+	var req models.AchievementTypeSchemaRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Invalid request body")
+	}
+	if req.Schema == nil {
+		return helper.BadRequest(c, "schema is required")
+	}
+	if req.Schema["type"] != "object" {
+		return helper.BadRequest(c, `schema.type must be "object"`)
+	}
+	if err := helper.CheckJSONSchema(req.Schema); err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
+
+	saved, err := repository.UpsertAchievementTypeSchema(achType, req.Schema)
+	if errors.Is(err, repository.ErrAchievementTypeNotFound) {
+		return helper.NotFound(c, err.Error())
+	}
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "achievement type schema saved", saved)
+}
+
+// AdminDeleteAchievementTypeSchema godoc
+// @Summary      Delete details schema (admin)
+// @Description  Menghapus schema details; details untuk tipe tersebut tidak lagi divalidasi
+// @Tags         Admin - Achievement Types
+// @Produce      json
+// @Param        type  path   string  true  "Achievement type"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{}  "envelope {status,message,data:null}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh achievement-type:manage)"
+// @Failure      404  {object}  map[string]interface{}  "Schema not found"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /admin/achievement-types/{type}/schema [delete]
+func AdminDeleteAchievementTypeSchema(c *fiber.Ctx) error {
+	deleted, err := repository.DeleteAchievementTypeSchema(c.Params("type"))
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	if !deleted {
+		return helper.NotFound(c, "achievement type schema not found")
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "achievement type schema deleted", nil)
+}
+
+var achievementTypeCodeRe = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// normalizeAchievementType merapikan request katalog dan mengembalikan pesan validasi.
+func normalizeAchievementType(req *models.AchievementTypeRequest, create bool) string {
+	if create {
+		req.Code = strings.ToLower(strings.TrimSpace(req.Code))
+		if !achievementTypeCodeRe.MatchString(req.Code) {
+			return "code must start with a letter and contain only a-z, 0-9 and _ (max. 50)"
+		}
+		if req.IsActive == nil {
+			active := true
+			req.IsActive = &active
+		}
+	}
+
+	labels := map[string]string{}
+	for lang, label := range req.Labels {
+		lang, label = strings.ToLower(strings.TrimSpace(lang)), strings.TrimSpace(label)
+		if lang != "" && label != "" {
+			labels[lang] = label
+		}
+	}
+	if len(labels) == 0 {
+		return "labels must contain at least one language"
+	}
+	req.Labels = labels
+
+	if req.DefaultPoints != nil && *req.DefaultPoints <= 0 {
+		return "default_points must be > 0"
+	}
+	return ""
+}
+
+func achievementTypeErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrAchievementTypeNotFound):
+		return helper.NotFound(c, err.Error())
+	case errors.Is(err, repository.ErrAchievementTypeExists):
+		return helper.Conflict(c, err.Error())
+	}
+	return helper.InternalError(c, err.Error())
+}
+
+// AdminListAchievementTypes godoc
+// @Summary      List achievement types (admin)
+// @Description  Seluruh katalog achievementType termasuk yang tidak aktif
+// @Tags         Admin - Achievement Types
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{}  "envelope {status,message,data:[models.AchievementType]}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh achievement-type:manage)"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /admin/achievement-types [get]
+func AdminListAchievementTypes(c *fiber.Ctx) error {
+	types, err := repository.ListAchievementTypes(false)
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "achievement types retrieved", types)
+}
+
+// AdminCreateAchievementType godoc
+// @Summary      Create achievement type (admin)
+// @Description  Menambah kode baru ke katalog achievementType
+// @Tags         Admin - Achievement Types
+// @Accept       json
+// @Produce      json
+// @Param        body  body   models.AchievementTypeRequest  true  "Achievement type"
+// @Security     BearerAuth
+// @Success      201  {object}  models.AchievementType
+// @Failure      400  {object}  map[string]interface{}  "Validation error"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh achievement-type:manage)"
+// @Failure      409  {object}  map[string]interface{}  "Kode sudah ada"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /admin/achievement-types [post]
+func AdminCreateAchievementType(c *fiber.Ctx) error {
+	var req models.AchievementTypeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Invalid request body")
+	}
+	if msg := normalizeAchievementType(&req, true); msg != "" {
+		return helper.BadRequest(c, msg)
+	}
+
+	t, err := repository.CreateAchievementType(req)
+	if err != nil {
+		return achievementTypeErrorResponse(c, err)
+	}
+	return helper.APIResponse(c, fiber.StatusCreated, "achievement type created", t)
+}
+
+// AdminUpdateAchievementType godoc
+// @Summary      Update achievement type (admin)
+// @Description  Mengubah label, status aktif, dan poin default. Kode tidak bisa diubah (gunakan remap). Tipe tidak aktif tidak bisa dipakai untuk prestasi baru.
+// @Tags         Admin - Achievement Types
+// @Accept       json
+// @Produce      json
+// @Param        code  path   string                         true  "Achievement type code"
+// @Param        body  body   models.AchievementTypeRequest  true  "Achievement type"
+// @Security     BearerAuth
+// @Success      200  {object}  models.AchievementType
+// @Failure      400  {object}  map[string]interface{}  "Validation error"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh achievement-type:manage)"
+// @Failure      404  {object}  map[string]interface{}  "Achievement type not found"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /admin/achievement-types/{code} [put]
+func AdminUpdateAchievementType(c *fiber.Ctx) error {
+	var req models.AchievementTypeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Invalid request body")
+	}
+	if msg := normalizeAchievementType(&req, false); msg != "" {
+		return helper.BadRequest(c, msg)
+	}
+
+	t, err := repository.UpdateAchievementType(c.Params("code"), req)
+	if err != nil {
+		return achievementTypeErrorResponse(c, err)
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "achievement type updated", t)
+}
+
+// AdminDeleteAchievementType godoc
+// @Summary      Delete achievement type (admin)
+// @Description  Menghapus kode dari katalog beserta schema dan rubriknya. Ditolak jika masih dipakai prestasi (nonaktifkan atau remap terlebih dahulu).
+// @Tags         Admin - Achievement Types
+// @Produce      json
+// @Param        code  path   string  true  "Achievement type code"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{}  "envelope {status,message,data:null}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh achievement-type:manage)"
+// @Failure      404  {object}  map[string]interface{}  "Achievement type not found"
+// @Failure      409  {object}  map[string]interface{}  "Masih dipakai prestasi"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /admin/achievement-types/{code} [delete]
+func AdminDeleteAchievementType(c *fiber.Ctx) error {
+	code := c.Params("code")
+
+	counts, err := repository.CountAchievementTypeValues()
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	if n := counts[code]; n > 0 {
+		return helper.Conflict(c, fmt.Sprintf("achievement type %s is still used by %d achievements", code, n))
+	}
+
+	if err := repository.DeleteAchievementType(code); err != nil {
+		return achievementTypeErrorResponse(c, err)
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "achievement type deleted", nil)
+}
+
+// ErrUnknownTypeCode: target remap tidak ada di katalog
+var ErrUnknownTypeCode = errors.New("unknown achievement type code")
+
+// RemapAchievementTypes mengganti nilai achievementType lama di MongoDB dengan kode katalog.
+// Nilai yang tidak ada di mappings dicocokkan otomatis dengan kode atau label katalog
+// (tanpa membedakan huruf besar/kecil). Dengan dryRun=true hanya laporan.
+func RemapAchievementTypes(mappings map[string]string, dryRun bool) (*models.TypeRemapReport, error) {
+	types, err := repository.ListAchievementTypes(false)
+	if err != nil {
+		return nil, err
+	}
+
+	known := map[string]bool{}
+	byKey := map[string]string{}
+	for _, t := range types {
+		known[t.Code] = true
+		byKey[strings.ToLower(t.Code)] = t.Code
+	}
+	for _, t := range types {
+		for _, label := range t.Labels {
+			key := strings.ToLower(strings.TrimSpace(label))
+			if _, taken := byKey[key]; !taken {
+				byKey[key] = t.Code
+			}
+		}
+	}
+	for from, to := range mappings {
+		if !known[to] {
+			return nil, fmt.Errorf("%w: %s (mapping %q)", ErrUnknownTypeCode, to, from)
+		}
+	}
+
+	counts, err := repository.CountAchievementTypeValues()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, 0, len(counts))
+	for v := range counts {
+		if !known[v] {
+			values = append(values, v)
+		}
+	}
+	sort.Strings(values)
+
+	report := &models.TypeRemapReport{DryRun: dryRun, Remapped: []models.TypeRemapEntry{}, Unmapped: []models.TypeRemapEntry{}}
+	for _, v := range values {
+		entry := models.TypeRemapEntry{From: v, Count: counts[v]}
+		if to, ok := mappings[v]; ok {
+			entry.To, entry.Source = to, "explicit"
+		} else if to, ok := byKey[strings.ToLower(strings.TrimSpace(v))]; ok && v != "" {
+			entry.To, entry.Source = to, "auto"
+		} else {
+			report.Unmapped = append(report.Unmapped, entry)
+			continue
+		}
+
+		if !dryRun {
+			n, err := repository.RemapAchievementTypeMongo(entry.From, entry.To)
+			if err != nil {
+				return nil, fmt.Errorf("remap %q -> %s: %w", entry.From, entry.To, err)
+			}
+			entry.Count = n
+		}
+		report.Remapped = append(report.Remapped, entry)
+	}
+	return report, nil
+}
+
+// AdminRemapAchievementTypes godoc
+// @Summary      Remap legacy achievement types (admin)
+// @Description  Mengganti nilai achievementType di MongoDB yang tidak ada di katalog. mappings opsional (nilai lama -> kode); sisanya dicocokkan otomatis dengan kode / label. Dengan dry_run=false perubahan ditulis.
+// @Tags         Admin - Achievement Types
+// @Accept       json
+// @Produce      json
+// @Param        dry_run  query  bool                     false  "Hanya laporan (default true)"
+// @Param        body     body   models.TypeRemapRequest  false  "Mapping eksplisit"
+// @Security     BearerAuth
+// @Success      200  {object}  models.TypeRemapReport
+// @Failure      400  {object}  map[string]interface{}  "Kode tujuan tidak ada di katalog"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh achievement-type:manage)"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /admin/achievement-types/remap [post]
+func AdminRemapAchievementTypes(c *fiber.Ctx) error {
+	var req models.TypeRemapRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return helper.BadRequest(c, "Invalid request body")
+		}
+	}
+
+	report, err := RemapAchievementTypes(req.Mappings, c.QueryBool("dry_run", true))
+	if errors.Is(err, ErrUnknownTypeCode) {
+		return helper.BadRequest(c, err.Error())
+	}
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "remap finished", report)
+}
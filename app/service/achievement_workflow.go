@@ -0,0 +1,224 @@
+package service
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/helper"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Aksi workflow prestasi
+const (
+	AchievementActionUpdate = "update"
+	AchievementActionSubmit = "submit"
+	AchievementActionVerify = "verify"
+	AchievementActionReject = "reject"
+	AchievementActionDelete = "delete"
+)
+
+// AchievementTransition: aksi yang boleh dilakukan dari status tertentu, oleh role tertentu.
+// To kosong berarti status tidak berubah (mis. edit isi prestasi).
+type AchievementTransition struct {
+	Action string
+	From   []string
+	To     string
+	Roles  []string
+}
+
+// AchievementWorkflow: satu-satunya sumber aturan status prestasi.
+// Handler tidak boleh membandingkan status sendiri; gunakan NextAchievementStatus.
+var AchievementWorkflow = []AchievementTransition{
+	{
+		Action: AchievementActionUpdate,
+		From:   []string{models.AchievementStatusDraft, models.AchievementStatusRejected},
+		Roles:  []string{"mahasiswa"},
+	},
+	{
+		Action: AchievementActionSubmit,
+		From:   []string{models.AchievementStatusDraft, models.AchievementStatusRejected},
+		To:     models.AchievementStatusSubmitted,
+		Roles:  []string{"mahasiswa"},
+	},
+	{
+		Action: AchievementActionVerify,
+		From:   []string{models.AchievementStatusSubmitted},
+		To:     models.AchievementStatusVerified,
+		Roles:  []string{"dosen_wali"},
+	},
+	{
+		Action: AchievementActionReject,
+		From:   []string{models.AchievementStatusSubmitted},
+		To:     models.AchievementStatusRejected,
+		Roles:  []string{"dosen_wali"},
+	},
+	{
+		Action: AchievementActionDelete,
+		From:   []string{models.AchievementStatusDraft},
+		To:     models.AchievementStatusDeleted,
+		Roles:  []string{"mahasiswa"},
+	},
+}
+
+// TransitionError: aksi tidak valid untuk status saat ini atau role saat ini.
+type TransitionError struct {
+	Action        string
+	CurrentStatus string
+	Role          string   // role pemanggil (dipisah koma jika lebih dari satu)
+	RoleDenied    bool     // status valid, tapi role tidak boleh melakukan aksi ini
+	AllowedNext   []string // status berikutnya yang bisa dicapai role ini dari CurrentStatus
+}
+
+func (e *TransitionError) Error() string {
+	if e.RoleDenied {
+		return fmt.Sprintf("role %q tidak boleh melakukan %s pada prestasi berstatus %s", e.Role, e.Action, e.CurrentStatus)
+	}
+	return fmt.Sprintf("aksi %s tidak diizinkan dari status %s", e.Action, e.CurrentStatus)
+}
+
+func contains(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(list, values []string) bool {
+	for _, v := range values {
+		if contains(list, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextAchievementStatus mengembalikan status tujuan aksi, atau *TransitionError.
+// Untuk aksi yang tidak mengubah status, status saat ini dikembalikan.
+// User dengan beberapa role boleh melakukan aksi jika salah satu rolenya diizinkan.
+func NextAchievementStatus(action, current string, roles ...string) (string, error) {
+	stateOK := false
+	for _, t := range AchievementWorkflow {
+		if t.Action != action || !contains(t.From, current) {
+			continue
+		}
+		stateOK = true
+		if !containsAny(t.Roles, roles) {
+			continue
+		}
+		if t.To == "" {
+			return current, nil
+		}
+		return t.To, nil
+	}
+
+	return "", &TransitionError{
+		Action:        action,
+		CurrentStatus: current,
+		Role:          strings.Join(roles, ","),
+		RoleDenied:    stateOK,
+		AllowedNext:   AllowedNextStatuses(current, roles...),
+	}
+}
+
+// AchievementTransitionFrom: status asal yang sah untuk aksi. Dipakai sebagai syarat UPDATE reference
+// supaya transisi yang statusnya berubah sejak dibaca (mis. verify dan reject bersamaan) tidak ikut tertulis.
+func AchievementTransitionFrom(action string) []string {
+	from := []string{}
+	for _, t := range AchievementWorkflow {
+		if t.Action != action {
+			continue
+		}
+		for _, s := range t.From {
+			if !contains(from, s) {
+				from = append(from, s)
+			}
+		}
+	}
+	return from
+}
+
+// staleTransitionError: *TransitionError jika runWriteSteps gagal karena UPDATE bersyarat
+// tidak mengenai reference (statusnya sudah berubah); nil untuk error lain.
+func staleTransitionError(err error, action string, roles []string) error {
+	var statusErr *repository.ReferenceStatusError
+	if !errors.As(err, &statusErr) {
+		return nil
+	}
+	return &TransitionError{
+		Action:        action,
+		CurrentStatus: statusErr.Current,
+		Role:          strings.Join(roles, ","),
+		AllowedNext:   AllowedNextStatuses(statusErr.Current, roles...),
+	}
+}
+
+// AuthorizingRole: role pemanggil (urutan roles) yang diizinkan melakukan aksi menurut AchievementWorkflow;
+// kosong jika tidak ada.
+func AuthorizingRole(action string, roles ...string) string {
+	for _, r := range roles {
+		for _, t := range AchievementWorkflow {
+			if t.Action == action && contains(t.Roles, r) {
+				return r
+			}
+		}
+	}
+	return ""
+}
+
+// AllowedNextStatuses: status yang bisa dicapai role dari status saat ini (tanpa aksi yang tidak mengubah status).
+func AllowedNextStatuses(current string, roles ...string) []string {
+	next := []string{}
+	for _, t := range AchievementWorkflow {
+		if t.To == "" || !contains(t.From, current) || !containsAny(t.Roles, roles) {
+			continue
+		}
+		if !contains(next, t.To) {
+			next = append(next, t.To)
+		}
+	}
+	return next
+}
+
+// transitionErrorResponse: 403 jika role tidak berhak, 409 + status yang diizinkan jika status tidak valid.
+func transitionErrorResponse(c *fiber.Ctx, err error) error {
+	te, ok := err.(*TransitionError)
+	if !ok {
+		return helper.InternalError(c, err.Error())
+	}
+	if te.RoleDenied {
+		return helper.Forbidden(c, fmt.Sprintf("You are not allowed to %s this achievement", te.Action))
+	}
+	return helper.APIResponse(c, fiber.StatusConflict,
+		fmt.Sprintf("Cannot %s achievement with status %s", te.Action, te.CurrentStatus),
+		fiber.Map{
+			"current_status":      te.CurrentStatus,
+			"allowed_next_states": te.AllowedNext,
+		})
+}
+
+// currentRole membaca role utama user dari JWT context.
+func currentRole(c *fiber.Ctx) string {
+	role, _ := c.Locals("role").(string)
+	return role
+}
+
+// currentRoles: semua role user (utama + tambahan).
+func currentRoles(c *fiber.Ctx) []string {
+	return helper.GetRoles(c)
+}
+
+// scopeRole: role dengan cakupan data terluas yang dipegang user (admin > dosen_wali > mahasiswa),
+// dipakai untuk menentukan data apa saja yang boleh dilihat.
+func scopeRole(c *fiber.Ctx) string {
+	for _, r := range []string{"admin", "dosen_wali", "mahasiswa"} {
+		if helper.HasRole(c, r) {
+			return r
+		}
+	}
+	return currentRole(c)
+}
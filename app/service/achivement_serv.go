@@ -5,14 +5,17 @@ import (
 	"UAS_GO/app/repository"
 	"UAS_GO/helper"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
@@ -20,43 +23,75 @@ var ErrNotFound = mongo.ErrNoDocuments
 
 // GetAllAchievements godoc
 // @Summary      List achievements
-// @Description  Mengambil daftar prestasi milik user yang sedang login.
+// @Description  Mengambil daftar prestasi sesuai role: admin semua prestasi, dosen wali prestasi mahasiswa bimbingannya, mahasiswa prestasi sendiri. Mendukung filter, urutan, dan pagination (page/limit atau cursor).
 // @Tags         Achievements
 // @Accept       json
 // @Produce      json
-// @Param        type       query   string  false  "Filter by achievement type"
+// @Param        studentId     query   string  false  "Filter mahasiswa (UUID), di dalam cakupan role"
+// @Param        programStudy  query   string  false  "Filter program studi (admin & dosen wali)"
+// @Param        type    query   string  false  "Filter achievementType (pisahkan dengan koma)"
+// @Param        status  query   string  false  "Filter status: draft, submitted, verified, rejected, deleted (pisahkan dengan koma)"
+// @Param        tags    query   string  false  "Hanya prestasi yang punya semua tag ini (pisahkan dengan koma)"
+// @Param        from    query   string  false  "createdAt mulai (YYYY-MM-DD atau RFC3339)"
+// @Param        to      query   string  false  "createdAt sampai (YYYY-MM-DD inklusif atau RFC3339)"
+// @Param        sort    query   string  false  "createdAt (default), updatedAt, points"
+// @Param        order   query   string  false  "desc (default) atau asc"
+// @Param        page    query   int     false  "Page number (default 1)"
+// @Param        limit   query   int     false  "Items per page (default 10, max. 100)"
+// @Param        cursor  query   string  false  "next_cursor dari halaman sebelumnya (page diabaikan)"
 // @Security     BearerAuth
-// @Success      200  {array}   models.Achievement
+// @Success      200  {object}  models.AchievementPage  "envelope {status,message,data:{page,limit,total,next_cursor,results}}"
+// @Failure      400  {object}  map[string]interface{}  "Query tidak valid"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
-// @Failure      403  {object}  map[string]interface{}  "Forbidden (not a student)"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (profil tidak ditemukan / studentId di luar cakupan)"
 // @Failure      500  {object}  map[string]interface{}  "Internal Server Error"
 // @Router       /achievements [get]
 func GetAllAchievements(c *fiber.Ctx) error {
-	achType := c.Query("type")
+	q, page, err := parseAchievementListQuery(c)
+	if err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
 
 	// Get authenticated user_id
 	currentUserID := helper.GetUserID(c)
 	if currentUserID == "" {
 		return helper.Unauthorized(c, "Unauthorized")
 	}
-	fmt.Println(currentUserID,"current user id")
-	// Resolve studentID from user_id
-	studentID, err := repository.GetStudentIDByUserID(currentUserID)
+
+	// cakupan data mengikuti role: admin semua, dosen wali bimbingan, mahasiswa milik sendiri
+	q.StudentIDs, err = achievementListScope(scopeRole(c), currentUserID, c.Query("studentId"), c.Query("programStudy"))
+	var scopeErr *listScopeError
+	if errors.As(err, &scopeErr) {
+		return helper.Forbidden(c, scopeErr.msg)
+	}
 	if err != nil {
-		return helper.Forbidden(c, "Student profile not found")
+		return helper.InternalError(c, err.Error())
 	}
 
-	data, err := repository.GetAllAchievements(studentID, achType)
+	// ambil satu item lebih untuk mengetahui apakah masih ada halaman berikutnya
+	limit := q.Limit
+	q.Limit++
+	data, total, err := repository.GetAllAchievements(q)
 	if err != nil {
 		return helper.InternalError(c, err.Error())
 	}
+	q.Limit = limit
 
-	return helper.APIResponse(c, 200, "Success", data)
+	result := models.AchievementPage{Page: page, Limit: int(limit), Total: total, Results: data}
+	if int64(len(data)) > limit {
+		result.Results = data[:limit]
+		result.NextCursor = encodeAchievementCursor(q, data[limit-1])
+	}
+	if result.Results == nil {
+		result.Results = []models.Achievement{}
+	}
+
+	return helper.APIResponse(c, 200, "Success", result)
 }
 
 // GetAchievementById godoc
 // @Summary      Get achievement detail
-// @Description  Mengambil detail prestasi berdasarkan ID Mongo
+// @Description  Mengambil detail prestasi berdasarkan ID Mongo. Hanya pemilik, dosen wali pemilik, atau admin.
 // @Tags         Achievements
 // @Accept       json
 // @Produce      json
@@ -64,11 +99,16 @@ func GetAllAchievements(c *fiber.Ctx) error {
 // @Security     BearerAuth
 // @Success      200  {object}  models.Achievement
 // @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{} "Bukan pemilik / dosen wali pemilik / admin"
 // @Failure      404  {object}  map[string]interface{} "Achievement not found"
+// @Failure      500  {object}  map[string]interface{} "error response"
 // @Router       /achievements/{id} [get]
 func GetAchievementById(c *fiber.Ctx) error {
 	id := c.Params("id")
 	data, err := repository.GetAchievementById(id)
+	if errors.Is(err, mongo.ErrNoDocuments) || errors.Is(err, primitive.ErrInvalidHex) {
+		return helper.NotFound(c, "Achievement not found")
+	}
 	if err != nil {
 		return helper.InternalError(c, err.Error())
 	}
@@ -81,11 +121,12 @@ func GetAchievementById(c *fiber.Ctx) error {
 // @Tags         Achievements
 // @Accept       json
 // @Produce      json
-// @Param        body  body   models.Achievement  true  "Achievement payload"
+// @Param        body  body   models.CreateAchievementRequest  true  "Achievement payload"
 // @Security     BearerAuth
 // @Success      201  {object}  map[string]interface{}  "Created"
 // @Failure      400  {object}  map[string]interface{}
 // @Failure      401  {object}  map[string]interface{}
+// @Failure      422  {object}  map[string]interface{}  "achievementType tidak ada di katalog / details tidak sesuai schema (data.errors: [{field,message}])"
 // @Router       /achievements [post]
 func CreateAchievement(c *fiber.Ctx) error {
 	// Parse body as map
@@ -154,6 +195,25 @@ func CreateAchievement(c *fiber.Ctx) error {
 		return helper.BadRequest(c, "title is required")
 	}
 
+	// achievementType harus ada di katalog aktif; disimpan dalam bentuk kode baku
+	achType, fieldErrs, err := resolveAchievementType(req.AchievementType)
+	if err != nil {
+		return helper.InternalError(c, "Failed to load achievement types")
+	}
+	if len(fieldErrs) > 0 {
+		return fieldErrorResponse(c, "invalid achievement type", fieldErrs)
+	}
+	req.AchievementType = achType
+
+	// details harus sesuai schema achievementType
+	fieldErrs, err = validateAchievementDetails(req.AchievementType, req.Details)
+	if err != nil {
+		return helper.InternalError(c, "Failed to load achievement type schema")
+	}
+	if len(fieldErrs) > 0 {
+		return detailsErrorResponse(c, req.AchievementType, fieldErrs)
+	}
+
 	// Force server-controlled fields
 	req.StudentID = studentID
 	now := time.Now()
@@ -166,15 +226,35 @@ func CreateAchievement(c *fiber.Ctx) error {
 		req.Attachments = []models.Attachment{}
 	}
 
-	// Insert into Mongo
-	mongoID, err := repository.AchievementInsertMongo(&req)
+	// Insert ke Mongo, event audit, lalu reference Postgres; dokumen Mongo dihapus lagi jika reference gagal
+	var mongoID primitive.ObjectID
+	created := func() models.AchievementEvent {
+		return statusEvent(mongoID.Hex(), models.AchievementEventCreated, "", models.AchievementStatusDraft)
+	}
+	err = runWriteSteps(
+		writeStep{
+			name:    "mongo insert",
+			failMsg: "Failed to create achievement",
+			do: func() (err error) {
+				mongoID, err = repository.AchievementInsertMongo(&req)
+				return err
+			},
+			undo: func() error { return repository.AchievementDeleteMongo(mongoID) },
+		},
+		writeStep{
+			name:    "audit event",
+			failMsg: "Failed to record achievement history",
+			do:      func() error { return recordAchievementEvent(c, created()) },
+			undo:    func() error { return recordAchievementEvent(c, revertedEvent(created())) },
+		},
+		writeStep{
+			name:    "reference insert",
+			failMsg: "Failed to create achievement reference",
+			do:      func() error { return repository.AchievementInsertReference(studentID, mongoID) },
+		},
+	)
 	if err != nil {
-		return helper.InternalError(c, "Failed to create achievement")
-	}
-
-	// Insert reference into Postgres
-	if err := repository.AchievementInsertReference(studentID, mongoID); err != nil {
-		return helper.InternalError(c, "Failed to create achievement reference")
+		return helper.InternalError(c, writeStepMessage(err))
 	}
 
 	return helper.APIResponse(
@@ -199,6 +279,8 @@ func CreateAchievement(c *fiber.Ctx) error {
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      403  {object}  map[string]interface{}  "Forbidden (not owner)"
 // @Failure      404  {object}  map[string]interface{}  "Achievement not found"
+// @Failure      409  {object}  map[string]interface{}  "Status tidak mengizinkan aksi ini (data: current_status, allowed_next_states)"
+// @Failure      422  {object}  map[string]interface{}  "achievementType tidak ada di katalog / details tidak sesuai schema (data.errors: [{field,message}])"
 // @Failure      500  {object}  map[string]interface{}  "error response"
 // @Router       /achievements/{id} [patch]
 func UpdateAchievement(c *fiber.Ctx) error {
@@ -273,19 +355,74 @@ func UpdateAchievement(c *fiber.Ctx) error {
 		return helper.BadRequest(c, "No updatable fields provided")
 	}
 
+	// hanya prestasi draft / rejected yang boleh diedit
+	ref, err := repository.GetAchievementReferenceByMongoID(id)
+	if err != nil {
+		return helper.NotFound(c, "Achievement reference not found")
+	}
+	if _, err := NextAchievementStatus(AchievementActionUpdate, ref.Status, currentRoles(c)...); err != nil {
+		return transitionErrorResponse(c, err)
+	}
+
+	// details divalidasi ulang jika details atau achievementType berubah
+	newType, typeChanged := reqMap["achievementType"]
+	newDetails, detailsChanged := reqMap["details"]
+	if typeChanged || detailsChanged {
+		achType := existing.AchievementType
+		if typeChanged {
+			code, fieldErrs, err := resolveAchievementType(newType)
+			if err != nil {
+				return helper.InternalError(c, "Failed to load achievement types")
+			}
+			if len(fieldErrs) > 0 {
+				return fieldErrorResponse(c, "invalid achievement type", fieldErrs)
+			}
+			achType = code
+			reqMap["achievementType"] = code
+		}
+		details := any(existing.Details)
+		if detailsChanged {
+			details = newDetails
+		}
+
+		fieldErrs, err := validateAchievementDetails(achType, details)
+		if err != nil {
+			return helper.InternalError(c, "Failed to load achievement type schema")
+		}
+		if len(fieldErrs) > 0 {
+			return detailsErrorResponse(c, achType, fieldErrs)
+		}
+	}
+
 	// set updatedAt
 	reqMap["updatedAt"] = time.Now()
 
-	// lakukan update partial
-	if err := repository.AchievementUpdateMongoMap(id, reqMap); err != nil {
-		// tangani ObjectID invalid
-		if err.Error() == "string is not a valid ObjectID" || err.Error() == "the provided hex string is not a valid ObjectID" {
-			return helper.NotFound(c, "Achievement not found: Invalid ID format")
-		}
-		if err == mongo.ErrNoDocuments {
-			return helper.NotFound(c, "Achievement not found")
+	// event audit (dihitung sebelum reqMap diubah repository), lalu update partial
+	event := models.AchievementEvent{
+		MongoAchievementID: id,
+		EventType:          models.AchievementEventUpdated,
+		Changes:            diffAchievementFields(existing, reqMap),
+	}
+	err = runWriteSteps(
+		achievementEventStep(c, event),
+		writeStep{
+			name:    "mongo update",
+			failMsg: "Failed to update achievement",
+			do:      func() error { return repository.AchievementUpdateMongoMap(id, reqMap) },
+		},
+	)
+	if err != nil {
+		var stepErr *writeStepError
+		if errors.As(err, &stepErr) && stepErr.Step == "mongo update" {
+			// tangani ObjectID invalid
+			if msg := stepErr.Err.Error(); msg == "string is not a valid ObjectID" || msg == "the provided hex string is not a valid ObjectID" {
+				return helper.NotFound(c, "Achievement not found: Invalid ID format")
+			}
+			if stepErr.Err == mongo.ErrNoDocuments {
+				return helper.NotFound(c, "Achievement not found")
+			}
 		}
-		return helper.InternalError(c, "Failed to update achievement")
+		return helper.InternalError(c, writeStepMessage(err))
 	}
 
 	return helper.APIResponse(c, fiber.StatusOK, "Achievement updated successfully", nil)
@@ -302,8 +439,9 @@ func UpdateAchievement(c *fiber.Ctx) error {
 // @Success      200  {object}  map[string]interface{}  "Achievement deleted (envelope)"
 // @Failure      400  {object}  map[string]interface{} "Bad request"
 // @Failure      401  {object}  map[string]interface{} "Unauthorized"
-// @Failure      403  {object}  map[string]interface{} "Forbidden (not owner / not draft)"
+// @Failure      403  {object}  map[string]interface{} "Forbidden (not owner)"
 // @Failure      404  {object}  map[string]interface{} "Achievement not found"
+// @Failure      409  {object}  map[string]interface{} "Status tidak mengizinkan aksi ini (data: current_status, allowed_next_states)"
 // @Failure      500  {object}  map[string]interface{} "error response"
 // @Router       /achievements/{id} [delete]
 func DeleteAchievement(c *fiber.Ctx) error {
@@ -340,21 +478,77 @@ func DeleteAchievement(c *fiber.Ctx) error {
 		return helper.InternalError(c, "Reference not found")
 	}
 
-	if ref.Status != "draft" {
-		return helper.BadRequest(c, "Only draft achievements can be deleted")
+	next, err := NextAchievementStatus(AchievementActionDelete, ref.Status, currentRoles(c)...)
+	if err != nil {
+		return transitionErrorResponse(c, err)
+	}
+
+	// 1) soft delete reference Postgres (pakai reference ID), 2) event audit, 3) soft delete Mongo.
+	// Jika Mongo gagal, status reference dikembalikan.
+	err = runWriteSteps(
+		writeStep{
+			name:    "reference soft delete",
+			failMsg: "Failed to delete achievement reference",
+			do: func() error {
+				return repository.AchievementSoftDeleteReference(ref.ID, AchievementTransitionFrom(AchievementActionDelete))
+			},
+			undo: func() error { return repository.RestoreReferenceState(ref, next) },
+		},
+		achievementEventStep(c, statusEvent(id, models.AchievementEventDeleted, ref.Status, next)),
+		writeStep{
+			name:    "mongo soft delete",
+			failMsg: "Failed to delete achievement in MongoDB",
+			do:      func() error { return repository.AchievementSoftDeleteMongo(id) },
+		},
+	)
+	if err != nil {
+		if te := staleTransitionError(err, AchievementActionDelete, currentRoles(c)); te != nil {
+			return transitionErrorResponse(c, te)
+		}
+		return helper.InternalError(c, writeStepMessage(err))
 	}
 
-	// 1) HAPUS Mongo DULU
-	if err := repository.AchievementSoftDeleteMongo(id); err != nil {
-		return helper.InternalError(c, "Failed to delete achievement in MongoDB")
+	return helper.APIResponse(c, fiber.StatusOK, "Achievement deleted successfully", nil)
+}
+
+// GetReviewQueue godoc
+// @Summary      Review queue dosen wali
+// @Description  Prestasi berstatus submitted milik mahasiswa bimbingan dosen yang login, urut dari yang paling lama menunggu, beserta poin saran rubrik (suggested_points).
+// @Tags         Achievements
+// @Produce      json
+// @Param        type          query  string  false  "Filter achievement type"
+// @Param        programStudy  query  string  false  "Filter program studi mahasiswa"
+// @Security     BearerAuth
+// @Success      200  {array}   models.ReviewQueueItem
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (not a lecturer)"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /achievements/review-queue [get]
+func GetReviewQueue(c *fiber.Ctx) error {
+	currentUserID := helper.GetUserID(c)
+	if currentUserID == "" {
+		return helper.Unauthorized(c, "Unauthorized")
+	}
+
+	lecturerID, err := repository.GetLecturerIDByUserID(currentUserID)
+	if err != nil {
+		return helper.Forbidden(c, "Lecturer profile not found")
 	}
 
-	// 2) HAPUS reference Postgres PAKAI reference ID
-	if err := repository.AchievementSoftDeleteReference(ref.ID); err != nil {
-		return helper.InternalError(c, "Failed to delete achievement reference")
+	items, err := repository.GetReviewQueue(lecturerID, c.Query("programStudy"), c.Query("type"))
+	if err != nil {
+		return helper.InternalError(c, err.Error())
 	}
 
-	return helper.APIResponse(c, fiber.StatusOK, "Achievement deleted successfully", nil)
+	rules, policy, err := loadScoring()
+	if err != nil {
+		return helper.InternalError(c, "Failed to load scoring rules")
+	}
+	for i := range items {
+		items[i].SuggestedPoints = suggestScore(rules, policy, items[i].AchievementType, items[i].Details).SuggestedPoints
+	}
+
+	return helper.APIResponse(c, fiber.StatusOK, "Success", items)
 }
 
 // SubmitAchievement godoc
@@ -366,10 +560,11 @@ func DeleteAchievement(c *fiber.Ctx) error {
 // @Param        id   path   string  true  "Mongo Achievement ID"
 // @Security     BearerAuth
 // @Success      200  {object}  map[string]interface{}  "Achievement submitted (envelope)"
-// @Failure      400  {object}  map[string]interface{} "Bad request (invalid ID / already submitted)"
+// @Failure      400  {object}  map[string]interface{} "Bad request (invalid ID)"
 // @Failure      401  {object}  map[string]interface{} "Unauthorized"
 // @Failure      403  {object}  map[string]interface{} "Forbidden (not owner)"
 // @Failure      404  {object}  map[string]interface{} "Achievement or reference not found"
+// @Failure      409  {object}  map[string]interface{} "Status tidak mengizinkan aksi ini (data: current_status, allowed_next_states)"
 // @Failure      500  {object}  map[string]interface{} "error response"
 // @Router       /achievements/{id}/submit [post]
 func SubmitAchievement(c *fiber.Ctx) error {
@@ -407,25 +602,37 @@ func SubmitAchievement(c *fiber.Ctx) error {
 		return helper.NotFound(c, "Achievement reference not found")
 	}
 
-	// tidak boleh submit ulang
-	if ref.Status == "submitted" || ref.Status == "verified" {
-		return helper.BadRequest(c, "Achievement already submitted")
-	}
-
-	// update MongoDB: hanya update updatedAt
-	updateMongo := map[string]any{
-		"updatedAt": time.Now(),
-	}
-
-	err = repository.AchievementUpdateMongoMap(id, updateMongo)
+	// draft / rejected -> submitted
+	next, err := NextAchievementStatus(AchievementActionSubmit, ref.Status, currentRoles(c)...)
 	if err != nil {
-		return helper.InternalError(c, "Failed to update achievement in MongoDB")
-	}
-
-	// update PostgreSQL: status + submitted_at
-	err = repository.UpdateReferenceStatusSubmitted(id)
+		return transitionErrorResponse(c, err)
+	}
+
+	// update PostgreSQL (status + submitted_at), event audit, lalu MongoDB (hanya updatedAt).
+	// Jika Mongo gagal, status reference dikembalikan.
+	err = runWriteSteps(
+		writeStep{
+			name:    "reference submit",
+			failMsg: "Failed to update achievement reference",
+			do: func() error {
+				return repository.UpdateReferenceStatusSubmitted(id, AchievementTransitionFrom(AchievementActionSubmit))
+			},
+			undo: func() error { return repository.RestoreReferenceState(ref, next) },
+		},
+		achievementEventStep(c, statusEvent(id, models.AchievementEventSubmitted, ref.Status, next)),
+		writeStep{
+			name:    "mongo touch",
+			failMsg: "Failed to update achievement in MongoDB",
+			do: func() error {
+				return repository.AchievementUpdateMongoMap(id, map[string]any{"updatedAt": time.Now()})
+			},
+		},
+	)
 	if err != nil {
-		return helper.InternalError(c, "Failed to update achievement reference")
+		if te := staleTransitionError(err, AchievementActionSubmit, currentRoles(c)); te != nil {
+			return transitionErrorResponse(c, te)
+		}
+		return helper.InternalError(c, writeStepMessage(err))
 	}
 
 	return helper.APIResponse(
@@ -438,7 +645,7 @@ func SubmitAchievement(c *fiber.Ctx) error {
 
 // VerifyAchievement godoc
 // @Summary      Verify achievement
-// @Description  Dosen memverifikasi prestasi dan memberi poin
+// @Description  Dosen memverifikasi prestasi dan memberi poin. Poin kosong = poin rubrik; poin berbeda dari rubrik hanya boleh pada mode override, dalam batas kebijakan dan dengan justification.
 // @Tags         Achievements
 // @Accept       json
 // @Produce      json
@@ -446,10 +653,11 @@ func SubmitAchievement(c *fiber.Ctx) error {
 // @Param        body  body   models.VerifyAchievementRequest  true  "Verification data"
 // @Security     BearerAuth
 // @Success      200  {object}  map[string]interface{}  "Achievement verified (envelope)"
-// @Failure      400  {object}  map[string]interface{}  "Bad request (invalid JSON / points)"
+// @Failure      400  {object}  map[string]interface{}  "Bad request (invalid JSON / points di luar rubrik / justification kosong)"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      403  {object}  map[string]interface{}  "Forbidden (not advisor)"
 // @Failure      404  {object}  map[string]interface{}  "Achievement/reference not found"
+// @Failure      409  {object}  map[string]interface{}  "Status tidak mengizinkan aksi ini (data: current_status, allowed_next_states)"
 // @Failure      500  {object}  map[string]interface{}  "error response"
 // @Router       /achievements/{id}/verify [post]
 func VerifyAchievement(c *fiber.Ctx) error {
@@ -473,9 +681,10 @@ func VerifyAchievement(c *fiber.Ctx) error {
 		return helper.NotFound(c, "Achievement reference not found")
 	}
 
-	// Only submitted can be verified
-	if ref.Status != "submitted" {
-		return helper.BadRequest(c, "Only submitted achievements can be verified")
+	// submitted -> verified
+	next, err := NextAchievementStatus(AchievementActionVerify, ref.Status, currentRoles(c)...)
+	if err != nil {
+		return transitionErrorResponse(c, err)
 	}
 
 	// Verify dosen advisor harus wali mahasiswa
@@ -492,18 +701,43 @@ func VerifyAchievement(c *fiber.Ctx) error {
 	if err := c.BodyParser(&body); err != nil {
 		return helper.BadRequest(c, "Invalid JSON")
 	}
-	if body.Points <= 0 {
-		return helper.BadRequest(c, "Points must be > 0")
-	}
 
-	// Update Mongo
-	if err := repository.VerifyAchievementMongo(id, body.Points, currentUserID); err != nil {
-		return helper.InternalError(c, "Failed to update MongoDB")
+	// Poin mengikuti rubrik; override hanya dalam batas kebijakan dan dengan justifikasi
+	ach, err := repository.GetAchievementByIdMongo(id)
+	if err != nil {
+		return helper.NotFound(c, "Achievement not found")
 	}
-	fmt.Println("REF DEBUG:", ref.ID, ref.MongoAchievementID, ref.Status)
-	// Update Postgres
-	if err := repository.VerifyAchievementReference(ref.ID, currentUserID); err != nil {
-		return helper.InternalError(c, err.Error())
+	rules, policy, err := loadScoring()
+	if err != nil {
+		return helper.InternalError(c, "Failed to load scoring rules")
+	}
+	suggestion := suggestScore(rules, policy, ach.AchievementType, ach.Details)
+	points, overridden, err := resolvePoints(suggestion, body.Points, body.Justification)
+	if err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
+
+	// Update Postgres, event audit, lalu Mongo; jika Mongo gagal, status reference dikembalikan
+	err = runWriteSteps(
+		writeStep{
+			name: "reference verify",
+			do: func() error {
+				return repository.VerifyAchievementReference(ref.ID, currentUserID, AchievementTransitionFrom(AchievementActionVerify))
+			},
+			undo: func() error { return repository.RestoreReferenceState(ref, next) },
+		},
+		achievementEventStep(c, verifiedEvent(id, ref.Status, next, points, suggestion, overridden, body.Justification)),
+		writeStep{
+			name:    "mongo verify",
+			failMsg: "Failed to update MongoDB",
+			do:      func() error { return repository.VerifyAchievementMongo(id, points, currentUserID) },
+		},
+	)
+	if err != nil {
+		if te := staleTransitionError(err, AchievementActionVerify, currentRoles(c)); te != nil {
+			return transitionErrorResponse(c, te)
+		}
+		return helper.InternalError(c, writeStepMessage(err))
 	}
 
 	return helper.APIResponse(c, 200, "Achievement verified", nil)
@@ -519,10 +753,11 @@ func VerifyAchievement(c *fiber.Ctx) error {
 // @Param        body  body   models.RejectAchievementRequest  true  "Rejection data"
 // @Security     BearerAuth
 // @Success      200  {object}  map[string]interface{}  "Achievement rejected (envelope)"
-// @Failure      400  {object}  map[string]interface{}  "Bad request (invalid JSON / status bukan submitted)"
+// @Failure      400  {object}  map[string]interface{}  "Bad request (invalid JSON / note kosong)"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      403  {object}  map[string]interface{}  "Forbidden (not advisor)"
 // @Failure      404  {object}  map[string]interface{}  "Achievement/reference not found"
+// @Failure      409  {object}  map[string]interface{}  "Status tidak mengizinkan aksi ini (data: current_status, allowed_next_states)"
 // @Failure      500  {object}  map[string]interface{}  "error response"
 // @Router       /achievements/{id}/reject [post]
 func RejectAchievement(c *fiber.Ctx) error {
@@ -546,8 +781,10 @@ func RejectAchievement(c *fiber.Ctx) error {
 		return helper.NotFound(c, "Achievement reference not found")
 	}
 
-	if ref.Status != "submitted" {
-		return helper.BadRequest(c, "Only submitted achievements can be rejected")
+	// submitted -> rejected
+	next, err := NextAchievementStatus(AchievementActionReject, ref.Status, currentRoles(c)...)
+	if err != nil {
+		return transitionErrorResponse(c, err)
 	}
 
 	// advisor validation
@@ -568,14 +805,31 @@ func RejectAchievement(c *fiber.Ctx) error {
 		return helper.BadRequest(c, "Rejection note is required")
 	}
 
-	// Update Mongo
-	if err := repository.RejectAchievementMongo(id, body.Note, currentUserID); err != nil {
-		return helper.InternalError(c, "Failed to update MongoDB")
-	}
-
-	// Update Postgres
-	if err := repository.RejectAchievementReference(ref.ID, body.Note, currentUserID); err != nil {
-		return helper.InternalError(c, "Failed to update reference")
+	event := statusEvent(id, models.AchievementEventRejected, ref.Status, next)
+	event.Note = optionalString(body.Note)
+
+	// Update Postgres, event audit, lalu Mongo; jika Mongo gagal, status reference dikembalikan
+	err = runWriteSteps(
+		writeStep{
+			name:    "reference reject",
+			failMsg: "Failed to update reference",
+			do: func() error {
+				return repository.RejectAchievementReference(ref.ID, body.Note, currentUserID, AchievementTransitionFrom(AchievementActionReject))
+			},
+			undo: func() error { return repository.RestoreReferenceState(ref, next) },
+		},
+		achievementEventStep(c, event),
+		writeStep{
+			name:    "mongo reject",
+			failMsg: "Failed to update MongoDB",
+			do:      func() error { return repository.RejectAchievementMongo(id, body.Note, currentUserID) },
+		},
+	)
+	if err != nil {
+		if te := staleTransitionError(err, AchievementActionReject, currentRoles(c)); te != nil {
+			return transitionErrorResponse(c, te)
+		}
+		return helper.InternalError(c, writeStepMessage(err))
 	}
 
 	return helper.APIResponse(c, 200, "Achievement rejected", nil)
@@ -595,6 +849,7 @@ func RejectAchievement(c *fiber.Ctx) error {
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
 // @Failure 403 {object} map[string]interface{} "Forbidden (not owner)"
 // @Failure 404 {object} map[string]interface{} "Achievement not found"
+// @Failure 409 {object} map[string]interface{} "Status tidak mengizinkan aksi ini (data: current_status, allowed_next_states)"
 // @Failure 500 {object} map[string]interface{} "error response"
 // @Router /achievements/{id}/attachments [post]
 func UploadAchievementFile(c *fiber.Ctx) error {
@@ -621,6 +876,15 @@ func UploadAchievementFile(c *fiber.Ctx) error {
 		return helper.Forbidden(c, "You are not allowed to upload attachment for this achievement")
 	}
 
+	// Lampiran mengubah isi prestasi: hanya boleh selama prestasi masih bisa diedit
+	ref, err := repository.GetAchievementReferenceByMongoID(id)
+	if err != nil {
+		return helper.NotFound(c, "Achievement reference not found")
+	}
+	if _, err := NextAchievementStatus(AchievementActionUpdate, ref.Status, currentRoles(c)...); err != nil {
+		return transitionErrorResponse(c, err)
+	}
+
 	// Ambil file dari form
 	fileHeader, err := c.FormFile("file")
 	if err != nil {
@@ -640,20 +904,6 @@ func UploadAchievementFile(c *fiber.Ctx) error {
 	newName := fmt.Sprintf("%d%s", time.Now().UnixNano(), ext)
 	targetPath := filepath.Join(uploadDir, newName)
 
-	src, _ := fileHeader.Open()
-	defer src.Close()
-
-	dst, err := os.Create(targetPath)
-	if err != nil {
-		return helper.InternalError(c, "Failed to create file on server")
-	}
-	defer dst.Close()
-
-	_, err = io.Copy(dst, src)
-	if err != nil {
-		return helper.InternalError(c, "Failed to save file")
-	}
-
 	// Bangun file URL (contoh: localhost:8080 atau domain)
 	fileURL := fmt.Sprintf("/static/achievements/%s/%s", id, newName)
 
@@ -664,9 +914,26 @@ func UploadAchievementFile(c *fiber.Ctx) error {
 		UploadedAt: time.Now(),
 	}
 
-	// Simpan metadata ke Mongo
-	if err := repository.AddAchievementAttachment(id, attachment); err != nil {
-		return helper.InternalError(c, err.Error())
+	// Simpan file, catat event audit, lalu simpan metadata ke Mongo; file dihapus lagi jika langkah berikutnya gagal
+	err = runWriteSteps(
+		writeStep{
+			name:    "file save",
+			failMsg: "Failed to save file",
+			do:      func() error { return saveUploadedFile(fileHeader, targetPath) },
+			undo:    func() error { return os.Remove(targetPath) },
+		},
+		achievementEventStep(c, models.AchievementEvent{
+			MongoAchievementID: id,
+			EventType:          models.AchievementEventAttachmentAdded,
+			Changes:            map[string]models.FieldChange{"attachments": {To: attachment}},
+		}),
+		writeStep{
+			name: "mongo attachment",
+			do:   func() error { return repository.AddAchievementAttachment(id, attachment) },
+		},
+	)
+	if err != nil {
+		return helper.InternalError(c, writeStepMessage(err))
 	}
 
 	return helper.APIResponse(c, fiber.StatusCreated, "Attachment uploaded", map[string]any{
@@ -674,9 +941,33 @@ func UploadAchievementFile(c *fiber.Ctx) error {
 	})
 }
 
+// saveUploadedFile menyalin file upload ke path; file setengah jadi dihapus jika penyalinan gagal.
+func saveUploadedFile(fileHeader *multipart.FileHeader, path string) error {
+	src, err := fileHeader.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(path)
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(path)
+		return err
+	}
+	return nil
+}
+
 // GetAchievementHistory godoc
 // @Summary      Get achievement history & timeline
-// @Description  Mengambil reference, achievement (jika ada), dan riwayat event (created, submitted, verified, rejected, attachment_uploaded, last_updated).
+// @Description  Mengambil reference, achievement (jika ada), dan riwayat event dari log audit (created, updated, attachment_added, submitted, verified, rejected, deleted) beserta actor, role, perubahan field, dan catatan. Hanya pemilik, dosen wali pemilik, atau admin.
 // @Tags         Achievements
 // @Accept       json
 // @Produce      json
@@ -684,7 +975,8 @@ func UploadAchievementFile(c *fiber.Ctx) error {
 // @Security     BearerAuth
 // @Success      200  {object}  map[string]interface{} "envelope {status,message,data:{reference,achievement,history}}"
 // @Failure      400  {object}  map[string]interface{} "Invalid achievement ID"
-// @Failure      404  {object}  map[string]interface{} "Achievement reference not found"
+// @Failure      403  {object}  map[string]interface{} "Bukan pemilik / dosen wali pemilik / admin"
+// @Failure      404  {object}  map[string]interface{} "Achievement not found"
 // @Failure      500  {object}  map[string]interface{} "error response"
 // @Router       /achievements/{id}/history [get]
 func GetAchievementHistory(c *fiber.Ctx) error {
@@ -707,103 +999,12 @@ func GetAchievementHistory(c *fiber.Ctx) error {
 		}
 	}
 
-	// helper untuk cek pointer time
-	timePtrIsZero := func(t *time.Time) bool {
-		if t == nil {
-			return true
-		}
-		// deref dan cek IsZero
-		return t.IsZero()
-	}
-
-	// Build history events
-	history := []map[string]any{}
-
-	// created
-	history = append(history, map[string]any{
-		"event":       "created",
-		"status":      "draft",
-		"timestamp":   ref.CreatedAt,
-		"actor":       nil,
-		"description": "Draft created",
-	})
-
-	// attachment_uploaded events (jika ada)
-	if ach != nil && len(ach.Attachments) > 0 {
-		for _, a := range ach.Attachments {
-			ts := a.UploadedAt
-			if ts.IsZero() {
-				// fallback ke reference.updatedAt bila UploadedAt belum terisi
-				ts = ref.UpdatedAt
-			}
-			history = append(history, map[string]any{
-				"event":       "attachment_uploaded",
-				"status":      ref.Status,
-				"timestamp":   ts,
-				"actor":       nil,
-				"description": "Uploaded file: " + a.FileName,
-				"file": map[string]any{
-					"fileName": a.FileName,
-					"fileUrl":  a.FileURL,
-					"fileType": a.FileType,
-				},
-			})
-		}
-	}
-
-	// submitted
-	if ref.SubmittedAt != nil && !timePtrIsZero(ref.SubmittedAt) {
-		history = append(history, map[string]any{
-			"event":       "submitted",
-			"status":      "submitted",
-			"timestamp":   *ref.SubmittedAt,
-			"actor":       ref.StudentID,
-			"description": "Submitted for verification",
-		})
-	}
-
-	// verified or rejected
-	if ref.VerifiedAt != nil && !timePtrIsZero(ref.VerifiedAt) {
-		if ref.Status == "verified" {
-			var points any = nil
-			if ach != nil {
-				points = ach.Points
-			}
-			history = append(history, map[string]any{
-				"event":       "verified",
-				"status":      "verified",
-				"timestamp":   *ref.VerifiedAt,
-				"actor":       ref.VerifiedBy,
-				"description": "Verified",
-				"meta": map[string]any{
-					"points": points,
-				},
-			})
-		} else if ref.Status == "rejected" {
-			// safe deref rejection note (ref.RejectionNote is *string)
-			note := ""
-			if ref.RejectionNote != nil {
-				note = *ref.RejectionNote
-			}
-			history = append(history, map[string]any{
-				"event":       "rejected",
-				"status":      "rejected",
-				"timestamp":   *ref.VerifiedAt,
-				"actor":       ref.VerifiedBy,
-				"description": "Rejected: " + note,
-			})
-		}
+	// 3) Riwayat dari log audit achievement_events
+	history, err := repository.GetAchievementEvents(id)
+	if err != nil {
+		return helper.InternalError(c, "Failed to load achievement history")
 	}
 
-	// last_updated (ambil dari reference.updated_at)
-	history = append(history, map[string]any{
-		"event":       "last_updated",
-		"status":      ref.Status,
-		"timestamp":   ref.UpdatedAt,
-		"actor":       nil,
-		"description": "Last update",
-	})
-
 	// Build achievement response minimal (jika ada)
 	var achievementResp map[string]any = nil
 	if ach != nil {
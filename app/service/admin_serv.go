@@ -4,6 +4,8 @@ import (
 	"UAS_GO/app/models"
 	"UAS_GO/app/repository"
 	"UAS_GO/helper"
+	"database/sql"
+	"errors"
 	"log"
 
 	"github.com/go-playground/validator/v10"
@@ -62,7 +64,7 @@ func AdminGetUserByID(c *fiber.Ctx) error {
 // @Param        body  body   models.CreateUserRequest  true  "User payload"
 // @Security     BearerAuth
 // @Success      201  {object}  map[string]interface{}  "envelope {status,message,data:user}"
-// @Failure      400  {object}  map[string]interface{}  "Validation error / invalid payload"
+// @Failure      400  {object}  map[string]interface{}  "Validation error / invalid payload / tidak memenuhi kebijakan password"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      403  {object}  map[string]interface{}  "Forbidden (not admin)"
 // @Failure      500  {object}  map[string]interface{}  "error response"
@@ -78,6 +80,9 @@ func AdminCreateUser(c *fiber.Ctx) error {
 	if err := validate.Struct(req); err != nil {
 		return helper.BadRequest(c, err.Error())
 	}
+	if err := helper.ValidatePassword(req.Password, req.Username, req.Email); err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
 
 	hashedPassword, err := helper.HashPassword(req.Password)
 	if err != nil {
@@ -104,6 +109,7 @@ func AdminCreateUser(c *fiber.Ctx) error {
 // AdminUpdateUser godoc
 // @Summary      Update user (admin)
 // @Description  Admin mengubah data user (email, username, full name, role, status aktif)
+// @Description  Jika password diisi, semua sesi user dicabut dan token reset yang masih terbuka dibatalkan.
 // @Tags         Admin - Users
 // @Accept       json
 // @Produce      json
@@ -111,10 +117,11 @@ func AdminCreateUser(c *fiber.Ctx) error {
 // @Param        body  body   models.UpdateUserRequest  true  "User update payload"
 // @Security     BearerAuth
 // @Success      200  {object}  map[string]interface{}  "envelope {status,message,data:user}"
-// @Failure      400  {object}  map[string]interface{}  "Invalid payload / email already used / invalid role"
+// @Failure      400  {object}  map[string]interface{}  "Invalid payload / email already used / invalid role / tidak memenuhi kebijakan password"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      403  {object}  map[string]interface{}  "Forbidden (not admin)"
 // @Failure      404  {object}  map[string]interface{}  "User not found"
+// @Failure      409  {object}  map[string]interface{}  "Admin aktif terakhir tidak boleh dihapus / diturunkan"
 // @Failure      500  {object}  map[string]interface{}  "error response"
 // @Router       /admin/users/{id} [put]
 func AdminUpdateUser(c *fiber.Ctx) error {
@@ -142,11 +149,36 @@ func AdminUpdateUser(c *fiber.Ctx) error {
 		IsActive: req.IsActive,
 	}
 
+	// password baru (opsional) harus memenuhi kebijakan password
+	if req.Password != "" {
+		ids, err := repository.GetUserPasswordIdentifiers(id)
+		if err == sql.ErrNoRows {
+			return helper.NotFound(c, "user not found")
+		}
+		if err != nil {
+			return helper.InternalError(c, err.Error())
+		}
+		if err := helper.ValidatePassword(req.Password, append(ids, req.Username, req.Email)...); err != nil {
+			return helper.BadRequest(c, err.Error())
+		}
+		if user.PasswordHash, err = helper.HashPassword(req.Password); err != nil {
+			return helper.InternalError(c, "Failed to hash password")
+		}
+	}
+
 	updatedUser, err := repository.UpdateUser(id, user)
+	if errors.Is(err, repository.ErrLastAdmin) {
+		return helper.Conflict(c, err.Error())
+	}
 	if err != nil {
 		return helper.InternalError(c, err.Error())
 	}
 
+	if req.Password != "" {
+		actor, _ := c.Locals("user_id").(string)
+		log.Printf("SECURITY: password user %s diganti oleh %s, semua sesi dicabut\n", id, actor)
+	}
+
 	return helper.APIResponse(c, fiber.StatusOK, "user updated", updatedUser)
 }
 
@@ -162,6 +194,7 @@ func AdminUpdateUser(c *fiber.Ctx) error {
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      403  {object}  map[string]interface{}  "Forbidden (not admin)"
 // @Failure      404  {object}  map[string]interface{}  "user not found"
+// @Failure      409  {object}  map[string]interface{}  "Admin aktif terakhir tidak boleh dihapus / diturunkan"
 // @Failure      500  {object}  map[string]interface{}  "error response"
 // @Router       /admin/users/{id} [delete]
 func AdminDeleteUser(c *fiber.Ctx) error {
@@ -169,14 +202,19 @@ func AdminDeleteUser(c *fiber.Ctx) error {
 	log.Println("DELETE USER ID:", id)
 	if err := repository.DeleteUser(id); err != nil {
 		log.Println("DELETE ERROR:", err)
+		if errors.Is(err, repository.ErrLastAdmin) {
+			return helper.Conflict(c, err.Error())
+		}
 		return helper.NotFound(c, "user not found")
 	}
 	return helper.APIResponse(c, fiber.StatusOK, "user deleted", nil)
 }
 
 // AdminUpdateUserRole godoc
-// @Summary      Update user role (admin)
+// @Summary      Update user roles (admin)
 // @Description  Admin mengubah role user (hanya role, tanpa mengubah field lain).
+// @Description  role_id mengganti role utama (bentuk lama, tetap didukung); add_role_ids / remove_role_ids
+// @Description  menambah / melepas role tambahan. Permission user adalah gabungan permission semua rolenya.
 // @Tags         Admin - Users
 // @Accept       json
 // @Produce      json
@@ -184,10 +222,11 @@ func AdminDeleteUser(c *fiber.Ctx) error {
 // @Param        body  body   models.UpdateUserRoleRequest  true  "Role update payload"
 // @Security     BearerAuth
 // @Success      200  {object}  map[string]interface{}  "user role updated (envelope)"
-// @Failure      400  {object}  map[string]interface{}  "invalid request body"
+// @Failure      400  {object}  map[string]interface{}  "invalid request body / role tidak ada / role utama dilepas"
 // @Failure      401  {object}  map[string]interface{}  "Unauthorized"
 // @Failure      403  {object}  map[string]interface{}  "Forbidden (not admin)"
 // @Failure      404  {object}  map[string]interface{}  "user not found"
+// @Failure      409  {object}  map[string]interface{}  "Admin aktif terakhir tidak boleh dihapus / diturunkan"
 // @Failure      500  {object}  map[string]interface{}  "error response"
 // @Router       /admin/users/{id}/role [put]
 func AdminUpdateUserRole(c *fiber.Ctx) error {
@@ -196,9 +235,56 @@ func AdminUpdateUserRole(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return helper.BadRequest(c, "invalid request body")
 	}
-	user, err := repository.UpdateUserRole(id, req.RoleID)
-	if err != nil {
+
+	// bentuk lama: hanya role_id
+	if len(req.AddRoleIDs) == 0 && len(req.RemoveRoleIDs) == 0 {
+		user, err := repository.UpdateUserRole(id, req.RoleID)
+		if errors.Is(err, repository.ErrLastAdmin) {
+			return helper.Conflict(c, err.Error())
+		}
+		if err != nil {
+			return helper.NotFound(c, "user not found")
+		}
+
+		recordRBACAudit(c, "user:assign-role", models.RBACAuditEntry{
+			Action:       models.RBACAuditUserRoleAssigned,
+			RoleID:       optionalString(user.RoleID),
+			TargetUserID: optionalString(user.ID),
+		})
+		return helper.APIResponse(c, fiber.StatusOK, "user role updated", user)
+	}
+
+	change, err := repository.UpdateUserRoles(id, req.RoleID, req.AddRoleIDs, req.RemoveRoleIDs)
+	switch {
+	case errors.Is(err, repository.ErrLastAdmin):
+		return helper.Conflict(c, err.Error())
+	case errors.Is(err, repository.ErrRoleNotFound), errors.Is(err, repository.ErrPrimaryRoleRemoval):
+		return helper.BadRequest(c, err.Error())
+	case err != nil:
 		return helper.NotFound(c, "user not found")
 	}
+
+	user := change.User
+	if req.RoleID != "" {
+		recordRBACAudit(c, "user:assign-role", models.RBACAuditEntry{
+			Action:       models.RBACAuditUserRoleAssigned,
+			RoleID:       optionalString(user.RoleID),
+			TargetUserID: optionalString(user.ID),
+		})
+	}
+	for _, roleID := range change.Added {
+		recordRBACAudit(c, "user:assign-role", models.RBACAuditEntry{
+			Action:       models.RBACAuditUserRoleAdded,
+			RoleID:       optionalString(roleID),
+			TargetUserID: optionalString(user.ID),
+		})
+	}
+	for _, roleID := range change.Removed {
+		recordRBACAudit(c, "user:assign-role", models.RBACAuditEntry{
+			Action:       models.RBACAuditUserRoleRemoved,
+			RoleID:       optionalString(roleID),
+			TargetUserID: optionalString(user.ID),
+		})
+	}
 	return helper.APIResponse(c, fiber.StatusOK, "user role updated", user)
 }
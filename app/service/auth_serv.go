@@ -7,9 +7,11 @@ import (
 	"UAS_GO/helper"
 	"database/sql"
 	"errors"
-	"fmt"
+	"log"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type AuthService struct{}
@@ -21,7 +23,8 @@ func NewAuthService() *AuthService {
 // AuthLogin godoc
 // @Summary      Login user
 // @Description  Autentikasi user menggunakan email+password atau NIM+password.
-// @Description  Mengembalikan JWT token, data user, dan permissions.
+// @Description  Mengembalikan access token (JWT, berumur pendek), refresh token (opaque, per perangkat), data user, dan permissions.
+// @Description  Login gagal dihitung per akun dan per IP: jeda progresif lalu kunci sementara (429).
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
@@ -29,6 +32,7 @@ func NewAuthService() *AuthService {
 // @Success      200   {object}  map[string]interface{}  "envelope {status,message,data:LoginResponse}"
 // @Failure      400   {object}  map[string]interface{}  "Invalid request format / missing fields"
 // @Failure      401   {object}  map[string]interface{}  "Invalid credentials / inactive account"
+// @Failure      429   {object}  map[string]interface{}  "Terlalu banyak login gagal (akun / IP); lihat header Retry-After"
 // @Failure      500   {object}  map[string]interface{}  "error response"
 // @Router       /auth/login [post]
 func AuthLogin(c *fiber.Ctx) error {
@@ -40,7 +44,6 @@ func AuthLogin(c *fiber.Ctx) error {
 	if err := c.BodyParser(&req); err != nil {
 		return helper.BadRequest(c, "Invalid request format")
 	}
-	fmt.Printf("DEBUG LoginRequest: %+v\n", req)
 
 	// Validasi input dasar: minimal email atau nim, dan password
 	if req.Password == "" || (req.Email == "" && req.NIM == "") {
@@ -55,13 +58,38 @@ func AuthLogin(c *fiber.Ctx) error {
 		identifier = req.NIM
 	}
 
+	// Nama perangkat untuk sesi refresh token
+	device := req.Device
+	if device == "" {
+		device = c.Get("User-Agent")
+	}
+
+	// Cek kunci / jeda login sebelum verifikasi password; berlaku sama untuk akun yang tidak ada
+	throttle := LoadLoginThrottleConfig()
+	accountKey := repository.LoginAccountKey(byNIM, identifier)
+	wait, err := throttle.loginWait(accountKey, c.IP())
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	if wait > 0 {
+		return respondLoginThrottled(c, wait)
+	}
+
 	// Memanggil service untuk logika bisnis (termasuk verifikasi password dan generate token)
-	resp, err := authService.Login(identifier, req.Password, byNIM)
+	resp, err := authService.Login(identifier, req.Password, byNIM, device)
 	if err != nil {
+		if errors.Is(err, ErrInvalidCredentials) {
+			throttle.recordFailure(accountKey, c.IP())
+		}
 		// Menggunakan helper.Unauthorized untuk error otentikasi
 		return helper.Unauthorized(c, err.Error())
 	}
 
+	// Hitungan per akun direset; hitungan per IP dibiarkan habis sendiri
+	if err := repository.ClearLoginThrottle(models.LoginThrottleAccount, accountKey); err != nil {
+		log.Printf("login throttle: gagal mereset %s: %v\n", accountKey, err)
+	}
+
 	// Respons sukses
 	return helper.APIResponse(c, fiber.StatusOK, "Login successful", resp)
 }
@@ -99,7 +127,8 @@ func AuthGetProfile(c *fiber.Ctx) error {
 
 // AuthLogout godoc
 // @Summary      Logout current user
-// @Description  Logout user yang sedang login (invalidate token / update state server).
+// @Description  Logout user yang sedang login. Access token (jti) dimasukkan ke blacklist sampai expired
+// @Description  dan refresh token milik sesi (perangkat) ini dicabut.
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
@@ -115,15 +144,29 @@ func AuthLogout(c *fiber.Ctx) error {
 		return helper.Unauthorized(c, "user not authenticated")
 	}
 
-	err := repository.LogoutUser(userID.(string))
+	jti, _ := c.Locals("jti").(string)
+	expiresAt, ok := c.Locals("token_exp").(time.Time)
+	if !ok {
+		// tanpa exp, simpan selama umur maksimum access token
+		expiresAt = time.Now().Add(helper.AccessTokenTTL())
+	}
+
+	err := repository.LogoutUser(userID.(string), jti, expiresAt)
 	if err != nil {
 		return helper.InternalError(c, err.Error())
 	}
 
+	// cabut refresh token milik sesi ini
+	if sessionID, _ := c.Locals("session_id").(string); sessionID != "" {
+		if err := repository.RevokeRefreshTokenFamily(sessionID); err != nil {
+			return helper.InternalError(c, err.Error())
+		}
+	}
+
 	return helper.APIResponse(c, fiber.StatusOK, "Logout successful", nil)
 }
 
-func (s *AuthService) Login(identifier, password string, byNIM bool) (*models.LoginResponse, error) {
+func (s *AuthService) Login(identifier, password string, byNIM bool, device string) (*models.LoginResponse, error) {
 	// Query user from PostgreSQL
 	var query string
 	var user = &models.User{}
@@ -144,51 +187,60 @@ func (s *AuthService) Login(identifier, password string, byNIM bool) (*models.Lo
 	err := database.PSQL.QueryRow(query, identifier).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.RoleID, &user.IsActive)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("user not found")
+			burnPasswordCheck(password)
+			return nil, ErrInvalidCredentials
 		}
 		return nil, err
 	}
 
 	// Verify password
 	if !helper.CheckPassword(password, user.PasswordHash) {
-		return nil, errors.New("invalid password")
+		return nil, ErrInvalidCredentials
 	}
 
 	if !user.IsActive {
 		return nil, errors.New("user account is inactive")
 	}
+	upgradePasswordHash(user, password)
 
-	// Generate JWT token
-	token, err := helper.GenerateToken(*user)
+	// Login baru = sesi (family) refresh token baru untuk perangkat ini
+	refresh, err := s.newRefreshToken(user.ID, uuid.New().String(), device)
 	if err != nil {
 		return nil, err
 	}
-
-	// Ambil daftar permissions berdasarkan role_id
-	perms, err := repository.GetPermissionsByRoleID(user.RoleID)
-	if err != nil {
-		// jangan gagalkan login hanya karena gagal ambil permissions; kembalikan tanpa permissions
-		perms = []string{}
+	if err := repository.CreateRefreshToken(refresh.record); err != nil {
+		return nil, err
 	}
 
-	return &models.LoginResponse{
-		User:        *user,
-		Token:       token,
-		Permissions: perms,
-	}, nil
+	return s.buildTokenResponse(user, refresh)
 }
 
-func (s *AuthService) RefreshToken(token string) (*models.LoginResponse, error) {
-	// Verify and parse the token
-	claims, err := helper.VerifyToken(token)
+func (s *AuthService) RefreshToken(refreshToken string) (*models.LoginResponse, error) {
+	current, err := repository.GetRefreshTokenByHash(helper.HashToken(refreshToken))
 	if err != nil {
-		return nil, errors.New("invalid or expired token")
+		if err == sql.ErrNoRows {
+			return nil, errors.New("invalid refresh token")
+		}
+		return nil, err
+	}
+
+	// Token yang sudah dirotasi dipakai lagi -> kemungkinan dicuri.
+	// Matikan seluruh family supaya pencuri maupun pemilik harus login ulang.
+	if current.RevokedAt != nil {
+		if current.ReplacedBy != nil {
+			return nil, s.revokeFamilyOnReuse(current)
+		}
+		return nil, errors.New("refresh token has been revoked")
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		return nil, errors.New("refresh token expired")
 	}
 
 	// Query user from PostgreSQL to get fresh data
 	query := `SELECT id, email, password_hash, role_id, is_active FROM users WHERE id = $1`
 	user := &models.User{}
-	err = database.PSQL.QueryRow(query, claims.UserID).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.RoleID, &user.IsActive)
+	err = database.PSQL.QueryRow(query, current.UserID).Scan(&user.ID, &user.Email, &user.PasswordHash, &user.RoleID, &user.IsActive)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.New("user not found")
@@ -197,38 +249,129 @@ func (s *AuthService) RefreshToken(token string) (*models.LoginResponse, error)
 	}
 
 	if !user.IsActive {
+		repository.RevokeRefreshTokenFamily(current.FamilyID)
 		return nil, errors.New("user account is inactive")
 	}
 
-	// Generate new JWT token
-	newToken, err := helper.GenerateToken(*user)
+	// Rotasi: token lama ditandai terpakai, token baru tetap di family yang sama
+	next, err := s.newRefreshToken(user.ID, current.FamilyID, current.Device)
 	if err != nil {
 		return nil, err
 	}
+	if err := repository.RotateRefreshToken(current.ID, next.record); err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenReused) {
+			return nil, s.revokeFamilyOnReuse(current)
+		}
+		return nil, err
+	}
+
+	return s.buildTokenResponse(user, next)
+}
+
+// issuedRefreshToken: token plaintext (dikirim ke client) + record yang disimpan (hash)
+type issuedRefreshToken struct {
+	plain  string
+	record *models.RefreshToken
+}
 
-	// Ambil permissions untuk role (jangan gagal token refresh jika error -> kembalikan perms kosong)
-	perms, err := repository.GetPermissionsByRoleID(user.RoleID)
+func (s *AuthService) newRefreshToken(userID, familyID, device string) (*issuedRefreshToken, error) {
+	plain, err := helper.GenerateOpaqueToken()
 	if err != nil {
+		return nil, err
+	}
+
+	return &issuedRefreshToken{
+		plain: plain,
+		record: &models.RefreshToken{
+			ID:        uuid.New().String(),
+			UserID:    userID,
+			FamilyID:  familyID,
+			TokenHash: helper.HashToken(plain),
+			Device:    device,
+			ExpiresAt: time.Now().Add(helper.RefreshTokenTTL()),
+		},
+	}, nil
+}
+
+func (s *AuthService) buildTokenResponse(user *models.User, refresh *issuedRefreshToken) (*models.LoginResponse, error) {
+	// Semua role user (utama + tambahan) ikut ke token; gagal baca = role utama saja
+	if ids, err := repository.GetUserRoleIDs(user.ID); err == nil && len(ids) > 0 {
+		user.RoleIDs = ids
+	} else {
+		user.RoleIDs = []string{user.RoleID}
+	}
+
+	// Ambil daftar permissions: gabungan permission semua role
+	perms, permsErr := rolesPermissions(user.RoleIDs)
+	if permsErr != nil {
+		// jangan gagalkan login hanya karena gagal ambil permissions; kembalikan tanpa permissions
 		perms = []string{}
+	} else if overrides, err := repository.GetUserPermissionOverrides(user.ID); err == nil {
+		// grant / deny per user ikut diterapkan supaya daftar ini sama dengan yang dicek PermissionRequired
+		perms = effectivePermissions(perms, overrides)
+	}
+
+	var accessToken string
+	var accessExp time.Time
+	var err error
+	if helper.EmbedPermissionsInToken() && permsErr == nil {
+		accessToken, accessExp, err = helper.GenerateAccessTokenWithPermissions(*user, refresh.record.FamilyID, perms)
+	} else {
+		accessToken, accessExp, err = helper.GenerateAccessToken(*user, refresh.record.FamilyID)
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	return &models.LoginResponse{
-		User:        *user,
-		Token:       newToken,
-		Permissions: perms,
+		User:                  *user,
+		TokenType:             "Bearer",
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessExp,
+		RefreshToken:          refresh.plain,
+		RefreshTokenExpiresAt: refresh.record.ExpiresAt,
+		Permissions:           perms,
 	}, nil
 }
 
+// rolesPermissions: gabungan permission beberapa role, tanpa duplikat
+func rolesPermissions(roleIDs []string) ([]string, error) {
+	seen := map[string]bool{}
+	perms := []string{}
+	for _, id := range roleIDs {
+		list, err := repository.GetPermissionsByRoleID(id)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range list {
+			if !seen[p] {
+				seen[p] = true
+				perms = append(perms, p)
+			}
+		}
+	}
+	return perms, nil
+}
+
+func (s *AuthService) revokeFamilyOnReuse(rt *models.RefreshToken) error {
+	log.Printf("refresh token reuse terdeteksi: user=%s family=%s device=%q\n", rt.UserID, rt.FamilyID, rt.Device)
+	if err := repository.RevokeRefreshTokenFamily(rt.FamilyID); err != nil {
+		return err
+	}
+	return repository.ErrRefreshTokenReused
+}
+
 // AuthRefreshToken godoc
-// @Summary      Refresh JWT token
-// @Description  Menerima token lama (masih valid) dan mengembalikan token baru.
+// @Summary      Refresh access token
+// @Description  Menukar refresh token dengan access token + refresh token baru (rotasi).
+// @Description  Refresh token lama langsung tidak berlaku; jika dipakai lagi, seluruh sesi perangkat tersebut dicabut.
 // @Tags         Auth
 // @Accept       json
 // @Produce      json
 // @Param        body  body   models.RefreshTokenRequest  true  "Refresh token payload"
 // @Success      200  {object}  map[string]interface{}  "envelope {status,message,data:LoginResponse}"
 // @Failure      400  {object}  map[string]interface{}  "Invalid request format / token empty"
-// @Failure      401  {object}  map[string]interface{}  "Invalid or expired token"
+// @Failure      401  {object}  map[string]interface{}  "Invalid, expired, revoked or reused refresh token"
 // @Failure      500  {object}  map[string]interface{}  "error response"
 // @Router       /auth/refresh [post]
 func AuthRefreshToken(c *fiber.Ctx) error {
@@ -241,15 +384,80 @@ func AuthRefreshToken(c *fiber.Ctx) error {
 		return helper.BadRequest(c, "Invalid request format")
 	}
 
-	if req.Token == "" {
-		return helper.BadRequest(c, "Token is required")
+	if req.RefreshToken == "" {
+		return helper.BadRequest(c, "Refresh token is required")
 	}
 
 	// Call service to refresh token
-	resp, err := authService.RefreshToken(req.Token)
+	resp, err := authService.RefreshToken(req.RefreshToken)
 	if err != nil {
 		return helper.Unauthorized(c, err.Error())
 	}
 
 	return helper.APIResponse(c, fiber.StatusOK, "Token refreshed successfully", resp)
 }
+
+// GetJWKS godoc
+// @Summary      JSON Web Key Set
+// @Description  Public key (RS256 / EdDSA) yang dipakai untuk menandatangani access token,
+// @Description  termasuk kunci lama yang masih berlaku selama rotasi. Kosong jika memakai HS256.
+// @Tags         Auth
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}  "{keys:[...]}"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /.well-known/jwks.json [get]
+func GetJWKS(c *fiber.Ctx) error {
+	jwks, err := helper.JWKS()
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+
+	// format JWKS standar (tanpa envelope) agar bisa dibaca library JWT lain
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.Status(fiber.StatusOK).JSON(jwks)
+}
+
+// StartTokenCleanup menjalankan goroutine yang secara berkala menghapus
+// entri blacklist access token, refresh token, dan token reset password yang sudah expired,
+// serta hitungan login gagal yang basi.
+func StartTokenCleanup(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			n, err := repository.DeleteExpiredRevokedTokens()
+			if err != nil {
+				log.Println("revoked token cleanup error:", err)
+			} else if n > 0 {
+				log.Printf("revoked token cleanup: %d entri dihapus\n", n)
+			}
+
+			n, err = repository.DeleteExpiredRefreshTokens()
+			if err != nil {
+				log.Println("refresh token cleanup error:", err)
+			} else if n > 0 {
+				log.Printf("refresh token cleanup: %d entri dihapus\n", n)
+			}
+
+			n, err = repository.DeleteExpiredPasswordResetTokens()
+			if err != nil {
+				log.Println("password reset token cleanup error:", err)
+			} else if n > 0 {
+				log.Printf("password reset token cleanup: %d entri dihapus\n", n)
+			}
+
+			// hitungan reset password bisa berumur lebih panjang dari lockout login
+			window := LoadLoginThrottleConfig().Lockout
+			if w := LoadPasswordResetThrottleConfig().Lockout; w > window {
+				window = w
+			}
+			n, err = repository.DeleteStaleLoginThrottles(window)
+			if err != nil {
+				log.Println("login throttle cleanup error:", err)
+			} else if n > 0 {
+				log.Printf("login throttle cleanup: %d entri dihapus\n", n)
+			}
+		}
+	}()
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"log"
+)
+
+// Prestasi disimpan di dua store (dokumen di MongoDB, status di Postgres) tanpa
+// transaksi bersama. Setiap perubahan yang menyentuh keduanya dijalankan sebagai
+// rangkaian langkah; jika satu langkah gagal, langkah sebelumnya dibatalkan lewat
+// aksi kompensasi (urutan terbalik) sehingga perubahan masuk ke kedua store atau tidak sama sekali.
+
+// writeStep: satu langkah penulisan beserta kompensasinya.
+type writeStep struct {
+	name    string
+	failMsg string // pesan ke client jika langkah ini gagal
+	do      func() error
+	undo    func() error // nil jika tidak perlu dibatalkan (mis. langkah terakhir)
+}
+
+// writeStepError: langkah yang gagal + error aslinya.
+type writeStepError struct {
+	Step    string
+	Message string
+	Err     error
+}
+
+func (e *writeStepError) Error() string {
+	return e.Step + ": " + e.Err.Error()
+}
+
+func (e *writeStepError) Unwrap() error {
+	return e.Err
+}
+
+// runWriteSteps menjalankan langkah berurutan dan mengompensasi langkah yang sudah
+// berhasil bila ada yang gagal. Kompensasi yang gagal dicatat di log agar bisa
+// diperbaiki oleh proses rekonsiliasi.
+func runWriteSteps(steps ...writeStep) error {
+	for i, step := range steps {
+		err := step.do()
+		if err == nil {
+			continue
+		}
+
+		for j := i - 1; j >= 0; j-- {
+			if steps[j].undo == nil {
+				continue
+			}
+			if undoErr := steps[j].undo(); undoErr != nil {
+				log.Printf("CONSISTENCY: kompensasi %q gagal setelah %q error (%v): %v\n",
+					steps[j].name, step.name, err, undoErr)
+			}
+		}
+
+		return &writeStepError{Step: step.name, Message: step.failMsg, Err: err}
+	}
+	return nil
+}
+
+// writeStepMessage mengambil pesan client dari error runWriteSteps.
+func writeStepMessage(err error) string {
+	stepErr, ok := err.(*writeStepError)
+	if !ok {
+		return err.Error()
+	}
+	if stepErr.Message == "" {
+		return stepErr.Err.Error()
+	}
+	return stepErr.Message
+}
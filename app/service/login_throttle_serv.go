@@ -0,0 +1,215 @@
+package service
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/config"
+	"UAS_GO/helper"
+	"errors"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultLoginMaxAttempts   = 5
+	defaultLoginIPMaxAttempts = 20
+	defaultLoginLockout       = 15 * time.Minute
+	defaultLoginDelayBase     = time.Second
+
+	defaultResetMaxRequests   = 3
+	defaultResetIPMaxRequests = 10
+	defaultResetWindow        = time.Hour
+
+	// batas eksponen jeda progresif supaya tidak overflow
+	maxLoginDelayShift = 10
+)
+
+// ErrInvalidCredentials: pesan tunggal untuk user tidak ada maupun password salah (mencegah enumerasi akun)
+var ErrInvalidCredentials = errors.New("invalid email/NIM or password")
+
+const (
+	loginThrottledMessage = "too many failed login attempts, please try again later"
+	resetThrottledMessage = "too many password reset requests, please try again later"
+)
+
+// LoginThrottleConfig: batas percobaan login, dibaca dari env setiap dipakai.
+type LoginThrottleConfig struct {
+	MaxAttempts   int           // LOGIN_MAX_ATTEMPTS, per akun
+	IPMaxAttempts int           // LOGIN_IP_MAX_ATTEMPTS, per IP
+	Lockout       time.Duration // LOGIN_LOCKOUT_DURATION, juga jendela reset hitungan gagal
+	DelayBase     time.Duration // LOGIN_DELAY_BASE, 0 = tanpa jeda progresif
+
+	// scope di login_throttles untuk hitungan per akun dan per IP
+	AccountScope, IPScope string
+}
+
+func LoadLoginThrottleConfig() LoginThrottleConfig {
+	return LoginThrottleConfig{
+		MaxAttempts:   envPositiveInt("LOGIN_MAX_ATTEMPTS", defaultLoginMaxAttempts),
+		IPMaxAttempts: envPositiveInt("LOGIN_IP_MAX_ATTEMPTS", defaultLoginIPMaxAttempts),
+		Lockout:       envDuration("LOGIN_LOCKOUT_DURATION", defaultLoginLockout, false),
+		DelayBase:     envDuration("LOGIN_DELAY_BASE", defaultLoginDelayBase, true),
+		AccountScope:  models.LoginThrottleAccount,
+		IPScope:       models.LoginThrottleIP,
+	}
+}
+
+// LoadPasswordResetThrottleConfig: batas permintaan reset password per identifier dan per IP
+// dalam PASSWORD_RESET_WINDOW; setiap permintaan dihitung, ada atau tidak akunnya.
+func LoadPasswordResetThrottleConfig() LoginThrottleConfig {
+	return LoginThrottleConfig{
+		MaxAttempts:   envPositiveInt("PASSWORD_RESET_MAX_REQUESTS", defaultResetMaxRequests),
+		IPMaxAttempts: envPositiveInt("PASSWORD_RESET_IP_MAX_REQUESTS", defaultResetIPMaxRequests),
+		Lockout:       envDuration("PASSWORD_RESET_WINDOW", defaultResetWindow, false),
+		AccountScope:  models.LoginThrottleReset,
+		IPScope:       models.LoginThrottleResetIP,
+	}
+}
+
+func envPositiveInt(key string, def int) int {
+	n, err := strconv.Atoi(strings.TrimSpace(config.GetEnv(key, "")))
+	if err != nil || n < 1 {
+		return def
+	}
+	return n
+}
+
+// envDuration: "0" hanya diterima jika allowZero; nilai tidak valid memakai default.
+func envDuration(key string, def time.Duration, allowZero bool) time.Duration {
+	raw := strings.TrimSpace(config.GetEnv(key, ""))
+	if raw == "" {
+		return def
+	}
+	if raw == "0" && allowZero {
+		return 0
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d < 0 || (d == 0 && !allowZero) {
+		return def
+	}
+	return d
+}
+
+// retryAfter: berapa lama klien harus menunggu sebelum boleh mencoba lagi (0 = boleh).
+// Kunci berlaku untuk semua scope; jeda progresif base·2^(gagal-1) hanya per akun.
+func (cfg LoginThrottleConfig) retryAfter(t *models.LoginThrottle, now time.Time) time.Duration {
+	if t == nil {
+		return 0
+	}
+	if t.LockedUntil != nil && t.LockedUntil.After(now) {
+		return t.LockedUntil.Sub(now)
+	}
+	if t.Scope != models.LoginThrottleAccount || cfg.DelayBase <= 0 || t.Failures < 1 {
+		return 0
+	}
+	if now.Sub(t.LastFailureAt) >= cfg.Lockout {
+		return 0
+	}
+	shift := t.Failures - 1
+	if shift > maxLoginDelayShift {
+		shift = maxLoginDelayShift
+	}
+	delay := cfg.DelayBase << shift
+	if wait := t.LastFailureAt.Add(delay).Sub(now); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// loginWait memeriksa throttle akun dan IP; mengembalikan waktu tunggu terlama.
+func (cfg LoginThrottleConfig) loginWait(accountKey, ip string) (time.Duration, error) {
+	var wait time.Duration
+	now := time.Now()
+	for _, k := range [][2]string{{cfg.AccountScope, accountKey}, {cfg.IPScope, ip}} {
+		t, err := repository.GetLoginThrottle(k[0], k[1])
+		if err != nil {
+			return 0, err
+		}
+		if w := cfg.retryAfter(t, now); w > wait {
+			wait = w
+		}
+	}
+	return wait, nil
+}
+
+// recordFailure mencatat login gagal untuk akun dan IP. Gagal mencatat hanya di-log
+// supaya respons tetap seragam; kunci baru dicatat sebagai event keamanan.
+func (cfg LoginThrottleConfig) recordFailure(accountKey, ip string) {
+	for _, k := range []struct {
+		scope, key string
+		max        int
+	}{
+		{cfg.AccountScope, accountKey, cfg.MaxAttempts},
+		{cfg.IPScope, ip, cfg.IPMaxAttempts},
+	} {
+		t, err := repository.RecordLoginFailure(k.scope, k.key, k.max, cfg.Lockout)
+		if err != nil {
+			log.Printf("login throttle: gagal mencatat kegagalan %s=%s: %v\n", k.scope, k.key, err)
+			continue
+		}
+		if t.Failures == k.max && t.LockedUntil != nil {
+			log.Printf("SECURITY: %s=%s dikunci sampai %s setelah %d percobaan (ip=%s)\n",
+				k.scope, k.key, t.LockedUntil.Format(time.RFC3339), t.Failures, ip)
+		}
+	}
+}
+
+func respondLoginThrottled(c *fiber.Ctx, wait time.Duration) error {
+	return respondThrottled(c, wait, loginThrottledMessage)
+}
+
+func respondThrottled(c *fiber.Ctx, wait time.Duration, message string) error {
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	return helper.TooManyRequests(c, message)
+}
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     string
+)
+
+// burnPasswordCheck menjalankan bcrypt terhadap hash dummy saat user tidak ditemukan,
+// supaya waktu respons tidak membedakan akun yang ada dan tidak.
+func burnPasswordCheck(password string) {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = helper.HashPassword("login-throttle-dummy-password")
+	})
+	helper.CheckPassword(password, dummyHash)
+}
+
+// AdminUnlockUser godoc
+// @Summary      Unlock user login (admin)
+// @Description  Menghapus hitungan login gagal dan kunci sementara untuk semua identifier user (email dan NIM).
+// @Description  Kunci per IP tidak ikut dihapus.
+// @Tags         Admin - Users
+// @Accept       json
+// @Produce      json
+// @Param        id   path   string  true  "User ID (UUID)"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{}  "envelope {status,message,data:{cleared}}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden"
+// @Failure      404  {object}  map[string]interface{}  "User not found"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /users/{id}/unlock [post]
+func AdminUnlockUser(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := repository.GetUserByID(id); err != nil {
+		return helper.NotFound(c, "user not found")
+	}
+
+	n, err := repository.ClearAccountLockout(id)
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	actor, _ := c.Locals("user_id").(string)
+	log.Printf("SECURITY: login user %s dibuka oleh %s (%d entri dihapus)\n", id, actor, n)
+
+	return helper.APIResponse(c, fiber.StatusOK, "user login unlocked", fiber.Map{"cleared": n})
+}
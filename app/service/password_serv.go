@@ -0,0 +1,249 @@
+package service
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/config"
+	"UAS_GO/helper"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// respons forgot selalu sama, ada atau tidak akunnya (mencegah enumerasi akun)
+const forgotPasswordMessage = "if the account exists, a password reset link has been sent"
+
+// AuthChangePassword godoc
+// @Summary      Change password
+// @Description  User yang sedang login mengganti password dengan menyertakan password lama.
+// @Description  Sesi (refresh token) di perangkat lain dicabut; sesi yang dipakai untuk request ini tetap berlaku.
+// @Description  Semua access token yang sudah terbit ditolak; perangkat ini mengambil access token baru lewat /auth/refresh.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body   models.ChangePasswordRequest  true  "Password lama dan baru"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{}  "password changed (envelope)"
+// @Failure      400  {object}  map[string]interface{}  "Invalid payload / password lama salah / password baru sama / tidak memenuhi kebijakan password"
+// @Failure      401  {object}  map[string]interface{}  "User not authenticated"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /auth/password [post]
+func AuthChangePassword(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(string)
+	if userID == "" {
+		return helper.Unauthorized(c, "user not authenticated")
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Invalid request format")
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
+
+	user, err := repository.GetUserByID(userID)
+	if err != nil {
+		return helper.Unauthorized(c, "user not authenticated")
+	}
+	if !helper.CheckPassword(req.CurrentPassword, user.PasswordHash) {
+		return helper.BadRequest(c, "current password is incorrect")
+	}
+	if req.NewPassword == req.CurrentPassword {
+		return helper.BadRequest(c, "new password must be different from the current password")
+	}
+	ids, err := repository.GetUserPasswordIdentifiers(userID)
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	if err := helper.ValidatePassword(req.NewPassword, ids...); err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
+
+	hashed, err := helper.HashPassword(req.NewPassword)
+	if err != nil {
+		return helper.InternalError(c, "Failed to hash password")
+	}
+	sessionID, _ := c.Locals("session_id").(string)
+	if err := repository.ChangeUserPassword(userID, hashed, sessionID); err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	log.Printf("SECURITY: password user %s diganti, sesi lain dicabut\n", userID)
+
+	return helper.APIResponse(c, fiber.StatusOK, "password changed, refresh your access token", nil)
+}
+
+// AuthForgotPassword godoc
+// @Summary      Request password reset
+// @Description  Mengirim token reset password (sekali pakai, berlaku PASSWORD_RESET_TTL) ke email akun.
+// @Description  Respons selalu sama walaupun email / NIM tidak terdaftar.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body   models.ForgotPasswordRequest  true  "Email atau NIM"
+// @Success      200  {object}  map[string]interface{}  "envelope (pesan seragam)"
+// @Failure      400  {object}  map[string]interface{}  "Email or NIM is required"
+// @Failure      429  {object}  map[string]interface{}  "Terlalu banyak permintaan reset (identifier / IP); lihat header Retry-After"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Failure      503  {object}  map[string]interface{}  "Mailer belum dikonfigurasi (MAIL_DRIVER)"
+// @Router       /auth/forgot [post]
+func AuthForgotPassword(c *fiber.Ctx) error {
+	var req models.ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Invalid request format")
+	}
+	req.Email, req.NIM = strings.TrimSpace(req.Email), strings.TrimSpace(req.NIM)
+	if req.Email == "" && req.NIM == "" {
+		return helper.BadRequest(c, "Email or NIM is required")
+	}
+	// dicek sebelum mencari akun supaya respons tetap sama untuk akun yang ada maupun tidak
+	if err := helper.CheckMailer(); err != nil {
+		log.Printf("password reset: email tidak bisa dikirim: %v\n", err)
+		return helper.ServiceUnavailable(c, "password reset by email is not available")
+	}
+
+	byNIM, identifier := req.NIM != "", req.Email
+	if byNIM {
+		identifier = req.NIM
+	}
+
+	// setiap permintaan dihitung (juga untuk akun yang tidak ada) per identifier dan per IP
+	throttle := LoadPasswordResetThrottleConfig()
+	accountKey := repository.LoginAccountKey(byNIM, identifier)
+	wait, err := throttle.loginWait(accountKey, c.IP())
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	if wait > 0 {
+		return respondThrottled(c, wait, resetThrottledMessage)
+	}
+	throttle.recordFailure(accountKey, c.IP())
+
+	user, err := repository.FindUserForPasswordReset(identifier, byNIM)
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	if user == nil || !user.IsActive {
+		return helper.APIResponse(c, fiber.StatusOK, forgotPasswordMessage, nil)
+	}
+
+	token, err := helper.GenerateOpaqueToken()
+	if err != nil {
+		return helper.InternalError(c, "Failed to generate reset token")
+	}
+	ttl := helper.PasswordResetTTL()
+	rt := &models.PasswordResetToken{
+		ID:        uuid.New().String(),
+		UserID:    user.ID,
+		TokenHash: helper.HashToken(token),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := repository.CreatePasswordResetToken(rt); err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+
+	// gagal kirim hanya di-log supaya respons tetap seragam
+	if err := helper.SendMail(passwordResetMail(user.Email, token, ttl)); err != nil {
+		log.Printf("password reset: gagal mengirim email ke user %s: %v\n", user.ID, err)
+	}
+	return helper.APIResponse(c, fiber.StatusOK, forgotPasswordMessage, nil)
+}
+
+// upgradePasswordHash: setelah login berhasil, hash dengan cost di bawah PASSWORD_BCRYPT_COST
+// dibuat ulang dari password yang baru saja diverifikasi. Gagal hanya di-log.
+func upgradePasswordHash(user *models.User, password string) {
+	if !helper.PasswordNeedsRehash(user.PasswordHash) {
+		return
+	}
+	hashed, err := helper.HashPassword(password)
+	if err == nil {
+		err = repository.RehashUserPassword(user.ID, user.PasswordHash, hashed)
+	}
+	if err != nil {
+		log.Printf("password rehash: gagal untuk user %s: %v\n", user.ID, err)
+	}
+}
+
+// passwordResetMail: PASSWORD_RESET_URL (mis. halaman reset di frontend) diberi query ?token=...
+func passwordResetMail(to, token string, ttl time.Duration) helper.Mail {
+	link := token
+	if base := config.GetEnv("PASSWORD_RESET_URL", ""); base != "" {
+		sep := "?"
+		if strings.Contains(base, "?") {
+			sep = "&"
+		}
+		link = base + sep + "token=" + url.QueryEscape(token)
+	}
+	return helper.Mail{
+		To:      to,
+		Subject: "Password reset",
+		Body: fmt.Sprintf("A password reset was requested for your account.\n\n%s\n\n"+
+			"This link can be used once and expires in %s. If you did not request it, you can ignore this email.",
+			link, ttl),
+	}
+}
+
+// AuthResetPassword godoc
+// @Summary      Reset password
+// @Description  Mengganti password memakai token dari email. Token hanya bisa dipakai sekali;
+// @Description  semua sesi (refresh token dan access token yang sudah terbit) user dicabut dan kunci login akun dibuka.
+// @Tags         Auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body   models.ResetPasswordRequest  true  "Token dan password baru"
+// @Success      200  {object}  map[string]interface{}  "password reset (envelope)"
+// @Failure      400  {object}  map[string]interface{}  "Invalid payload / token tidak valid atau expired / tidak memenuhi kebijakan password"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /auth/reset [post]
+func AuthResetPassword(c *fiber.Ctx) error {
+	var req models.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Invalid request format")
+	}
+	if err := validator.New().Struct(req); err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
+
+	// token dicek dulu supaya kebijakan password bisa dibandingkan dengan identitas pemiliknya
+	tokenHash := helper.HashToken(req.Token)
+	userID, err := repository.GetResetTokenUserID(tokenHash)
+	if errors.Is(err, repository.ErrResetTokenInvalid) {
+		return helper.BadRequest(c, err.Error())
+	}
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	ids, err := repository.GetUserPasswordIdentifiers(userID)
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	if err := helper.ValidatePassword(req.NewPassword, ids...); err != nil {
+		return helper.BadRequest(c, err.Error())
+	}
+
+	hashed, err := helper.HashPassword(req.NewPassword)
+	if err != nil {
+		return helper.InternalError(c, "Failed to hash password")
+	}
+	userID, err = repository.ResetPasswordWithToken(tokenHash, hashed)
+	if errors.Is(err, repository.ErrResetTokenInvalid) {
+		return helper.BadRequest(c, err.Error())
+	}
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+
+	if _, err := repository.ClearAccountLockout(userID); err != nil {
+		log.Printf("password reset: gagal membuka kunci login user %s: %v\n", userID, err)
+	}
+	log.Printf("SECURITY: password user %s direset lewat token, semua sesi dicabut\n", userID)
+
+	return helper.APIResponse(c, fiber.StatusOK, "password has been reset, please log in again", nil)
+}
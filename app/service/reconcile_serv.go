@@ -0,0 +1,262 @@
+package service
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/helper"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Aksi perbaikan rekonsiliasi
+const (
+	reconcileInsertReference     = "insert_reference"
+	reconcileSoftDeleteReference = "soft_delete_reference"
+	reconcileSoftDeleteMongo     = "soft_delete_mongo"
+	reconcileManualReview        = "manual_review" // tidak diperbaiki otomatis
+)
+
+// reconcileGrace: prestasi yang dibuat sejak (awal scan - reconcileGrace) tidak diperiksa.
+// CreateAchievement menulis dokumen Mongo lebih dulu lalu reference, dan kedua store dipindai
+// bergantian, jadi prestasi yang baru dibuat bisa terlihat hanya di satu sisi.
+const reconcileGrace = time.Minute
+
+// ReconcileAchievements membandingkan dokumen Mongo dengan achievement_references,
+// melaporkan setiap selisih per kategori, dan memperbaikinya jika dryRun=false.
+//
+// Aturan perbaikan:
+//   - dokumen tanpa reference  -> buat reference (draft, atau deleted jika dokumen sudah deleted)
+//   - reference tanpa dokumen  -> soft delete reference
+//   - deleted hanya di satu sisi -> hapus juga sisi lain, hanya jika sisi itu masih draft
+//     (satu-satunya status yang boleh dihapus); selain itu perlu dicek manual.
+//
+// Sebelum diperbaiki, kedua sisi prestasi dibaca ulang; selisih yang sudah tidak ada
+// (mis. diselesaikan request yang sedang berjalan) ditandai stale dan tidak disentuh.
+func ReconcileAchievements(dryRun bool) (*models.ReconcileReport, error) {
+	cutoff := time.Now().Add(-reconcileGrace)
+	docs, err := repository.ListAchievementMongoStates()
+	if err != nil {
+		return nil, err
+	}
+	refs, err := repository.ListAchievementReferences()
+	if err != nil {
+		return nil, err
+	}
+
+	settledDocs, settledRefs, skipped := settledReconcileState(docs, refs, cutoff)
+	issues := findReconcileIssues(settledDocs, settledRefs)
+	report := &models.ReconcileReport{
+		DryRun:            dryRun,
+		ScannedMongo:      len(docs),
+		ScannedReferences: len(refs),
+		SkippedRecent:     skipped,
+		Counts:            map[string]int{},
+		Issues:            issues,
+	}
+
+	for i := range issues {
+		report.Counts[issues[i].Category]++
+		if dryRun || issues[i].Action == reconcileManualReview {
+			continue
+		}
+		fresh, err := recheckReconcileIssue(issues[i], cutoff)
+		if err != nil {
+			issues[i].Error = err.Error()
+			log.Printf("reconcile: gagal membaca ulang %s (%s): %v\n", issues[i].MongoID, issues[i].Category, err)
+			continue
+		}
+		if fresh == nil {
+			issues[i].Stale = true
+			continue
+		}
+		issues[i] = *fresh
+		if err := repairReconcileIssue(&issues[i]); err != nil {
+			issues[i].Error = err.Error()
+			log.Printf("reconcile: gagal memperbaiki %s (%s): %v\n", issues[i].MongoID, issues[i].Category, err)
+			continue
+		}
+		issues[i].Repaired = true
+	}
+
+	return report, nil
+}
+
+// settledReconcileState membuang prestasi yang dokumen atau reference-nya dibuat setelah cutoff
+// (kedua sisinya, supaya sisi lain tidak terlihat sebagai orphan). Mengembalikan jumlah prestasi yang dibuang.
+func settledReconcileState(docs []models.AchievementMongoState, refs []models.AchievementReference, cutoff time.Time) ([]models.AchievementMongoState, []models.AchievementReference, int) {
+	recent := map[string]bool{}
+	for _, d := range docs {
+		if d.CreatedAt.After(cutoff) {
+			recent[d.MongoID] = true
+		}
+	}
+	for _, r := range refs {
+		if r.CreatedAt.After(cutoff) {
+			recent[r.MongoAchievementID] = true
+		}
+	}
+	if len(recent) == 0 {
+		return docs, refs, 0
+	}
+
+	settledDocs := make([]models.AchievementMongoState, 0, len(docs))
+	for _, d := range docs {
+		if !recent[d.MongoID] {
+			settledDocs = append(settledDocs, d)
+		}
+	}
+	settledRefs := make([]models.AchievementReference, 0, len(refs))
+	for _, r := range refs {
+		if !recent[r.MongoAchievementID] {
+			settledRefs = append(settledRefs, r)
+		}
+	}
+	return settledDocs, settledRefs, len(recent)
+}
+
+// recheckReconcileIssue membaca ulang dokumen dan reference satu prestasi. Mengembalikan issue
+// dengan data terbaru jika selisih yang sama (kategori dan aksi) masih ada, atau nil jika sudah tidak ada.
+func recheckReconcileIssue(issue models.ReconcileIssue, cutoff time.Time) (*models.ReconcileIssue, error) {
+	doc, err := repository.GetAchievementMongoState(issue.MongoID)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := repository.GetAchievementReferenceByMongoID(issue.MongoID)
+	if errors.Is(err, sql.ErrNoRows) {
+		ref, err = nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []models.AchievementMongoState
+	var refs []models.AchievementReference
+	if doc != nil {
+		docs = append(docs, *doc)
+	}
+	if ref != nil {
+		refs = append(refs, *ref)
+	}
+	docs, refs, _ = settledReconcileState(docs, refs, cutoff)
+
+	for _, fresh := range findReconcileIssues(docs, refs) {
+		if fresh.Category == issue.Category && fresh.Action == issue.Action {
+			return &fresh, nil
+		}
+	}
+	return nil, nil
+}
+
+func findReconcileIssues(docs []models.AchievementMongoState, refs []models.AchievementReference) []models.ReconcileIssue {
+	issues := []models.ReconcileIssue{}
+
+	refByMongoID := make(map[string]models.AchievementReference, len(refs))
+	for _, r := range refs {
+		refByMongoID[r.MongoAchievementID] = r
+	}
+
+	seen := make(map[string]bool, len(docs))
+	for _, d := range docs {
+		seen[d.MongoID] = true
+		ref, ok := refByMongoID[d.MongoID]
+
+		if !ok {
+			issue := models.ReconcileIssue{
+				Category:    models.ReconcileMongoOrphan,
+				MongoID:     d.MongoID,
+				StudentID:   d.StudentID,
+				MongoStatus: d.Status,
+				Action:      reconcileInsertReference,
+			}
+			if d.StudentID == "" {
+				issue.Action = reconcileManualReview
+			}
+			issues = append(issues, issue)
+			continue
+		}
+
+		mongoDeleted := d.Status == "deleted"
+		refDeleted := ref.Status == "deleted"
+		if mongoDeleted == refDeleted {
+			continue
+		}
+
+		issue := models.ReconcileIssue{
+			MongoID:         d.MongoID,
+			ReferenceID:     ref.ID,
+			StudentID:       ref.StudentID,
+			MongoStatus:     d.Status,
+			ReferenceStatus: ref.Status,
+			Action:          reconcileManualReview,
+		}
+		if mongoDeleted {
+			issue.Category = models.ReconcileDeletedOnlyInMongo
+			if ref.Status == "draft" {
+				issue.Action = reconcileSoftDeleteReference
+			}
+		} else {
+			issue.Category = models.ReconcileDeletedOnlyReference
+			issue.Action = reconcileSoftDeleteMongo
+		}
+		issues = append(issues, issue)
+	}
+
+	for _, r := range refs {
+		if seen[r.MongoAchievementID] || r.Status == "deleted" {
+			continue
+		}
+		issues = append(issues, models.ReconcileIssue{
+			Category:        models.ReconcileReferenceOrphan,
+			MongoID:         r.MongoAchievementID,
+			ReferenceID:     r.ID,
+			StudentID:       r.StudentID,
+			ReferenceStatus: r.Status,
+			Action:          reconcileSoftDeleteReference,
+		})
+	}
+
+	return issues
+}
+
+func repairReconcileIssue(issue *models.ReconcileIssue) error {
+	switch issue.Action {
+	case reconcileInsertReference:
+		status := "draft"
+		if issue.MongoStatus == "deleted" {
+			status = "deleted"
+		}
+		return repository.InsertMissingReference(issue.StudentID, issue.MongoID, status)
+	case reconcileSoftDeleteReference:
+		return repository.AchievementSoftDeleteReference(issue.ReferenceID, []string{issue.ReferenceStatus})
+	case reconcileSoftDeleteMongo:
+		return repository.AchievementSoftDeleteMongo(issue.MongoID)
+	}
+	return nil
+}
+
+// AdminReconcileAchievements godoc
+// @Summary      Reconcile achievements (admin)
+// @Description  Membandingkan dokumen MongoDB dengan achievement_references dan melaporkan selisihnya per kategori. Dengan dry_run=false selisih diperbaiki
+// @Description  setelah kedua sisi dibaca ulang (selisih yang sudah hilang ditandai stale). Prestasi yang dibuat kurang dari satu menit sebelum scan tidak diperiksa.
+// @Tags         Admin - Maintenance
+// @Produce      json
+// @Param        dry_run  query  bool  false  "Hanya laporan, tanpa perbaikan (default true)"
+// @Security     BearerAuth
+// @Success      200  {object}  models.ReconcileReport
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh maintenance:reconcile)"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /admin/reconcile [post]
+func AdminReconcileAchievements(c *fiber.Ctx) error {
+	dryRun := c.QueryBool("dry_run", true)
+
+	report, err := ReconcileAchievements(dryRun)
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "reconcile finished", report)
+}
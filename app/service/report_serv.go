@@ -24,8 +24,8 @@ import (
 // @Failure      500  {object}  map[string]interface{}  "error response"
 // @Router       /statistics/global [get]
 func GetGlobalStatistics(c *fiber.Ctx) error {
-	role, ok := c.Locals("role").(string)
-	if !ok || role == "" {
+	role := scopeRole(c)
+	if role == "" {
 		return helper.Unauthorized(c, "Unauthorized: role not found")
 	}
 	userID, ok := c.Locals("user_id").(string)
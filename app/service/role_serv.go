@@ -0,0 +1,439 @@
+package service
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/helper"
+	"errors"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// role admin selalu memegang semua permission; namanya dipakai langsung oleh middleware
+const adminRoleName = "admin"
+
+// roleManagePermission: permission route yang mengizinkan perubahan role (lihat route/role_route.go)
+const roleManagePermission = "role:manage"
+
+var roleNameRe = regexp.MustCompile(`^[a-z][a-z0-9_]{1,49}$`)
+
+// rbacAuthorizingRole: role user yang memberi permission untuk perubahan RBAC ini (lewat cache role).
+// Izin dari grant per user atau role yang tidak ditemukan → semua role user (dipisah koma), seperti eventActorRole.
+func rbacAuthorizingRole(c *fiber.Ctx, permission string) string {
+	roleIDs, _ := c.Locals("role_ids").([]string)
+	for _, id := range roleIDs {
+		if has, err := repository.CachedRoleHasPermission(id, permission); err != nil || !has {
+			continue
+		}
+		if name, err := repository.CachedRoleName(id); err == nil {
+			return name
+		}
+	}
+	return strings.Join(currentRoles(c), ",")
+}
+
+// rbacAuditEntry melengkapi entri audit dengan actor dari JWT context;
+// permission = permission route yang mengizinkan perubahan.
+func rbacAuditEntry(c *fiber.Ctx, permission string, e models.RBACAuditEntry) models.RBACAuditEntry {
+	e.ActorUserID = optionalString(helper.GetUserID(c))
+	e.ActorRole = optionalString(rbacAuthorizingRole(c, permission))
+	return e
+}
+
+// recordRBACAudit menulis log audit RBAC setelah perubahan penugasan user berhasil disimpan.
+// Kegagalan hanya dicatat di log. Perubahan role / permission role menulis auditnya
+// di transaksi yang sama lewat repository (lihat AdminCreateRole dkk.).
+func recordRBACAudit(c *fiber.Ctx, permission string, e models.RBACAuditEntry) {
+	e = rbacAuditEntry(c, permission, e)
+
+	if err := repository.InsertRBACAudit(&e); err != nil {
+		log.Printf("AUDIT: gagal mencatat %s (role %v): %v\n", e.Action, e.RoleID, err)
+	}
+}
+
+func roleErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrRoleNotFound):
+		return helper.NotFound(c, err.Error())
+	case errors.Is(err, repository.ErrRoleExists), errors.Is(err, repository.ErrRoleInUse):
+		return helper.Conflict(c, err.Error())
+	}
+	return helper.InternalError(c, err.Error())
+}
+
+// normalizePermissionNames merapikan daftar permission dan mengembalikan nama yang tidak dikenal.
+func normalizePermissionNames(names []string) ([]string, []string, error) {
+	known, err := repository.ListPermissions()
+	if err != nil {
+		return nil, nil, err
+	}
+	exists := make(map[string]bool, len(known))
+	for _, p := range known {
+		exists[p.Name] = true
+	}
+
+	seen := map[string]bool{}
+	out, unknown := []string{}, []string{}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		if exists[name] {
+			out = append(out, name)
+		} else {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(out)
+	return out, unknown, nil
+}
+
+// normalizeRoleRequest merapikan nama / deskripsi role dan mengembalikan pesan validasi.
+func normalizeRoleRequest(req *models.RoleRequest) string {
+	req.Name = strings.ToLower(strings.TrimSpace(req.Name))
+	req.Description = strings.TrimSpace(req.Description)
+	if !roleNameRe.MatchString(req.Name) {
+		return "name must start with a letter and contain only a-z, 0-9 and _ (2-50 characters)"
+	}
+	return ""
+}
+
+// AdminListRoles godoc
+// @Summary      List roles
+// @Description  Semua role beserta permission dan jumlah user-nya
+// @Tags         Admin - Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{}  "envelope {status,message,data:[models.Role]}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh role:read)"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /roles [get]
+func AdminListRoles(c *fiber.Ctx) error {
+	roles, err := repository.ListRoles()
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "roles retrieved", roles)
+}
+
+// AdminGetRole godoc
+// @Summary      Get role
+// @Description  Detail satu role beserta permission dan jumlah user-nya
+// @Tags         Admin - Roles
+// @Produce      json
+// @Param        id   path   string  true  "Role ID (UUID)"
+// @Security     BearerAuth
+// @Success      200  {object}  models.Role
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh role:read)"
+// @Failure      404  {object}  map[string]interface{}  "Role not found"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /roles/{id} [get]
+func AdminGetRole(c *fiber.Ctx) error {
+	role, err := repository.GetRoleByID(c.Params("id"))
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "role retrieved", role)
+}
+
+// AdminCreateRole godoc
+// @Summary      Create role
+// @Description  Membuat role baru (mis. kaprodi) sekaligus permission awalnya
+// @Tags         Admin - Roles
+// @Accept       json
+// @Produce      json
+// @Param        body  body   models.RoleRequest  true  "Role"
+// @Security     BearerAuth
+// @Success      201  {object}  models.Role
+// @Failure      400  {object}  map[string]interface{}  "Nama tidak valid / permission tidak dikenal"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh role:manage)"
+// @Failure      409  {object}  map[string]interface{}  "Nama role sudah dipakai"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /roles [post]
+func AdminCreateRole(c *fiber.Ctx) error {
+	var req models.RoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Invalid request body")
+	}
+	if msg := normalizeRoleRequest(&req); msg != "" {
+		return helper.BadRequest(c, msg)
+	}
+
+	perms, unknown, err := normalizePermissionNames(req.Permissions)
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	if len(unknown) > 0 {
+		return helper.BadRequest(c, "unknown permissions: "+strings.Join(unknown, ", "))
+	}
+	req.Permissions = perms
+
+	// RoleID diisi repository setelah role dibuat
+	role, err := repository.CreateRole(req, rbacAuditEntry(c, roleManagePermission, models.RBACAuditEntry{
+		Action:   models.RBACAuditRoleCreated,
+		RoleName: optionalString(req.Name),
+		Changes: map[string]models.FieldChange{
+			"name":        {To: req.Name},
+			"description": {To: req.Description},
+			"permissions": {To: req.Permissions},
+		},
+	}))
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+	return helper.APIResponse(c, fiber.StatusCreated, "role created", role)
+}
+
+// AdminUpdateRole godoc
+// @Summary      Update role
+// @Description  Mengubah nama dan deskripsi role. Role sistem (admin, mahasiswa, dosen_wali) tidak bisa diganti nama. Permission diatur lewat /roles/{id}/permissions.
+// @Tags         Admin - Roles
+// @Accept       json
+// @Produce      json
+// @Param        id    path   string              true  "Role ID (UUID)"
+// @Param        body  body   models.RoleRequest  true  "Role (permissions diabaikan)"
+// @Security     BearerAuth
+// @Success      200  {object}  models.Role
+// @Failure      400  {object}  map[string]interface{}  "Nama tidak valid"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh role:manage)"
+// @Failure      404  {object}  map[string]interface{}  "Role not found"
+// @Failure      409  {object}  map[string]interface{}  "Nama sudah dipakai / role sistem"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /roles/{id} [put]
+func AdminUpdateRole(c *fiber.Ctx) error {
+	var req models.RoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Invalid request body")
+	}
+	if msg := normalizeRoleRequest(&req); msg != "" {
+		return helper.BadRequest(c, msg)
+	}
+
+	existing, err := repository.GetRoleByID(c.Params("id"))
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+	if existing.IsSystem && req.Name != existing.Name {
+		return helper.Conflict(c, "role "+existing.Name+" is a system role and cannot be renamed")
+	}
+
+	changes := map[string]models.FieldChange{}
+	if existing.Name != req.Name {
+		changes["name"] = models.FieldChange{From: existing.Name, To: req.Name}
+	}
+	if existing.Description != req.Description {
+		changes["description"] = models.FieldChange{From: existing.Description, To: req.Description}
+	}
+	var audit *models.RBACAuditEntry
+	if len(changes) > 0 {
+		e := rbacAuditEntry(c, roleManagePermission, models.RBACAuditEntry{
+			Action:   models.RBACAuditRoleUpdated,
+			RoleID:   optionalString(existing.ID),
+			RoleName: optionalString(req.Name),
+			Changes:  changes,
+		})
+		audit = &e
+	}
+
+	role, err := repository.UpdateRole(existing.ID, req, audit)
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "role updated", role)
+}
+
+// AdminDeleteRole godoc
+// @Summary      Delete role
+// @Description  Menghapus role non-sistem yang tidak lagi dipakai user
+// @Tags         Admin - Roles
+// @Produce      json
+// @Param        id   path   string  true  "Role ID (UUID)"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{}  "envelope {status,message,data:null}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh role:manage)"
+// @Failure      404  {object}  map[string]interface{}  "Role not found"
+// @Failure      409  {object}  map[string]interface{}  "Role sistem / masih dipakai user"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /roles/{id} [delete]
+func AdminDeleteRole(c *fiber.Ctx) error {
+	role, err := repository.GetRoleByID(c.Params("id"))
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+	if role.IsSystem {
+		return helper.Conflict(c, "role "+role.Name+" is a system role and cannot be deleted")
+	}
+	if role.UserCount > 0 {
+		return helper.Conflict(c, repository.ErrRoleInUse.Error())
+	}
+
+	err = repository.DeleteRole(role.ID, rbacAuditEntry(c, roleManagePermission, models.RBACAuditEntry{
+		Action:   models.RBACAuditRoleDeleted,
+		RoleID:   optionalString(role.ID),
+		RoleName: optionalString(role.Name),
+		Changes: map[string]models.FieldChange{
+			"permissions": {From: role.Permissions},
+		},
+	}))
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "role deleted", nil)
+}
+
+// AdminListPermissions godoc
+// @Summary      List permissions
+// @Description  Semua permission yang dikenal aplikasi (didefinisikan oleh route, tidak bisa dibuat lewat API)
+// @Tags         Admin - Roles
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{}  "envelope {status,message,data:[models.Permission]}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh role:read)"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /permissions [get]
+func AdminListPermissions(c *fiber.Ctx) error {
+	perms, err := repository.ListPermissions()
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "permissions retrieved", perms)
+}
+
+// AdminGrantRolePermissions godoc
+// @Summary      Grant permissions to role
+// @Description  Menambah permission ke role (idempoten). Data berisi role terbaru dan permission yang baru ditambahkan.
+// @Tags         Admin - Roles
+// @Accept       json
+// @Produce      json
+// @Param        id    path   string                         true  "Role ID (UUID)"
+// @Param        body  body   models.RolePermissionsRequest  true  "Permissions"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{}  "envelope {status,message,data:{role,granted}}"
+// @Failure      400  {object}  map[string]interface{}  "Permission kosong / tidak dikenal"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh role:manage)"
+// @Failure      404  {object}  map[string]interface{}  "Role not found"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /roles/{id}/permissions [post]
+func AdminGrantRolePermissions(c *fiber.Ctx) error {
+	var req models.RolePermissionsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Invalid request body")
+	}
+
+	perms, unknown, err := normalizePermissionNames(req.Permissions)
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	if len(unknown) > 0 {
+		return helper.BadRequest(c, "unknown permissions: "+strings.Join(unknown, ", "))
+	}
+	if len(perms) == 0 {
+		return helper.BadRequest(c, "permissions is required")
+	}
+
+	role, err := repository.GetRoleByID(c.Params("id"))
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+	// satu baris audit per permission yang benar-benar baru (Permission diisi repository)
+	granted, err := repository.GrantRolePermissions(role.ID, perms, rbacAuditEntry(c, roleManagePermission, models.RBACAuditEntry{
+		Action:   models.RBACAuditPermissionGranted,
+		RoleID:   optionalString(role.ID),
+		RoleName: optionalString(role.Name),
+	}))
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+
+	if role, err = repository.GetRoleByID(role.ID); err != nil {
+		return roleErrorResponse(c, err)
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "permissions granted", fiber.Map{"role": role, "granted": granted})
+}
+
+// AdminRevokeRolePermission godoc
+// @Summary      Revoke permission from role
+// @Description  Mencabut satu permission dari role. Permission role admin tidak bisa dicabut agar admin tidak terkunci dari sistem.
+// @Tags         Admin - Roles
+// @Produce      json
+// @Param        id          path   string  true  "Role ID (UUID)"
+// @Param        permission  path   string  true  "Nama permission, mis. achievement:verify"
+// @Security     BearerAuth
+// @Success      200  {object}  models.Role
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh role:manage)"
+// @Failure      404  {object}  map[string]interface{}  "Role not found / role tidak memiliki permission tsb"
+// @Failure      409  {object}  map[string]interface{}  "Role admin"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /roles/{id}/permissions/{permission} [delete]
+func AdminRevokeRolePermission(c *fiber.Ctx) error {
+	permission := c.Params("permission")
+
+	role, err := repository.GetRoleByID(c.Params("id"))
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+	if role.Name == adminRoleName {
+		return helper.Conflict(c, "permissions cannot be revoked from the admin role")
+	}
+
+	revoked, err := repository.RevokeRolePermission(role.ID, permission, rbacAuditEntry(c, roleManagePermission, models.RBACAuditEntry{
+		Action:     models.RBACAuditPermissionRevoked,
+		RoleID:     optionalString(role.ID),
+		RoleName:   optionalString(role.Name),
+		Permission: optionalString(permission),
+	}))
+	if err != nil {
+		return roleErrorResponse(c, err)
+	}
+	if !revoked {
+		return helper.NotFound(c, "role "+role.Name+" does not have permission "+permission)
+	}
+
+	if role, err = repository.GetRoleByID(role.ID); err != nil {
+		return roleErrorResponse(c, err)
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "permission revoked", role)
+}
+
+// AdminGetRBACAudit godoc
+// @Summary      RBAC audit log
+// @Description  Riwayat perubahan role, permission, dan penugasan role user (terbaru dulu)
+// @Tags         Admin - Roles
+// @Produce      json
+// @Param        role_id  query  string  false  "Filter per role (UUID)"
+// @Param        limit    query  int     false  "Jumlah entri (default 50, maks. 200)"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{}  "envelope {status,message,data:[models.RBACAuditEntry]}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh role:read)"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /roles/audit [get]
+func AdminGetRBACAudit(c *fiber.Ctx) error {
+	limit := helper.GetIntQuery(c, "limit", 50)
+	if limit <= 0 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200
+	}
+
+	entries, err := repository.ListRBACAudit(c.Query("role_id"), limit)
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "rbac audit log retrieved", entries)
+}
@@ -0,0 +1,421 @@
+package service
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/helper"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// matchScoringRule memilih aturan rubrik untuk satu prestasi.
+// Aturan cocok jika tipenya sama dan setiap kriteria yang terisi sama dengan details;
+// dari yang cocok dipilih yang paling spesifik (kriteria terisi paling banyak).
+func matchScoringRule(rules []models.ScoringRule, achType string, details map[string]any) *models.ScoringRule {
+	level := detailString(details, "competitionLevel")
+	participation := strings.ToLower(detailString(details, "participation"))
+	rank, hasRank := detailInt(details, "rank")
+
+	var best *models.ScoringRule
+	bestScore := -1
+	for i := range rules {
+		r := &rules[i]
+		if r.AchievementType != achType {
+			continue
+		}
+
+		score := 0
+		if r.CompetitionLevel != nil {
+			if !strings.EqualFold(*r.CompetitionLevel, level) {
+				continue
+			}
+			score++
+		}
+		if r.Rank != nil {
+			if !hasRank || *r.Rank != rank {
+				continue
+			}
+			score++
+		}
+		if r.Participation != nil {
+			if *r.Participation != participation {
+				continue
+			}
+			score++
+		}
+
+		if score > bestScore {
+			best, bestScore = r, score
+		}
+	}
+	return best
+}
+
+func detailString(details map[string]any, key string) string {
+	s, _ := details[key].(string)
+	return strings.TrimSpace(s)
+}
+
+// detailInt membaca angka dari details (bisa int32/int64 dari Mongo, float64 dari JSON, atau string).
+func detailInt(details map[string]any, key string) (int, bool) {
+	switch v := details[key].(type) {
+	case int:
+		return v, true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), v == float64(int(v))
+	case string:
+		n, err := strconv.Atoi(strings.TrimSpace(v))
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// suggestScore menghitung poin rubrik beserta rentang yang diizinkan kebijakan.
+func suggestScore(rules []models.ScoringRule, policy *models.ScoringPolicy, achType string, details map[string]any) models.ScoreSuggestion {
+	s := models.ScoreSuggestion{Mode: policy.Mode}
+
+	rule := matchScoringRule(rules, achType, details)
+	if rule == nil {
+		return s
+	}
+
+	points := rule.Points
+	minPoints, maxPoints := points, points
+	if policy.Mode == models.ScoringModeOverride {
+		delta := points * policy.MaxOverridePercent / 100
+		minPoints, maxPoints = max(1, points-delta), points+delta
+	}
+
+	s.SuggestedPoints = &points
+	s.RuleID = rule.ID
+	s.MinPoints = &minPoints
+	s.MaxPoints = &maxPoints
+	return s
+}
+
+// resolvePoints menentukan poin akhir dari input dosen. Points 0 = pakai rubrik.
+// Mengembalikan overridden=true jika poin menyimpang dari rubrik (dengan justifikasi).
+func resolvePoints(s models.ScoreSuggestion, requested int, justification string) (int, bool, error) {
+	if s.SuggestedPoints == nil {
+		// belum ada aturan untuk prestasi ini: poin tetap diisi dosen
+		if requested <= 0 {
+			return 0, false, errors.New("Points must be > 0")
+		}
+		return requested, false, nil
+	}
+
+	suggested := *s.SuggestedPoints
+	if requested == 0 || requested == suggested {
+		return suggested, false, nil
+	}
+	if s.Mode == models.ScoringModeEnforce {
+		return 0, false, fmt.Errorf("Points must follow the scoring rubric (%d)", suggested)
+	}
+	if requested < *s.MinPoints || requested > *s.MaxPoints {
+		return 0, false, fmt.Errorf("Points must be between %d and %d", *s.MinPoints, *s.MaxPoints)
+	}
+	if strings.TrimSpace(justification) == "" {
+		return 0, false, errors.New("Justification is required when overriding the suggested points")
+	}
+	return requested, true, nil
+}
+
+// verifiedEvent: event verifikasi; override rubrik dicatat beserta poin saran dan justifikasinya.
+func verifiedEvent(mongoID, from, to string, points int, s models.ScoreSuggestion, overridden bool, justification string) models.AchievementEvent {
+	e := statusEvent(mongoID, models.AchievementEventVerified, from, to)
+	e.Changes = map[string]models.FieldChange{"points": {To: points}}
+	if overridden {
+		e.Changes["suggested_points"] = models.FieldChange{To: *s.SuggestedPoints}
+		e.Note = optionalString(strings.TrimSpace(justification))
+	}
+	return e
+}
+
+// loadScoring mengambil rubrik dan kebijakan sekaligus.
+// default_points katalog ditambahkan sebagai aturan tanpa kriteria di akhir daftar,
+// sehingga hanya dipakai jika tidak ada aturan rubrik yang cocok.
+func loadScoring() ([]models.ScoringRule, *models.ScoringPolicy, error) {
+	rules, err := repository.ListScoringRules()
+	if err != nil {
+		return nil, nil, err
+	}
+	defaults, err := repository.GetAchievementTypeDefaultPoints()
+	if err != nil {
+		return nil, nil, err
+	}
+	codes := make([]string, 0, len(defaults))
+	for code := range defaults {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		rules = append(rules, models.ScoringRule{AchievementType: code, Points: defaults[code]})
+	}
+
+	policy, err := repository.GetScoringPolicy()
+	if err != nil {
+		return nil, nil, err
+	}
+	return rules, policy, nil
+}
+
+// GetAchievementScore godoc
+// @Summary      Suggested points
+// @Description  Poin yang disarankan rubrik untuk prestasi mahasiswa bimbingan, beserta rentang poin yang diizinkan kebijakan
+// @Tags         Achievements
+// @Produce      json
+// @Param        id   path   string  true  "Mongo Achievement ID"
+// @Security     BearerAuth
+// @Success      200  {object}  models.ScoreSuggestion
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (not advisor)"
+// @Failure      404  {object}  map[string]interface{}  "Achievement not found"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /achievements/{id}/score [get]
+func GetAchievementScore(c *fiber.Ctx) error {
+	id := c.Params("id")
+
+	currentUserID := helper.GetUserID(c)
+	if currentUserID == "" {
+		return helper.Unauthorized(c, "Unauthorized")
+	}
+
+	lecturerID, err := repository.GetLecturerIDByUserID(currentUserID)
+	if err != nil {
+		return helper.Forbidden(c, "Lecturer profile not found")
+	}
+
+	ref, err := repository.GetAchievementReferenceByMongoID(id)
+	if err != nil {
+		return helper.NotFound(c, "Achievement reference not found")
+	}
+
+	isAdvisor, err := repository.IsLecturerAdvisorOfStudent(lecturerID, ref.StudentID)
+	if err != nil {
+		return helper.InternalError(c, "Error verifying advisor relationship")
+	}
+	if !isAdvisor {
+		return helper.Forbidden(c, "You are not the academic advisor for this student")
+	}
+
+	ach, err := repository.GetAchievementByIdMongo(id)
+	if err != nil {
+		return helper.NotFound(c, "Achievement not found")
+	}
+
+	rules, policy, err := loadScoring()
+	if err != nil {
+		return helper.InternalError(c, "Failed to load scoring rules")
+	}
+
+	return helper.APIResponse(c, fiber.StatusOK, "Success", suggestScore(rules, policy, ach.AchievementType, ach.Details))
+}
+
+// normalizeScoringRule merapikan kriteria (string kosong = semua nilai) dan mengembalikan pesan validasi.
+func normalizeScoringRule(req *models.ScoringRuleRequest) string {
+	req.AchievementType = strings.TrimSpace(req.AchievementType)
+	if req.AchievementType == "" {
+		return "achievement_type is required"
+	}
+	if req.Points <= 0 {
+		return "points must be > 0"
+	}
+	if req.CompetitionLevel != nil {
+		level := strings.TrimSpace(*req.CompetitionLevel)
+		req.CompetitionLevel = nil
+		if level != "" {
+			req.CompetitionLevel = &level
+		}
+	}
+	if req.Rank != nil && *req.Rank <= 0 {
+		return "rank must be > 0"
+	}
+	if req.Participation != nil {
+		p := strings.ToLower(strings.TrimSpace(*req.Participation))
+		req.Participation = nil
+		switch p {
+		case "":
+		case models.ParticipationIndividual, models.ParticipationTeam:
+			req.Participation = &p
+		default:
+			return "participation must be individual or team"
+		}
+	}
+	return ""
+}
+
+func scoringRuleErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrScoringRuleNotFound):
+		return helper.NotFound(c, err.Error())
+	case errors.Is(err, repository.ErrScoringRuleExists):
+		return helper.Conflict(c, err.Error())
+	case errors.Is(err, repository.ErrAchievementTypeNotFound):
+		return helper.BadRequest(c, "achievement_type is not in the achievement type catalogue")
+	}
+	return helper.InternalError(c, err.Error())
+}
+
+// AdminListScoringRules godoc
+// @Summary      List scoring rules (admin)
+// @Description  Mengambil seluruh rubrik poin prestasi
+// @Tags         Admin - Scoring
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{}  "envelope {status,message,data:[rules]}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh scoring:manage)"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /admin/scoring-rules [get]
+func AdminListScoringRules(c *fiber.Ctx) error {
+	rules, err := repository.ListScoringRules()
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "scoring rules retrieved", rules)
+}
+
+// AdminCreateScoringRule godoc
+// @Summary      Create scoring rule (admin)
+// @Description  Menambah aturan rubrik. Kriteria kosong (competition_level, rank, participation) berlaku untuk semua nilai.
+// @Tags         Admin - Scoring
+// @Accept       json
+// @Produce      json
+// @Param        body  body   models.ScoringRuleRequest  true  "Scoring rule"
+// @Security     BearerAuth
+// @Success      201  {object}  map[string]interface{}  "envelope {status,message,data:rule}"
+// @Failure      400  {object}  map[string]interface{}  "Validation error"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh scoring:manage)"
+// @Failure      409  {object}  map[string]interface{}  "Aturan dengan kriteria yang sama sudah ada"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /admin/scoring-rules [post]
+func AdminCreateScoringRule(c *fiber.Ctx) error {
+	var req models.ScoringRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Invalid request body")
+	}
+	if msg := normalizeScoringRule(&req); msg != "" {
+		return helper.BadRequest(c, msg)
+	}
+
+	rule, err := repository.CreateScoringRule(req)
+	if err != nil {
+		return scoringRuleErrorResponse(c, err)
+	}
+	return helper.APIResponse(c, fiber.StatusCreated, "scoring rule created", rule)
+}
+
+// AdminUpdateScoringRule godoc
+// @Summary      Update scoring rule (admin)
+// @Description  Mengganti kriteria dan poin satu aturan rubrik
+// @Tags         Admin - Scoring
+// @Accept       json
+// @Produce      json
+// @Param        id    path   string                     true  "Scoring rule ID (UUID)"
+// @Param        body  body   models.ScoringRuleRequest  true  "Scoring rule"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{}  "envelope {status,message,data:rule}"
+// @Failure      400  {object}  map[string]interface{}  "Validation error"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh scoring:manage)"
+// @Failure      404  {object}  map[string]interface{}  "Scoring rule not found"
+// @Failure      409  {object}  map[string]interface{}  "Aturan dengan kriteria yang sama sudah ada"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /admin/scoring-rules/{id} [put]
+func AdminUpdateScoringRule(c *fiber.Ctx) error {
+	var req models.ScoringRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Invalid request body")
+	}
+	if msg := normalizeScoringRule(&req); msg != "" {
+		return helper.BadRequest(c, msg)
+	}
+
+	rule, err := repository.UpdateScoringRule(c.Params("id"), req)
+	if err != nil {
+		return scoringRuleErrorResponse(c, err)
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "scoring rule updated", rule)
+}
+
+// AdminDeleteScoringRule godoc
+// @Summary      Delete scoring rule (admin)
+// @Description  Menghapus satu aturan rubrik. Prestasi yang sudah diverifikasi tidak berubah.
+// @Tags         Admin - Scoring
+// @Produce      json
+// @Param        id   path   string  true  "Scoring rule ID (UUID)"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{}  "envelope {status,message,data:null}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh scoring:manage)"
+// @Failure      404  {object}  map[string]interface{}  "Scoring rule not found"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /admin/scoring-rules/{id} [delete]
+func AdminDeleteScoringRule(c *fiber.Ctx) error {
+	if err := repository.DeleteScoringRule(c.Params("id")); err != nil {
+		return scoringRuleErrorResponse(c, err)
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "scoring rule deleted", nil)
+}
+
+// AdminGetScoringPolicy godoc
+// @Summary      Get scoring policy (admin)
+// @Description  Mode penerapan rubrik (enforce / override) dan batas override dalam persen
+// @Tags         Admin - Scoring
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  models.ScoringPolicy
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh scoring:manage)"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /admin/scoring-policy [get]
+func AdminGetScoringPolicy(c *fiber.Ctx) error {
+	policy, err := repository.GetScoringPolicy()
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "scoring policy retrieved", policy)
+}
+
+// AdminUpdateScoringPolicy godoc
+// @Summary      Update scoring policy (admin)
+// @Description  enforce = poin harus sama dengan rubrik; override = dosen boleh menyimpang maks. max_override_percent dengan justifikasi
+// @Tags         Admin - Scoring
+// @Accept       json
+// @Produce      json
+// @Param        body  body   models.ScoringPolicyRequest  true  "Scoring policy"
+// @Security     BearerAuth
+// @Success      200  {object}  models.ScoringPolicy
+// @Failure      400  {object}  map[string]interface{}  "Validation error"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (butuh scoring:manage)"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /admin/scoring-policy [put]
+func AdminUpdateScoringPolicy(c *fiber.Ctx) error {
+	var req models.ScoringPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Invalid request body")
+	}
+	if req.Mode != models.ScoringModeEnforce && req.Mode != models.ScoringModeOverride {
+		return helper.BadRequest(c, "mode must be enforce or override")
+	}
+	if req.MaxOverridePercent < 0 || req.MaxOverridePercent > 100 {
+		return helper.BadRequest(c, "max_override_percent must be between 0 and 100")
+	}
+
+	policy, err := repository.UpdateScoringPolicy(req)
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	return helper.APIResponse(c, fiber.StatusOK, "scoring policy updated", policy)
+}
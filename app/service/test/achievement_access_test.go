@@ -0,0 +1,126 @@
+package service_test
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/app/service"
+	"UAS_GO/middleware"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	bm "bou.ke/monkey"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// patchAccessLookups: stu-1 milik student-user, dibimbing lec-1 (lecturer-user);
+// other-student-user = stu-2, other-lecturer-user = lec-2
+func patchAccessLookups(t *testing.T) {
+	students := map[string]string{"student-user": "stu-1", "other-student-user": "stu-2"}
+	lecturers := map[string]string{"lecturer-user": "lec-1", "other-lecturer-user": "lec-2"}
+
+	pS := bm.Patch(repository.GetStudentIDByUserID, func(userID string) (string, error) {
+		if id, ok := students[userID]; ok {
+			return id, nil
+		}
+		return "", errors.New("student not found")
+	})
+	pL := bm.Patch(repository.GetLecturerIDByUserID, func(userID string) (string, error) {
+		if id, ok := lecturers[userID]; ok {
+			return id, nil
+		}
+		return "", sql.ErrNoRows
+	})
+	pA := bm.Patch(repository.IsLecturerAdvisorOfStudent, func(lecturerID, studentID string) (bool, error) {
+		return lecturerID == "lec-1" && studentID == "stu-1", nil
+	})
+	pR := bm.Patch(repository.GetAchievementReferenceByMongoID, func(mongoID string) (*models.AchievementReference, error) {
+		if mongoID != consistencyMongoID {
+			return nil, sql.ErrNoRows
+		}
+		return consistencyRef("draft")(mongoID)
+	})
+	pM := bm.Patch(repository.GetAchievementById, func(id string) (*models.Achievement, error) {
+		return &models.Achievement{ID: oid(id), StudentID: "stu-1", Title: "Draft"}, nil
+	})
+	pH := bm.Patch(repository.GetAchievementByIdMongo, func(id string) (*models.Achievement, error) {
+		return &models.Achievement{ID: oid(id), StudentID: "stu-1", Title: "Draft"}, nil
+	})
+	pE := bm.Patch(repository.GetAchievementEvents, func(mongoID string) ([]models.AchievementEvent, error) {
+		return []models.AchievementEvent{}, nil
+	})
+	t.Cleanup(func() {
+		pS.Unpatch()
+		pL.Unpatch()
+		pA.Unpatch()
+		pR.Unpatch()
+		pM.Unpatch()
+		pH.Unpatch()
+		pE.Unpatch()
+	})
+}
+
+func TestAchievementReadAccess(t *testing.T) {
+	patchAccessLookups(t)
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", c.Get("user_id"))
+		c.Locals("role", c.Get("role"))
+		return c.Next()
+	})
+	app.Get("/achievements/:id", middleware.AchievementOwnerOrAdvisorOrAdmin(), service.GetAchievementById)
+	app.Get("/achievements/:id/history", middleware.AchievementOwnerOrAdvisorOrAdmin(), service.GetAchievementHistory)
+
+	const missingID = "507f1f77bcf86cd799439099"
+	cases := []struct {
+		name, userID, role, id string
+		status                 int
+	}{
+		{"Admin", "admin-user", "admin", consistencyMongoID, 200},
+		{"Owner", "student-user", "mahasiswa", consistencyMongoID, 200},
+		{"OtherStudent", "other-student-user", "mahasiswa", consistencyMongoID, 403},
+		{"StudentWithoutProfile", "nobody", "mahasiswa", consistencyMongoID, 403},
+		{"Advisor", "lecturer-user", "dosen_wali", consistencyMongoID, 200},
+		{"OtherLecturer", "other-lecturer-user", "dosen_wali", consistencyMongoID, 403},
+		{"LecturerWithoutProfile", "nobody", "dosen_wali", consistencyMongoID, 403},
+		{"UnknownRole", "student-user", "guest", consistencyMongoID, 403},
+		{"NoUser", "", "mahasiswa", consistencyMongoID, 401},
+		// tidak ada / id tidak valid selalu 404, untuk role apa pun
+		{"Missing_Admin", "admin-user", "admin", missingID, 404},
+		{"Missing_Student", "student-user", "mahasiswa", missingID, 404},
+		{"InvalidID_Lecturer", "lecturer-user", "dosen_wali", "abc", 404},
+	}
+
+	for _, path := range []string{"/achievements/%s", "/achievements/%s/history"} {
+		for _, tc := range cases {
+			url := fmt.Sprintf(path, tc.id)
+			t.Run(url+"/"+tc.name, func(t *testing.T) {
+				req := httptest.NewRequest("GET", url, nil)
+				req.Header.Set("user_id", tc.userID)
+				req.Header.Set("role", tc.role)
+
+				resp, err := app.Test(req)
+				require.NoError(t, err)
+				require.Equal(t, tc.status, resp.StatusCode)
+			})
+		}
+	}
+}
+
+func TestAchievementReadAccess_ReferenceError(t *testing.T) {
+	p := bm.Patch(repository.GetAchievementReferenceByMongoID, func(mongoID string) (*models.AchievementReference, error) {
+		return nil, errors.New("pg down")
+	})
+	defer p.Unpatch()
+
+	app := fiber.New()
+	app.Get("/achievements/:id", middleware.AchievementOwnerOrAdvisorOrAdmin(), service.GetAchievementById)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/achievements/"+consistencyMongoID, nil))
+	require.NoError(t, err)
+	require.Equal(t, 500, resp.StatusCode)
+}
@@ -0,0 +1,328 @@
+package service_test
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/app/service"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+
+	bm "bou.ke/monkey"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// patchBulkLookups: m-ok & m-mongo-fail submitted milik bimbingan, m-verified sudah verified,
+// m-other milik mahasiswa lain; id lain tidak ditemukan.
+func patchBulkLookups(t *testing.T) {
+	pL := bm.Patch(repository.GetLecturerIDByUserID,
+		func(userID string) (string, error) { return "lec-1", nil })
+	pR := bm.Patch(repository.GetAchievementReferencesByMongoIDs,
+		func(ids []string) ([]models.AchievementReference, error) {
+			all := map[string]models.AchievementReference{
+				"m-ok":         {ID: "r-ok", StudentID: "stu-1", MongoAchievementID: "m-ok", Status: "submitted"},
+				"m-mongo-fail": {ID: "r-mongo-fail", StudentID: "stu-1", MongoAchievementID: "m-mongo-fail", Status: "submitted"},
+				"m-verified":   {ID: "r-verified", StudentID: "stu-1", MongoAchievementID: "m-verified", Status: "verified"},
+				"m-other":      {ID: "r-other", StudentID: "stu-2", MongoAchievementID: "m-other", Status: "submitted"},
+			}
+			var out []models.AchievementReference
+			for _, id := range ids {
+				if r, ok := all[id]; ok {
+					out = append(out, r)
+				}
+			}
+			return out, nil
+		})
+	pA := bm.Patch(repository.GetAdviseeStudentSet,
+		func(lecturerID string, studentIDs []string) (map[string]bool, error) {
+			return map[string]bool{"stu-1": true}, nil
+		})
+	t.Cleanup(func() {
+		pL.Unpatch()
+		pR.Unpatch()
+		pA.Unpatch()
+	})
+}
+
+// patchBulkRestore menyalin reference yang dikembalikan (pointer dari caller bisa menunjuk ke stack)
+func patchBulkRestore(t *testing.T) *[]models.AchievementReference {
+	restored := &[]models.AchievementReference{}
+	p := bm.Patch(repository.RestoreReferenceState, func(ref *models.AchievementReference, writtenStatus string) error {
+		*restored = append(*restored, *ref)
+		return nil
+	})
+	t.Cleanup(p.Unpatch)
+	return restored
+}
+
+func patchBulkEventLog(t *testing.T) *[]models.AchievementEvent {
+	events := &[]models.AchievementEvent{}
+	p := bm.Patch(repository.InsertAchievementEvents, func(e []models.AchievementEvent) error {
+		*events = append(*events, e...)
+		return nil
+	})
+	t.Cleanup(p.Unpatch)
+	return events
+}
+
+func decodeBulk(t *testing.T, app *fiber.App, path, role string, body any) (int, models.BulkResponse) {
+	req := makeReq("POST", path, body)
+	req.Header.Set("user_id", "lecturer-user-1")
+	req.Header.Set("role", role)
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+
+	var out struct {
+		Data models.BulkResponse `json:"data"`
+	}
+	if resp.StatusCode == 200 {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	}
+	return resp.StatusCode, out.Data
+}
+
+func TestBulkVerifyAchievements(t *testing.T) {
+	app := fiber.New()
+	app.Post("/achievements/bulk/verify", roleFromHeader, service.BulkVerifyAchievements)
+
+	t.Run("MixedResults", func(t *testing.T) {
+		patchBulkLookups(t)
+		patchScoring(t, models.ScoringModeOverride)
+		restored := patchBulkRestore(t)
+		events := patchBulkEventLog(t)
+
+		var gotRefIDs []string
+		pV := bm.Patch(repository.BulkVerifyAchievementReferences,
+			func(refIDs []string, dosenID string) (map[string]bool, error) {
+				gotRefIDs = append([]string{}, refIDs...)
+				return map[string]bool{"r-ok": true, "r-mongo-fail": true}, nil
+			})
+		defer pV.Unpatch()
+
+		// disalin: map asli boleh dialokasikan di stack oleh caller
+		gotSets := map[string]bson.M{}
+		pM := bm.Patch(repository.BulkUpdateAchievementsMongo,
+			func(sets map[string]bson.M) map[string]error {
+				for k, v := range sets {
+					gotSets[k] = v
+				}
+				return map[string]error{"m-mongo-fail": errors.New("write failed")}
+			})
+		defer pM.Unpatch()
+
+		status, out := decodeBulk(t, app, "/achievements/bulk/verify", "dosen_wali", fiber.Map{
+			"items": []fiber.Map{
+				{"id": "m-ok", "points": 10},
+				{"id": "m-mongo-fail", "points": 5},
+				{"id": "m-verified", "points": 5},
+				{"id": "m-other", "points": 5},
+				{"id": "m-missing", "points": 5},
+				{"id": "m-ok", "points": 7},
+				{"id": "m-negative", "points": -1},
+			},
+		})
+		require.Equal(t, 200, status)
+		require.ElementsMatch(t, []string{"r-ok", "r-mongo-fail"}, gotRefIDs)
+		require.Equal(t, 10, gotSets["m-ok"]["points"])
+
+		got := map[int]string{}
+		for i, r := range out.Results {
+			got[i] = r.Result
+		}
+		require.Equal(t, map[int]string{
+			0: models.BulkResultOK,
+			1: models.BulkResultError,
+			2: models.BulkResultWrongStatus,
+			3: models.BulkResultForbidden,
+			4: models.BulkResultNotFound,
+			5: models.BulkResultInvalid,
+			6: models.BulkResultInvalid,
+		}, got)
+		require.Equal(t, "verified", out.Results[2].CurrentStatus)
+		require.Equal(t, "Points must be >= 0 (0 = use the scoring rubric)", out.Results[6].Message)
+		require.Equal(t, 1, out.Summary[models.BulkResultOK])
+		require.Equal(t, 2, out.Summary[models.BulkResultInvalid])
+
+		// reference yang Mongo-nya gagal dikembalikan ke submitted
+		require.Len(t, *restored, 1)
+		require.Equal(t, "r-mongo-fail", (*restored)[0].ID)
+		require.Equal(t, "submitted", (*restored)[0].Status)
+
+		// event dicatat sebelum Mongo; yang Mongo-nya gagal diberi event reverted
+		require.Len(t, *events, 3)
+		require.Equal(t, "m-ok", (*events)[0].MongoAchievementID)
+		require.Equal(t, models.AchievementEventVerified, (*events)[0].EventType)
+		require.Equal(t, "m-mongo-fail", (*events)[1].MongoAchievementID)
+		require.Equal(t, models.AchievementEventVerified, (*events)[1].EventType)
+		require.Equal(t, "m-mongo-fail", (*events)[2].MongoAchievementID)
+		require.Equal(t, models.AchievementEventReverted, (*events)[2].EventType)
+		require.Equal(t, "verified", *(*events)[2].FromStatus)
+		require.Equal(t, "submitted", *(*events)[2].ToStatus)
+	})
+
+	t.Run("ChangedConcurrently", func(t *testing.T) {
+		patchBulkLookups(t)
+		patchScoring(t, models.ScoringModeOverride)
+		events := patchBulkEventLog(t)
+
+		pV := bm.Patch(repository.BulkVerifyAchievementReferences,
+			func(refIDs []string, dosenID string) (map[string]bool, error) { return map[string]bool{}, nil })
+		defer pV.Unpatch()
+		pM := bm.Patch(repository.BulkUpdateAchievementsMongo,
+			func(sets map[string]bson.M) map[string]error {
+				require.Empty(t, sets)
+				return map[string]error{}
+			})
+		defer pM.Unpatch()
+
+		status, out := decodeBulk(t, app, "/achievements/bulk/verify", "dosen_wali", fiber.Map{
+			"items": []fiber.Map{{"id": "m-ok", "points": 10}},
+		})
+		require.Equal(t, 200, status)
+		require.Equal(t, models.BulkResultWrongStatus, out.Results[0].Result)
+		require.Empty(t, *events)
+	})
+
+	t.Run("EventFails_ReferencesRestored", func(t *testing.T) {
+		patchBulkLookups(t)
+		patchScoring(t, models.ScoringModeOverride)
+		restored := patchBulkRestore(t)
+
+		pE := bm.Patch(repository.InsertAchievementEvents,
+			func(e []models.AchievementEvent) error { return errors.New("pg down") })
+		defer pE.Unpatch()
+		pV := bm.Patch(repository.BulkVerifyAchievementReferences,
+			func(refIDs []string, dosenID string) (map[string]bool, error) {
+				return map[string]bool{"r-ok": true}, nil
+			})
+		defer pV.Unpatch()
+		mongoCalled := false
+		pM := bm.Patch(repository.BulkUpdateAchievementsMongo,
+			func(sets map[string]bson.M) map[string]error { mongoCalled = true; return map[string]error{} })
+		defer pM.Unpatch()
+
+		status, out := decodeBulk(t, app, "/achievements/bulk/verify", "dosen_wali", fiber.Map{
+			"items": []fiber.Map{{"id": "m-ok", "points": 10}},
+		})
+		require.Equal(t, 200, status)
+		require.Equal(t, models.BulkResultError, out.Results[0].Result)
+		require.False(t, mongoCalled)
+		require.Len(t, *restored, 1)
+		require.Equal(t, "submitted", (*restored)[0].Status)
+	})
+
+	t.Run("Rubric", func(t *testing.T) {
+		patchBulkLookups(t)
+		patchScoring(t, models.ScoringModeOverride, sampleRules()...)
+		patchBulkEventLog(t)
+
+		pV := bm.Patch(repository.BulkVerifyAchievementReferences,
+			func(refIDs []string, dosenID string) (map[string]bool, error) {
+				return map[string]bool{"r-ok": true}, nil
+			})
+		defer pV.Unpatch()
+		gotPoints := map[string]any{}
+		pM := bm.Patch(repository.BulkUpdateAchievementsMongo,
+			func(sets map[string]bson.M) map[string]error {
+				for k, v := range sets {
+					gotPoints[k] = v["points"]
+				}
+				return map[string]error{}
+			})
+		defer pM.Unpatch()
+
+		status, out := decodeBulk(t, app, "/achievements/bulk/verify", "dosen_wali", fiber.Map{
+			"items": []fiber.Map{
+				{"id": "m-ok"},
+				{"id": "m-mongo-fail", "points": 55},
+			},
+		})
+		require.Equal(t, 200, status)
+		require.Equal(t, models.BulkResultOK, out.Results[0].Result)
+		require.Equal(t, models.BulkResultInvalid, out.Results[1].Result)
+		require.Equal(t, map[string]any{"m-ok": 50}, gotPoints)
+	})
+
+	t.Run("WrongRole", func(t *testing.T) {
+		patchBulkLookups(t)
+
+		status, out := decodeBulk(t, app, "/achievements/bulk/verify", "mahasiswa", fiber.Map{
+			"items": []fiber.Map{{"id": "m-ok", "points": 10}},
+		})
+		require.Equal(t, 200, status)
+		require.Equal(t, models.BulkResultForbidden, out.Results[0].Result)
+	})
+
+	t.Run("EmptyItems", func(t *testing.T) {
+		patchBulkLookups(t)
+
+		status, _ := decodeBulk(t, app, "/achievements/bulk/verify", "dosen_wali", fiber.Map{"items": []fiber.Map{}})
+		require.Equal(t, 400, status)
+	})
+
+	t.Run("NotLecturer", func(t *testing.T) {
+		pL := bm.Patch(repository.GetLecturerIDByUserID,
+			func(userID string) (string, error) { return "", errors.New("not found") })
+		defer pL.Unpatch()
+
+		status, _ := decodeBulk(t, app, "/achievements/bulk/verify", "mahasiswa", fiber.Map{
+			"items": []fiber.Map{{"id": "m-ok", "points": 10}},
+		})
+		require.Equal(t, 403, status)
+	})
+}
+
+func TestBulkRejectAchievements(t *testing.T) {
+	app := fiber.New()
+	app.Post("/achievements/bulk/reject", roleFromHeader, service.BulkRejectAchievements)
+
+	patchBulkLookups(t)
+	events := patchBulkEventLog(t)
+
+	gotNotes := map[string]string{}
+	pR := bm.Patch(repository.BulkRejectAchievementReferences,
+		func(notes map[string]string, dosenID string) (map[string]bool, error) {
+			for k, v := range notes {
+				gotNotes[k] = v
+			}
+			return map[string]bool{"r-ok": true}, nil
+		})
+	defer pR.Unpatch()
+	pM := bm.Patch(repository.BulkUpdateAchievementsMongo,
+		func(sets map[string]bson.M) map[string]error { return map[string]error{} })
+	defer pM.Unpatch()
+
+	status, out := decodeBulk(t, app, "/achievements/bulk/reject", "dosen_wali", fiber.Map{
+		"items": []fiber.Map{
+			{"id": "m-ok", "note": "  Bukti kurang  "},
+			{"id": "m-mongo-fail", "note": "   "},
+		},
+	})
+	require.Equal(t, 200, status)
+	require.Equal(t, map[string]string{"r-ok": "Bukti kurang"}, gotNotes)
+	require.Equal(t, models.BulkResultOK, out.Results[0].Result)
+	require.Equal(t, models.BulkResultInvalid, out.Results[1].Result)
+
+	require.Len(t, *events, 1)
+	require.Equal(t, models.AchievementEventRejected, (*events)[0].EventType)
+	require.Equal(t, "Bukti kurang", *(*events)[0].Note)
+}
+
+func TestBulkVerifyAchievementReferences(t *testing.T) {
+	db, mock := setupDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE id = ANY($1::uuid[]) AND status = 'submitted'`)).
+		WithArgs(sqlmock.AnyArg(), "dosen-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("r-1"))
+
+	updated, err := repository.BulkVerifyAchievementReferences([]string{"r-1", "r-2"}, "dosen-1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"r-1": true}, updated)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
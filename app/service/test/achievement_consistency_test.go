@@ -0,0 +1,580 @@
+package service_test
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/app/service"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	bm "bou.ke/monkey"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Setiap perubahan yang menyentuh Mongo + Postgres harus masuk ke keduanya atau tidak sama sekali:
+// jika langkah kedua gagal, langkah pertama dikompensasi.
+
+const consistencyMongoID = "507f1f77bcf86cd799439011"
+
+func consistencyRef(status string) func(string) (*models.AchievementReference, error) {
+	return func(mongoID string) (*models.AchievementReference, error) {
+		now := time.Now()
+		return &models.AchievementReference{
+			ID:                 "ref-1",
+			StudentID:          "stu-1",
+			MongoAchievementID: mongoID,
+			Status:             status,
+			CreatedAt:          now,
+			UpdatedAt:          now,
+		}, nil
+	}
+}
+
+// patchRestore mencatat snapshot yang dipakai untuk mengembalikan reference
+func patchRestore(t *testing.T) *[]*models.AchievementReference {
+	restored := &[]*models.AchievementReference{}
+	p := bm.Patch(repository.RestoreReferenceState, func(ref *models.AchievementReference, writtenStatus string) error {
+		*restored = append(*restored, ref)
+		return nil
+	})
+	t.Cleanup(p.Unpatch)
+	return restored
+}
+
+func patchOwnedAchievement(t *testing.T, status string) {
+	p1 := bm.Patch(repository.GetStudentIDByUserID,
+		func(uid string) (string, error) { return "stu-1", nil })
+	p2 := bm.Patch(repository.GetAchievementByIdMongo,
+		func(id string) (*models.Achievement, error) {
+			return &models.Achievement{ID: oid(id), StudentID: "stu-1"}, nil
+		})
+	p3 := bm.Patch(repository.GetAchievementReferenceByMongoID, consistencyRef(status))
+	t.Cleanup(func() { p1.Unpatch(); p2.Unpatch(); p3.Unpatch() })
+}
+
+func patchAdvisor(t *testing.T) {
+	p1 := bm.Patch(repository.GetLecturerIDByUserID,
+		func(userID string) (string, error) { return "lec-1", nil })
+	p2 := bm.Patch(repository.GetAchievementReferenceByMongoID, consistencyRef("submitted"))
+	p3 := bm.Patch(repository.IsLecturerAdvisorOfStudent,
+		func(lecturerID, studentID string) (bool, error) { return true, nil })
+	t.Cleanup(func() { p1.Unpatch(); p2.Unpatch(); p3.Unpatch() })
+}
+
+func TestCreateAchievement_Consistency(t *testing.T) {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if uid := c.Get("user_id"); uid != "" {
+			c.Locals("user_id", uid)
+		}
+		return c.Next()
+	})
+	app.Post("/achievements", service.CreateAchievement)
+	patchTypeSchema(t, nil)
+	patchTypeCatalogue(t)
+
+	p := bm.Patch(repository.GetStudentIDByUserID,
+		func(userID string) (string, error) { return "stu-1", nil })
+	defer p.Unpatch()
+
+	t.Run("MongoFails_NothingWritten", func(t *testing.T) {
+		pM := bm.Patch(repository.AchievementInsertMongo,
+			func(a *models.Achievement) (primitive.ObjectID, error) {
+				return primitive.NilObjectID, errors.New("mongo down")
+			})
+		defer pM.Unpatch()
+
+		refCalled, deleteCalled := false, false
+		pR := bm.Patch(repository.AchievementInsertReference,
+			func(studentID string, mongoID primitive.ObjectID) error { refCalled = true; return nil })
+		defer pR.Unpatch()
+		pD := bm.Patch(repository.AchievementDeleteMongo,
+			func(mongoID primitive.ObjectID) error { deleteCalled = true; return nil })
+		defer pD.Unpatch()
+
+		req := makeReq("POST", "/achievements", map[string]any{"title": "A", "achievementType": "competition"})
+		req.Header.Set("user_id", "user-1")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 500, resp.StatusCode)
+		require.False(t, refCalled)
+		require.False(t, deleteCalled)
+	})
+
+	t.Run("ReferenceFails_MongoDocumentRemoved", func(t *testing.T) {
+		pM := bm.Patch(repository.AchievementInsertMongo,
+			func(a *models.Achievement) (primitive.ObjectID, error) { return oid(consistencyMongoID), nil })
+		defer pM.Unpatch()
+		pR := bm.Patch(repository.AchievementInsertReference,
+			func(studentID string, mongoID primitive.ObjectID) error { return errors.New("pg down") })
+		defer pR.Unpatch()
+
+		var deleted primitive.ObjectID
+		pD := bm.Patch(repository.AchievementDeleteMongo,
+			func(mongoID primitive.ObjectID) error { deleted = mongoID; return nil })
+		defer pD.Unpatch()
+
+		req := makeReq("POST", "/achievements", map[string]any{"title": "A", "achievementType": "competition"})
+		req.Header.Set("user_id", "user-1")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 500, resp.StatusCode)
+		require.Equal(t, oid(consistencyMongoID), deleted)
+	})
+}
+
+func TestSubmitAchievement_Consistency(t *testing.T) {
+	app := fiber.New()
+	app.Use(roleFromHeader)
+	app.Post("/achievements/:id/submit", service.SubmitAchievement)
+
+	t.Run("ReferenceFails_MongoUntouched", func(t *testing.T) {
+		patchOwnedAchievement(t, "draft")
+		restored := patchRestore(t)
+
+		pR := bm.Patch(repository.UpdateReferenceStatusSubmitted,
+			func(mongoID string, expectedFrom []string) error { return errors.New("pg down") })
+		defer pR.Unpatch()
+		mongoCalled := false
+		pM := bm.Patch(repository.AchievementUpdateMongoMap,
+			func(id string, updates map[string]any) error { mongoCalled = true; return nil })
+		defer pM.Unpatch()
+
+		req := makeReq("POST", "/achievements/"+consistencyMongoID+"/submit", nil)
+		req.Header.Set("user_id", "user-1")
+		req.Header.Set("role", "mahasiswa")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 500, resp.StatusCode)
+		require.False(t, mongoCalled)
+		require.Empty(t, *restored)
+	})
+
+	t.Run("MongoFails_ReferenceRestored", func(t *testing.T) {
+		patchOwnedAchievement(t, "draft")
+		restored := patchRestore(t)
+
+		pR := bm.Patch(repository.UpdateReferenceStatusSubmitted,
+			func(mongoID string, expectedFrom []string) error { return nil })
+		defer pR.Unpatch()
+		pM := bm.Patch(repository.AchievementUpdateMongoMap,
+			func(id string, updates map[string]any) error { return errors.New("mongo down") })
+		defer pM.Unpatch()
+
+		req := makeReq("POST", "/achievements/"+consistencyMongoID+"/submit", nil)
+		req.Header.Set("user_id", "user-1")
+		req.Header.Set("role", "mahasiswa")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 500, resp.StatusCode)
+		require.Len(t, *restored, 1)
+		require.Equal(t, "draft", (*restored)[0].Status)
+	})
+}
+
+func TestVerifyAchievement_Consistency(t *testing.T) {
+	app := fiber.New()
+	app.Use(roleFromHeader)
+	app.Post("/achievements/:id/verify", service.VerifyAchievement)
+
+	t.Run("ReferenceFails_MongoUntouched", func(t *testing.T) {
+		patchAdvisor(t)
+		patchScoring(t, models.ScoringModeOverride)
+		restored := patchRestore(t)
+
+		pR := bm.Patch(repository.VerifyAchievementReference,
+			func(refID string, dosenID string, expectedFrom []string) error { return errors.New("pg down") })
+		defer pR.Unpatch()
+		mongoCalled := false
+		pM := bm.Patch(repository.VerifyAchievementMongo,
+			func(id string, points int, dosenID string) error { mongoCalled = true; return nil })
+		defer pM.Unpatch()
+
+		req := makeReq("POST", "/achievements/"+consistencyMongoID+"/verify", map[string]any{"points": 10})
+		req.Header.Set("user_id", "lecturer-user-1")
+		req.Header.Set("role", "dosen_wali")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 500, resp.StatusCode)
+		require.False(t, mongoCalled)
+		require.Empty(t, *restored)
+	})
+
+	t.Run("MongoFails_ReferenceRestored", func(t *testing.T) {
+		patchAdvisor(t)
+		patchScoring(t, models.ScoringModeOverride)
+		restored := patchRestore(t)
+		events := patchEventLog(t)
+
+		pR := bm.Patch(repository.VerifyAchievementReference,
+			func(refID string, dosenID string, expectedFrom []string) error { return nil })
+		defer pR.Unpatch()
+		pM := bm.Patch(repository.VerifyAchievementMongo,
+			func(id string, points int, dosenID string) error { return errors.New("mongo down") })
+		defer pM.Unpatch()
+
+		req := makeReq("POST", "/achievements/"+consistencyMongoID+"/verify", map[string]any{"points": 10})
+		req.Header.Set("user_id", "lecturer-user-1")
+		req.Header.Set("role", "dosen_wali")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 500, resp.StatusCode)
+		require.Len(t, *restored, 1)
+		require.Equal(t, "submitted", (*restored)[0].Status)
+
+		// log audit append-only: event verified dibatalkan dengan event reverted
+		require.Len(t, *events, 2)
+		require.Equal(t, models.AchievementEventVerified, (*events)[0].EventType)
+		require.Equal(t, models.AchievementEventReverted, (*events)[1].EventType)
+		require.Equal(t, "verified", *(*events)[1].FromStatus)
+		require.Equal(t, "submitted", *(*events)[1].ToStatus)
+	})
+
+	t.Run("StatusChangedConcurrently_Conflict", func(t *testing.T) {
+		// reject lain sudah masuk di antara pembacaan reference dan UPDATE bersyarat
+		patchAdvisor(t)
+		patchScoring(t, models.ScoringModeOverride)
+		restored := patchRestore(t)
+		events := patchEventLog(t)
+
+		var gotFrom []string
+		pR := bm.Patch(repository.VerifyAchievementReference,
+			func(refID string, dosenID string, expectedFrom []string) error {
+				gotFrom = expectedFrom
+				return &repository.ReferenceStatusError{Current: "rejected"}
+			})
+		defer pR.Unpatch()
+		mongoCalled := false
+		pM := bm.Patch(repository.VerifyAchievementMongo,
+			func(id string, points int, dosenID string) error { mongoCalled = true; return nil })
+		defer pM.Unpatch()
+
+		req := makeReq("POST", "/achievements/"+consistencyMongoID+"/verify", map[string]any{"points": 10})
+		req.Header.Set("user_id", "lecturer-user-1")
+		req.Header.Set("role", "dosen_wali")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 409, resp.StatusCode)
+
+		var out map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		data := out["data"].(map[string]any)
+		require.Equal(t, "rejected", data["current_status"])
+
+		require.Equal(t, []string{"submitted"}, gotFrom)
+		require.False(t, mongoCalled)
+		require.Empty(t, *restored)
+		require.Empty(t, *events)
+	})
+
+	t.Run("EventFails_ReferenceRestored", func(t *testing.T) {
+		patchAdvisor(t)
+		patchScoring(t, models.ScoringModeOverride)
+		restored := patchRestore(t)
+
+		pE := bm.Patch(repository.InsertAchievementEvent,
+			func(e *models.AchievementEvent) error { return errors.New("pg down") })
+		defer pE.Unpatch()
+		pR := bm.Patch(repository.VerifyAchievementReference,
+			func(refID string, dosenID string, expectedFrom []string) error { return nil })
+		defer pR.Unpatch()
+		mongoCalled := false
+		pM := bm.Patch(repository.VerifyAchievementMongo,
+			func(id string, points int, dosenID string) error { mongoCalled = true; return nil })
+		defer pM.Unpatch()
+
+		req := makeReq("POST", "/achievements/"+consistencyMongoID+"/verify", map[string]any{"points": 10})
+		req.Header.Set("user_id", "lecturer-user-1")
+		req.Header.Set("role", "dosen_wali")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 500, resp.StatusCode)
+		require.False(t, mongoCalled)
+		require.Len(t, *restored, 1)
+		require.Equal(t, "submitted", (*restored)[0].Status)
+	})
+}
+
+func TestRejectAchievement_Consistency(t *testing.T) {
+	app := fiber.New()
+	app.Use(roleFromHeader)
+	app.Post("/achievements/:id/reject", service.RejectAchievement)
+
+	t.Run("ReferenceFails_MongoUntouched", func(t *testing.T) {
+		patchAdvisor(t)
+		restored := patchRestore(t)
+
+		pR := bm.Patch(repository.RejectAchievementReference,
+			func(refID, note, dosenID string, expectedFrom []string) error { return errors.New("pg down") })
+		defer pR.Unpatch()
+		mongoCalled := false
+		pM := bm.Patch(repository.RejectAchievementMongo,
+			func(id, note, dosenID string) error { mongoCalled = true; return nil })
+		defer pM.Unpatch()
+
+		req := makeReq("POST", "/achievements/"+consistencyMongoID+"/reject", map[string]any{"note": "kurang bukti"})
+		req.Header.Set("user_id", "lecturer-user-1")
+		req.Header.Set("role", "dosen_wali")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 500, resp.StatusCode)
+		require.False(t, mongoCalled)
+		require.Empty(t, *restored)
+	})
+
+	t.Run("MongoFails_ReferenceRestored", func(t *testing.T) {
+		patchAdvisor(t)
+		restored := patchRestore(t)
+
+		pR := bm.Patch(repository.RejectAchievementReference,
+			func(refID, note, dosenID string, expectedFrom []string) error { return nil })
+		defer pR.Unpatch()
+		pM := bm.Patch(repository.RejectAchievementMongo,
+			func(id, note, dosenID string) error { return errors.New("mongo down") })
+		defer pM.Unpatch()
+
+		req := makeReq("POST", "/achievements/"+consistencyMongoID+"/reject", map[string]any{"note": "kurang bukti"})
+		req.Header.Set("user_id", "lecturer-user-1")
+		req.Header.Set("role", "dosen_wali")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 500, resp.StatusCode)
+		require.Len(t, *restored, 1)
+		require.Equal(t, "submitted", (*restored)[0].Status)
+	})
+}
+
+func TestDeleteAchievement_Consistency(t *testing.T) {
+	app := fiber.New()
+	app.Use(roleFromHeader)
+	app.Delete("/achievements/:id", service.DeleteAchievement)
+
+	t.Run("ReferenceFails_MongoUntouched", func(t *testing.T) {
+		patchOwnedAchievement(t, "draft")
+		restored := patchRestore(t)
+
+		pR := bm.Patch(repository.AchievementSoftDeleteReference,
+			func(referenceID string, expectedFrom []string) error { return errors.New("pg down") })
+		defer pR.Unpatch()
+		mongoCalled := false
+		pM := bm.Patch(repository.AchievementSoftDeleteMongo,
+			func(mongoID string) error { mongoCalled = true; return nil })
+		defer pM.Unpatch()
+
+		req := makeReq("DELETE", "/achievements/"+consistencyMongoID, nil)
+		req.Header.Set("user_id", "user-1")
+		req.Header.Set("role", "mahasiswa")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 500, resp.StatusCode)
+		require.False(t, mongoCalled)
+		require.Empty(t, *restored)
+	})
+
+	t.Run("MongoFails_ReferenceRestored", func(t *testing.T) {
+		patchOwnedAchievement(t, "draft")
+		restored := patchRestore(t)
+
+		pR := bm.Patch(repository.AchievementSoftDeleteReference,
+			func(referenceID string, expectedFrom []string) error { return nil })
+		defer pR.Unpatch()
+		pM := bm.Patch(repository.AchievementSoftDeleteMongo,
+			func(mongoID string) error { return errors.New("mongo down") })
+		defer pM.Unpatch()
+
+		req := makeReq("DELETE", "/achievements/"+consistencyMongoID, nil)
+		req.Header.Set("user_id", "user-1")
+		req.Header.Set("role", "mahasiswa")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 500, resp.StatusCode)
+		require.Len(t, *restored, 1)
+		require.Equal(t, "draft", (*restored)[0].Status)
+	})
+}
+
+func TestUpdateAchievement_Consistency(t *testing.T) {
+	app := fiber.New()
+	app.Use(roleFromHeader)
+	app.Put("/achievements/:id", service.UpdateAchievement)
+
+	send := func(t *testing.T) int {
+		req := makeReq("PUT", "/achievements/"+consistencyMongoID, map[string]any{"title": "B"})
+		req.Header.Set("user_id", "user-1")
+		req.Header.Set("role", "mahasiswa")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	t.Run("EventFails_MongoUntouched", func(t *testing.T) {
+		patchOwnedAchievement(t, "draft")
+
+		pE := bm.Patch(repository.InsertAchievementEvent,
+			func(e *models.AchievementEvent) error { return errors.New("pg down") })
+		defer pE.Unpatch()
+		mongoCalled := false
+		pM := bm.Patch(repository.AchievementUpdateMongoMap,
+			func(id string, updates map[string]any) error { mongoCalled = true; return nil })
+		defer pM.Unpatch()
+
+		require.Equal(t, 500, send(t))
+		require.False(t, mongoCalled)
+	})
+
+	t.Run("MongoFails_EventReverted", func(t *testing.T) {
+		patchOwnedAchievement(t, "draft")
+		events := patchEventLog(t)
+
+		pM := bm.Patch(repository.AchievementUpdateMongoMap,
+			func(id string, updates map[string]any) error { return errors.New("mongo down") })
+		defer pM.Unpatch()
+
+		require.Equal(t, 500, send(t))
+		require.Len(t, *events, 2)
+		require.Equal(t, models.AchievementEventUpdated, (*events)[0].EventType)
+		require.Equal(t, models.AchievementEventReverted, (*events)[1].EventType)
+	})
+}
+
+// uploadedFiles: file yang ada di direktori upload prestasi uji
+func uploadedFiles(t *testing.T) []string {
+	entries, err := os.ReadDir(filepath.Join("uploads", "achievements", consistencyMongoID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	require.NoError(t, err)
+	names := []string{}
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestUploadAchievementFile_Consistency(t *testing.T) {
+	app := fiber.New()
+	app.Use(roleFromHeader)
+	app.Post("/achievements/:id/attachments", service.UploadAchievementFile)
+
+	send := func(t *testing.T) int {
+		req, err := makeMultipartReq("POST", "/achievements/"+consistencyMongoID+"/attachments",
+			"file", "bukti.txt", "text/plain", []byte("hello"))
+		require.NoError(t, err)
+		req.Header.Set("user_id", "user-1")
+		req.Header.Set("role", "mahasiswa")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode
+	}
+
+	t.Run("EventFails_FileRemoved", func(t *testing.T) {
+		patchOwnedAchievement(t, "draft")
+		before := uploadedFiles(t)
+
+		pE := bm.Patch(repository.InsertAchievementEvent,
+			func(e *models.AchievementEvent) error { return errors.New("pg down") })
+		defer pE.Unpatch()
+		mongoCalled := false
+		pM := bm.Patch(repository.AddAchievementAttachment,
+			func(mongoID string, att models.Attachment) error { mongoCalled = true; return nil })
+		defer pM.Unpatch()
+
+		require.Equal(t, 500, send(t))
+		require.False(t, mongoCalled)
+		require.ElementsMatch(t, before, uploadedFiles(t))
+	})
+
+	t.Run("MongoFails_FileRemoved", func(t *testing.T) {
+		patchOwnedAchievement(t, "draft")
+		events := patchEventLog(t)
+		before := uploadedFiles(t)
+
+		pM := bm.Patch(repository.AddAchievementAttachment,
+			func(mongoID string, att models.Attachment) error { return errors.New("mongo down") })
+		defer pM.Unpatch()
+
+		require.Equal(t, 500, send(t))
+		require.ElementsMatch(t, before, uploadedFiles(t))
+		require.Len(t, *events, 2)
+		require.Equal(t, models.AchievementEventAttachmentAdded, (*events)[0].EventType)
+		require.Equal(t, models.AchievementEventReverted, (*events)[1].EventType)
+	})
+}
+
+func TestGuardedReferenceTransition(t *testing.T) {
+	t.Run("StatusChanged", func(t *testing.T) {
+		db, mock := setupDB(t)
+		defer db.Close()
+
+		mock.ExpectExec(regexp.QuoteMeta(`WHERE id = $1 AND status = ANY($4)`)).
+			WithArgs("ref-1", "kurang bukti", "dosen-1", pq.Array([]string{"submitted"})).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT status FROM achievement_references WHERE id = $1`)).
+			WithArgs("ref-1").
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("verified"))
+
+		err := repository.RejectAchievementReference("ref-1", "kurang bukti", "dosen-1", []string{"submitted"})
+		var statusErr *repository.ReferenceStatusError
+		require.ErrorAs(t, err, &statusErr)
+		require.Equal(t, "verified", statusErr.Current)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("ReferenceMissing", func(t *testing.T) {
+		db, mock := setupDB(t)
+		defer db.Close()
+
+		mock.ExpectExec(regexp.QuoteMeta(`WHERE mongo_achievement_id = $1 AND status = ANY($2)`)).
+			WithArgs(consistencyMongoID, pq.Array([]string{"draft", "rejected"})).
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT status FROM achievement_references WHERE mongo_achievement_id = $1`)).
+			WithArgs(consistencyMongoID).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}))
+
+		err := repository.UpdateReferenceStatusSubmitted(consistencyMongoID, []string{"draft", "rejected"})
+		require.ErrorIs(t, err, sql.ErrNoRows)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("RestoreDoesNotOverwriteLaterTransition", func(t *testing.T) {
+		// kompensasi verify: reference sudah di-reject oleh request lain, snapshot submitted tidak boleh menimpanya
+		db, mock := setupDB(t)
+		defer db.Close()
+
+		ref, _ := consistencyRef("submitted")(consistencyMongoID)
+		mock.ExpectExec(regexp.QuoteMeta(`WHERE id = $1 AND status = $8`)).
+			WithArgs("ref-1", "submitted", nil, nil, nil, nil, ref.UpdatedAt, "verified").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT status FROM achievement_references WHERE id = $1`)).
+			WithArgs("ref-1").
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("rejected"))
+
+		err := repository.RestoreReferenceState(ref, "verified")
+		var statusErr *repository.ReferenceStatusError
+		require.ErrorAs(t, err, &statusErr)
+		require.Equal(t, "rejected", statusErr.Current)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
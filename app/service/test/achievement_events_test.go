@@ -0,0 +1,127 @@
+package service_test
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/app/service"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	bm "bou.ke/monkey"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// patchEventLog mengganti penulisan log audit dan mengembalikan event yang tercatat
+func patchEventLog(t *testing.T) *[]models.AchievementEvent {
+	events := &[]models.AchievementEvent{}
+	p := bm.Patch(repository.InsertAchievementEvent, func(e *models.AchievementEvent) error {
+		*events = append(*events, *e)
+		return nil
+	})
+	t.Cleanup(p.Unpatch)
+	return events
+}
+
+func TestInsertAchievementEvent(t *testing.T) {
+	db, mock := setupDB(t)
+	defer db.Close()
+
+	actor, role, from, to := "user-1", "dosen_wali", "submitted", "verified"
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO achievement_events`)).
+		WithArgs("m-1", "verified", "user-1", "dosen_wali", "submitted", "verified", `{"points":{"from":null,"to":10}}`, nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(7, now))
+
+	e := &models.AchievementEvent{
+		MongoAchievementID: "m-1",
+		EventType:          "verified",
+		ActorUserID:        &actor,
+		ActorRole:          &role,
+		FromStatus:         &from,
+		ToStatus:           &to,
+		Changes:            map[string]models.FieldChange{"points": {To: 10}},
+	}
+	require.NoError(t, repository.InsertAchievementEvent(e))
+	require.Equal(t, int64(7), e.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAchievementEvents(t *testing.T) {
+	db, mock := setupDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM achievement_events`)).
+		WithArgs("m-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "mongo_achievement_id", "event_type", "actor_user_id", "actor_role",
+			"from_status", "to_status", "changes", "note", "created_at"}).
+			AddRow(1, "m-1", "created", nil, nil, nil, "draft", nil, nil, now).
+			AddRow(2, "m-1", "updated", "user-1", "mahasiswa", nil, nil, []byte(`{"title":{"from":"A","to":"B"}}`), nil, now))
+
+	events, err := repository.GetAchievementEvents("m-1")
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	require.Nil(t, events[0].ActorUserID)
+	require.Equal(t, "draft", *events[0].ToStatus)
+	require.Equal(t, models.FieldChange{From: "A", To: "B"}, events[1].Changes["title"])
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAchievementEvent_NotRecordedOnFailure(t *testing.T) {
+	// perubahan yang gagal tidak boleh meninggalkan event
+	events := patchEventLog(t)
+	patchAdvisor(t)
+	patchRestore(t)
+
+	pR := bm.Patch(repository.VerifyAchievementReference,
+		func(refID string, dosenID string, expectedFrom []string) error { return errors.New("pg down") })
+	defer pR.Unpatch()
+	patchScoring(t, models.ScoringModeOverride)
+
+	app := fiber.New()
+	app.Use(roleFromHeader)
+	app.Post("/achievements/:id/verify", service.VerifyAchievement)
+	req := makeReq("POST", "/achievements/"+consistencyMongoID+"/verify", map[string]any{"points": 10})
+	req.Header.Set("user_id", "lecturer-user-1")
+	req.Header.Set("role", "dosen_wali")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, 500, resp.StatusCode)
+	require.Empty(t, *events)
+}
+
+func TestAchievementEvent_ActorRoleIsAuthorizingRole(t *testing.T) {
+	// user dengan role utama mahasiswa dan role tambahan dosen_wali memverifikasi sebagai dosen_wali
+	events := patchEventLog(t)
+	patchAdvisor(t)
+	patchRestore(t)
+	patchScoring(t, models.ScoringModeOverride)
+
+	pR := bm.Patch(repository.VerifyAchievementReference,
+		func(refID string, dosenID string, expectedFrom []string) error { return nil })
+	defer pR.Unpatch()
+	pM := bm.Patch(repository.VerifyAchievementMongo,
+		func(id string, points int, dosenID string) error { return nil })
+	defer pM.Unpatch()
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("role", "mahasiswa")
+		c.Locals("roles", []string{"mahasiswa", "dosen_wali"})
+		return c.Next()
+	})
+	app.Post("/achievements/:id/verify", service.VerifyAchievement)
+	req := makeReq("POST", "/achievements/"+consistencyMongoID+"/verify", map[string]any{"points": 10})
+	req.Header.Set("user_id", "lecturer-user-1")
+
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+	require.Len(t, *events, 1)
+	require.Equal(t, "dosen_wali", *(*events)[0].ActorRole)
+}
@@ -0,0 +1,574 @@
+package service_test
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/app/service"
+	"UAS_GO/helper"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	bm "bou.ke/monkey"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// patchTypeCatalogue mengganti katalog achievementType (default: empat tipe bawaan, semuanya aktif)
+func patchTypeCatalogue(t *testing.T, types ...models.AchievementType) {
+	if types == nil {
+		for _, code := range []string{"certification", "competition", "organization", "publication"} {
+			types = append(types, models.AchievementType{Code: code, Labels: map[string]string{"en": code}, IsActive: true})
+		}
+	}
+	p := bm.Patch(repository.ListAchievementTypes, func(activeOnly bool) ([]models.AchievementType, error) {
+		out := []models.AchievementType{}
+		for _, at := range types {
+			if at.IsActive || !activeOnly {
+				out = append(out, at)
+			}
+		}
+		return out, nil
+	})
+	t.Cleanup(p.Unpatch)
+}
+
+// patchTypeSchema mengganti schema details untuk semua tipe (nil = tipe tanpa schema)
+func patchTypeSchema(t *testing.T, schema map[string]any) *[]string {
+	asked := &[]string{}
+	p := bm.Patch(repository.GetAchievementTypeSchema, func(achType string) (map[string]any, error) {
+		*asked = append(*asked, achType)
+		return schema, nil
+	})
+	t.Cleanup(p.Unpatch)
+	return asked
+}
+
+// schema competition dalam bentuk hasil decode JSON (seperti dari Postgres)
+func competitionSchema(t *testing.T) map[string]any {
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"required": ["competitionName", "competitionLevel"],
+		"additionalProperties": false,
+		"properties": {
+			"competitionName":  {"type": "string", "minLength": 1},
+			"competitionLevel": {"type": "string", "enum": ["international", "national"]},
+			"rank":             {"type": "integer", "minimum": 1},
+			"eventDate":        {"type": "string", "format": "date"},
+			"members":          {"type": "array", "items": {"type": "string"}, "maxItems": 2}
+		}
+	}`), &schema))
+	return schema
+}
+
+type detailsErrorBody struct {
+	Status int `json:"status"`
+	Data   struct {
+		Errors []helper.FieldError `json:"errors"`
+	} `json:"data"`
+}
+
+func TestCreateAchievement_DetailsSchema(t *testing.T) {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", c.Get("user_id"))
+		return c.Next()
+	})
+	app.Post("/achievements", service.CreateAchievement)
+	patchTypeCatalogue(t)
+
+	pS := bm.Patch(repository.GetStudentIDByUserID,
+		func(userID string) (string, error) { return "stu-1", nil })
+	defer pS.Unpatch()
+
+	t.Run("FieldErrors", func(t *testing.T) {
+		asked := patchTypeSchema(t, competitionSchema(t))
+		inserted := false
+		pM := bm.Patch(repository.AchievementInsertMongo,
+			func(a *models.Achievement) (primitive.ObjectID, error) {
+				inserted = true
+				return primitive.NewObjectID(), nil
+			})
+		defer pM.Unpatch()
+
+		req := makeReq("POST", "/achievements", map[string]any{
+			"title":           "Juara",
+			"achievementType": "competition",
+			"details": map[string]any{
+				"competitionLevel": "kampus",
+				"rank":             1.5,
+				"eventDate":        "12-01-2025",
+				"members":          []any{"a", "b", 3},
+				"extra":            true,
+			},
+		})
+		req.Header.Set("user_id", "user-1")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 422, resp.StatusCode)
+		require.False(t, inserted)
+		require.Equal(t, []string{"competition"}, *asked)
+
+		var out detailsErrorBody
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		fields := map[string]string{}
+		for _, e := range out.Data.Errors {
+			fields[e.Field] = e.Message
+		}
+		require.Equal(t, map[string]string{
+			"details.competitionName":  "is required",
+			"details.competitionLevel": "must be one of: international, national",
+			"details.rank":             "must be of type integer",
+			"details.eventDate":        "must be a valid date",
+			"details.members":          "must contain at most 2 items",
+			"details.members[2]":       "must be of type string",
+			"details.extra":            "is not allowed",
+		}, fields)
+	})
+
+	t.Run("MissingDetails_ReportsRequired", func(t *testing.T) {
+		patchTypeSchema(t, competitionSchema(t))
+
+		req := makeReq("POST", "/achievements", map[string]any{"title": "Juara", "achievementType": "competition"})
+		req.Header.Set("user_id", "user-1")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 422, resp.StatusCode)
+
+		var out detailsErrorBody
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		require.Len(t, out.Data.Errors, 2)
+		require.Equal(t, "details.competitionLevel", out.Data.Errors[0].Field)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		patchTypeSchema(t, competitionSchema(t))
+		patchEventLog(t)
+		pM := bm.Patch(repository.AchievementInsertMongo,
+			func(a *models.Achievement) (primitive.ObjectID, error) { return primitive.NewObjectID(), nil })
+		defer pM.Unpatch()
+		pR := bm.Patch(repository.AchievementInsertReference,
+			func(studentID string, mongoID primitive.ObjectID) error { return nil })
+		defer pR.Unpatch()
+
+		req := makeReq("POST", "/achievements", map[string]any{
+			"title":           "Juara",
+			"achievementType": "competition",
+			"details":         map[string]any{"competitionName": "Gemastik", "competitionLevel": "national", "rank": 1},
+		})
+		req.Header.Set("user_id", "user-1")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 201, resp.StatusCode)
+	})
+}
+
+func TestUpdateAchievement_DetailsSchema(t *testing.T) {
+	app := fiber.New()
+	app.Use(roleFromHeader)
+	app.Patch("/achievements/:id", service.UpdateAchievement)
+	patchTypeCatalogue(t)
+
+	pS := bm.Patch(repository.GetStudentIDByUserID,
+		func(uid string) (string, error) { return "stu-1", nil })
+	defer pS.Unpatch()
+	pRef := bm.Patch(repository.GetAchievementReferenceByMongoID, consistencyRef("draft"))
+	defer pRef.Unpatch()
+
+	// details lama dari Mongo: nested document & angka int32
+	pA := bm.Patch(repository.GetAchievementByIdMongo,
+		func(id string) (*models.Achievement, error) {
+			return &models.Achievement{
+				StudentID:       "stu-1",
+				AchievementType: "seminar",
+				Details: map[string]any{
+					"competitionName":  "Gemastik",
+					"competitionLevel": "national",
+					"rank":             int32(2),
+					"members":          primitive.A{"a"},
+				},
+			}, nil
+		})
+	defer pA.Unpatch()
+
+	t.Run("TypeChange_ValidatesExistingDetails", func(t *testing.T) {
+		asked := patchTypeSchema(t, competitionSchema(t))
+		patchEventLog(t)
+		pU := bm.Patch(repository.AchievementUpdateMongoMap,
+			func(id string, m map[string]any) error { return nil })
+		defer pU.Unpatch()
+
+		req := makeReq("PATCH", "/achievements/"+consistencyMongoID, map[string]any{"achievementType": "competition"})
+		req.Header.Set("user_id", "user-1")
+		req.Header.Set("role", "mahasiswa")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 200, resp.StatusCode)
+		require.Equal(t, []string{"competition"}, *asked)
+	})
+
+	t.Run("DetailsChange_Invalid", func(t *testing.T) {
+		asked := patchTypeSchema(t, competitionSchema(t))
+		updated := false
+		pU := bm.Patch(repository.AchievementUpdateMongoMap,
+			func(id string, m map[string]any) error { updated = true; return nil })
+		defer pU.Unpatch()
+
+		req := makeReq("PATCH", "/achievements/"+consistencyMongoID, map[string]any{
+			"details": map[string]any{"competitionName": "", "competitionLevel": "national"},
+		})
+		req.Header.Set("user_id", "user-1")
+		req.Header.Set("role", "mahasiswa")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 422, resp.StatusCode)
+		require.False(t, updated)
+		// tipe tidak berubah: schema tipe lama yang dipakai
+		require.Equal(t, []string{"seminar"}, *asked)
+	})
+
+	t.Run("TitleOnly_NoValidation", func(t *testing.T) {
+		asked := patchTypeSchema(t, competitionSchema(t))
+		patchEventLog(t)
+		pU := bm.Patch(repository.AchievementUpdateMongoMap,
+			func(id string, m map[string]any) error { return nil })
+		defer pU.Unpatch()
+
+		req := makeReq("PATCH", "/achievements/"+consistencyMongoID, map[string]any{"title": "Baru"})
+		req.Header.Set("user_id", "user-1")
+		req.Header.Set("role", "mahasiswa")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 200, resp.StatusCode)
+		require.Empty(t, *asked)
+	})
+}
+
+func TestAdminPutAchievementTypeSchema(t *testing.T) {
+	app := fiber.New()
+	app.Put("/admin/achievement-types/:type/schema", service.AdminPutAchievementTypeSchema)
+
+	t.Run("Saved", func(t *testing.T) {
+		var gotType string
+		p := bm.Patch(repository.UpsertAchievementTypeSchema,
+			func(achType string, schema map[string]any) (*models.AchievementTypeSchema, error) {
+				gotType = achType
+				return &models.AchievementTypeSchema{AchievementType: achType, Schema: schema}, nil
+			})
+		defer p.Unpatch()
+
+		resp, err := app.Test(makeReq("PUT", "/admin/achievement-types/competition/schema",
+			map[string]any{"schema": competitionSchema(t)}))
+		require.NoError(t, err)
+		require.Equal(t, 200, resp.StatusCode)
+		require.Equal(t, "competition", gotType)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, schema := range []map[string]any{
+			{"type": "string"},
+			{"type": "object", "oneOf": []any{}},
+			{"type": "object", "properties": map[string]any{"rank": map[string]any{"type": "int"}}},
+			{"type": "object", "properties": map[string]any{"code": map[string]any{"pattern": "("}}},
+			{"type": "object", "required": "name"},
+		} {
+			resp, err := app.Test(makeReq("PUT", "/admin/achievement-types/competition/schema", map[string]any{"schema": schema}))
+			require.NoError(t, err)
+			require.Equal(t, 400, resp.StatusCode, schema)
+		}
+	})
+}
+
+func TestUpsertAchievementTypeSchema(t *testing.T) {
+	db, mock := setupDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO achievement_type_schemas`)).
+		WithArgs("competition", `{"type":"object"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"updated_at"}).AddRow(time.Now()))
+
+	out, err := repository.UpsertAchievementTypeSchema("competition", map[string]any{"type": "object"})
+	require.NoError(t, err)
+	require.Equal(t, "competition", out.AchievementType)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateAchievement_TypeCatalogue(t *testing.T) {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", c.Get("user_id"))
+		return c.Next()
+	})
+	app.Post("/achievements", service.CreateAchievement)
+	patchTypeSchema(t, nil)
+	patchTypeCatalogue(t,
+		models.AchievementType{Code: "competition", IsActive: true},
+		models.AchievementType{Code: "seminar", IsActive: false})
+
+	pS := bm.Patch(repository.GetStudentIDByUserID,
+		func(userID string) (string, error) { return "stu-1", nil })
+	defer pS.Unpatch()
+
+	t.Run("Rejected", func(t *testing.T) {
+		inserted := false
+		pM := bm.Patch(repository.AchievementInsertMongo,
+			func(a *models.Achievement) (primitive.ObjectID, error) {
+				inserted = true
+				return primitive.NewObjectID(), nil
+			})
+		defer pM.Unpatch()
+
+		for value, msg := range map[string]string{
+			"":        "is required",
+			"lomba":   "must be one of: competition",
+			"seminar": "must be one of: competition", // tidak aktif
+		} {
+			req := makeReq("POST", "/achievements", map[string]any{"title": "Juara", "achievementType": value})
+			req.Header.Set("user_id", "user-1")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, 422, resp.StatusCode, value)
+
+			var out detailsErrorBody
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+			require.Equal(t, []helper.FieldError{{Field: "achievementType", Message: msg}}, out.Data.Errors, value)
+		}
+		require.False(t, inserted)
+	})
+
+	t.Run("StoredAsCanonicalCode", func(t *testing.T) {
+		patchEventLog(t)
+		var gotType string
+		pM := bm.Patch(repository.AchievementInsertMongo,
+			func(a *models.Achievement) (primitive.ObjectID, error) {
+				gotType = a.AchievementType
+				return primitive.NewObjectID(), nil
+			})
+		defer pM.Unpatch()
+		pR := bm.Patch(repository.AchievementInsertReference,
+			func(studentID string, mongoID primitive.ObjectID) error { return nil })
+		defer pR.Unpatch()
+
+		req := makeReq("POST", "/achievements", map[string]any{"title": "Juara", "achievementType": " Competition "})
+		req.Header.Set("user_id", "user-1")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 201, resp.StatusCode)
+		require.Equal(t, "competition", gotType)
+	})
+}
+
+func TestUpdateAchievement_TypeCatalogue(t *testing.T) {
+	app := fiber.New()
+	app.Use(roleFromHeader)
+	app.Patch("/achievements/:id", service.UpdateAchievement)
+	patchTypeSchema(t, nil)
+	patchTypeCatalogue(t)
+	patchEventLog(t)
+
+	pS := bm.Patch(repository.GetStudentIDByUserID,
+		func(uid string) (string, error) { return "stu-1", nil })
+	defer pS.Unpatch()
+	pRef := bm.Patch(repository.GetAchievementReferenceByMongoID, consistencyRef("draft"))
+	defer pRef.Unpatch()
+	pA := bm.Patch(repository.GetAchievementByIdMongo,
+		func(id string) (*models.Achievement, error) {
+			return &models.Achievement{StudentID: "stu-1", AchievementType: "lomba"}, nil
+		})
+	defer pA.Unpatch()
+
+	var gotType any
+	pU := bm.Patch(repository.AchievementUpdateMongoMap,
+		func(id string, m map[string]any) error { gotType = m["achievementType"]; return nil })
+	defer pU.Unpatch()
+
+	for body, want := range map[string]int{"PUBLICATION": 200, "lomba": 422} {
+		gotType = nil
+		req := makeReq("PATCH", "/achievements/"+consistencyMongoID, map[string]any{"achievementType": body})
+		req.Header.Set("user_id", "user-1")
+		req.Header.Set("role", "mahasiswa")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, want, resp.StatusCode, body)
+		if want == 200 {
+			require.Equal(t, "publication", gotType)
+		} else {
+			require.Nil(t, gotType)
+		}
+	}
+}
+
+func TestAdminAchievementTypes(t *testing.T) {
+	app := fiber.New()
+	app.Post("/admin/achievement-types", service.AdminCreateAchievementType)
+	app.Put("/admin/achievement-types/:code", service.AdminUpdateAchievementType)
+	app.Delete("/admin/achievement-types/:code", service.AdminDeleteAchievementType)
+
+	t.Run("Create_Normalizes", func(t *testing.T) {
+		var got models.AchievementTypeRequest
+		p := bm.Patch(repository.CreateAchievementType,
+			func(req models.AchievementTypeRequest) (*models.AchievementType, error) {
+				got = models.AchievementTypeRequest{Code: req.Code, IsActive: req.IsActive, Labels: map[string]string{}}
+				for k, v := range req.Labels {
+					got.Labels[k] = v
+				}
+				return &models.AchievementType{Code: req.Code}, nil
+			})
+		defer p.Unpatch()
+
+		resp, err := app.Test(makeReq("POST", "/admin/achievement-types", map[string]any{
+			"code":   " Hackathon ",
+			"labels": map[string]any{"ID": " Hackathon ", "en": ""},
+		}))
+		require.NoError(t, err)
+		require.Equal(t, 201, resp.StatusCode)
+		require.Equal(t, "hackathon", got.Code)
+		require.Equal(t, map[string]string{"id": "Hackathon"}, got.Labels)
+		require.True(t, *got.IsActive)
+	})
+
+	t.Run("Create_Invalid", func(t *testing.T) {
+		for _, body := range []map[string]any{
+			{"code": "1st", "labels": map[string]any{"en": "First"}},
+			{"code": "lomba-kampus", "labels": map[string]any{"en": "Campus"}},
+			{"code": "lomba", "labels": map[string]any{}},
+			{"code": "lomba", "labels": map[string]any{"id": "Lomba"}, "default_points": 0},
+		} {
+			resp, err := app.Test(makeReq("POST", "/admin/achievement-types", body))
+			require.NoError(t, err)
+			require.Equal(t, 400, resp.StatusCode, body)
+		}
+	})
+
+	t.Run("Create_Duplicate", func(t *testing.T) {
+		p := bm.Patch(repository.CreateAchievementType,
+			func(req models.AchievementTypeRequest) (*models.AchievementType, error) {
+				return nil, repository.ErrAchievementTypeExists
+			})
+		defer p.Unpatch()
+
+		resp, err := app.Test(makeReq("POST", "/admin/achievement-types",
+			map[string]any{"code": "competition", "labels": map[string]any{"en": "Competition"}}))
+		require.NoError(t, err)
+		require.Equal(t, 409, resp.StatusCode)
+	})
+
+	t.Run("Update_NotFound", func(t *testing.T) {
+		p := bm.Patch(repository.UpdateAchievementType,
+			func(code string, req models.AchievementTypeRequest) (*models.AchievementType, error) {
+				return nil, repository.ErrAchievementTypeNotFound
+			})
+		defer p.Unpatch()
+
+		resp, err := app.Test(makeReq("PUT", "/admin/achievement-types/nope",
+			map[string]any{"labels": map[string]any{"en": "Nope"}, "is_active": false}))
+		require.NoError(t, err)
+		require.Equal(t, 404, resp.StatusCode)
+	})
+
+	t.Run("Delete_InUse", func(t *testing.T) {
+		deleted := false
+		pC := bm.Patch(repository.CountAchievementTypeValues,
+			func() (map[string]int64, error) { return map[string]int64{"competition": 3}, nil })
+		defer pC.Unpatch()
+		pD := bm.Patch(repository.DeleteAchievementType,
+			func(code string) error { deleted = true; return nil })
+		defer pD.Unpatch()
+
+		resp, err := app.Test(makeReq("DELETE", "/admin/achievement-types/competition", nil))
+		require.NoError(t, err)
+		require.Equal(t, 409, resp.StatusCode)
+		require.False(t, deleted)
+
+		resp, err = app.Test(makeReq("DELETE", "/admin/achievement-types/organization", nil))
+		require.NoError(t, err)
+		require.Equal(t, 200, resp.StatusCode)
+		require.True(t, deleted)
+	})
+}
+
+func TestRemapAchievementTypes(t *testing.T) {
+	patchTypeCatalogue(t,
+		models.AchievementType{Code: "competition", Labels: map[string]string{"id": "Kompetisi", "en": "Competition"}, IsActive: true},
+		models.AchievementType{Code: "publication", Labels: map[string]string{"id": "Publikasi"}, IsActive: false})
+	pC := bm.Patch(repository.CountAchievementTypeValues,
+		func() (map[string]int64, error) {
+			return map[string]int64{"competition": 10, "Competition": 4, "lomba": 2, "kompetisi": 1, "PUBLIKASI": 1, "misc": 5}, nil
+		})
+	defer pC.Unpatch()
+
+	var calls []string
+	pR := bm.Patch(repository.RemapAchievementTypeMongo,
+		func(from, to string) (int64, error) {
+			calls = append(calls, from+"->"+to)
+			return 1, nil
+		})
+	defer pR.Unpatch()
+
+	t.Run("DryRun", func(t *testing.T) {
+		calls = nil
+		report, err := service.RemapAchievementTypes(map[string]string{"lomba": "competition"}, true)
+		require.NoError(t, err)
+		require.Empty(t, calls)
+		require.Equal(t, []models.TypeRemapEntry{
+			{From: "Competition", To: "competition", Source: "auto", Count: 4},
+			{From: "PUBLIKASI", To: "publication", Source: "auto", Count: 1},
+			{From: "kompetisi", To: "competition", Source: "auto", Count: 1},
+			{From: "lomba", To: "competition", Source: "explicit", Count: 2},
+		}, report.Remapped)
+		require.Equal(t, []models.TypeRemapEntry{{From: "misc", Count: 5}}, report.Unmapped)
+	})
+
+	t.Run("Apply", func(t *testing.T) {
+		calls = nil
+		report, err := service.RemapAchievementTypes(nil, false)
+		require.NoError(t, err)
+		require.False(t, report.DryRun)
+		require.Equal(t, []string{"Competition->competition", "PUBLIKASI->publication", "kompetisi->competition"}, calls)
+		require.Len(t, report.Unmapped, 2)
+	})
+
+	t.Run("UnknownTarget", func(t *testing.T) {
+		app := fiber.New()
+		app.Post("/admin/achievement-types/remap", service.AdminRemapAchievementTypes)
+
+		resp, err := app.Test(makeReq("POST", "/admin/achievement-types/remap",
+			map[string]any{"mappings": map[string]any{"lomba": "contest"}}))
+		require.NoError(t, err)
+		require.Equal(t, 400, resp.StatusCode)
+	})
+}
+
+func TestListAchievementTypes(t *testing.T) {
+	db, mock := setupDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM achievement_types t`)).
+		WithArgs(true).
+		WillReturnRows(sqlmock.NewRows([]string{"code", "labels", "is_active", "default_points", "schema", "created_at", "updated_at"}).
+			AddRow("competition", []byte(`{"en":"Competition"}`), true, 10, []byte(`{"type":"object"}`), now, now).
+			AddRow("organization", []byte(`{"id":"Organisasi"}`), true, nil, nil, now, now))
+
+	types, err := repository.ListAchievementTypes(true)
+	require.NoError(t, err)
+	require.Len(t, types, 2)
+	require.Equal(t, 10, *types[0].DefaultPoints)
+	require.Equal(t, "object", types[0].Schema["type"])
+	require.Nil(t, types[1].DefaultPoints)
+	require.Nil(t, types[1].Schema)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
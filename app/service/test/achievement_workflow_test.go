@@ -0,0 +1,63 @@
+package service_test
+
+import (
+	"UAS_GO/app/service"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNextAchievementStatus(t *testing.T) {
+	cases := []struct {
+		name       string
+		action     string
+		current    string
+		role       string
+		want       string
+		roleDenied bool
+		allowed    []string
+	}{
+		{"SubmitDraft", service.AchievementActionSubmit, "draft", "mahasiswa", "submitted", false, nil},
+		{"ResubmitRejected", service.AchievementActionSubmit, "rejected", "mahasiswa", "submitted", false, nil},
+		{"UpdateDraftKeepsStatus", service.AchievementActionUpdate, "draft", "mahasiswa", "draft", false, nil},
+		{"VerifySubmitted", service.AchievementActionVerify, "submitted", "dosen_wali", "verified", false, nil},
+		{"RejectSubmitted", service.AchievementActionReject, "submitted", "dosen_wali", "rejected", false, nil},
+		{"DeleteDraft", service.AchievementActionDelete, "draft", "mahasiswa", "deleted", false, nil},
+
+		{"UpdateVerified", service.AchievementActionUpdate, "verified", "mahasiswa", "", false, []string{}},
+		{"DeleteRejected", service.AchievementActionDelete, "rejected", "mahasiswa", "", false, []string{"submitted"}},
+		{"VerifyDraft", service.AchievementActionVerify, "draft", "dosen_wali", "", false, []string{}},
+		{"VerifyVerified", service.AchievementActionVerify, "verified", "dosen_wali", "", false, []string{}},
+		{"VerifyByStudent", service.AchievementActionVerify, "submitted", "mahasiswa", "", true, []string{}},
+		{"SubmitByLecturer", service.AchievementActionSubmit, "draft", "dosen_wali", "", true, []string{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := service.NextAchievementStatus(tc.action, tc.current, tc.role)
+			if tc.allowed == nil {
+				require.NoError(t, err)
+				require.Equal(t, tc.want, got)
+				return
+			}
+
+			te, ok := err.(*service.TransitionError)
+			require.True(t, ok, "expected *TransitionError, got %v", err)
+			require.Equal(t, tc.roleDenied, te.RoleDenied)
+			require.Equal(t, tc.current, te.CurrentStatus)
+			require.Equal(t, tc.allowed, te.AllowedNext)
+		})
+	}
+}
+
+func TestAllowedNextStatuses(t *testing.T) {
+	require.Equal(t, []string{"submitted", "deleted"}, service.AllowedNextStatuses("draft", "mahasiswa"))
+	require.Equal(t, []string{"verified", "rejected"}, service.AllowedNextStatuses("submitted", "dosen_wali"))
+	require.Empty(t, service.AllowedNextStatuses("verified", "mahasiswa"))
+}
+
+func TestAuthorizingRole(t *testing.T) {
+	require.Equal(t, "dosen_wali", service.AuthorizingRole(service.AchievementActionVerify, "mahasiswa", "dosen_wali"))
+	require.Equal(t, "mahasiswa", service.AuthorizingRole(service.AchievementActionSubmit, "admin", "mahasiswa"))
+	require.Empty(t, service.AuthorizingRole(service.AchievementActionReject, "admin"))
+}
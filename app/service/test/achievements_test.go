@@ -6,9 +6,8 @@ import (
 	"UAS_GO/app/service"
 	"UAS_GO/helper"
 	"errors"
-	"io"
 	"mime/multipart"
-	"strings"
+	"regexp"
 	"time"
 
 	"bytes"
@@ -18,6 +17,7 @@ import (
 	"testing"
 
 	bm "bou.ke/monkey"
+	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/require"
 	"github.com/valyala/fasthttp"
@@ -54,47 +54,260 @@ func makeCtx(app *fiber.App, method, path string, body []byte) *fiber.Ctx {
 // 1. TEST GET ALL ACHIEVEMENTS
 ///////////////////////////////////////////////////////////////////////////
 
+// patchListAchievements mengganti repository.GetAllAchievements; query yang diterima disalin ke *got
+func patchListAchievements(t *testing.T, got *models.AchievementListQuery, items []models.Achievement, total int64) {
+	p := bm.Patch(repository.GetAllAchievements,
+		func(q models.AchievementListQuery) ([]models.Achievement, int64, error) {
+			cp := q
+			if q.StudentIDs != nil {
+				cp.StudentIDs = append([]string{}, q.StudentIDs...)
+			}
+			cp.Types = append([]string(nil), q.Types...)
+			cp.Statuses = append([]string(nil), q.Statuses...)
+			cp.Tags = append([]string(nil), q.Tags...)
+			if q.After != nil {
+				after := *q.After
+				cp.After = &after
+			}
+			*got = cp
+			if int64(len(items)) > q.Limit {
+				return items[:q.Limit], total, nil
+			}
+			return items, total, nil
+		})
+	t.Cleanup(p.Unpatch)
+}
+
+type achievementPageBody struct {
+	Status int                    `json:"status"`
+	Data   models.AchievementPage `json:"data"`
+}
+
 func TestGetAllAchievements(t *testing.T) {
 	app := fiber.New()
+	app.Get("/achievements", service.GetAllAchievements)
 
-	t.Run("Success", func(t *testing.T) {
+	pS := bm.Patch(repository.GetStudentIDByUserID,
+		func(userID string) (string, error) { return "stu-1", nil })
+	defer pS.Unpatch()
 
-		// monkey patch repository
-		patch := bm.Patch(repository.GetAllAchievements,
-			func(studentId string, achType string) ([]models.Achievement, error) {
-				return []models.Achievement{
-					{
-						ID:        oid("507f1f77bcf86cd799439011"),
-						StudentID: "stu-1",
-						Title:     "Achievement A",
-					},
-				}, nil
-			},
-		)
-		defer patch.Unpatch()
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	items := []models.Achievement{
+		{ID: oid("507f1f77bcf86cd799439011"), StudentID: "stu-1", Title: "A", Points: 30, CreatedAt: now},
+		{ID: oid("507f1f77bcf86cd799439012"), StudentID: "stu-1", Title: "B", Points: 20, CreatedAt: now.Add(-time.Hour)},
+		{ID: oid("507f1f77bcf86cd799439013"), StudentID: "stu-1", Title: "C", Points: 10, CreatedAt: now.Add(-2 * time.Hour)},
+	}
 
-		c := makeCtx(app, "GET", "/achievements?studentId=stu-1&type=academic", nil)
-		err := service.GetAllAchievements(c)
+	get := func(t *testing.T, path string) (*http.Response, achievementPageBody) {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("user_id", "user-1")
+		resp, err := app.Test(req)
 		require.NoError(t, err)
-		require.Equal(t, 200, c.Response().StatusCode())
+		var body achievementPageBody
+		if resp.StatusCode == 200 {
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		}
+		return resp, body
+	}
+
+	t.Run("Success_FiltersAndPage", func(t *testing.T) {
+		var got models.AchievementListQuery
+		patchListAchievements(t, &got, items, 3)
+
+		resp, body := get(t, "/achievements?type=competition,publication&status=Verified&tags=ai&tags=web"+
+			"&from=2025-01-01&to=2025-01-31&sort=points&order=asc&page=2&limit=2")
+		require.Equal(t, 200, resp.StatusCode)
+
+		require.Equal(t, []string{"stu-1"}, got.StudentIDs)
+		require.Equal(t, []string{"competition", "publication"}, got.Types)
+		require.Equal(t, []string{"verified"}, got.Statuses)
+		require.Equal(t, []string{"ai", "web"}, got.Tags)
+		require.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), *got.CreatedFrom)
+		require.Equal(t, time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), *got.CreatedBefore)
+		require.Equal(t, "points", got.SortBy)
+		require.False(t, got.Desc)
+		require.Equal(t, int64(2), got.Skip)
+		require.Equal(t, int64(3), got.Limit) // satu lebih untuk mendeteksi halaman berikutnya
+
+		require.Equal(t, 2, body.Data.Page)
+		require.Equal(t, 2, body.Data.Limit)
+		require.Equal(t, int64(3), body.Data.Total)
+		require.Len(t, body.Data.Results, 2)
+		require.NotEmpty(t, body.Data.NextCursor)
+	})
+
+	t.Run("Defaults_LastPage", func(t *testing.T) {
+		var got models.AchievementListQuery
+		patchListAchievements(t, &got, items, 3)
+
+		resp, body := get(t, "/achievements")
+		require.Equal(t, 200, resp.StatusCode)
+		require.Equal(t, "createdAt", got.SortBy)
+		require.True(t, got.Desc)
+		require.Equal(t, int64(0), got.Skip)
+		require.Equal(t, int64(11), got.Limit)
+		require.Nil(t, got.CreatedFrom)
+		require.Len(t, body.Data.Results, 3)
+		require.Empty(t, body.Data.NextCursor)
+	})
+
+	t.Run("Cursor_RoundTrip", func(t *testing.T) {
+		var got models.AchievementListQuery
+		patchListAchievements(t, &got, items, 3)
+
+		_, first := get(t, "/achievements?limit=1")
+		require.NotEmpty(t, first.Data.NextCursor)
+
+		resp, second := get(t, "/achievements?limit=1&page=5&cursor="+first.Data.NextCursor)
+		require.Equal(t, 200, resp.StatusCode)
+		require.NotNil(t, got.After)
+		require.Equal(t, "507f1f77bcf86cd799439011", got.After.ID)
+		require.Equal(t, "createdAt", got.After.SortBy)
+		require.True(t, got.After.Time.Equal(now))
+		require.Equal(t, int64(0), got.Skip)
+		require.Zero(t, second.Data.Page)
+
+		// cursor dari urutan lain ditolak
+		resp, _ = get(t, "/achievements?sort=points&cursor="+first.Data.NextCursor)
+		require.Equal(t, 400, resp.StatusCode)
+	})
+
+	t.Run("InvalidQuery", func(t *testing.T) {
+		called := false
+		p := bm.Patch(repository.GetAllAchievements,
+			func(q models.AchievementListQuery) ([]models.Achievement, int64, error) {
+				called = true
+				return nil, 0, nil
+			})
+		defer p.Unpatch()
+
+		for _, query := range []string{
+			"sort=title", "order=up", "status=archived", "from=01-01-2025",
+			"from=2025-02-01&to=2025-01-01", "limit=0", "page=-1", "cursor=!!",
+		} {
+			resp, _ := get(t, "/achievements?"+query)
+			require.Equal(t, 400, resp.StatusCode, query)
+		}
+		require.False(t, called)
 	})
 
 	t.Run("RepositoryError", func(t *testing.T) {
+		p := bm.Patch(repository.GetAllAchievements,
+			func(q models.AchievementListQuery) ([]models.Achievement, int64, error) {
+				return nil, 0, fiber.ErrInternalServerError
+			})
+		defer p.Unpatch()
 
-		patch := bm.Patch(repository.GetAllAchievements,
-			func(studentId string, achType string) ([]models.Achievement, error) {
-				return nil, fiber.ErrInternalServerError
-			},
-		)
-		defer patch.Unpatch()
+		resp, _ := get(t, "/achievements")
+		require.Equal(t, 500, resp.StatusCode)
+	})
 
-		c := makeCtx(app, "GET", "/achievements", nil)
-		err := service.GetAllAchievements(c)
+	t.Run("Unauthorized", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/achievements", nil))
 		require.NoError(t, err)
-		require.Equal(t, 500, c.Response().StatusCode())
+		require.Equal(t, 401, resp.StatusCode)
 	})
 }
 
+func TestGetAllAchievements_RoleScope(t *testing.T) {
+	app := fiber.New()
+	app.Use(roleFromHeader)
+	app.Get("/achievements", service.GetAllAchievements)
+
+	pS := bm.Patch(repository.GetStudentIDByUserID,
+		func(userID string) (string, error) {
+			if userID == "student-user" {
+				return "stu-1", nil
+			}
+			return "", errors.New("student not found")
+		})
+	defer pS.Unpatch()
+	pL := bm.Patch(repository.GetLecturerIDByUserID,
+		func(userID string) (string, error) {
+			if userID == "lecturer-user" {
+				return "lec-1", nil
+			}
+			return "", errors.New("no rows")
+		})
+	defer pL.Unpatch()
+
+	// lec-1 membimbing stu-1 (TI) dan stu-2 (SI); stu-3 (TI) bukan bimbingannya
+	var scopeCalls []string
+	pI := bm.Patch(repository.GetStudentIDsInScope,
+		func(advisorID, programStudy string) ([]string, error) {
+			scopeCalls = append(scopeCalls, advisorID+"|"+programStudy)
+			all := []struct{ id, advisor, prodi string }{
+				{"stu-1", "lec-1", "TI"}, {"stu-2", "lec-1", "SI"}, {"stu-3", "lec-2", "TI"},
+			}
+			ids := []string{}
+			for _, s := range all {
+				if (advisorID == "" || s.advisor == advisorID) && (programStudy == "" || s.prodi == programStudy) {
+					ids = append(ids, s.id)
+				}
+			}
+			return ids, nil
+		})
+	defer pI.Unpatch()
+
+	var got models.AchievementListQuery
+	patchListAchievements(t, &got, nil, 0)
+
+	cases := []struct {
+		name, userID, role, query string
+		status                    int
+		want                      []string
+		scope                     []string
+	}{
+		{"Admin_All", "admin-user", "admin", "", 200, nil, nil},
+		{"Admin_Student", "admin-user", "admin", "studentId=stu-3", 200, []string{"stu-3"}, nil},
+		{"Admin_ProgramStudy", "admin-user", "admin", "programStudy=TI", 200, []string{"stu-1", "stu-3"}, []string{"|TI"}},
+		{"Admin_ProgramStudyAndStudent", "admin-user", "admin", "programStudy=SI&studentId=stu-3", 200, []string{}, []string{"|SI"}},
+		{"Lecturer_Advisees", "lecturer-user", "dosen_wali", "", 200, []string{"stu-1", "stu-2"}, []string{"lec-1|"}},
+		{"Lecturer_ProgramStudy", "lecturer-user", "dosen_wali", "programStudy=TI", 200, []string{"stu-1"}, []string{"lec-1|TI"}},
+		{"Lecturer_Advisee", "lecturer-user", "dosen_wali", "studentId=stu-2", 200, []string{"stu-2"}, []string{"lec-1|"}},
+		{"Lecturer_NotAdvisee", "lecturer-user", "dosen_wali", "studentId=stu-3", 403, nil, []string{"lec-1|"}},
+		{"Lecturer_NoProfile", "other-user", "dosen_wali", "", 403, nil, nil},
+		{"Student_Own", "student-user", "mahasiswa", "studentId=stu-1", 200, []string{"stu-1"}, nil},
+		{"Student_Other", "student-user", "mahasiswa", "studentId=stu-2", 403, nil, nil},
+		{"Student_NoProfile", "other-user", "mahasiswa", "", 403, nil, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = models.AchievementListQuery{Limit: -1}
+			scopeCalls = nil
+
+			req := httptest.NewRequest("GET", "/achievements?"+tc.query, nil)
+			req.Header.Set("user_id", tc.userID)
+			req.Header.Set("role", tc.role)
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, tc.status, resp.StatusCode)
+			require.Equal(t, tc.scope, scopeCalls)
+
+			if tc.status != 200 {
+				require.Equal(t, int64(-1), got.Limit, "repository must not be called")
+				return
+			}
+			require.Equal(t, tc.want, got.StudentIDs)
+		})
+	}
+}
+
+func TestGetAchievementStatusesByMongoIDs(t *testing.T) {
+	db, mock := setupDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM achievement_references`)).
+		WillReturnRows(sqlmock.NewRows([]string{"mongo_achievement_id", "status"}).
+			AddRow("m-1", "verified").
+			AddRow("m-2", "draft"))
+
+	statuses, err := repository.GetAchievementStatusesByMongoIDs([]string{"m-1", "m-2", "m-3"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"m-1": "verified", "m-2": "draft"}, statuses)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 ///////////////////////////////////////////////////////////////////////////
 // 2. TEST GET ACHIEVEMENT BY ID
 ///////////////////////////////////////////////////////////////////////////
@@ -173,7 +386,10 @@ func TestCreateAchievement(t *testing.T) {
 
 	// ROUTE
 	app.Post("/achievements", service.CreateAchievement)
-	
+
+	// tipe tanpa schema: details tidak divalidasi
+	patchTypeSchema(t, nil)
+	patchTypeCatalogue(t)
 
 	t.Run("Success", func(t *testing.T) {
 
@@ -195,7 +411,9 @@ func TestCreateAchievement(t *testing.T) {
 			})
 		defer p3.Unpatch()
 
-		body, _ := json.Marshal(map[string]any{"title": "New A"})
+		events := patchEventLog(t)
+
+		body, _ := json.Marshal(map[string]any{"title": "New A", "achievementType": "competition"})
 
 		req := httptest.NewRequest("POST", "/achievements", bytes.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
@@ -213,6 +431,8 @@ func TestCreateAchievement(t *testing.T) {
 		resp, err := app.Test(req)
 		require.NoError(t, err)
 		require.Equal(t, 201, resp.StatusCode)
+		require.Len(t, *events, 1)
+		require.Equal(t, "created", (*events)[0].EventType)
 	})
 
 	t.Run("Unauthorized", func(t *testing.T) {
@@ -273,8 +493,17 @@ func makeReq(method, path string, body any) *http.Request {
     return req
 }
 
+// roleFromHeader menyalin header "role" ke Locals, seperti AuthRequired dari claims JWT
+func roleFromHeader(c *fiber.Ctx) error {
+	if role := c.Get("role"); role != "" {
+		c.Locals("role", role)
+	}
+	return c.Next()
+}
+
 func TestUpdateAchievement(t *testing.T) {
 	app := fiber.New()
+	app.Use(roleFromHeader)
 	app.Patch("/achievements/:id", func(c *fiber.Ctx) error {
 		return service.UpdateAchievement(c)
 	})
@@ -299,14 +528,54 @@ func TestUpdateAchievement(t *testing.T) {
 			func(id string, m map[string]any) error { return nil })
 		defer p3.Unpatch()
 
+		p4 := bm.Patch(repository.GetAchievementReferenceByMongoID, consistencyRef("draft"))
+		defer p4.Unpatch()
+
+		events := patchEventLog(t)
+
 		body := map[string]any{"title": "Updated"}
 
 		req := makeReq("PATCH", "/achievements/507f1f77bcf86cd799439011", body)
 		req.Header.Set("user_id", "user-1")
+		req.Header.Set("role", "mahasiswa")
 
 		resp, err := app.Test(req)
 		require.NoError(t, err)
 		require.Equal(t, 200, resp.StatusCode)
+
+		require.Len(t, *events, 1)
+		require.Equal(t, "updated", (*events)[0].EventType)
+		require.Equal(t, models.FieldChange{From: "Old", To: "Updated"}, (*events)[0].Changes["title"])
+	})
+
+	t.Run("Verified_Conflict", func(t *testing.T) {
+
+		p1 := bm.Patch(repository.GetStudentIDByUserID,
+			func(uid string) (string, error) { return "stu-1", nil })
+		defer p1.Unpatch()
+
+		p2 := bm.Patch(repository.GetAchievementByIdMongo,
+			func(id string) (*models.Achievement, error) {
+				return &models.Achievement{ID: oid(id), StudentID: "stu-1"}, nil
+			})
+		defer p2.Unpatch()
+
+		p3 := bm.Patch(repository.GetAchievementReferenceByMongoID, consistencyRef("verified"))
+		defer p3.Unpatch()
+
+		updated := false
+		p4 := bm.Patch(repository.AchievementUpdateMongoMap,
+			func(id string, m map[string]any) error { updated = true; return nil })
+		defer p4.Unpatch()
+
+		req := makeReq("PATCH", "/achievements/507f1f77bcf86cd799439011", map[string]any{"title": "X"})
+		req.Header.Set("user_id", "user-1")
+		req.Header.Set("role", "mahasiswa")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 409, resp.StatusCode)
+		require.False(t, updated)
 	})
 
 	t.Run("BlockedFieldPresent", func(t *testing.T) {
@@ -408,10 +677,14 @@ func TestUpdateAchievement(t *testing.T) {
 			func(id string, m map[string]any) error { return errors.New("update failed") })
 		defer p3.Unpatch()
 
+		p4 := bm.Patch(repository.GetAchievementReferenceByMongoID, consistencyRef("rejected"))
+		defer p4.Unpatch()
+
 		body := map[string]any{"title": "X"}
 
 		req := makeReq("PATCH", "/achievements/507f1f77bcf86cd799439011", body)
 		req.Header.Set("user_id", "user-1")
+		req.Header.Set("role", "mahasiswa")
 
 		resp, err := app.Test(req)
 		require.NoError(t, err)
@@ -465,7 +738,7 @@ func DeleteAchievement(c *fiber.Ctx) error {
 	}
 
 	// 2) HAPUS reference Postgres PAKAI reference ID
-	if err := repository.AchievementSoftDeleteReference(ref.ID); err != nil {
+	if err := repository.AchievementSoftDeleteReference(ref.ID, []string{"draft"}); err != nil {
 		return helper.InternalError(c, "Failed to delete achievement reference")
 	}
 
@@ -496,6 +769,7 @@ func TestSubmitVerifyRejectUploadHistory(t *testing.T) {
 	app := fiber.New()
 
 	// register routes (use same param names as service expects)
+	app.Use(roleFromHeader)
 	app.Post("/achievements/:id/submit", func(c *fiber.Ctx) error {
 		return service.SubmitAchievement(c)
 	})
@@ -553,16 +827,27 @@ t.Run("SubmitAchievement_Success", func(t *testing.T) {
 
     // Patch UpdateReferenceStatusSubmitted -> success
     p5 := bm.Patch(repository.UpdateReferenceStatusSubmitted,
-        func(mongoID string) error { return nil })
+        func(mongoID string, expectedFrom []string) error { return nil })
     defer p5.Unpatch()
 
+    events := patchEventLog(t)
+
     // Buat request & set header user_id (helper.GetUserID membaca header)
     req := makeReq("POST", "/achievements/507f1f77bcf86cd799439011/submit", nil)
     req.Header.Set("user_id", "user-1")
+    req.Header.Set("role", "mahasiswa")
 
     resp, err := app.Test(req)
     require.NoError(t, err)
     require.Equal(t, 200, resp.StatusCode)
+
+    require.Len(t, *events, 1)
+    e := (*events)[0]
+    require.Equal(t, "submitted", e.EventType)
+    require.Equal(t, "draft", *e.FromStatus)
+    require.Equal(t, "submitted", *e.ToStatus)
+    require.Equal(t, "user-1", *e.ActorUserID)
+    require.Equal(t, "mahasiswa", *e.ActorRole)
 })
 
 
@@ -597,11 +882,18 @@ t.Run("SubmitAchievement_Success", func(t *testing.T) {
 
 		req := makeReq("POST", "/achievements/507f1f77bcf86cd799439011/submit", nil)
 		req.Header.Set("user_id", "user-1")
+		req.Header.Set("role", "mahasiswa")
 
 		resp, err := app.Test(req)
 		require.NoError(t, err)
-		// service returns BadRequest when already submitted
-		require.Equal(t, 400, resp.StatusCode)
+		// submitted -> submitted bukan transisi yang valid
+		require.Equal(t, 409, resp.StatusCode)
+
+		var out map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		data := out["data"].(map[string]any)
+		require.Equal(t, "submitted", data["current_status"])
+		require.Empty(t, data["allowed_next_states"])
 	})
 
 	// -------------------------------
@@ -641,17 +933,26 @@ t.Run("SubmitAchievement_Success", func(t *testing.T) {
 
 		// VerifyAchievementReference -> success
 		pVR := bm.Patch(repository.VerifyAchievementReference,
-			func(refID string, dosenID string) error { return nil })
+			func(refID string, dosenID string, expectedFrom []string) error { return nil })
 		defer pVR.Unpatch()
 
+		patchScoring(t, models.ScoringModeOverride)
+		events := patchEventLog(t)
+
 		// body: points
 		body := map[string]any{"points": 10}
 		req := makeReq("POST", "/achievements/507f1f77bcf86cd799439011/verify", body)
 		req.Header.Set("user_id", "lecturer-user-1")
+		req.Header.Set("role", "dosen_wali")
 
 		resp, err := app.Test(req)
 		require.NoError(t, err)
 		require.Equal(t, 200, resp.StatusCode)
+
+		require.Len(t, *events, 1)
+		require.Equal(t, "verified", (*events)[0].EventType)
+		require.Equal(t, "dosen_wali", *(*events)[0].ActorRole)
+		require.Equal(t, 10, (*events)[0].Changes["points"].To)
 	})
 
 	t.Run("VerifyAchievement_NotAdvisor", func(t *testing.T) {
@@ -680,6 +981,7 @@ t.Run("SubmitAchievement_Success", func(t *testing.T) {
 		body := map[string]any{"points": 5}
 		req := makeReq("POST", "/achievements/507f1f77bcf86cd799439011/verify", body)
 		req.Header.Set("user_id", "lecturer-user-2")
+		req.Header.Set("role", "dosen_wali")
 
 		resp, err := app.Test(req)
 		require.NoError(t, err)
@@ -709,9 +1011,13 @@ t.Run("SubmitAchievement_Success", func(t *testing.T) {
 			func(lecturerID, studentID string) (bool, error) { return true, nil })
 		defer pAdvisor.Unpatch()
 
+		// tanpa aturan rubrik yang cocok, poin tetap wajib diisi
+		patchScoring(t, models.ScoringModeOverride)
+
 		body := map[string]any{"points": 0} // invalid, service requires >0
 		req := makeReq("POST", "/achievements/507f1f77bcf86cd799439011/verify", body)
 		req.Header.Set("user_id", "lecturer-user-3")
+		req.Header.Set("role", "dosen_wali")
 
 		resp, err := app.Test(req)
 		require.NoError(t, err)
@@ -748,16 +1054,23 @@ t.Run("SubmitAchievement_Success", func(t *testing.T) {
 			func(id, note, dosenID string) error { return nil })
 		defer pRM.Unpatch()
 		pRR := bm.Patch(repository.RejectAchievementReference,
-			func(refID, note, dosenID string) error { return nil })
+			func(refID, note, dosenID string, expectedFrom []string) error { return nil })
 		defer pRR.Unpatch()
 
+		events := patchEventLog(t)
+
 		body := map[string]any{"note": "not sufficient"}
 		req := makeReq("POST", "/achievements/507f1f77bcf86cd799439011/reject", body)
 		req.Header.Set("user_id", "lecturer-user-4")
+		req.Header.Set("role", "dosen_wali")
 
 		resp, err := app.Test(req)
 		require.NoError(t, err)
 		require.Equal(t, 200, resp.StatusCode)
+
+		require.Len(t, *events, 1)
+		require.Equal(t, "rejected", (*events)[0].EventType)
+		require.Equal(t, "not sufficient", *(*events)[0].Note)
 	})
 
 	t.Run("RejectAchievement_MissingNote", func(t *testing.T) {
@@ -786,6 +1099,7 @@ t.Run("SubmitAchievement_Success", func(t *testing.T) {
 		body := map[string]any{} // missing note
 		req := makeReq("POST", "/achievements/507f1f77bcf86cd799439011/reject", body)
 		req.Header.Set("user_id", "lecturer-user-5")
+		req.Header.Set("role", "dosen_wali")
 
 		resp, err := app.Test(req)
 		require.NoError(t, err)
@@ -807,19 +1121,32 @@ t.Run("SubmitAchievement_Success", func(t *testing.T) {
 			})
 		defer pDoc.Unpatch()
 
+		pRef := bm.Patch(repository.GetAchievementReferenceByMongoID,
+			func(mongoID string) (*models.AchievementReference, error) {
+				return &models.AchievementReference{ID: "ref-u", Status: models.AchievementStatusDraft}, nil
+			})
+		defer pRef.Unpatch()
+
 		pAdd := bm.Patch(repository.AddAchievementAttachment,
 			func(mongoID string, att models.Attachment) error { return nil })
 		defer pAdd.Unpatch()
 
+		events := patchEventLog(t)
+
 		// make multipart request (small text file)
 		req, err := makeMultipartReq("POST", "/achievements/507f1f77bcf86cd799439011/upload",
 			"file", "test.txt", "text/plain", []byte("hello"))
 		require.NoError(t, err)
 		req.Header.Set("user_id", "user-1")
+		req.Header.Set("role", "mahasiswa")
 
 		resp, err := app.Test(req)
 		require.NoError(t, err)
 		require.Equal(t, 201, resp.StatusCode)
+
+		require.Len(t, *events, 1)
+		require.Equal(t, "attachment_added", (*events)[0].EventType)
+		require.Equal(t, "user-1", *(*events)[0].ActorUserID)
 	})
 
 	t.Run("UploadAchievementFile_NotOwner", func(t *testing.T) {
@@ -844,6 +1171,44 @@ t.Run("SubmitAchievement_Success", func(t *testing.T) {
 		require.Equal(t, 403, resp.StatusCode)
 	})
 
+	// lampiran hanya boleh ditambahkan selama prestasi masih bisa diedit (draft / rejected)
+	for _, status := range []string{models.AchievementStatusSubmitted, models.AchievementStatusVerified} {
+		status := status
+		t.Run("UploadAchievementFile_"+status, func(t *testing.T) {
+			pS := bm.Patch(repository.GetStudentIDByUserID,
+				func(uid string) (string, error) { return "stu-u", nil })
+			defer pS.Unpatch()
+
+			pDoc := bm.Patch(repository.GetAchievementByIdMongo,
+				func(id string) (*models.Achievement, error) {
+					return &models.Achievement{ID: oid(id), StudentID: "stu-u"}, nil
+				})
+			defer pDoc.Unpatch()
+
+			pRef := bm.Patch(repository.GetAchievementReferenceByMongoID,
+				func(mongoID string) (*models.AchievementReference, error) {
+					return &models.AchievementReference{ID: "ref-u", Status: status}, nil
+				})
+			defer pRef.Unpatch()
+
+			added := false
+			pAdd := bm.Patch(repository.AddAchievementAttachment,
+				func(mongoID string, att models.Attachment) error { added = true; return nil })
+			defer pAdd.Unpatch()
+
+			req, err := makeMultipartReq("POST", "/achievements/507f1f77bcf86cd799439011/upload",
+				"file", "test.txt", "text/plain", []byte("hello"))
+			require.NoError(t, err)
+			req.Header.Set("user_id", "user-1")
+			req.Header.Set("role", "mahasiswa")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, 409, resp.StatusCode)
+			require.False(t, added)
+		})
+	}
+
 	// -------------------------------
 	// 5) GetAchievementHistory
 	// -------------------------------
@@ -894,6 +1259,20 @@ t.Run("SubmitAchievement_Success", func(t *testing.T) {
 			})
 		defer pDoc.Unpatch()
 
+		// ditolak lalu diajukan ulang: penolakan tetap ada di riwayat
+		note := "bukti kurang"
+		pEv := bm.Patch(repository.GetAchievementEvents,
+			func(mongoID string) ([]models.AchievementEvent, error) {
+				return []models.AchievementEvent{
+					{ID: 1, MongoAchievementID: mongoID, EventType: "created"},
+					{ID: 2, MongoAchievementID: mongoID, EventType: "submitted"},
+					{ID: 3, MongoAchievementID: mongoID, EventType: "rejected", Note: &note},
+					{ID: 4, MongoAchievementID: mongoID, EventType: "submitted"},
+					{ID: 5, MongoAchievementID: mongoID, EventType: "verified"},
+				}, nil
+			})
+		defer pEv.Unpatch()
+
 		req := makeReq("GET", "/achievements/507f1f77bcf86cd799439011/history", nil)
 		// no auth required for history - but set header anyway
 		req.Header.Set("user_id", "user-1")
@@ -902,9 +1281,15 @@ t.Run("SubmitAchievement_Success", func(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, 200, resp.StatusCode)
 
-		// optional: check response body contains "history"
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		require.True(t, strings.Contains(string(bodyBytes), "history"))
+		var out struct {
+			Data struct {
+				History []models.AchievementEvent `json:"history"`
+			} `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		require.Len(t, out.Data.History, 5)
+		require.Equal(t, "rejected", out.Data.History[2].EventType)
+		require.Equal(t, note, *out.Data.History[2].Note)
 	})
 
 	t.Run("GetAchievementHistory_ReferenceNotFound", func(t *testing.T) {
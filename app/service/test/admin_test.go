@@ -223,12 +223,23 @@ t.Run("AdminCreateUser_Success", func(t *testing.T) {
 			})
 		defer p.Unpatch()
 
+		var audited []models.RBACAuditEntry
+		pa := bm.Patch(repository.InsertRBACAudit,
+			func(e *models.RBACAuditEntry) error {
+				audited = append(audited, *e)
+				return nil
+			})
+		defer pa.Unpatch()
+
 		body, _ := json.Marshal(map[string]any{"roleId": "role-2"})
 		req := httptest.NewRequest("PUT", "/admin/users/uid-1/role", bytes.NewReader(body))
 		req.Header.Set("Content-Type", "application/json")
 		resp, err := app.Test(req)
 		require.NoError(t, err)
 		require.Equal(t, 200, resp.StatusCode)
+		require.Len(t, audited, 1)
+		require.Equal(t, models.RBACAuditUserRoleAssigned, audited[0].Action)
+		require.Equal(t, "uid-1", *audited[0].TargetUserID)
 	})
 
 	t.Run("AdminUpdateUserRole_NotFound", func(t *testing.T) {
@@ -2,9 +2,15 @@ package service_test
 
 import (
 	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
 
+	"reflect"
 	"regexp"
 	"testing"
+	"time"
 	
 	"UAS_GO/app/service"
 	"UAS_GO/database"
@@ -14,6 +20,7 @@ import (
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"bou.ke/monkey"
+	"github.com/gofiber/fiber/v2"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -27,10 +34,9 @@ func setupDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
 }
 
 func TestAuthService_Login(t *testing.T) {
-	// Patch helper.GenerateToken with the exact signature used in your code.
-	// According to the panic, GenerateToken accepts models.User.
-	monkey.Patch(helper.GenerateToken, func(u models.User) (string, error) {
-		return "fixed-token", nil
+	// Patch helper.GenerateAccessToken with the exact signature used in your code.
+	monkey.Patch(helper.GenerateAccessToken, func(u models.User, sessionID string) (string, time.Time, error) {
+		return "fixed-token", time.Now().Add(15 * time.Minute), nil
 	})
 	// Patch CheckPassword (likely signature func(password, hash string) bool)
 	monkey.Patch(helper.CheckPassword, func(password, hash string) bool {
@@ -61,7 +67,7 @@ func TestAuthService_Login(t *testing.T) {
 					WithArgs("notfound@example.com").WillReturnError(sql.ErrNoRows)
 			},
 			wantErr: true,
-			errText: "user not found",
+			errText: "invalid email/NIM or password",
 		},
 		{
 			name:       "InvalidPassword",
@@ -75,7 +81,7 @@ func TestAuthService_Login(t *testing.T) {
 						AddRow("u-1", "bob@example.com", string(hashed), "r-1", true))
 			},
 			wantErr: true,
-			errText: "invalid password",
+			errText: "invalid email/NIM or password",
 		},
 		{
 			name:       "InactiveUser",
@@ -102,6 +108,16 @@ func TestAuthService_Login(t *testing.T) {
 					WithArgs("alice@example.com").WillReturnRows(sqlmock.NewRows([]string{"id", "email", "password_hash", "role_id", "is_active"}).
 						AddRow("u-1", "alice@example.com", string(hashed), "r-1", true))
 
+				// login membuat refresh token baru untuk perangkat ini
+				mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO refresh_tokens`)).
+					WithArgs(sqlmock.AnyArg(), "u-1", sqlmock.AnyArg(), sqlmock.AnyArg(), "test-device", sqlmock.AnyArg()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+
+				// user memegang dua role: r-1 (utama) dan r-2
+				mock.ExpectQuery(regexp.QuoteMeta(`FROM user_roles ur`)).
+					WithArgs("u-1").
+					WillReturnRows(sqlmock.NewRows([]string{"role_id"}).AddRow("r-1").AddRow("r-2"))
+
 				// repository.GetPermissionsByRoleID runs a SQL query inside Login -> mock it
 				mock.ExpectQuery(regexp.QuoteMeta(`
 		SELECT p.name
@@ -109,6 +125,15 @@ func TestAuthService_Login(t *testing.T) {
 		JOIN permissions p ON rp.permission_id = p.id
 		WHERE rp.role_id = $1
 	`)).WithArgs("r-1").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("achievement:create"))
+				mock.ExpectQuery(regexp.QuoteMeta(`FROM role_permissions rp`)).
+					WithArgs("r-2").WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("achievement:read"))
+
+				// override per user ikut menentukan daftar permission di respons login
+				mock.ExpectQuery(regexp.QuoteMeta(`FROM user_permissions up`)).
+					WithArgs("u-1").
+					WillReturnRows(sqlmock.NewRows([]string{"user_id", "name", "effect", "reason", "granted_by", "created_at", "updated_at"}).
+						AddRow("u-1", "achievement:create", "deny", nil, nil, time.Now(), time.Now()).
+						AddRow("u-1", "report:statistics", "grant", nil, nil, time.Now(), time.Now()))
 			},
 			wantErr: false,
 		},
@@ -118,7 +143,7 @@ func TestAuthService_Login(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setupMock()
-			resp, err := svc.Login(tc.identifier, tc.password, tc.byNIM)
+			resp, err := svc.Login(tc.identifier, tc.password, tc.byNIM, "test-device")
 			if tc.wantErr {
 				if err == nil {
 					t.Fatalf("expected error, got nil")
@@ -134,12 +159,175 @@ func TestAuthService_Login(t *testing.T) {
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
-			if resp == nil || resp.Token == "" {
+			if resp == nil || resp.AccessToken == "" {
 				t.Fatalf("expected valid token in response")
 			}
+			if resp.RefreshToken == "" {
+				t.Fatalf("expected refresh token in response")
+			}
+			if tc.name == "Success" && !reflect.DeepEqual(resp.Permissions, []string{"achievement:read", "report:statistics"}) {
+				t.Fatalf("expected effective permissions [achievement:read report:statistics], got %v", resp.Permissions)
+			}
+			if tc.name == "Success" && !reflect.DeepEqual(resp.User.RoleIDs, []string{"r-1", "r-2"}) {
+				t.Fatalf("expected role ids [r-1 r-2], got %v", resp.User.RoleIDs)
+			}
 			if err := mock.ExpectationsWereMet(); err != nil {
 				t.Fatalf("unmet expectations: %v", err)
 			}
 		})
 	}
 }
+
+func TestAuthLogout_RevokesToken(t *testing.T) {
+	db, mock := setupDB(t)
+	defer db.Close()
+
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", "u-1")
+		c.Locals("jti", "jti-1")
+		c.Locals("token_exp", time.Now().Add(time.Hour))
+		c.Locals("session_id", "fam-1")
+		return c.Next()
+	})
+	app.Post("/auth/logout", service.AuthLogout)
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO revoked_tokens`)).
+		WithArgs("jti-1", "u-1", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE refresh_tokens`)).
+		WithArgs("fam-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	resp, err := app.Test(httptest.NewRequest("POST", "/auth/logout", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("unmet expectations: %v", err)
+	}
+}
+
+func TestAuthService_RefreshToken(t *testing.T) {
+	db, mock := setupDB(t)
+	defer db.Close()
+
+	svc := service.NewAuthService()
+	refreshCols := []string{"id", "user_id", "family_id", "token_hash", "device", "expires_at", "created_at", "revoked_at", "replaced_by"}
+	plain := "plain-refresh-token"
+
+	t.Run("Success_RotatesToken", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM refresh_tokens`)).
+			WithArgs(helper.HashToken(plain)).
+			WillReturnRows(sqlmock.NewRows(refreshCols).
+				AddRow("rt-1", "u-1", "fam-1", helper.HashToken(plain), "laptop", time.Now().Add(time.Hour), time.Now(), nil, nil))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, email, password_hash, role_id, is_active FROM users WHERE id = $1`)).
+			WithArgs("u-1").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "password_hash", "role_id", "is_active"}).
+				AddRow("u-1", "alice@example.com", "hash", "r-1", true))
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE refresh_tokens`)).
+			WithArgs("rt-1", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO refresh_tokens`)).
+			WithArgs(sqlmock.AnyArg(), "u-1", "fam-1", sqlmock.AnyArg(), "laptop", sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM user_roles ur`)).
+			WithArgs("u-1").
+			WillReturnRows(sqlmock.NewRows([]string{"role_id"}).AddRow("r-1"))
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM role_permissions rp`)).
+			WithArgs("r-1").
+			WillReturnRows(sqlmock.NewRows([]string{"name"}))
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM user_permissions up`)).
+			WithArgs("u-1").
+			WillReturnRows(sqlmock.NewRows([]string{"user_id", "name", "effect", "reason", "granted_by", "created_at", "updated_at"}))
+
+		resp, err := svc.RefreshToken(plain)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if resp.AccessToken == "" || resp.RefreshToken == "" || resp.RefreshToken == plain {
+			t.Fatalf("expected new access and refresh tokens")
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("unmet expectations: %v", err)
+		}
+	})
+
+	t.Run("ReuseRevokesFamily", func(t *testing.T) {
+		revokedAt := time.Now().Add(-time.Minute)
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM refresh_tokens`)).
+			WithArgs(helper.HashToken(plain)).
+			WillReturnRows(sqlmock.NewRows(refreshCols).
+				AddRow("rt-1", "u-1", "fam-1", helper.HashToken(plain), "laptop", time.Now().Add(time.Hour), time.Now(), revokedAt, "rt-2"))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE refresh_tokens`)).
+			WithArgs("fam-1").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		if _, err := svc.RefreshToken(plain); err == nil {
+			t.Fatalf("expected error for reused refresh token")
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("unmet expectations: %v", err)
+		}
+	})
+
+	t.Run("UnknownToken", func(t *testing.T) {
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM refresh_tokens`)).
+			WithArgs(helper.HashToken("unknown")).
+			WillReturnError(sql.ErrNoRows)
+
+		if _, err := svc.RefreshToken("unknown"); err == nil {
+			t.Fatalf("expected error for unknown refresh token")
+		}
+	})
+}
+
+func TestGetJWKS(t *testing.T) {
+	app := fiber.New()
+	app.Get("/.well-known/jwks.json", service.GetJWKS)
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.StatusCode != 200 {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+
+	var body map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if _, ok := body["keys"]; !ok {
+		t.Fatalf("expected keys field in JWKS, got %v", body)
+	}
+}
+
+func TestAccessToken_CarriesKidAndValidates(t *testing.T) {
+	token, _, err := helper.GenerateAccessToken(models.User{ID: "u-1", Email: "alice@example.com", RoleID: "r-1"}, "fam-1")
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+
+	header, _, _ := strings.Cut(token, ".")
+	raw, err := base64.RawURLEncoding.DecodeString(header)
+	if err != nil {
+		t.Fatalf("invalid token header: %v", err)
+	}
+	if !strings.Contains(string(raw), `"kid"`) {
+		t.Fatalf("expected kid in token header, got %s", raw)
+	}
+
+	claims, err := helper.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if claims.UserID != "u-1" || claims.SessionID != "fam-1" {
+		t.Fatalf("unexpected claims: %+v", claims)
+	}
+}
@@ -0,0 +1,248 @@
+package service_test
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/app/service"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	bm "bou.ke/monkey"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// patchThrottleStore: penyimpanan login_throttles di memori dengan aturan yang sama seperti RecordLoginFailure
+func patchThrottleStore(t *testing.T) map[string]*models.LoginThrottle {
+	store := map[string]*models.LoginThrottle{}
+	pG := bm.Patch(repository.GetLoginThrottle, func(scope, key string) (*models.LoginThrottle, error) {
+		if th, ok := store[scope+"|"+key]; ok {
+			cp := *th
+			return &cp, nil
+		}
+		return nil, nil
+	})
+	pR := bm.Patch(repository.RecordLoginFailure, func(scope, key string, maxAttempts int, lockout time.Duration) (*models.LoginThrottle, error) {
+		th, ok := store[scope+"|"+key]
+		if !ok {
+			th = &models.LoginThrottle{Scope: scope, Key: key}
+			store[scope+"|"+key] = th
+		}
+		th.Failures++
+		th.LastFailureAt = time.Now()
+		if th.Failures >= maxAttempts {
+			until := time.Now().Add(lockout)
+			th.LockedUntil = &until
+		}
+		cp := *th
+		return &cp, nil
+	})
+	pC := bm.Patch(repository.ClearLoginThrottle, func(scope, key string) error {
+		delete(store, scope+"|"+key)
+		return nil
+	})
+	t.Cleanup(func() {
+		pG.Unpatch()
+		pR.Unpatch()
+		pC.Unpatch()
+	})
+	return store
+}
+
+// patchLoginResult mengganti AuthService.Login; password "secret" berhasil, selain itu kredensial salah
+func patchLoginResult(t *testing.T) *int {
+	calls := new(int)
+	p := bm.PatchInstanceMethod(reflect.TypeOf(&service.AuthService{}), "Login",
+		func(_ *service.AuthService, identifier, password string, byNIM bool, device string) (*models.LoginResponse, error) {
+			*calls++
+			if password != "secret" {
+				return nil, service.ErrInvalidCredentials
+			}
+			return &models.LoginResponse{AccessToken: "tok"}, nil
+		})
+	t.Cleanup(p.Unpatch)
+	return calls
+}
+
+func loginMessage(t *testing.T, body io.Reader) string {
+	raw, _ := io.ReadAll(body)
+	var out struct {
+		Message string `json:"message"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &out))
+	return out.Message
+}
+
+func TestAuthLogin_Lockout(t *testing.T) {
+	t.Setenv("LOGIN_MAX_ATTEMPTS", "3")
+	t.Setenv("LOGIN_DELAY_BASE", "0")
+	t.Setenv("LOGIN_LOCKOUT_DURATION", "10m")
+	store := patchThrottleStore(t)
+	calls := patchLoginResult(t)
+
+	app := fiber.New()
+	app.Post("/login", service.AuthLogin)
+	wrong := map[string]any{"email": "Bob@Example.com", "password": "nope"}
+
+	for i := 0; i < 3; i++ {
+		resp, err := app.Test(makeReq("POST", "/login", wrong))
+		require.NoError(t, err)
+		require.Equal(t, 401, resp.StatusCode)
+	}
+	require.Equal(t, 3, store["account|email:bob@example.com"].Failures)
+	require.NotNil(t, store["account|email:bob@example.com"].LockedUntil)
+
+	// terkunci: password benar pun ditolak tanpa memanggil Login
+	resp, err := app.Test(makeReq("POST", "/login", map[string]any{"email": "bob@example.com", "password": "secret"}))
+	require.NoError(t, err)
+	require.Equal(t, 429, resp.StatusCode)
+	retry, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+	require.InDelta(t, 600, retry, 2)
+	require.Equal(t, 3, *calls)
+
+	// akun lain dari IP yang sama masih boleh login; sukses mereset hitungan akunnya saja
+	resp, err = app.Test(makeReq("POST", "/login", map[string]any{"email": "alice@example.com", "password": "nope"}))
+	require.NoError(t, err)
+	require.Equal(t, 401, resp.StatusCode)
+	resp, err = app.Test(makeReq("POST", "/login", map[string]any{"email": "alice@example.com", "password": "secret"}))
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+	require.NotContains(t, store, "account|email:alice@example.com")
+	require.Equal(t, 4, store["ip|0.0.0.0"].Failures)
+}
+
+func TestAuthLogin_IPLockout(t *testing.T) {
+	t.Setenv("LOGIN_IP_MAX_ATTEMPTS", "2")
+	t.Setenv("LOGIN_DELAY_BASE", "0")
+	patchThrottleStore(t)
+	patchLoginResult(t)
+
+	app := fiber.New()
+	app.Post("/login", service.AuthLogin)
+
+	for _, email := range []string{"a@example.com", "b@example.com"} {
+		resp, err := app.Test(makeReq("POST", "/login", map[string]any{"email": email, "password": "nope"}))
+		require.NoError(t, err)
+		require.Equal(t, 401, resp.StatusCode)
+	}
+	resp, err := app.Test(makeReq("POST", "/login", map[string]any{"email": "c@example.com", "password": "secret"}))
+	require.NoError(t, err)
+	require.Equal(t, 429, resp.StatusCode)
+}
+
+func TestAuthLogin_ProgressiveDelay(t *testing.T) {
+	t.Setenv("LOGIN_DELAY_BASE", "1m")
+	store := patchThrottleStore(t)
+	patchLoginResult(t)
+
+	app := fiber.New()
+	app.Post("/login", service.AuthLogin)
+	body := map[string]any{"nim": "2101", "password": "nope"}
+
+	resp, err := app.Test(makeReq("POST", "/login", body))
+	require.NoError(t, err)
+	require.Equal(t, 401, resp.StatusCode)
+
+	resp, err = app.Test(makeReq("POST", "/login", body))
+	require.NoError(t, err)
+	require.Equal(t, 429, resp.StatusCode)
+	retry, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+	require.InDelta(t, 60, retry, 2)
+
+	// jeda berlipat setelah kegagalan berikutnya
+	th := store["account|nim:2101"]
+	th.Failures = 3
+	th.LastFailureAt = time.Now()
+	resp, err = app.Test(makeReq("POST", "/login", body))
+	require.NoError(t, err)
+	require.Equal(t, 429, resp.StatusCode)
+	retry, _ = strconv.Atoi(resp.Header.Get("Retry-After"))
+	require.InDelta(t, 240, retry, 2)
+
+	th.LastFailureAt = time.Now().Add(-5 * time.Minute)
+	resp, err = app.Test(makeReq("POST", "/login", body))
+	require.NoError(t, err)
+	require.Equal(t, 401, resp.StatusCode)
+}
+
+func TestAuthLogin_UniformError(t *testing.T) {
+	t.Setenv("LOGIN_DELAY_BASE", "0")
+	patchThrottleStore(t)
+	db, mock := setupDB(t)
+	defer db.Close()
+
+	userQuery := regexp.QuoteMeta(`SELECT id, email, password_hash, role_id, is_active FROM users WHERE email = $1`)
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("correctpass"), bcrypt.MinCost)
+	mock.ExpectQuery(userQuery).WithArgs("ghost@example.com").WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery(userQuery).WithArgs("bob@example.com").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "password_hash", "role_id", "is_active"}).
+			AddRow("u-1", "bob@example.com", string(hashed), "r-1", true))
+
+	app := fiber.New()
+	app.Post("/login", service.AuthLogin)
+
+	var messages []string
+	for _, email := range []string{"ghost@example.com", "bob@example.com"} {
+		resp, err := app.Test(makeReq("POST", "/login", map[string]any{"email": email, "password": "wrong"}))
+		require.NoError(t, err)
+		require.Equal(t, 401, resp.StatusCode)
+		messages = append(messages, loginMessage(t, resp.Body))
+	}
+	require.Equal(t, []string{service.ErrInvalidCredentials.Error(), service.ErrInvalidCredentials.Error()}, messages)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAdminUnlockUser(t *testing.T) {
+	pU := bm.Patch(repository.GetUserByID, func(id string) (*models.User, error) {
+		if id != "u-1" {
+			return nil, errors.New("sql: no rows in result set")
+		}
+		return &models.User{ID: id}, nil
+	})
+	defer pU.Unpatch()
+	var cleared []string
+	pC := bm.Patch(repository.ClearAccountLockout, func(userID string) (int64, error) {
+		cleared = append(cleared, strings.Clone(userID))
+		return 2, nil
+	})
+	defer pC.Unpatch()
+
+	app := fiber.New()
+	app.Post("/users/:id/unlock", service.AdminUnlockUser)
+
+	resp, err := app.Test(makeReq("POST", "/users/u-1/unlock", nil))
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+	require.Equal(t, []string{"u-1"}, cleared)
+
+	resp, err = app.Test(makeReq("POST", "/users/u-x/unlock", nil))
+	require.NoError(t, err)
+	require.Equal(t, 404, resp.StatusCode)
+	require.Equal(t, []string{"u-1"}, cleared)
+}
+
+func TestRecordLoginFailure(t *testing.T) {
+	db, mock := setupDB(t)
+	defer db.Close()
+
+	until := time.Now().Add(15 * time.Minute)
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO login_throttles AS t`)).
+		WithArgs(models.LoginThrottleAccount, "email:bob@example.com", 5, float64(900)).
+		WillReturnRows(sqlmock.NewRows([]string{"failures", "last_failure_at", "locked_until"}).AddRow(5, time.Now(), until))
+
+	th, err := repository.RecordLoginFailure(models.LoginThrottleAccount, repository.LoginAccountKey(false, " Bob@Example.com "), 5, 15*time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, 5, th.Failures)
+	require.NotNil(t, th.LockedUntil)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,12 @@
+package service_test
+
+import (
+	"os"
+	"testing"
+)
+
+// Test memakai secret JWT development dan default lokal lain (mailer log).
+func TestMain(m *testing.M) {
+	os.Setenv("APP_ENV", "development")
+	os.Exit(m.Run())
+}
@@ -0,0 +1,129 @@
+package service_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"UAS_GO/database"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := database.LoadMigrations()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatalf("expected embedded migrations")
+	}
+
+	for i, m := range migrations {
+		if m.Up == "" || m.Down == "" || m.Checksum == "" {
+			t.Fatalf("migration %d_%s incomplete", m.Version, m.Name)
+		}
+		if i > 0 && migrations[i-1].Version >= m.Version {
+			t.Fatalf("migrations not ordered: %d before %d", migrations[i-1].Version, m.Version)
+		}
+	}
+}
+
+func TestMigrateUp(t *testing.T) {
+	migrations, err := database.LoadMigrations()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expectLock := func(mock sqlmock.Sqlmock) {
+		mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_lock($1)`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS schema_migrations`)).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+
+	t.Run("AppliesPendingOnly", func(t *testing.T) {
+		db, mock := setupDB(t)
+		defer db.Close()
+
+		expectLock(mock)
+		// migrasi pertama sudah di-apply
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT version, checksum, applied_at FROM schema_migrations`)).
+			WillReturnRows(sqlmock.NewRows([]string{"version", "checksum", "applied_at"}).
+				AddRow(migrations[0].Version, migrations[0].Checksum, time.Now()))
+		for _, m := range migrations[1:] {
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta(m.Up)).WillReturnResult(sqlmock.NewResult(0, 0))
+			mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO schema_migrations`)).
+				WithArgs(m.Version, m.Name, m.Checksum).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+		}
+		mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_unlock($1)`)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		done, err := database.MigrateUp(db)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(done) != len(migrations)-1 {
+			t.Fatalf("expected %d applied migrations, got %d", len(migrations)-1, len(done))
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Fatalf("unmet expectations: %v", err)
+		}
+	})
+
+	t.Run("ChecksumMismatch", func(t *testing.T) {
+		db, mock := setupDB(t)
+		defer db.Close()
+
+		expectLock(mock)
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT version, checksum, applied_at FROM schema_migrations`)).
+			WillReturnRows(sqlmock.NewRows([]string{"version", "checksum", "applied_at"}).
+				AddRow(migrations[0].Version, "tampered", time.Now()))
+		mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_unlock($1)`)).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		if _, err := database.MigrateUp(db); err == nil {
+			t.Fatalf("expected checksum mismatch error")
+		}
+	})
+}
+
+// TestSeedRBACMigration: database dari `migrate up` saja harus punya role sistem dan permission route.
+func TestSeedRBACMigration(t *testing.T) {
+	migrations, err := database.LoadMigrations()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var seed *database.Migration
+	for i := range migrations {
+		if migrations[i].Name == "seed_rbac" {
+			seed = &migrations[i]
+		}
+	}
+	if seed == nil {
+		t.Fatalf("seed_rbac migration not found")
+	}
+
+	for _, role := range []string{"admin", "mahasiswa", "dosen_wali"} {
+		if !strings.Contains(seed.Up, "('"+role+"',") {
+			t.Errorf("role %s not seeded", role)
+		}
+	}
+	for role, perms := range seededRolePermissions {
+		_, grants, ok := strings.Cut(seed.Up, "WHERE r.name = '"+role+"'")
+		if !ok {
+			t.Errorf("no grants for %s", role)
+			continue
+		}
+		grants, _, _ = strings.Cut(grants, "ON CONFLICT")
+		for _, p := range perms {
+			if !strings.Contains(seed.Up, "('"+p+"',") {
+				t.Errorf("permission %s not seeded", p)
+			}
+			if !strings.Contains(grants, "'"+p+"'") {
+				t.Errorf("permission %s not granted to %s", p, role)
+			}
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package service_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"UAS_GO/app/models"
+	"UAS_GO/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// schema validator harus mencakup semua field bson di models.Achievement,
+// supaya dokumen yang dibuat aplikasi tidak pernah ditolak MongoDB.
+func TestAchievementJSONSchema_MatchesModel(t *testing.T) {
+	schema := database.AchievementJSONSchema()
+	props := schema["properties"].(bson.M)
+
+	modelType := reflect.TypeOf(models.Achievement{})
+	for i := 0; i < modelType.NumField(); i++ {
+		tag := strings.Split(modelType.Field(i).Tag.Get("bson"), ",")[0]
+		if _, ok := props[tag]; !ok {
+			t.Errorf("field %q missing from $jsonSchema", tag)
+		}
+	}
+
+	for _, req := range schema["required"].([]string) {
+		if _, ok := props[req]; !ok {
+			t.Errorf("required field %q has no property definition", req)
+		}
+	}
+
+	attProps := props["attachments"].(bson.M)["items"].(bson.M)["properties"].(bson.M)
+	attType := reflect.TypeOf(models.Attachment{})
+	for i := 0; i < attType.NumField(); i++ {
+		tag := strings.Split(attType.Field(i).Tag.Get("bson"), ",")[0]
+		if _, ok := attProps[tag]; !ok {
+			t.Errorf("attachment field %q missing from $jsonSchema", tag)
+		}
+	}
+}
+
+func TestAchievementIndexes_CoverQueriedFields(t *testing.T) {
+	covered := map[string]bool{}
+	for _, idx := range database.AchievementIndexes() {
+		keys := idx.Keys.(bson.D)
+		covered[keys[0].Key] = true
+	}
+
+	for _, field := range []string{"studentId", "achievementType", "createdAt", "details.competitionLevel"} {
+		if !covered[field] {
+			t.Errorf("no index leading with %q", field)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package service_test
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/app/service"
+	"UAS_GO/helper"
+	"regexp"
+	"testing"
+	"time"
+
+	bm "bou.ke/monkey"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestValidatePassword(t *testing.T) {
+	ids := []string{"alice", "Alice.W@example.com", "2101"}
+
+	cases := []struct {
+		name, password string
+		problems       int
+	}{
+		{"Valid", "Tr1cky-Horse", 0},
+		{"TooShort", "Ab1!", 1},
+		{"TooFewClasses", "longlowercaseonly", 1},
+		{"Common", "Password123", 1},
+		{"CommonCaseInsensitive", "QWERTY123", 2}, // hanya 2 jenis karakter
+		{"SameAsUsername", "ALICE", 3},
+		{"SameAsEmailLocalPart", "alice.w", 3},
+		{"SameAsNIM", "2101", 3},
+		{"TooLong", "Aa1!" + string(make([]byte, 72)), 1},
+	}
+	for _, tc := range cases {
+		err := helper.ValidatePassword(tc.password, ids...)
+		if tc.problems == 0 {
+			require.NoError(t, err, tc.name)
+			continue
+		}
+		var perr *helper.PasswordPolicyError
+		require.ErrorAs(t, err, &perr, tc.name)
+		require.Len(t, perr.Problems, tc.problems, tc.name+": "+err.Error())
+	}
+
+	t.Run("Configurable", func(t *testing.T) {
+		t.Setenv("PASSWORD_MIN_LENGTH", "4")
+		t.Setenv("PASSWORD_MIN_CLASSES", "1")
+		require.NoError(t, helper.ValidatePassword("kopi"))
+		require.Error(t, helper.ValidatePassword("kopi", "kopi@example.com"))
+	})
+}
+
+func TestHashPassword_Cost(t *testing.T) {
+	t.Setenv("PASSWORD_BCRYPT_COST", "5")
+	hash, err := helper.HashPassword("Tr1cky-Horse")
+	require.NoError(t, err)
+	cost, err := bcrypt.Cost([]byte(hash))
+	require.NoError(t, err)
+	require.Equal(t, 5, cost)
+	require.False(t, helper.PasswordNeedsRehash(hash))
+
+	t.Setenv("PASSWORD_BCRYPT_COST", "6")
+	require.True(t, helper.PasswordNeedsRehash(hash))
+
+	// nilai di luar rentang bcrypt memakai default
+	t.Setenv("PASSWORD_BCRYPT_COST", "99")
+	require.Equal(t, bcrypt.DefaultCost, helper.BcryptCost())
+}
+
+func TestLogin_RehashesWeakCost(t *testing.T) {
+	pC := bm.Patch(repository.CreateRefreshToken, func(rt *models.RefreshToken) error { return nil })
+	defer pC.Unpatch()
+	pR := bm.Patch(repository.GetUserRoleIDs, func(userID string) ([]string, error) { return []string{"r-1"}, nil })
+	defer pR.Unpatch()
+	pP := bm.Patch(repository.GetPermissionsByRoleID, func(roleID string) ([]string, error) { return []string{}, nil })
+	defer pP.Unpatch()
+	patchUserOverrides(t, map[string][]models.UserPermission{})
+
+	oldHash, _ := bcrypt.GenerateFromPassword([]byte("Tr1cky-Horse"), bcrypt.MinCost)
+	userQuery := regexp.QuoteMeta(`SELECT id, email, password_hash, role_id, is_active FROM users WHERE email = $1`)
+	userRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "email", "password_hash", "role_id", "is_active"}).
+			AddRow("u-1", "alice@example.com", string(oldHash), "r-1", true)
+	}
+
+	t.Run("BelowConfiguredCost", func(t *testing.T) {
+		t.Setenv("PASSWORD_BCRYPT_COST", "5")
+		db, mock := setupDB(t)
+		defer db.Close()
+
+		mock.ExpectQuery(userQuery).WithArgs("alice@example.com").WillReturnRows(userRow())
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET password_hash = $1`)).
+			WithArgs(sqlmock.AnyArg(), "u-1", string(oldHash)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		_, err := service.NewAuthService().Login("alice@example.com", "Tr1cky-Horse", false, "test")
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("AlreadyAtCost", func(t *testing.T) {
+		t.Setenv("PASSWORD_BCRYPT_COST", "4")
+		db, mock := setupDB(t)
+		defer db.Close()
+
+		mock.ExpectQuery(userQuery).WithArgs("alice@example.com").WillReturnRows(userRow())
+
+		_, err := service.NewAuthService().Login("alice@example.com", "Tr1cky-Horse", false, "test")
+		require.NoError(t, err)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("WrongPasswordNotRehashed", func(t *testing.T) {
+		t.Setenv("PASSWORD_BCRYPT_COST", "5")
+		db, mock := setupDB(t)
+		defer db.Close()
+
+		mock.ExpectQuery(userQuery).WithArgs("alice@example.com").WillReturnRows(userRow())
+
+		_, err := service.NewAuthService().Login("alice@example.com", "wrong", false, "test")
+		require.ErrorIs(t, err, service.ErrInvalidCredentials)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAdminUpdateUser_PasswordPolicy(t *testing.T) {
+	patchPasswordIdentifiers(t)
+	pE := bm.Patch(repository.IsEmailExistsForOtherUser, func(id, email string) (bool, error) { return false, nil })
+	defer pE.Unpatch()
+	var saved []string
+	pU := bm.Patch(repository.UpdateUser, func(id string, user *models.User) (*models.User, error) {
+		saved = append(saved, user.PasswordHash)
+		return user, nil
+	})
+	defer pU.Unpatch()
+
+	app := fiber.New()
+	app.Put("/users/:id", service.AdminUpdateUser)
+	body := func(password string) map[string]any {
+		return map[string]any{"username": "alice", "email": "alice@example.com", "role_id": "r-1", "is_active": true, "password": password}
+	}
+
+	resp, err := app.Test(makeReq("PUT", "/users/u-1", body("2101")))
+	require.NoError(t, err)
+	require.Equal(t, 400, resp.StatusCode)
+	require.Empty(t, saved)
+
+	resp, err = app.Test(makeReq("PUT", "/users/u-1", body("")))
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+
+	resp, err = app.Test(makeReq("PUT", "/users/u-1", body("Tr1cky-Horse")))
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+
+	require.Len(t, saved, 2)
+	require.Empty(t, saved[0]) // tanpa password: hash lama tidak disentuh
+	require.NoError(t, bcrypt.CompareHashAndPassword([]byte(saved[1]), []byte("Tr1cky-Horse")))
+}
+
+func TestUpdateUser_PasswordRevokesSessions(t *testing.T) {
+	db, mock := setupDB(t)
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`FOR UPDATE OF u`)).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE users`)).
+		WithArgs("alice@example.com", "alice", "Alice", "r-1", true, "u-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "username", "full_name", "role_id", "is_active", "created_at", "updated_at"}).
+			AddRow("u-1", "alice@example.com", "alice", "Alice", "r-1", true, time.Now(), time.Now()))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET password_hash = $1, password_changed_at = NOW()`)).
+		WithArgs("new-hash", "u-1").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE refresh_tokens SET revoked_at = NOW()`)).WithArgs("u-1", "").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE password_reset_tokens SET used_at = NOW()`)).WithArgs("u-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	_, err := repository.UpdateUser("u-1", &models.User{
+		Email: "alice@example.com", Username: "alice", FullName: "Alice", RoleID: "r-1", IsActive: true, PasswordHash: "new-hash",
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
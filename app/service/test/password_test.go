@@ -0,0 +1,362 @@
+package service_test
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/app/service"
+	"UAS_GO/helper"
+	"UAS_GO/middleware"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	bm "bou.ke/monkey"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type captureMailer struct{ sent []helper.Mail }
+
+func (m *captureMailer) Send(mail helper.Mail) error {
+	m.sent = append(m.sent, mail)
+	return nil
+}
+
+func patchCaptureMailer(t *testing.T) *captureMailer {
+	m := &captureMailer{}
+	helper.SetMailer(m)
+	t.Cleanup(func() { helper.SetMailer(nil) })
+	return m
+}
+
+func TestAuthForgotPassword(t *testing.T) {
+	t.Setenv("PASSWORD_RESET_URL", "https://app.example.com/reset")
+	mailer := patchCaptureMailer(t)
+	patchThrottleStore(t)
+
+	pF := bm.Patch(repository.FindUserForPasswordReset, func(identifier string, byNIM bool) (*models.User, error) {
+		switch {
+		case byNIM && identifier == "2101":
+			return &models.User{ID: "u-1", Email: "alice@example.com", IsActive: true}, nil
+		case identifier == "inactive@example.com":
+			return &models.User{ID: "u-2", Email: "inactive@example.com"}, nil
+		}
+		return nil, nil
+	})
+	defer pF.Unpatch()
+	var stored []models.PasswordResetToken
+	pC := bm.Patch(repository.CreatePasswordResetToken, func(rt *models.PasswordResetToken) error {
+		stored = append(stored, *rt)
+		return nil
+	})
+	defer pC.Unpatch()
+
+	app := fiber.New()
+	app.Post("/auth/forgot", service.AuthForgotPassword)
+
+	var messages []string
+	for _, body := range []map[string]any{
+		{"nim": "2101"},
+		{"email": "ghost@example.com"},
+		{"email": "inactive@example.com"},
+	} {
+		resp, err := app.Test(makeReq("POST", "/auth/forgot", body))
+		require.NoError(t, err)
+		require.Equal(t, 200, resp.StatusCode)
+		messages = append(messages, loginMessage(t, resp.Body))
+	}
+	require.Equal(t, messages[0], messages[1])
+	require.Equal(t, messages[0], messages[2])
+
+	require.Len(t, stored, 1)
+	require.Equal(t, "u-1", stored[0].UserID)
+	require.Len(t, mailer.sent, 1)
+	require.Equal(t, "alice@example.com", mailer.sent[0].To)
+
+	// email berisi token mentah; database hanya menyimpan hash-nya
+	token := regexp.MustCompile(`reset\?token=([A-Za-z0-9_-]+)`).FindStringSubmatch(mailer.sent[0].Body)
+	require.Len(t, token, 2)
+	require.Equal(t, helper.HashToken(token[1]), stored[0].TokenHash)
+	require.NotContains(t, stored[0].TokenHash, token[1])
+
+	resp, err := app.Test(makeReq("POST", "/auth/forgot", map[string]any{}))
+	require.NoError(t, err)
+	require.Equal(t, 400, resp.StatusCode)
+}
+
+func TestAuthForgotPassword_MailerRequiredOutsideDevelopment(t *testing.T) {
+	t.Setenv("APP_ENV", "production")
+	t.Setenv("MAIL_DRIVER", "")
+	helper.SetMailer(nil)
+	t.Cleanup(func() { helper.SetMailer(nil) })
+	patchThrottleStore(t)
+
+	lookups := 0
+	pF := bm.Patch(repository.FindUserForPasswordReset, func(identifier string, byNIM bool) (*models.User, error) {
+		lookups++
+		return &models.User{ID: "u-1", Email: "alice@example.com", IsActive: true}, nil
+	})
+	defer pF.Unpatch()
+	pC := bm.Patch(repository.CreatePasswordResetToken, func(rt *models.PasswordResetToken) error { return nil })
+	defer pC.Unpatch()
+
+	app := fiber.New()
+	app.Post("/auth/forgot", service.AuthForgotPassword)
+
+	// tanpa MAIL_DRIVER token tidak dibuat (dan tidak tercetak ke log)
+	resp, err := app.Test(makeReq("POST", "/auth/forgot", map[string]any{"email": "alice@example.com"}))
+	require.NoError(t, err)
+	require.Equal(t, 503, resp.StatusCode)
+	require.Zero(t, lookups)
+
+	t.Setenv("MAIL_DRIVER", "smtp")
+	require.Error(t, helper.CheckMailer())
+
+	// driver file dipilih eksplisit
+	dir := t.TempDir()
+	t.Setenv("MAIL_DRIVER", "file")
+	t.Setenv("MAIL_DIR", dir)
+	resp, err = app.Test(makeReq("POST", "/auth/forgot", map[string]any{"email": "alice@example.com"}))
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+}
+
+func TestAuthForgotPassword_Throttle(t *testing.T) {
+	t.Setenv("PASSWORD_RESET_MAX_REQUESTS", "2")
+	t.Setenv("PASSWORD_RESET_IP_MAX_REQUESTS", "4")
+	t.Setenv("PASSWORD_RESET_WINDOW", "1h")
+	mailer := patchCaptureMailer(t)
+	store := patchThrottleStore(t)
+
+	pF := bm.Patch(repository.FindUserForPasswordReset, func(identifier string, byNIM bool) (*models.User, error) {
+		return &models.User{ID: "u-1", Email: "alice@example.com", IsActive: true}, nil
+	})
+	defer pF.Unpatch()
+	pC := bm.Patch(repository.CreatePasswordResetToken, func(rt *models.PasswordResetToken) error { return nil })
+	defer pC.Unpatch()
+
+	app := fiber.New()
+	app.Post("/auth/forgot", service.AuthForgotPassword)
+	forgot := func(body map[string]any) *http.Response {
+		resp, err := app.Test(makeReq("POST", "/auth/forgot", body))
+		require.NoError(t, err)
+		return resp
+	}
+
+	// per identifier: email yang sama (beda huruf besar) dihitung bersama
+	require.Equal(t, 200, forgot(map[string]any{"email": "alice@example.com"}).StatusCode)
+	require.Equal(t, 200, forgot(map[string]any{"email": "Alice@Example.com"}).StatusCode)
+	resp := forgot(map[string]any{"email": "alice@example.com"})
+	require.Equal(t, 429, resp.StatusCode)
+	retry, _ := strconv.Atoi(resp.Header.Get("Retry-After"))
+	require.InDelta(t, 3600, retry, 2)
+	require.Len(t, mailer.sent, 2)
+	// hitungan login tidak tersentuh
+	require.NotContains(t, store, "account|email:alice@example.com")
+
+	// per IP: identifier lain tetap dibatasi setelah batas IP tercapai
+	require.Equal(t, 200, forgot(map[string]any{"nim": "2101"}).StatusCode)
+	require.Equal(t, 200, forgot(map[string]any{"email": "ghost@example.com"}).StatusCode)
+	require.Equal(t, 429, forgot(map[string]any{"email": "other@example.com"}).StatusCode)
+	require.Equal(t, 4, store["reset_ip|0.0.0.0"].Failures)
+}
+
+// patchPasswordIdentifiers: u-1 = username "alice", email alice@example.com, NIM 2101
+func patchPasswordIdentifiers(t *testing.T) {
+	p := bm.Patch(repository.GetUserPasswordIdentifiers, func(userID string) ([]string, error) {
+		return []string{"alice", "alice@example.com", "2101"}, nil
+	})
+	t.Cleanup(p.Unpatch)
+}
+
+func TestAuthResetPassword(t *testing.T) {
+	patchPasswordIdentifiers(t)
+	pT := bm.Patch(repository.GetResetTokenUserID, func(tokenHash string) (string, error) {
+		if tokenHash != helper.HashToken("valid-token") {
+			return "", repository.ErrResetTokenInvalid
+		}
+		return "u-1", nil
+	})
+	defer pT.Unpatch()
+	var gotHash, gotPassword string
+	pR := bm.Patch(repository.ResetPasswordWithToken, func(tokenHash, passwordHash string) (string, error) {
+		if tokenHash != helper.HashToken("valid-token") {
+			return "", repository.ErrResetTokenInvalid
+		}
+		gotHash, gotPassword = tokenHash, passwordHash
+		return "u-1", nil
+	})
+	defer pR.Unpatch()
+	var unlocked []string
+	pL := bm.Patch(repository.ClearAccountLockout, func(userID string) (int64, error) {
+		unlocked = append(unlocked, userID)
+		return 1, nil
+	})
+	defer pL.Unpatch()
+
+	app := fiber.New()
+	app.Post("/auth/reset", service.AuthResetPassword)
+
+	cases := []struct {
+		name string
+		body map[string]any
+		want int
+	}{
+		{"InvalidToken", map[string]any{"token": "used-token", "new_password": "N3w-secret"}, 400},
+		{"ShortPassword", map[string]any{"token": "valid-token", "new_password": "123"}, 400},
+		{"SameAsNIM", map[string]any{"token": "valid-token", "new_password": "2101"}, 400},
+		{"Success", map[string]any{"token": "valid-token", "new_password": "N3w-secret"}, 200},
+	}
+	for _, tc := range cases {
+		resp, err := app.Test(makeReq("POST", "/auth/reset", tc.body))
+		require.NoError(t, err)
+		require.Equal(t, tc.want, resp.StatusCode, tc.name)
+	}
+	require.Equal(t, helper.HashToken("valid-token"), gotHash)
+	require.NoError(t, bcrypt.CompareHashAndPassword([]byte(gotPassword), []byte("N3w-secret")))
+	require.Equal(t, []string{"u-1"}, unlocked)
+}
+
+func TestResetPasswordWithToken(t *testing.T) {
+	consume := regexp.QuoteMeta(`UPDATE password_reset_tokens SET used_at = NOW()
+		WHERE token_hash = $1`)
+
+	t.Run("UsedOrExpired", func(t *testing.T) {
+		db, mock := setupDB(t)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(consume).WithArgs("h-1").WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+		mock.ExpectRollback()
+
+		_, err := repository.ResetPasswordWithToken("h-1", "new-hash")
+		require.ErrorIs(t, err, repository.ErrResetTokenInvalid)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("RevokesAllSessions", func(t *testing.T) {
+		db, mock := setupDB(t)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(consume).WithArgs("h-1").WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow("u-1"))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE users SET password_hash = $1, password_changed_at = NOW()`)).WithArgs("new-hash", "u-1").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE refresh_tokens SET revoked_at = NOW()`)).WithArgs("u-1", "").
+			WillReturnResult(sqlmock.NewResult(0, 3))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE password_reset_tokens SET used_at = NOW()
+		WHERE user_id::text = $1`)).WithArgs("u-1").
+			WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		userID, err := repository.ResetPasswordWithToken("h-1", "new-hash")
+		require.NoError(t, err)
+		require.Equal(t, "u-1", userID)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+// patchPasswordChangedAt: waktu ganti password per user; user yang tidak ada di map belum pernah ganti.
+func patchPasswordChangedAt(t *testing.T, changed map[string]time.Time) {
+	p := bm.Patch(repository.GetPasswordChangedAt, func(userID string) (time.Time, error) {
+		return changed[userID], nil
+	})
+	t.Cleanup(p.Unpatch)
+}
+
+func TestAuthRequired_RejectsTokensIssuedBeforePasswordChange(t *testing.T) {
+	issued := time.Now().Add(-time.Minute).Truncate(time.Second)
+	pV := bm.Patch(helper.ValidateToken, func(token string) (*models.JWTClaims, error) {
+		claims := &models.JWTClaims{UserID: token, Role: "r-1"}
+		claims.ID = "jti-" + token
+		claims.IssuedAt = jwt.NewNumericDate(issued)
+		return claims, nil
+	})
+	defer pV.Unpatch()
+	pR := bm.Patch(repository.IsTokenRevoked, func(jti string) (bool, error) { return false, nil })
+	defer pR.Unpatch()
+	patchRoleLoader(t, map[string][]string{"r-1": {}})
+	patchPasswordChangedAt(t, map[string]time.Time{
+		"u-reset":       issued.Add(30 * time.Second),
+		"u-same-second": issued.Add(300 * time.Millisecond), // iat dibulatkan ke detik
+		"u-old-change":  issued.Add(-time.Hour),
+	})
+
+	app := fiber.New()
+	app.Get("/profile", middleware.AuthRequired(), func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	for user, want := range map[string]int{"u-reset": 401, "u-same-second": 200, "u-old-change": 200, "u-never": 200} {
+		req := makeReq("GET", "/profile", nil)
+		req.Header.Set("Authorization", "Bearer "+user)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, want, resp.StatusCode, user)
+	}
+}
+
+func TestAuthChangePassword(t *testing.T) {
+	patchPasswordIdentifiers(t)
+	hashed, _ := bcrypt.GenerateFromPassword([]byte("oldsecret"), bcrypt.MinCost)
+	pU := bm.Patch(repository.GetUserByID, func(id string) (*models.User, error) {
+		return &models.User{ID: id, PasswordHash: string(hashed)}, nil
+	})
+	defer pU.Unpatch()
+	var keep []string
+	pC := bm.Patch(repository.ChangeUserPassword, func(userID, passwordHash, keepFamilyID string) error {
+		keep = append(keep, strings.Clone(keepFamilyID))
+		return nil
+	})
+	defer pC.Unpatch()
+
+	app := fiber.New()
+	app.Post("/auth/password", func(c *fiber.Ctx) error {
+		c.Locals("user_id", "u-1")
+		c.Locals("session_id", "fam-1")
+		return c.Next()
+	}, service.AuthChangePassword)
+
+	cases := []struct {
+		name string
+		body map[string]any
+		want int
+	}{
+		{"WrongCurrent", map[string]any{"current_password": "nope", "new_password": "N3w-secret"}, 400},
+		{"SamePassword", map[string]any{"current_password": "oldsecret", "new_password": "oldsecret"}, 400},
+		{"Missing", map[string]any{"new_password": "N3w-secret"}, 400},
+		{"Common", map[string]any{"current_password": "oldsecret", "new_password": "Password123"}, 400},
+		{"Success", map[string]any{"current_password": "oldsecret", "new_password": "N3w-secret"}, 200},
+	}
+	for _, tc := range cases {
+		resp, err := app.Test(makeReq("POST", "/auth/password", tc.body))
+		require.NoError(t, err)
+		require.Equal(t, tc.want, resp.StatusCode, tc.name)
+	}
+	// sesi yang sedang dipakai tidak ikut dicabut
+	require.Equal(t, []string{"fam-1"}, keep)
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, helper.FileMailer{Dir: dir}.Send(helper.Mail{
+		From: "no-reply@localhost", To: "alice@example.com", Subject: "Password reset", Body: "token-123",
+	}))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	content, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.Contains(t, string(content), "To: alice@example.com\r\n")
+	require.Contains(t, string(content), "token-123")
+}
@@ -0,0 +1,235 @@
+package service_test
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/helper"
+	"UAS_GO/middleware"
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	bm "bou.ke/monkey"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// patchRoleLoader menghitung berapa kali cache memuat role dari DB
+func patchRoleLoader(t *testing.T, perms map[string][]string) *int {
+	loads := new(int)
+	pN := bm.Patch(repository.GetRoleNameByID, func(roleID string) (string, error) {
+		if _, ok := perms[roleID]; !ok {
+			return "", errors.New("role not found")
+		}
+		return roleID, nil
+	})
+	pP := bm.Patch(repository.GetPermissionsByRoleID, func(roleID string) ([]string, error) {
+		*loads++
+		return append([]string{}, perms[roleID]...), nil
+	})
+	repository.InvalidateRoleCache()
+	t.Cleanup(func() {
+		pN.Unpatch()
+		pP.Unpatch()
+		repository.InvalidateRoleCache()
+	})
+	return loads
+}
+
+// patchUserOverrides menyajikan override per user dari map; mengembalikan jumlah load dari DB
+func patchUserOverrides(t *testing.T, overrides map[string][]models.UserPermission) *int {
+	loads := new(int)
+	p := bm.Patch(repository.GetUserPermissionOverrides, func(userID string) ([]models.UserPermission, error) {
+		*loads++
+		return append([]models.UserPermission{}, overrides[userID]...), nil
+	})
+	repository.InvalidateUserPermissionCache()
+	t.Cleanup(func() {
+		p.Unpatch()
+		repository.InvalidateUserPermissionCache()
+	})
+	return loads
+}
+
+func TestPermissionCache(t *testing.T) {
+	t.Run("HitsCacheWithinTTL", func(t *testing.T) {
+		loads := patchRoleLoader(t, map[string][]string{"r-1": {"achievement:read"}})
+
+		for i := 0; i < 3; i++ {
+			has, err := repository.CachedRoleHasPermission("r-1", "achievement:read")
+			require.NoError(t, err)
+			require.True(t, has)
+		}
+		name, err := repository.CachedRoleName("r-1")
+		require.NoError(t, err)
+		require.Equal(t, "r-1", name)
+		require.Equal(t, 1, *loads)
+
+		has, err := repository.CachedRoleHasPermission("r-1", "achievement:verify")
+		require.NoError(t, err)
+		require.False(t, has)
+		require.Equal(t, 1, *loads)
+	})
+
+	t.Run("Invalidate", func(t *testing.T) {
+		perms := map[string][]string{"r-1": {"achievement:read"}, "r-2": {}}
+		loads := patchRoleLoader(t, perms)
+
+		_, _ = repository.CachedRoleHasPermission("r-1", "achievement:read")
+		_, _ = repository.CachedRoleHasPermission("r-2", "achievement:read")
+		require.Equal(t, 2, *loads)
+
+		perms["r-1"] = []string{"achievement:read", "achievement:verify"}
+		repository.InvalidateRoleCache("r-1")
+
+		has, err := repository.CachedRoleHasPermission("r-1", "achievement:verify")
+		require.NoError(t, err)
+		require.True(t, has)
+		_, _ = repository.CachedRoleHasPermission("r-2", "achievement:read")
+		require.Equal(t, 3, *loads)
+	})
+
+	t.Run("Expires", func(t *testing.T) {
+		t.Setenv("PERMISSION_CACHE_TTL", "1ns")
+		loads := patchRoleLoader(t, map[string][]string{"r-1": {}})
+
+		_, _ = repository.CachedRoleName("r-1")
+		_, _ = repository.CachedRoleName("r-1")
+		require.Equal(t, 2, *loads)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Setenv("PERMISSION_CACHE_TTL", "0")
+		loads := patchRoleLoader(t, map[string][]string{"r-1": {}})
+
+		_, _ = repository.CachedRoleName("r-1")
+		_, _ = repository.CachedRoleName("r-1")
+		require.Equal(t, 2, *loads)
+	})
+
+	// invalidasi saat load masih berjalan: hasil load (yang mungkin basi) tidak boleh masuk cache
+	t.Run("InvalidateDuringLoad", func(t *testing.T) {
+		perms := map[string][]string{"r-1": {"achievement:read"}}
+		loads := patchRoleLoader(t, perms)
+		pP := bm.Patch(repository.GetPermissionsByRoleID, func(roleID string) ([]string, error) {
+			*loads++
+			stale := append([]string{}, perms[roleID]...)
+			if *loads == 1 {
+				perms[roleID] = []string{"achievement:read", "achievement:verify"}
+				repository.InvalidateRoleCache(roleID)
+			}
+			return stale, nil
+		})
+		defer pP.Unpatch()
+
+		has, err := repository.CachedRoleHasPermission("r-1", "achievement:verify")
+		require.NoError(t, err)
+		require.False(t, has)
+		has, err = repository.CachedRoleHasPermission("r-1", "achievement:verify")
+		require.NoError(t, err)
+		require.True(t, has)
+		require.Equal(t, 2, *loads)
+	})
+
+	t.Run("InvalidateAllDuringLoad", func(t *testing.T) {
+		loads := patchRoleLoader(t, map[string][]string{"r-1": {}})
+		pP := bm.Patch(repository.GetPermissionsByRoleID, func(roleID string) ([]string, error) {
+			*loads++
+			if *loads == 1 {
+				repository.InvalidateRoleCache()
+				return []string{"achievement:verify"}, nil
+			}
+			return []string{}, nil
+		})
+		defer pP.Unpatch()
+
+		_, _ = repository.CachedRoleHasPermission("r-1", "achievement:verify")
+		has, err := repository.CachedRoleHasPermission("r-1", "achievement:verify")
+		require.NoError(t, err)
+		require.False(t, has)
+		require.Equal(t, 2, *loads)
+	})
+
+	t.Run("UserOverridesInvalidateDuringLoad", func(t *testing.T) {
+		patchUserOverrides(t, nil)
+		loads := 0
+		p := bm.Patch(repository.GetUserPermissionOverrides, func(userID string) ([]models.UserPermission, error) {
+			loads++
+			if loads == 1 {
+				// override deny ditambahkan setelah data lama terbaca
+				repository.InvalidateUserPermissionCache(userID)
+				return []models.UserPermission{}, nil
+			}
+			return []models.UserPermission{{Permission: "report:statistics", Effect: models.PermissionEffectDeny}}, nil
+		})
+		defer p.Unpatch()
+
+		effect, err := repository.CachedUserPermissionEffect("u-1", "report:statistics")
+		require.NoError(t, err)
+		require.Empty(t, effect)
+		effect, err = repository.CachedUserPermissionEffect("u-1", "report:statistics")
+		require.NoError(t, err)
+		require.Equal(t, models.PermissionEffectDeny, effect)
+		require.Equal(t, 2, loads)
+	})
+
+	t.Run("UnknownRoleNotCached", func(t *testing.T) {
+		loads := patchRoleLoader(t, map[string][]string{})
+
+		_, err := repository.CachedRoleName("r-x")
+		require.Error(t, err)
+		_, err = repository.CachedRoleName("r-x")
+		require.Error(t, err)
+		require.Equal(t, 0, *loads)
+	})
+}
+
+func TestAuthorize_PermissionsFromClaims(t *testing.T) {
+	pV := bm.Patch(helper.ValidateToken, func(token string) (*models.JWTClaims, error) {
+		claims := &models.JWTClaims{UserID: "u-1", Role: "r-1"}
+		claims.ID = "jti-1"
+		if token == "with-perms" {
+			claims.Permissions = []string{"report:statistics"}
+		}
+		return claims, nil
+	})
+	defer pV.Unpatch()
+	pR := bm.Patch(repository.IsTokenRevoked, func(jti string) (bool, error) { return false, nil })
+	defer pR.Unpatch()
+	patchPasswordChangedAt(t, nil)
+	loads := patchRoleLoader(t, map[string][]string{"r-1": {}})
+	patchUserOverrides(t, map[string][]models.UserPermission{})
+
+	app := fiber.New()
+	app.Get("/stats", middleware.AuthRequired(), middleware.Authorize(middleware.Policy{Permission: "report:statistics"}),
+		func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+
+	for token, want := range map[string]int{"with-perms": 200, "without-perms": 403} {
+		req := httptest.NewRequest("GET", "/stats", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, want, resp.StatusCode, token)
+	}
+	// nama role dimuat sekali, lalu dipakai ulang dari cache
+	require.Equal(t, 1, *loads)
+}
+
+func TestAccessToken_EmbedsPermissions(t *testing.T) {
+	user := models.User{ID: "u-1", Email: "alice@example.com", RoleID: "r-1"}
+
+	token, _, err := helper.GenerateAccessTokenWithPermissions(user, "fam-1", []string{"achievement:read"})
+	require.NoError(t, err)
+	claims, err := helper.ValidateToken(token)
+	require.NoError(t, err)
+	require.Equal(t, []string{"achievement:read"}, claims.Permissions)
+
+	token, _, err = helper.GenerateAccessToken(user, "fam-1")
+	require.NoError(t, err)
+	claims, err = helper.ValidateToken(token)
+	require.NoError(t, err)
+	require.Nil(t, claims.Permissions)
+
+	t.Setenv("JWT_EMBED_PERMISSIONS", "true")
+	require.True(t, helper.EmbedPermissionsInToken())
+}
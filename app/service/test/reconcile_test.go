@@ -0,0 +1,190 @@
+package service_test
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/app/service"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	bm "bou.ke/monkey"
+	"github.com/stretchr/testify/require"
+)
+
+// patchReconcileStores mengganti kedua store; pembacaan ulang per prestasi memakai data yang sama.
+func patchReconcileStores(t *testing.T) (*[]models.AchievementMongoState, *[]models.AchievementReference) {
+	docs := []models.AchievementMongoState{
+		{MongoID: "m-ok", StudentID: "stu-1"},
+		{MongoID: "m-orphan", StudentID: "stu-1"},
+		{MongoID: "m-deleted", StudentID: "stu-1", Status: "deleted"},
+		{MongoID: "m-deleted-verified", StudentID: "stu-1", Status: "deleted"},
+		{MongoID: "m-ref-deleted", StudentID: "stu-1"},
+	}
+	refs := []models.AchievementReference{
+		{ID: "r-ok", MongoAchievementID: "m-ok", StudentID: "stu-1", Status: "submitted"},
+		{ID: "r-deleted", MongoAchievementID: "m-deleted", StudentID: "stu-1", Status: "draft"},
+		{ID: "r-deleted-verified", MongoAchievementID: "m-deleted-verified", StudentID: "stu-1", Status: "verified"},
+		{ID: "r-ref-deleted", MongoAchievementID: "m-ref-deleted", StudentID: "stu-1", Status: "deleted"},
+		{ID: "r-orphan", MongoAchievementID: "m-missing", StudentID: "stu-1", Status: "draft"},
+		{ID: "r-orphan-deleted", MongoAchievementID: "m-gone", StudentID: "stu-1", Status: "deleted"},
+	}
+
+	p1 := bm.Patch(repository.ListAchievementMongoStates,
+		func() ([]models.AchievementMongoState, error) { return docs, nil })
+	p2 := bm.Patch(repository.ListAchievementReferences,
+		func() ([]models.AchievementReference, error) { return refs, nil })
+	p3 := bm.Patch(repository.GetAchievementMongoState,
+		func(mongoID string) (*models.AchievementMongoState, error) {
+			for _, d := range docs {
+				if d.MongoID == mongoID {
+					return &d, nil
+				}
+			}
+			return nil, nil
+		})
+	p4 := bm.Patch(repository.GetAchievementReferenceByMongoID,
+		func(mongoID string) (*models.AchievementReference, error) {
+			for _, r := range refs {
+				if r.MongoAchievementID == mongoID {
+					return &r, nil
+				}
+			}
+			return nil, sql.ErrNoRows
+		})
+	t.Cleanup(func() { p1.Unpatch(); p2.Unpatch(); p3.Unpatch(); p4.Unpatch() })
+	return &docs, &refs
+}
+
+func issuesByMongoID(report *models.ReconcileReport) map[string]models.ReconcileIssue {
+	out := map[string]models.ReconcileIssue{}
+	for _, i := range report.Issues {
+		out[i.MongoID] = i
+	}
+	return out
+}
+
+func TestReconcileAchievements(t *testing.T) {
+	t.Run("DryRun_ReportsWithoutWriting", func(t *testing.T) {
+		patchReconcileStores(t)
+
+		writes := 0
+		p1 := bm.Patch(repository.InsertMissingReference,
+			func(studentID, mongoID, status string) error { writes++; return nil })
+		defer p1.Unpatch()
+		p2 := bm.Patch(repository.AchievementSoftDeleteReference,
+			func(referenceID string, expectedFrom []string) error { writes++; return nil })
+		defer p2.Unpatch()
+		p3 := bm.Patch(repository.AchievementSoftDeleteMongo,
+			func(mongoID string) error { writes++; return nil })
+		defer p3.Unpatch()
+
+		report, err := service.ReconcileAchievements(true)
+		require.NoError(t, err)
+		require.Zero(t, writes)
+		require.True(t, report.DryRun)
+		require.Equal(t, 5, report.ScannedMongo)
+		require.Equal(t, 6, report.ScannedReferences)
+
+		require.Equal(t, map[string]int{
+			models.ReconcileMongoOrphan:          1,
+			models.ReconcileReferenceOrphan:      1,
+			models.ReconcileDeletedOnlyInMongo:   2,
+			models.ReconcileDeletedOnlyReference: 1,
+		}, report.Counts)
+
+		issues := issuesByMongoID(report)
+		require.Equal(t, "insert_reference", issues["m-orphan"].Action)
+		require.Equal(t, "soft_delete_reference", issues["m-missing"].Action)
+		require.Equal(t, "soft_delete_reference", issues["m-deleted"].Action)
+		require.Equal(t, "manual_review", issues["m-deleted-verified"].Action)
+		require.Equal(t, "soft_delete_mongo", issues["m-ref-deleted"].Action)
+		require.NotContains(t, issues, "m-ok")
+		require.NotContains(t, issues, "m-gone")
+	})
+
+	t.Run("Apply_RepairsEachCategory", func(t *testing.T) {
+		patchReconcileStores(t)
+
+		var inserted, refDeleted, mongoDeleted []string
+		p1 := bm.Patch(repository.InsertMissingReference,
+			func(studentID, mongoID, status string) error {
+				inserted = append(inserted, mongoID+":"+status)
+				return nil
+			})
+		defer p1.Unpatch()
+		p2 := bm.Patch(repository.AchievementSoftDeleteReference,
+			func(referenceID string, expectedFrom []string) error {
+				if referenceID == "r-orphan" {
+					return errors.New("pg down")
+				}
+				refDeleted = append(refDeleted, referenceID)
+				return nil
+			})
+		defer p2.Unpatch()
+		p3 := bm.Patch(repository.AchievementSoftDeleteMongo,
+			func(mongoID string) error { mongoDeleted = append(mongoDeleted, mongoID); return nil })
+		defer p3.Unpatch()
+
+		report, err := service.ReconcileAchievements(false)
+		require.NoError(t, err)
+
+		require.Equal(t, []string{"m-orphan:draft"}, inserted)
+		require.Equal(t, []string{"r-deleted"}, refDeleted)
+		require.Equal(t, []string{"m-ref-deleted"}, mongoDeleted)
+
+		issues := issuesByMongoID(report)
+		require.True(t, issues["m-orphan"].Repaired)
+		require.False(t, issues["m-deleted-verified"].Repaired)
+		require.False(t, issues["m-missing"].Repaired)
+		require.Equal(t, "pg down", issues["m-missing"].Error)
+	})
+	t.Run("Apply_SkipsDiscrepancyResolvedSinceScan", func(t *testing.T) {
+		docs, _ := patchReconcileStores(t)
+
+		// dokumen m-missing muncul setelah scan (mis. CreateAchievement yang sedang berjalan)
+		pL := bm.Patch(repository.ListAchievementMongoStates,
+			func() ([]models.AchievementMongoState, error) {
+				snapshot := append([]models.AchievementMongoState{}, *docs...)
+				*docs = append(*docs, models.AchievementMongoState{MongoID: "m-missing", StudentID: "stu-1"})
+				return snapshot, nil
+			})
+		defer pL.Unpatch()
+
+		var refDeleted []string
+		p1 := bm.Patch(repository.InsertMissingReference,
+			func(studentID, mongoID, status string) error { return nil })
+		defer p1.Unpatch()
+		p2 := bm.Patch(repository.AchievementSoftDeleteReference,
+			func(referenceID string, expectedFrom []string) error {
+				refDeleted = append(refDeleted, referenceID)
+				return nil
+			})
+		defer p2.Unpatch()
+		p3 := bm.Patch(repository.AchievementSoftDeleteMongo,
+			func(mongoID string) error { return nil })
+		defer p3.Unpatch()
+
+		report, err := service.ReconcileAchievements(false)
+		require.NoError(t, err)
+
+		issues := issuesByMongoID(report)
+		require.True(t, issues["m-missing"].Stale)
+		require.False(t, issues["m-missing"].Repaired)
+		require.Equal(t, []string{"r-deleted"}, refDeleted)
+	})
+
+	t.Run("SkipsRecentlyCreated", func(t *testing.T) {
+		_, refs := patchReconcileStores(t)
+		// reference baru, dokumen Mongo-nya belum terlihat oleh scan
+		*refs = append(*refs, models.AchievementReference{
+			ID: "r-new", MongoAchievementID: "m-new", StudentID: "stu-1", Status: "draft", CreatedAt: time.Now(),
+		})
+
+		report, err := service.ReconcileAchievements(true)
+		require.NoError(t, err)
+		require.Equal(t, 1, report.SkippedRecent)
+		require.NotContains(t, issuesByMongoID(report), "m-new")
+	})
+}
@@ -0,0 +1,103 @@
+package service_test
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/app/service"
+	"encoding/json"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	bm "bou.ke/monkey"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetReviewQueue(t *testing.T) {
+	app := fiber.New()
+	app.Get("/achievements/review-queue", service.GetReviewQueue)
+
+	t.Run("Success_PassesFilters", func(t *testing.T) {
+		pL := bm.Patch(repository.GetLecturerIDByUserID,
+			func(userID string) (string, error) { return "lec-1", nil })
+		defer pL.Unpatch()
+
+		submitted := time.Now().Add(-48 * time.Hour)
+		var gotLecturer, gotProdi, gotType string
+		pQ := bm.Patch(repository.GetReviewQueue,
+			func(lecturerID, programStudy, achType string) ([]models.ReviewQueueItem, error) {
+				gotLecturer, gotProdi, gotType = lecturerID, programStudy, achType
+				return []models.ReviewQueueItem{{
+					ReferenceID:     "ref-1",
+					StudentName:     "Budi",
+					NIM:             "434221001",
+					Title:           "Juara 1",
+					AchievementType: "competition",
+					Details:         scoringDetails,
+					AttachmentCount: 2,
+					SubmittedAt:     submitted,
+					AgeSeconds:      172800,
+				}}, nil
+			})
+		defer pQ.Unpatch()
+		patchScoring(t, models.ScoringModeOverride, sampleRules()...)
+
+		req := makeReq("GET", "/achievements/review-queue?type=competition&programStudy=Informatika", nil)
+		req.Header.Set("user_id", "lecturer-user-1")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 200, resp.StatusCode)
+		require.Equal(t, "lec-1", gotLecturer)
+		require.Equal(t, "Informatika", gotProdi)
+		require.Equal(t, "competition", gotType)
+
+		var out struct {
+			Data []models.ReviewQueueItem `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		require.Len(t, out.Data, 1)
+		require.Equal(t, "434221001", out.Data[0].NIM)
+		require.Equal(t, 2, out.Data[0].AttachmentCount)
+		require.Equal(t, 50, *out.Data[0].SuggestedPoints)
+	})
+
+	t.Run("NotLecturer", func(t *testing.T) {
+		pL := bm.Patch(repository.GetLecturerIDByUserID,
+			func(userID string) (string, error) { return "", errors.New("not found") })
+		defer pL.Unpatch()
+
+		req := makeReq("GET", "/achievements/review-queue", nil)
+		req.Header.Set("user_id", "user-1")
+
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, 403, resp.StatusCode)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		resp, err := app.Test(makeReq("GET", "/achievements/review-queue", nil))
+		require.NoError(t, err)
+		require.Equal(t, 401, resp.StatusCode)
+	})
+}
+
+func TestRepositoryGetReviewQueue_Empty(t *testing.T) {
+	db, mock := setupDB(t)
+	defer db.Close()
+
+	// hanya submitted milik bimbingan, paling lama di depan; tanpa hasil Mongo tidak disentuh
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE s.advisor_id = $1`)).
+		WithArgs("lec-1", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "mongo_achievement_id", "student_id", "full_name",
+			"nim", "program_study", "submitted_at"}))
+
+	items, err := repository.GetReviewQueue("lec-1", "", "")
+	require.NoError(t, err)
+	require.Empty(t, items)
+	require.NotNil(t, items)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
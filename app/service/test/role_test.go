@@ -0,0 +1,472 @@
+package service_test
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/app/service"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	bm "bou.ke/monkey"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// patchRoleStore: role admin (sistem, 1 user), mahasiswa (sistem) dan kaprodi (0 user);
+// mengembalikan pointer ke log audit yang tercatat
+func patchRoleStore(t *testing.T) *[]models.RBACAuditEntry {
+	roles := map[string]models.Role{
+		"r-admin":   {ID: "r-admin", Name: "admin", IsSystem: true, Permissions: []string{"role:manage"}, UserCount: 1},
+		"r-mhs":     {ID: "r-mhs", Name: "mahasiswa", IsSystem: true, Permissions: []string{"achievement:read"}, UserCount: 5},
+		"r-kaprodi": {ID: "r-kaprodi", Name: "kaprodi", Permissions: []string{"achievement:read"}},
+	}
+	known := []models.Permission{{Name: "achievement:read"}, {Name: "report:statistics"}, {Name: "role:manage"}}
+	audit := &[]models.RBACAuditEntry{}
+
+	pG := bm.Patch(repository.GetRoleByID, func(id string) (*models.Role, error) {
+		r, ok := roles[id]
+		if !ok {
+			return nil, repository.ErrRoleNotFound
+		}
+		r.Permissions = append([]string{}, r.Permissions...)
+		return &r, nil
+	})
+	pP := bm.Patch(repository.ListPermissions, func() ([]models.Permission, error) {
+		return append([]models.Permission{}, known...), nil
+	})
+	pGr := bm.Patch(repository.GrantRolePermissions, func(roleID string, names []string, e models.RBACAuditEntry) ([]string, error) {
+		r := roles[roleID]
+		added := []string{}
+		for _, n := range names {
+			if !contains(r.Permissions, n) {
+				r.Permissions = append(r.Permissions, n)
+				added = append(added, n)
+				e.Permission = &n
+				*audit = append(*audit, e)
+			}
+		}
+		roles[roleID] = r
+		return added, nil
+	})
+	pR := bm.Patch(repository.RevokeRolePermission, func(roleID, name string, e models.RBACAuditEntry) (bool, error) {
+		r := roles[roleID]
+		for i, p := range r.Permissions {
+			if p == name {
+				r.Permissions = append(r.Permissions[:i:i], r.Permissions[i+1:]...)
+				roles[roleID] = r
+				*audit = append(*audit, e)
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	t.Cleanup(func() {
+		pG.Unpatch()
+		pP.Unpatch()
+		pGr.Unpatch()
+		pR.Unpatch()
+	})
+	return audit
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAdminRoles(t *testing.T) {
+	app := fiber.New()
+	app.Post("/roles", service.AdminCreateRole)
+	app.Put("/roles/:id", service.AdminUpdateRole)
+	app.Delete("/roles/:id", service.AdminDeleteRole)
+	app.Post("/roles/:id/permissions", service.AdminGrantRolePermissions)
+	app.Delete("/roles/:id/permissions/:permission", service.AdminRevokeRolePermission)
+
+	t.Run("Create_Normalizes", func(t *testing.T) {
+		audit := patchRoleStore(t)
+		var got models.RoleRequest
+		p := bm.Patch(repository.CreateRole, func(req models.RoleRequest, e models.RBACAuditEntry) (*models.Role, error) {
+			got = models.RoleRequest{Name: req.Name, Description: req.Description, Permissions: append([]string{}, req.Permissions...)}
+			id := "r-new"
+			e.RoleID = &id
+			*audit = append(*audit, e)
+			return &models.Role{ID: id, Name: req.Name, Permissions: got.Permissions}, nil
+		})
+		defer p.Unpatch()
+
+		resp, err := app.Test(makeReq("POST", "/roles", map[string]any{
+			"name":        " Kaprodi_TI ",
+			"description": " Ketua program studi ",
+			"permissions": []string{"report:statistics", "achievement:read", "report:statistics"},
+		}))
+		require.NoError(t, err)
+		require.Equal(t, 201, resp.StatusCode)
+		require.Equal(t, models.RoleRequest{
+			Name:        "kaprodi_ti",
+			Description: "Ketua program studi",
+			Permissions: []string{"achievement:read", "report:statistics"},
+		}, got)
+		require.Len(t, *audit, 1)
+		require.Equal(t, models.RBACAuditRoleCreated, (*audit)[0].Action)
+		require.Equal(t, "r-new", *(*audit)[0].RoleID)
+	})
+
+	t.Run("Create_Invalid", func(t *testing.T) {
+		patchRoleStore(t)
+		for _, body := range []map[string]any{
+			{"name": "x"},
+			{"name": "kaprodi-ti"},
+			{"name": "1kaprodi"},
+			{"name": "kaprodi", "permissions": []string{"achievement:read", "root:everything"}},
+		} {
+			resp, err := app.Test(makeReq("POST", "/roles", body))
+			require.NoError(t, err)
+			require.Equal(t, 400, resp.StatusCode, body)
+		}
+	})
+
+	t.Run("Create_Duplicate", func(t *testing.T) {
+		patchRoleStore(t)
+		p := bm.Patch(repository.CreateRole, func(req models.RoleRequest, e models.RBACAuditEntry) (*models.Role, error) {
+			return nil, repository.ErrRoleExists
+		})
+		defer p.Unpatch()
+
+		resp, err := app.Test(makeReq("POST", "/roles", map[string]any{"name": "admin"}))
+		require.NoError(t, err)
+		require.Equal(t, 409, resp.StatusCode)
+	})
+
+	t.Run("Update_SystemRoleRename", func(t *testing.T) {
+		patchRoleStore(t)
+
+		resp, err := app.Test(makeReq("PUT", "/roles/r-admin", map[string]any{"name": "superuser"}))
+		require.NoError(t, err)
+		require.Equal(t, 409, resp.StatusCode)
+	})
+
+	t.Run("Update_RecordsChanges", func(t *testing.T) {
+		audit := patchRoleStore(t)
+		p := bm.Patch(repository.UpdateRole, func(id string, req models.RoleRequest, e *models.RBACAuditEntry) (*models.Role, error) {
+			if e != nil {
+				*audit = append(*audit, *e)
+			}
+			return &models.Role{ID: id, Name: req.Name, Description: req.Description, IsSystem: true}, nil
+		})
+		defer p.Unpatch()
+
+		resp, err := app.Test(makeReq("PUT", "/roles/r-mhs", map[string]any{"name": "mahasiswa", "description": "Mahasiswa aktif"}))
+		require.NoError(t, err)
+		require.Equal(t, 200, resp.StatusCode)
+		require.Len(t, *audit, 1)
+		require.Equal(t, map[string]models.FieldChange{"description": {From: "", To: "Mahasiswa aktif"}}, (*audit)[0].Changes)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		audit := patchRoleStore(t)
+		deleted := ""
+		p := bm.Patch(repository.DeleteRole, func(id string, e models.RBACAuditEntry) error {
+			deleted = id
+			*audit = append(*audit, e)
+			return nil
+		})
+		defer p.Unpatch()
+
+		for _, tc := range []struct {
+			id     string
+			status int
+		}{
+			{"r-admin", 409},
+			{"r-mhs", 409},
+			{"r-missing", 404},
+			{"r-kaprodi", 200},
+		} {
+			resp, err := app.Test(makeReq("DELETE", "/roles/"+tc.id, nil))
+			require.NoError(t, err)
+			require.Equal(t, tc.status, resp.StatusCode, tc.id)
+		}
+		require.Equal(t, "r-kaprodi", deleted)
+		require.Len(t, *audit, 1)
+		require.Equal(t, models.RBACAuditRoleDeleted, (*audit)[0].Action)
+	})
+
+	t.Run("Grant", func(t *testing.T) {
+		audit := patchRoleStore(t)
+
+		resp, err := app.Test(makeReq("POST", "/roles/r-kaprodi/permissions",
+			map[string]any{"permissions": []string{"achievement:read", "report:statistics"}}))
+		require.NoError(t, err)
+		require.Equal(t, 200, resp.StatusCode)
+		// achievement:read sudah dimiliki: hanya satu event
+		require.Len(t, *audit, 1)
+		require.Equal(t, models.RBACAuditPermissionGranted, (*audit)[0].Action)
+		require.Equal(t, "report:statistics", *(*audit)[0].Permission)
+
+		resp, err = app.Test(makeReq("POST", "/roles/r-kaprodi/permissions",
+			map[string]any{"permissions": []string{"root:everything"}}))
+		require.NoError(t, err)
+		require.Equal(t, 400, resp.StatusCode)
+
+		resp, err = app.Test(makeReq("POST", "/roles/r-missing/permissions",
+			map[string]any{"permissions": []string{"achievement:read"}}))
+		require.NoError(t, err)
+		require.Equal(t, 404, resp.StatusCode)
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		audit := patchRoleStore(t)
+
+		for _, tc := range []struct {
+			path   string
+			status int
+		}{
+			{"/roles/r-admin/permissions/role:manage", 409},
+			{"/roles/r-kaprodi/permissions/report:statistics", 404},
+			{"/roles/r-kaprodi/permissions/achievement:read", 200},
+		} {
+			resp, err := app.Test(makeReq("DELETE", tc.path, nil))
+			require.NoError(t, err)
+			require.Equal(t, tc.status, resp.StatusCode, tc.path)
+		}
+		require.Len(t, *audit, 1)
+		require.Equal(t, models.RBACAuditPermissionRevoked, (*audit)[0].Action)
+	})
+}
+
+// TestRoleChangeAudit: baris audit ditulis di transaksi perubahan role; gagal menulis audit = perubahan batal.
+func TestRoleChangeAudit(t *testing.T) {
+	insertAudit := regexp.QuoteMeta(`INSERT INTO rbac_audit_log`)
+	entry := models.RBACAuditEntry{Action: models.RBACAuditPermissionRevoked}
+
+	t.Run("Revoke_AuditFailsRollsBack", func(t *testing.T) {
+		db, mock := setupDB(t)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM role_permissions`)).WithArgs("r-kaprodi", "achievement:read").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(insertAudit).WillReturnError(errors.New("audit down"))
+		mock.ExpectRollback()
+
+		_, err := repository.RevokeRolePermission("r-kaprodi", "achievement:read", entry)
+		require.EqualError(t, err, "audit down")
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Revoke_NothingToRevokeWritesNoAudit", func(t *testing.T) {
+		db, mock := setupDB(t)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM role_permissions`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		revoked, err := repository.RevokeRolePermission("r-kaprodi", "achievement:read", entry)
+		require.NoError(t, err)
+		require.False(t, revoked)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Grant_OneAuditRowPerPermission", func(t *testing.T) {
+		db, mock := setupDB(t)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO role_permissions`)).
+			WillReturnRows(sqlmock.NewRows([]string{"name"}).AddRow("achievement:read").AddRow("report:statistics"))
+		for _, name := range []string{"achievement:read", "report:statistics"} {
+			mock.ExpectQuery(insertAudit).
+				WithArgs(models.RBACAuditPermissionGranted, sqlmock.AnyArg(), sqlmock.AnyArg(), &name,
+					sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+				WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now()))
+		}
+		mock.ExpectCommit()
+
+		added, err := repository.GrantRolePermissions("r-kaprodi", []string{"achievement:read", "report:statistics"},
+			models.RBACAuditEntry{Action: models.RBACAuditPermissionGranted})
+		require.NoError(t, err)
+		require.Equal(t, []string{"achievement:read", "report:statistics"}, added)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Handler_AuditFailureIs500", func(t *testing.T) {
+		patchRoleStore(t)
+		p := bm.Patch(repository.DeleteRole, func(id string, e models.RBACAuditEntry) error {
+			return errors.New("audit down")
+		})
+		defer p.Unpatch()
+
+		app := fiber.New()
+		app.Delete("/roles/:id", service.AdminDeleteRole)
+		resp, err := app.Test(makeReq("DELETE", "/roles/r-kaprodi", nil))
+		require.NoError(t, err)
+		require.Equal(t, 500, resp.StatusCode)
+	})
+
+	t.Run("ActorIsAuthorizingRole", func(t *testing.T) {
+		audit := patchRoleStore(t)
+		pH := bm.Patch(repository.CachedRoleHasPermission, func(roleID, permission string) (bool, error) {
+			return roleID == "r-kaprodi" && permission == "role:manage", nil
+		})
+		pN := bm.Patch(repository.CachedRoleName, func(roleID string) (string, error) {
+			return map[string]string{"r-dosen": "dosen_wali", "r-kaprodi": "kaprodi"}[roleID], nil
+		})
+		defer pH.Unpatch()
+		defer pN.Unpatch()
+
+		app := fiber.New()
+		app.Use(func(c *fiber.Ctx) error {
+			c.Locals("role", "dosen_wali")
+			c.Locals("roles", []string{"dosen_wali", "kaprodi"})
+			c.Locals("role_ids", []string{"r-dosen", "r-kaprodi"})
+			return c.Next()
+		})
+		app.Delete("/roles/:id/permissions/:permission", service.AdminRevokeRolePermission)
+
+		resp, err := app.Test(makeReq("DELETE", "/roles/r-kaprodi/permissions/achievement:read", nil))
+		require.NoError(t, err)
+		require.Equal(t, 200, resp.StatusCode)
+		require.Len(t, *audit, 1)
+		require.Equal(t, "kaprodi", *(*audit)[0].ActorRole)
+	})
+}
+
+func TestRBACAuditLimit(t *testing.T) {
+	var got int
+	p := bm.Patch(repository.ListRBACAudit, func(roleID string, limit int) ([]models.RBACAuditEntry, error) {
+		got = limit
+		return []models.RBACAuditEntry{}, nil
+	})
+	defer p.Unpatch()
+
+	app := fiber.New()
+	app.Get("/roles/audit", service.AdminGetRBACAudit)
+
+	for query, want := range map[string]int{"": 50, "?limit=10": 10, "?limit=5000": 200, "?limit=-1": 50} {
+		resp, err := app.Test(makeReq("GET", "/roles/audit"+query, nil))
+		require.NoError(t, err)
+		require.Equal(t, 200, resp.StatusCode)
+		require.Equal(t, want, got, query)
+	}
+}
+
+func TestLastAdminProtection(t *testing.T) {
+	lockAdmins := regexp.QuoteMeta(`FOR UPDATE OF u`)
+	countAdmins := regexp.QuoteMeta(`SELECT COUNT(*) FROM users u WHERE`)
+	userRoles := regexp.QuoteMeta(`FROM user_roles ur`)
+
+	t.Run("DemoteLastAdmin", func(t *testing.T) {
+		db, mock := setupDB(t)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockAdmins).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("admin-1"))
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE users SET role_id`)).WithArgs("r-mhs", "admin-1").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "role_id", "is_active", "created_at", "updated_at"}).
+				AddRow("admin-1", "a@example.com", "r-mhs", true, time.Now(), time.Now()))
+		mock.ExpectQuery(countAdmins).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectRollback()
+
+		_, err := repository.UpdateUserRole("admin-1", "r-mhs")
+		require.ErrorIs(t, err, repository.ErrLastAdmin)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DemoteWithAnotherAdmin", func(t *testing.T) {
+		db, mock := setupDB(t)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockAdmins).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("admin-1").AddRow("admin-2"))
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE users SET role_id`)).WithArgs("r-mhs", "admin-1").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "role_id", "is_active", "created_at", "updated_at"}).
+				AddRow("admin-1", "a@example.com", "r-mhs", true, time.Now(), time.Now()))
+		mock.ExpectQuery(countAdmins).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+		mock.ExpectQuery(userRoles).WithArgs("admin-1").
+			WillReturnRows(sqlmock.NewRows([]string{"role_id"}).AddRow("r-mhs"))
+		mock.ExpectCommit()
+
+		user, err := repository.UpdateUserRole("admin-1", "r-mhs")
+		require.NoError(t, err)
+		require.Equal(t, "r-mhs", user.RoleID)
+		require.Equal(t, []string{"r-mhs"}, user.RoleIDs)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("RemoveSecondaryAdminRoleFromLastAdmin", func(t *testing.T) {
+		db, mock := setupDB(t)
+		defer db.Close()
+
+		// dosen-1: role utama dosen_wali, admin hanya sebagai role tambahan
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockAdmins).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("dosen-1"))
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE users SET updated_at`)).WithArgs("dosen-1").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email", "role_id", "is_active", "created_at", "updated_at"}).
+				AddRow("dosen-1", "d@example.com", "r-dosen", true, time.Now(), time.Now()))
+		mock.ExpectQuery(regexp.QuoteMeta(`DELETE FROM user_roles`)).WithArgs("dosen-1", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"role_id"}).AddRow("r-admin"))
+		mock.ExpectQuery(countAdmins).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectRollback()
+
+		_, err := repository.UpdateUserRoles("dosen-1", "", nil, []string{"r-admin"})
+		require.ErrorIs(t, err, repository.ErrLastAdmin)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("DeleteLastAdmin", func(t *testing.T) {
+		db, mock := setupDB(t)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockAdmins).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("admin-1"))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM students`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM lecturers`)).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM users`)).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(countAdmins).WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+		mock.ExpectRollback()
+
+		require.ErrorIs(t, repository.DeleteUser("admin-1"), repository.ErrLastAdmin)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Handlers", func(t *testing.T) {
+		pD := bm.Patch(repository.DeleteUser, func(id string) error { return repository.ErrLastAdmin })
+		defer pD.Unpatch()
+		pR := bm.Patch(repository.UpdateUserRole, func(id, roleID string) (*models.User, error) {
+			return nil, repository.ErrLastAdmin
+		})
+		defer pR.Unpatch()
+		pE := bm.Patch(repository.IsEmailExistsForOtherUser, func(id, email string) (bool, error) { return false, nil })
+		defer pE.Unpatch()
+		pU := bm.Patch(repository.UpdateUser, func(id string, u *models.User) (*models.User, error) {
+			return nil, repository.ErrLastAdmin
+		})
+		defer pU.Unpatch()
+
+		app := fiber.New()
+		app.Delete("/admin/users/:id", service.AdminDeleteUser)
+		app.Put("/admin/users/:id", service.AdminUpdateUser)
+		app.Put("/admin/users/:id/role", service.AdminUpdateUserRole)
+
+		for _, req := range []struct{ method, path string }{
+			{"DELETE", "/admin/users/admin-1"},
+			{"PUT", "/admin/users/admin-1"},
+			{"PUT", "/admin/users/admin-1/role"},
+		} {
+			resp, err := app.Test(makeReq(req.method, req.path, map[string]any{"role_id": "r-mhs", "email": "a@example.com"}))
+			require.NoError(t, err)
+			require.Equal(t, 409, resp.StatusCode, req.path)
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package service_test
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/app/service"
+	"UAS_GO/helper"
+	"UAS_GO/route"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	bm "bou.ke/monkey"
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+// permission per role, sama dengan migrasi 0014_seed_rbac (dan seed di database/migrate.go)
+var seededRolePermissions = map[string][]string{
+	"mahasiswa": {
+		"achievement:create", "achievement:read", "achievement:update", "achievement:delete",
+		"achievement:submit", "report:student", "auth:profile",
+	},
+	"dosen_wali": {
+		"achievement:read", "achievement:view-advisee", "achievement:verify", "achievement:reject",
+		"lecturer:advisee-list", "report:statistics", "auth:profile",
+	},
+}
+
+// patchRouteAuth: token "Bearer <user_id>|<role>" diterima AuthRequired tanpa JWT sungguhan;
+// role_id sama dengan nama role.
+func patchRouteAuth(t *testing.T) {
+	pV := bm.Patch(helper.ValidateToken, func(token string) (*models.JWTClaims, error) {
+		userID, role, ok := strings.Cut(token, "|")
+		if !ok {
+			return nil, errors.New("invalid token")
+		}
+		return &models.JWTClaims{UserID: userID, Role: role, RegisteredClaims: jwt.RegisteredClaims{ID: "jti-" + userID}}, nil
+	})
+	pR := bm.Patch(repository.IsTokenRevoked, func(jti string) (bool, error) { return false, nil })
+	patchPasswordChangedAt(t, nil)
+	pN := bm.Patch(repository.GetRoleNameByID, func(roleID string) (string, error) { return roleID, nil })
+	pP := bm.Patch(repository.GetPermissionsByRoleID, func(roleID string) ([]string, error) {
+		if roleID == "admin" {
+			all := []string{}
+			for _, perms := range seededRolePermissions {
+				all = append(all, perms...)
+			}
+			return append(all, "student:read", "student:update", "user:manage", "user:read",
+				"role:read", "role:manage", "user:assign-permission", "user:update",
+				"maintenance:reconcile", "scoring:manage", "achievement-type:manage"), nil
+		}
+		// role non-sistem yang diberi permission maintenance tertentu
+		if roleID == "kaprodi" {
+			return []string{"scoring:manage"}, nil
+		}
+		return append([]string{}, seededRolePermissions[roleID]...), nil
+	})
+	pO := bm.Patch(repository.GetUserPermissionOverrides, func(userID string) ([]models.UserPermission, error) {
+		return []models.UserPermission{}, nil
+	})
+	repository.InvalidateRoleCache()
+	repository.InvalidateUserPermissionCache()
+	t.Cleanup(func() {
+		repository.InvalidateRoleCache()
+		repository.InvalidateUserPermissionCache()
+		pV.Unpatch()
+		pR.Unpatch()
+		pN.Unpatch()
+		pP.Unpatch()
+		pO.Unpatch()
+	})
+}
+
+// patchReached mengganti handler agar matrix hanya menguji lapisan policy
+func patchReached(t *testing.T, handlers ...func(*fiber.Ctx) error) {
+	for _, h := range handlers {
+		p := bm.Patch(h, func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) })
+		t.Cleanup(p.Unpatch)
+	}
+}
+
+func TestRoutePolicyMatrix(t *testing.T) {
+	patchRouteAuth(t)
+	patchAccessLookups(t)
+	patchReached(t,
+		service.GetStudentByID, service.GetStudentAchievements, service.UpdateStudentAdvisor,
+		service.GetStudentReport, service.GetLecturerAdvisees,
+		service.GetAchievementById, service.GetAchievementHistory, service.UpdateAchievement,
+		service.VerifyAchievement, service.GetReviewQueue,
+		service.AdminListScoringRules, service.AdminGetAllUsers,
+		service.AdminReconcileAchievements, service.AdminListAchievementTypes,
+		service.AdminListRoles, service.AdminCreateRole, service.AdminListPermissions,
+		service.AdminGetUserPermissions, service.AdminSetUserPermission, service.AdminUnlockUser,
+	)
+
+	app := fiber.New()
+	route.RegisterRoutes(app)
+
+	// stu-1 (student-user) dibimbing lec-1 (lecturer-user); prestasi consistencyMongoID milik stu-1
+	callers := []struct{ name, token string }{
+		{"admin", "admin-user|admin"},
+		{"owner", "student-user|mahasiswa"},
+		{"otherStudent", "other-student-user|mahasiswa"},
+		{"advisor", "lecturer-user|dosen_wali"},
+		{"otherLecturer", "other-lecturer-user|dosen_wali"},
+	}
+	const missingID = "507f1f77bcf86cd799439099"
+	ach := "/api/v1/achievements/" + consistencyMongoID
+
+	matrix := []struct {
+		method, path string
+		want         []int // urutan sesuai callers
+	}{
+		{"GET", "/api/v1/students/stu-1", []int{200, 403, 403, 403, 403}},
+		{"GET", "/api/v1/students/stu-1/achievements", []int{200, 403, 403, 403, 403}},
+		{"PUT", "/api/v1/students/stu-1/advisor", []int{200, 403, 403, 403, 403}},
+		{"GET", "/api/v1/reports/student/stu-1", []int{200, 200, 403, 403, 403}},
+		{"GET", "/api/v1/lecturers/lec-1/advisees", []int{200, 403, 403, 200, 403}},
+		{"GET", ach, []int{200, 200, 403, 200, 403}},
+		{"GET", ach + "/history", []int{200, 200, 403, 200, 403}},
+		{"PUT", ach, []int{200, 200, 403, 403, 403}},
+		{"POST", ach + "/verify", []int{200, 403, 403, 200, 403}},
+		{"GET", "/api/v1/achievements/" + missingID, []int{404, 404, 404, 404, 404}},
+		{"GET", "/api/v1/achievements/review-queue", []int{403, 403, 403, 200, 200}},
+		{"GET", "/api/v1/admin/scoring-rules", []int{200, 403, 403, 403, 403}},
+		{"POST", "/api/v1/admin/reconcile", []int{200, 403, 403, 403, 403}},
+		{"GET", "/api/v1/admin/achievement-types", []int{200, 403, 403, 403, 403}},
+		{"GET", "/api/v1/users", []int{200, 403, 403, 403, 403}},
+		{"GET", "/api/v1/roles", []int{200, 403, 403, 403, 403}},
+		{"POST", "/api/v1/roles", []int{200, 403, 403, 403, 403}},
+		{"GET", "/api/v1/permissions", []int{200, 403, 403, 403, 403}},
+		{"GET", "/api/v1/users/u-2/permissions", []int{200, 403, 403, 403, 403}},
+		{"PUT", "/api/v1/users/u-2/permissions/report:statistics", []int{200, 403, 403, 403, 403}},
+		{"POST", "/api/v1/users/u-2/unlock", []int{200, 403, 403, 403, 403}},
+	}
+
+	for _, row := range matrix {
+		for i, caller := range callers {
+			t.Run(row.method+" "+row.path+"/"+caller.name, func(t *testing.T) {
+				req := httptest.NewRequest(row.method, row.path, nil)
+				req.Header.Set("Authorization", "Bearer "+caller.token)
+
+				resp, err := app.Test(req)
+				require.NoError(t, err)
+				require.Equal(t, row.want[i], resp.StatusCode)
+			})
+		}
+	}
+
+	// /admin/* dijaga per permission: role lain bisa diberi sebagian tanpa menjadi admin
+	t.Run("DelegatedMaintenancePermission", func(t *testing.T) {
+		for path, want := range map[string]int{
+			"/api/v1/admin/scoring-rules":     200,
+			"/api/v1/admin/achievement-types": 403,
+		} {
+			req := httptest.NewRequest("GET", path, nil)
+			req.Header.Set("Authorization", "Bearer kaprodi-user|kaprodi")
+
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			require.Equal(t, want, resp.StatusCode, path)
+		}
+	})
+
+	t.Run("NoToken", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", ach, nil))
+		require.NoError(t, err)
+		require.Equal(t, 401, resp.StatusCode)
+	})
+}
@@ -0,0 +1,283 @@
+package service_test
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/app/service"
+	"encoding/json"
+	"regexp"
+	"testing"
+	"time"
+
+	bm "bou.ke/monkey"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func strPtr(s string) *string { return &s }
+func intPtr(n int) *int       { return &n }
+
+// scoringDetails: details dokumen prestasi yang dipakai patchScoring
+var scoringDetails = map[string]any{"competitionLevel": "National", "rank": int32(1), "participation": "team"}
+
+// patchScoring mengganti rubrik, kebijakan, dan dokumen prestasi (tipe competition, scoringDetails)
+func patchScoring(t *testing.T, mode string, rules ...models.ScoringRule) {
+	if rules == nil {
+		rules = []models.ScoringRule{}
+	}
+	pR := bm.Patch(repository.ListScoringRules,
+		func() ([]models.ScoringRule, error) { return rules, nil })
+	pP := bm.Patch(repository.GetScoringPolicy,
+		func() (*models.ScoringPolicy, error) {
+			return &models.ScoringPolicy{Mode: mode, MaxOverridePercent: 20}, nil
+		})
+	pA := bm.Patch(repository.GetAchievementByIdMongo,
+		func(id string) (*models.Achievement, error) {
+			return &models.Achievement{AchievementType: "competition", Details: scoringDetails}, nil
+		})
+	pD := bm.Patch(repository.GetAchievementTypeDefaultPoints,
+		func() (map[string]int, error) { return map[string]int{}, nil })
+	pB := bm.Patch(repository.GetAchievementsByMongoIDs,
+		func(ids []string) (map[string]models.Achievement, error) {
+			out := map[string]models.Achievement{}
+			for _, id := range ids {
+				out[id] = models.Achievement{AchievementType: "competition", Details: scoringDetails}
+			}
+			return out, nil
+		})
+	t.Cleanup(func() {
+		pR.Unpatch()
+		pP.Unpatch()
+		pA.Unpatch()
+		pB.Unpatch()
+		pD.Unpatch()
+	})
+}
+
+// rubrik contoh: aturan umum competition (10) dan aturan nasional juara 1 (50)
+func sampleRules() []models.ScoringRule {
+	return []models.ScoringRule{
+		{ID: "rule-any", AchievementType: "competition", Points: 10},
+		{ID: "rule-national-1", AchievementType: "competition", CompetitionLevel: strPtr("national"), Rank: intPtr(1), Points: 50},
+		{ID: "rule-intl", AchievementType: "competition", CompetitionLevel: strPtr("international"), Points: 100},
+	}
+}
+
+func TestVerifyAchievement_Scoring(t *testing.T) {
+	app := fiber.New()
+	app.Use(roleFromHeader)
+	app.Post("/achievements/:id/verify", service.VerifyAchievement)
+
+	verify := func(t *testing.T, body map[string]any) (int, *int) {
+		var stored *int
+		pM := bm.Patch(repository.VerifyAchievementMongo,
+			func(id string, points int, dosenID string) error { stored = &points; return nil })
+		t.Cleanup(pM.Unpatch)
+		pR := bm.Patch(repository.VerifyAchievementReference,
+			func(refID string, dosenID string, expectedFrom []string) error { return nil })
+		t.Cleanup(pR.Unpatch)
+
+		req := makeReq("POST", "/achievements/"+consistencyMongoID+"/verify", body)
+		req.Header.Set("user_id", "lecturer-user-1")
+		req.Header.Set("role", "dosen_wali")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		return resp.StatusCode, stored
+	}
+
+	t.Run("EmptyPoints_UsesMostSpecificRule", func(t *testing.T) {
+		patchAdvisor(t)
+		patchScoring(t, models.ScoringModeOverride, sampleRules()...)
+		events := patchEventLog(t)
+
+		status, stored := verify(t, map[string]any{})
+		require.Equal(t, 200, status)
+		require.Equal(t, 50, *stored)
+		require.Len(t, *events, 1)
+		require.Nil(t, (*events)[0].Note)
+	})
+
+	t.Run("Override_WithinBound", func(t *testing.T) {
+		patchAdvisor(t)
+		patchScoring(t, models.ScoringModeOverride, sampleRules()...)
+		events := patchEventLog(t)
+
+		status, stored := verify(t, map[string]any{"points": 60, "justification": "Tim beranggotakan 2 orang"})
+		require.Equal(t, 200, status)
+		require.Equal(t, 60, *stored)
+		require.Equal(t, 50, (*events)[0].Changes["suggested_points"].To)
+		require.Equal(t, "Tim beranggotakan 2 orang", *(*events)[0].Note)
+	})
+
+	t.Run("Override_MissingJustification", func(t *testing.T) {
+		patchAdvisor(t)
+		patchScoring(t, models.ScoringModeOverride, sampleRules()...)
+
+		status, stored := verify(t, map[string]any{"points": 55})
+		require.Equal(t, 400, status)
+		require.Nil(t, stored)
+	})
+
+	t.Run("Override_OutOfBound", func(t *testing.T) {
+		patchAdvisor(t)
+		patchScoring(t, models.ScoringModeOverride, sampleRules()...)
+
+		status, _ := verify(t, map[string]any{"points": 61, "justification": "x"})
+		require.Equal(t, 400, status)
+	})
+
+	t.Run("Enforce_RejectsOtherPoints", func(t *testing.T) {
+		patchAdvisor(t)
+		patchScoring(t, models.ScoringModeEnforce, sampleRules()...)
+
+		status, _ := verify(t, map[string]any{"points": 55, "justification": "x"})
+		require.Equal(t, 400, status)
+	})
+
+	t.Run("NoRule_FreePoints", func(t *testing.T) {
+		patchAdvisor(t)
+		patchScoring(t, models.ScoringModeEnforce)
+		patchEventLog(t)
+
+		status, stored := verify(t, map[string]any{"points": 7})
+		require.Equal(t, 200, status)
+		require.Equal(t, 7, *stored)
+	})
+}
+
+func TestGetAchievementScore(t *testing.T) {
+	app := fiber.New()
+	app.Get("/achievements/:id/score", service.GetAchievementScore)
+
+	patchAdvisor(t)
+	patchScoring(t, models.ScoringModeOverride, sampleRules()...)
+
+	req := makeReq("GET", "/achievements/"+consistencyMongoID+"/score", nil)
+	req.Header.Set("user_id", "lecturer-user-1")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+
+	var out struct {
+		Data models.ScoreSuggestion `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	require.Equal(t, 50, *out.Data.SuggestedPoints)
+	require.Equal(t, "rule-national-1", out.Data.RuleID)
+	require.Equal(t, 40, *out.Data.MinPoints)
+	require.Equal(t, 60, *out.Data.MaxPoints)
+}
+
+func TestGetAchievementScore_DefaultPointsFallback(t *testing.T) {
+	app := fiber.New()
+	app.Get("/achievements/:id/score", service.GetAchievementScore)
+
+	patchAdvisor(t)
+	// tidak ada aturan rubrik untuk competition: poin default katalog yang dipakai
+	patchScoring(t, models.ScoringModeOverride, models.ScoringRule{ID: "rule-intl", AchievementType: "publication", Points: 100})
+	pD := bm.Patch(repository.GetAchievementTypeDefaultPoints,
+		func() (map[string]int, error) { return map[string]int{"competition": 30}, nil })
+	defer pD.Unpatch()
+
+	req := makeReq("GET", "/achievements/"+consistencyMongoID+"/score", nil)
+	req.Header.Set("user_id", "lecturer-user-1")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+
+	var out struct {
+		Data models.ScoreSuggestion `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	require.Equal(t, 30, *out.Data.SuggestedPoints)
+	require.Empty(t, out.Data.RuleID)
+	require.Equal(t, 24, *out.Data.MinPoints)
+	require.Equal(t, 36, *out.Data.MaxPoints)
+}
+
+func TestAdminScoringRules(t *testing.T) {
+	app := fiber.New()
+	app.Post("/admin/scoring-rules", service.AdminCreateScoringRule)
+	app.Put("/admin/scoring-policy", service.AdminUpdateScoringPolicy)
+
+	t.Run("Create_NormalizesCriteria", func(t *testing.T) {
+		var got models.ScoringRuleRequest
+		p := bm.Patch(repository.CreateScoringRule,
+			func(req models.ScoringRuleRequest) (*models.ScoringRule, error) {
+				got = req
+				return &models.ScoringRule{ID: "rule-1", AchievementType: req.AchievementType, Points: req.Points}, nil
+			})
+		defer p.Unpatch()
+
+		resp, err := app.Test(makeReq("POST", "/admin/scoring-rules", map[string]any{
+			"achievement_type": "competition", "competition_level": "  ", "participation": "Team", "points": 30,
+		}))
+		require.NoError(t, err)
+		require.Equal(t, 201, resp.StatusCode)
+		require.Nil(t, got.CompetitionLevel)
+		require.Equal(t, "team", *got.Participation)
+	})
+
+	t.Run("Create_Invalid", func(t *testing.T) {
+		for _, body := range []map[string]any{
+			{"achievement_type": "", "points": 10},
+			{"achievement_type": "competition", "points": 0},
+			{"achievement_type": "competition", "points": 10, "participation": "solo"},
+			{"achievement_type": "competition", "points": 10, "rank": 0},
+		} {
+			resp, err := app.Test(makeReq("POST", "/admin/scoring-rules", body))
+			require.NoError(t, err)
+			require.Equal(t, 400, resp.StatusCode, body)
+		}
+	})
+
+	t.Run("Create_Duplicate", func(t *testing.T) {
+		p := bm.Patch(repository.CreateScoringRule,
+			func(req models.ScoringRuleRequest) (*models.ScoringRule, error) {
+				return nil, repository.ErrScoringRuleExists
+			})
+		defer p.Unpatch()
+
+		resp, err := app.Test(makeReq("POST", "/admin/scoring-rules", map[string]any{"achievement_type": "competition", "points": 10}))
+		require.NoError(t, err)
+		require.Equal(t, 409, resp.StatusCode)
+	})
+
+	t.Run("Policy_InvalidMode", func(t *testing.T) {
+		resp, err := app.Test(makeReq("PUT", "/admin/scoring-policy", map[string]any{"mode": "free", "max_override_percent": 10}))
+		require.NoError(t, err)
+		require.Equal(t, 400, resp.StatusCode)
+	})
+}
+
+func TestCreateScoringRule_UniqueViolation(t *testing.T) {
+	db, mock := setupDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO scoring_rules`)).
+		WillReturnError(&pq.Error{Code: "23505"})
+
+	_, err := repository.CreateScoringRule(models.ScoringRuleRequest{AchievementType: "competition", Points: 10})
+	require.ErrorIs(t, err, repository.ErrScoringRuleExists)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListScoringRules(t *testing.T) {
+	db, mock := setupDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM scoring_rules`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "achievement_type", "competition_level", "rank", "participation", "points", "created_at", "updated_at"}).
+			AddRow("rule-1", "competition", "national", 1, nil, 50, now, now))
+
+	rules, err := repository.ListScoringRules()
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	require.Equal(t, "national", *rules[0].CompetitionLevel)
+	require.Equal(t, 1, *rules[0].Rank)
+	require.Nil(t, rules[0].Participation)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,253 @@
+package service_test
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/app/service"
+	"UAS_GO/helper"
+	"UAS_GO/middleware"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	bm "bou.ke/monkey"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorize_UserPermissionOverrides(t *testing.T) {
+	pV := bm.Patch(helper.ValidateToken, func(token string) (*models.JWTClaims, error) {
+		// token dari header memakai buffer fasthttp yang dipakai ulang; salin sebelum jadi key cache
+		token = strings.Clone(token)
+		claims := &models.JWTClaims{UserID: token, Role: "r-dosen"}
+		claims.ID = "jti-" + token
+		if token == "u-suspended" {
+			// token lama masih membawa permission yang sekarang dicabut
+			claims.Permissions = []string{"report:statistics"}
+		}
+		return claims, nil
+	})
+	defer pV.Unpatch()
+	pR := bm.Patch(repository.IsTokenRevoked, func(jti string) (bool, error) { return false, nil })
+	defer pR.Unpatch()
+	patchPasswordChangedAt(t, nil)
+	patchRoleLoader(t, map[string][]string{"r-dosen": {"report:statistics"}})
+	loads := patchUserOverrides(t, map[string][]models.UserPermission{
+		"u-suspended": {{Permission: "report:statistics", Effect: models.PermissionEffectDeny}},
+		"u-denied":    {{Permission: "report:statistics", Effect: models.PermissionEffectDeny}},
+		"u-granted":   {{Permission: "user:read", Effect: models.PermissionEffectGrant}},
+	})
+
+	app := fiber.New()
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }
+	app.Get("/stats", middleware.AuthRequired(), middleware.Authorize(middleware.Policy{Permission: "report:statistics"}), ok)
+	app.Get("/users", middleware.AuthRequired(), middleware.Authorize(middleware.Policy{Permission: "user:read"}), ok)
+
+	cases := []struct {
+		user, path string
+		want       int
+	}{
+		{"u-plain", "/stats", 200},     // dari role
+		{"u-denied", "/stats", 403},    // deny menang atas role
+		{"u-suspended", "/stats", 403}, // deny menang atas permission di token
+		{"u-granted", "/users", 200},   // grant tanpa permission role
+		{"u-plain", "/users", 403},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		req.Header.Set("Authorization", "Bearer "+tc.user)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, tc.want, resp.StatusCode, tc.user+" "+tc.path)
+	}
+	// override tiap user dimuat sekali lalu dipakai dari cache
+	require.Equal(t, 4, *loads)
+}
+
+func TestUserHasPermission_OverrideFirst(t *testing.T) {
+	db, mock := setupDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM user_permissions up`)).
+		WithArgs("u-1", "report:statistics").
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(false))
+
+	allowed, err := repository.UserHasPermission("u-1", "report:statistics")
+	require.NoError(t, err)
+	require.False(t, allowed)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// patchUserPermissionStore: u-dosen (role dosen_wali) dengan satu grant, u-admin (role admin),
+// u-dual (dosen_wali + admin sebagai role tambahan)
+func patchUserPermissionStore(t *testing.T) (*[]models.UserPermission, *[]models.RBACAuditEntry) {
+	users := map[string]models.User{
+		"u-dosen": {ID: "u-dosen", RoleID: "r-dosen"},
+		"u-admin": {ID: "u-admin", RoleID: "r-admin"},
+		"u-dual":  {ID: "u-dual", RoleID: "r-dosen"},
+	}
+	userRoles := map[string][]string{"u-dosen": {"r-dosen"}, "u-admin": {"r-admin"}, "u-dual": {"r-dosen", "r-admin"}}
+	roleNames := map[string]string{"r-dosen": "dosen_wali", "r-admin": "admin"}
+	known := map[string]bool{"achievement:read": true, "achievement:verify": true, "report:statistics": true}
+	overrides := &[]models.UserPermission{
+		{UserID: "u-dosen", Permission: "report:statistics", Effect: models.PermissionEffectGrant},
+	}
+
+	pU := bm.Patch(repository.GetUserByID, func(id string) (*models.User, error) {
+		u, ok := users[id]
+		if !ok {
+			return nil, errors.New("sql: no rows in result set")
+		}
+		return &u, nil
+	})
+	pR := bm.Patch(repository.GetUserRoleIDs, func(userID string) ([]string, error) {
+		return append([]string{}, userRoles[userID]...), nil
+	})
+	pN := bm.Patch(repository.GetRoleNameByID, func(roleID string) (string, error) { return roleNames[roleID], nil })
+	pP := bm.Patch(repository.GetPermissionsByRoleID, func(roleID string) ([]string, error) {
+		return []string{"achievement:verify", "achievement:read"}, nil
+	})
+	pO := bm.Patch(repository.GetUserPermissionOverrides, func(userID string) ([]models.UserPermission, error) {
+		out := []models.UserPermission{}
+		for _, o := range *overrides {
+			if o.UserID == userID {
+				out = append(out, o)
+			}
+		}
+		return out, nil
+	})
+	pS := bm.Patch(repository.SetUserPermission, func(userID, permission, effect string, reason, grantedBy *string) (*models.UserPermission, error) {
+		if !known[permission] {
+			return nil, repository.ErrPermissionNotFound
+		}
+		up := models.UserPermission{UserID: userID, Permission: permission, Effect: effect, Reason: reason, GrantedBy: grantedBy}
+		*overrides = append(*overrides, up)
+		return &up, nil
+	})
+	pD := bm.Patch(repository.DeleteUserPermission, func(userID, permission string) (bool, error) {
+		for i, o := range *overrides {
+			if o.UserID == userID && o.Permission == permission {
+				*overrides = append((*overrides)[:i:i], (*overrides)[i+1:]...)
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+
+	audit := &[]models.RBACAuditEntry{}
+	pA := bm.Patch(repository.InsertRBACAudit, func(e *models.RBACAuditEntry) error {
+		*audit = append(*audit, *e)
+		return nil
+	})
+	t.Cleanup(func() {
+		pU.Unpatch()
+		pR.Unpatch()
+		pN.Unpatch()
+		pP.Unpatch()
+		pO.Unpatch()
+		pS.Unpatch()
+		pD.Unpatch()
+		pA.Unpatch()
+	})
+	return overrides, audit
+}
+
+func TestAdminUserPermissions(t *testing.T) {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user_id", "u-root")
+		c.Locals("role", "admin")
+		return c.Next()
+	})
+	app.Get("/users/:id/permissions", service.AdminGetUserPermissions)
+	app.Put("/users/:id/permissions/:permission", service.AdminSetUserPermission)
+	app.Delete("/users/:id/permissions/:permission", service.AdminDeleteUserPermission)
+
+	t.Run("Get_Effective", func(t *testing.T) {
+		overrides, _ := patchUserPermissionStore(t)
+		*overrides = append(*overrides, models.UserPermission{UserID: "u-dosen", Permission: "achievement:verify", Effect: models.PermissionEffectDeny})
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/users/u-dosen/permissions", nil))
+		require.NoError(t, err)
+		require.Equal(t, 200, resp.StatusCode)
+
+		body, _ := io.ReadAll(resp.Body)
+		var out struct {
+			Data models.UserPermissionsView `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(body, &out))
+		require.Equal(t, "dosen_wali", out.Data.Role)
+		require.Equal(t, []string{"dosen_wali"}, out.Data.Roles)
+		require.Equal(t, []string{"achievement:read", "achievement:verify"}, out.Data.RolePermissions)
+		require.Len(t, out.Data.Overrides, 2)
+		require.Equal(t, []string{"achievement:read", "report:statistics"}, out.Data.Effective)
+	})
+
+	t.Run("Get_UserNotFound", func(t *testing.T) {
+		patchUserPermissionStore(t)
+		resp, err := app.Test(httptest.NewRequest("GET", "/users/u-x/permissions", nil))
+		require.NoError(t, err)
+		require.Equal(t, 404, resp.StatusCode)
+	})
+
+	t.Run("Set_Deny", func(t *testing.T) {
+		overrides, audit := patchUserPermissionStore(t)
+		resp, err := app.Test(makeReq("PUT", "/users/u-dosen/permissions/achievement:verify",
+			map[string]any{"effect": " Deny ", "reason": "sedang diskors"}))
+		require.NoError(t, err)
+		require.Equal(t, 200, resp.StatusCode)
+
+		last := (*overrides)[len(*overrides)-1]
+		require.Equal(t, models.PermissionEffectDeny, last.Effect)
+		require.Equal(t, "sedang diskors", *last.Reason)
+		require.Equal(t, "u-root", *last.GrantedBy)
+
+		require.Len(t, *audit, 1)
+		require.Equal(t, models.RBACAuditUserPermissionSet, (*audit)[0].Action)
+		require.Equal(t, "u-dosen", *(*audit)[0].TargetUserID)
+		require.Equal(t, "achievement:verify", *(*audit)[0].Permission)
+		require.Equal(t, models.PermissionEffectDeny, (*audit)[0].Changes["effect"].To)
+	})
+
+	t.Run("Set_Rejected", func(t *testing.T) {
+		_, audit := patchUserPermissionStore(t)
+		cases := []struct {
+			name, path string
+			body       map[string]any
+			want       int
+		}{
+			{"InvalidEffect", "/users/u-dosen/permissions/report:statistics", map[string]any{"effect": "allow"}, 400},
+			{"DenyAdmin", "/users/u-admin/permissions/report:statistics", map[string]any{"effect": "deny"}, 409},
+			{"DenySecondaryAdmin", "/users/u-dual/permissions/report:statistics", map[string]any{"effect": "deny"}, 409},
+			{"UnknownPermission", "/users/u-dosen/permissions/nope:nope", map[string]any{"effect": "grant"}, 404},
+			{"UnknownUser", "/users/u-x/permissions/report:statistics", map[string]any{"effect": "grant"}, 404},
+		}
+		for _, tc := range cases {
+			resp, err := app.Test(makeReq("PUT", tc.path, tc.body))
+			require.NoError(t, err)
+			require.Equal(t, tc.want, resp.StatusCode, tc.name)
+		}
+		require.Empty(t, *audit)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		overrides, audit := patchUserPermissionStore(t)
+
+		resp, err := app.Test(httptest.NewRequest("DELETE", "/users/u-dosen/permissions/report:statistics", nil))
+		require.NoError(t, err)
+		require.Equal(t, 200, resp.StatusCode)
+		require.Empty(t, *overrides)
+		require.Len(t, *audit, 1)
+		require.Equal(t, models.RBACAuditUserPermissionRemoved, (*audit)[0].Action)
+
+		resp, err = app.Test(httptest.NewRequest("DELETE", "/users/u-dosen/permissions/report:statistics", nil))
+		require.NoError(t, err)
+		require.Equal(t, 404, resp.StatusCode)
+		require.Len(t, *audit, 1)
+	})
+}
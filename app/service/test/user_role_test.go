@@ -0,0 +1,183 @@
+package service_test
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/app/service"
+	"UAS_GO/helper"
+	"UAS_GO/middleware"
+	"io"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	bm "bou.ke/monkey"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthRequired_MultipleRoles(t *testing.T) {
+	pV := bm.Patch(helper.ValidateToken, func(token string) (*models.JWTClaims, error) {
+		claims := &models.JWTClaims{UserID: "u-1", Role: "dosen_wali"}
+		claims.ID = "jti-1"
+		if token == "multi" {
+			// role tambahan yang sudah dihapus dilewati
+			claims.Roles = []string{"dosen_wali", "admin", "r-deleted"}
+		}
+		return claims, nil
+	})
+	defer pV.Unpatch()
+	pR := bm.Patch(repository.IsTokenRevoked, func(jti string) (bool, error) { return false, nil })
+	defer pR.Unpatch()
+	patchPasswordChangedAt(t, nil)
+	patchRoleLoader(t, map[string][]string{
+		"dosen_wali": {"achievement:verify"},
+		"admin":      {"user:read"},
+	})
+	patchUserOverrides(t, map[string][]models.UserPermission{})
+
+	app := fiber.New()
+	ok := func(c *fiber.Ctx) error {
+		return c.SendString(c.Locals("role").(string) + "|" + strings.Join(helper.GetRoles(c), ","))
+	}
+	app.Get("/verify", middleware.AuthRequired(), middleware.Authorize(middleware.Policy{Permission: "achievement:verify"}), ok)
+	app.Get("/users", middleware.AuthRequired(), middleware.Authorize(middleware.Policy{Permission: "user:read"}), ok)
+	app.Get("/maintenance", middleware.AuthRequired(), middleware.Authorize(middleware.Policy{Roles: []string{"admin"}}), ok)
+	app.Get("/roles", middleware.AuthRequired(), middleware.Authorize(middleware.Policy{Permission: "role:manage"}), ok)
+
+	cases := []struct {
+		token, path string
+		want        int
+	}{
+		{"multi", "/verify", 200},
+		{"multi", "/users", 200}, // permission dari role tambahan
+		{"multi", "/maintenance", 200},
+		{"multi", "/roles", 403},
+		{"single", "/verify", 200}, // token lama tanpa claim roles
+		{"single", "/users", 403},
+		{"single", "/maintenance", 403},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest("GET", tc.path, nil)
+		req.Header.Set("Authorization", "Bearer "+tc.token)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, tc.want, resp.StatusCode, tc.token+" "+tc.path)
+	}
+
+	req := httptest.NewRequest("GET", "/verify", nil)
+	req.Header.Set("Authorization", "Bearer multi")
+	resp, err := app.Test(req)
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	require.Equal(t, "dosen_wali|dosen_wali,admin", string(body))
+}
+
+func TestNextAchievementStatus_MultipleRoles(t *testing.T) {
+	next, err := service.NextAchievementStatus(service.AchievementActionVerify, "submitted", "admin", "dosen_wali")
+	require.NoError(t, err)
+	require.Equal(t, "verified", next)
+
+	_, err = service.NextAchievementStatus(service.AchievementActionVerify, "submitted", "admin")
+	te, ok := err.(*service.TransitionError)
+	require.True(t, ok)
+	require.True(t, te.RoleDenied)
+
+	require.Equal(t, []string{"verified", "rejected"}, service.AllowedNextStatuses("submitted", "mahasiswa", "dosen_wali"))
+}
+
+func TestUpdateUserRoles(t *testing.T) {
+	lockAdmins := regexp.QuoteMeta(`FOR UPDATE OF u`)
+	userRow := []string{"id", "email", "role_id", "is_active", "created_at", "updated_at"}
+
+	t.Run("AddRole", func(t *testing.T) {
+		db, mock := setupDB(t)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockAdmins).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("admin-1"))
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE users SET updated_at`)).WithArgs("u-1").
+			WillReturnRows(sqlmock.NewRows(userRow).AddRow("u-1", "d@example.com", "r-dosen", true, time.Now(), time.Now()))
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO user_roles`)).WithArgs("u-1", sqlmock.AnyArg()).
+			WillReturnRows(sqlmock.NewRows([]string{"role_id"}).AddRow("r-admin"))
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT COUNT(*) FROM users u WHERE`)).
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+		mock.ExpectQuery(regexp.QuoteMeta(`FROM user_roles ur`)).WithArgs("u-1").
+			WillReturnRows(sqlmock.NewRows([]string{"role_id"}).AddRow("r-dosen").AddRow("r-admin"))
+		mock.ExpectCommit()
+
+		change, err := repository.UpdateUserRoles("u-1", "", []string{"r-admin", "r-dosen"}, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"r-admin"}, change.Added)
+		require.Empty(t, change.Removed)
+		require.Equal(t, "r-dosen", change.User.RoleID)
+		require.Equal(t, []string{"r-dosen", "r-admin"}, change.User.RoleIDs)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("RemovePrimaryRole", func(t *testing.T) {
+		db, mock := setupDB(t)
+		defer db.Close()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockAdmins).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectQuery(regexp.QuoteMeta(`UPDATE users SET updated_at`)).WithArgs("u-1").
+			WillReturnRows(sqlmock.NewRows(userRow).AddRow("u-1", "d@example.com", "r-dosen", true, time.Now(), time.Now()))
+		mock.ExpectRollback()
+
+		_, err := repository.UpdateUserRoles("u-1", "", nil, []string{"r-dosen"})
+		require.ErrorIs(t, err, repository.ErrPrimaryRoleRemoval)
+		require.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestAdminUpdateUserRole_AddRemove(t *testing.T) {
+	var audit []models.RBACAuditEntry
+	pA := bm.Patch(repository.InsertRBACAudit, func(e *models.RBACAuditEntry) error {
+		audit = append(audit, *e)
+		return nil
+	})
+	defer pA.Unpatch()
+
+	var result error
+	var got []string
+	pU := bm.Patch(repository.UpdateUserRoles, func(id, roleID string, add, remove []string) (*repository.UserRolesChange, error) {
+		got = append([]string{id, roleID}, append(append([]string{}, add...), remove...)...)
+		if result != nil {
+			return nil, result
+		}
+		user := &models.User{ID: id, RoleID: "r-dosen", RoleIDs: []string{"r-dosen", "r-admin"}}
+		return &repository.UserRolesChange{User: user, Added: []string{"r-admin"}, Removed: []string{"r-old"}}, nil
+	})
+	defer pU.Unpatch()
+
+	app := fiber.New()
+	app.Put("/users/:id/role", service.AdminUpdateUserRole)
+	body := map[string]any{"add_role_ids": []string{"r-admin"}, "remove_role_ids": []string{"r-old"}}
+
+	resp, err := app.Test(makeReq("PUT", "/users/u-1/role", body))
+	require.NoError(t, err)
+	require.Equal(t, 200, resp.StatusCode)
+	require.Equal(t, []string{"u-1", "", "r-admin", "r-old"}, got)
+	require.Len(t, audit, 2)
+	require.Equal(t, models.RBACAuditUserRoleAdded, audit[0].Action)
+	require.Equal(t, "r-admin", *audit[0].RoleID)
+	require.Equal(t, models.RBACAuditUserRoleRemoved, audit[1].Action)
+	require.Equal(t, "r-old", *audit[1].RoleID)
+	require.Equal(t, "u-1", *audit[1].TargetUserID)
+
+	for err, want := range map[error]int{
+		repository.ErrPrimaryRoleRemoval: 400,
+		repository.ErrRoleNotFound:       400,
+		repository.ErrLastAdmin:          409,
+	} {
+		result = err
+		resp, e := app.Test(makeReq("PUT", "/users/u-1/role", body))
+		require.NoError(t, e)
+		require.Equal(t, want, resp.StatusCode, err.Error())
+	}
+	require.Len(t, audit, 2)
+}
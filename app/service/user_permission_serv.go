@@ -0,0 +1,188 @@
+package service
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/helper"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// effectivePermissions: permission role ditambah grant user, dikurangi deny user (deny menang).
+func effectivePermissions(rolePerms []string, overrides []models.UserPermission) []string {
+	set := make(map[string]bool, len(rolePerms))
+	for _, p := range rolePerms {
+		set[p] = true
+	}
+	for _, o := range overrides {
+		set[o.Permission] = o.Effect == models.PermissionEffectGrant
+	}
+
+	out := []string{}
+	for p, ok := range set {
+		if ok {
+			out = append(out, p)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func userPermissionErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrUserNotFound), errors.Is(err, repository.ErrPermissionNotFound):
+		return helper.NotFound(c, err.Error())
+	}
+	return helper.InternalError(c, err.Error())
+}
+
+// AdminGetUserPermissions godoc
+// @Summary      Get user permissions (admin)
+// @Description  Permission semua role user, override per user (grant / deny), dan permission efektifnya. Urutan resolusi: deny user > grant user > role.
+// @Tags         Admin - Users
+// @Produce      json
+// @Param        id   path   string  true  "User ID (UUID)"
+// @Security     BearerAuth
+// @Success      200  {object}  models.UserPermissionsView
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (not admin)"
+// @Failure      404  {object}  map[string]interface{}  "User not found"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /users/{id}/permissions [get]
+func AdminGetUserPermissions(c *fiber.Ctx) error {
+	user, err := repository.GetUserByID(c.Params("id"))
+	if err != nil {
+		return helper.NotFound(c, "user not found")
+	}
+
+	roleIDs, err := repository.GetUserRoleIDs(user.ID)
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	view := models.UserPermissionsView{UserID: user.ID, RoleID: user.RoleID, Roles: []string{}}
+	for _, roleID := range roleIDs {
+		name, err := repository.GetRoleNameByID(roleID)
+		if err != nil {
+			return helper.InternalError(c, err.Error())
+		}
+		if roleID == user.RoleID {
+			view.Role = name
+		}
+		view.Roles = append(view.Roles, name)
+	}
+	if view.RolePermissions, err = rolesPermissions(roleIDs); err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	sort.Strings(view.RolePermissions)
+
+	if view.Overrides, err = repository.GetUserPermissionOverrides(user.ID); err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	view.Effective = effectivePermissions(view.RolePermissions, view.Overrides)
+	return helper.APIResponse(c, fiber.StatusOK, "user permissions retrieved", view)
+}
+
+// AdminSetUserPermission godoc
+// @Summary      Grant / deny permission for user (admin)
+// @Description  Membuat atau mengganti override satu permission untuk user, mis. memberi satu dosen report:statistics atau mencabut achievement:create dari mahasiswa yang diskors. Deny tidak bisa diberikan ke user ber-role admin.
+// @Tags         Admin - Users
+// @Accept       json
+// @Produce      json
+// @Param        id          path   string                        true  "User ID (UUID)"
+// @Param        permission  path   string                        true  "Nama permission, mis. report:statistics"
+// @Param        body        body   models.UserPermissionRequest  true  "Effect (grant / deny) dan alasan"
+// @Security     BearerAuth
+// @Success      200  {object}  models.UserPermission
+// @Failure      400  {object}  map[string]interface{}  "Effect tidak valid"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (not admin)"
+// @Failure      404  {object}  map[string]interface{}  "User / permission not found"
+// @Failure      409  {object}  map[string]interface{}  "Deny untuk admin"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /users/{id}/permissions/{permission} [put]
+func AdminSetUserPermission(c *fiber.Ctx) error {
+	var req models.UserPermissionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return helper.BadRequest(c, "Invalid request body")
+	}
+	req.Effect = strings.ToLower(strings.TrimSpace(req.Effect))
+	if req.Effect != models.PermissionEffectGrant && req.Effect != models.PermissionEffectDeny {
+		return helper.BadRequest(c, `effect must be "grant" or "deny"`)
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	permission := c.Params("permission")
+
+	user, err := repository.GetUserByID(c.Params("id"))
+	if err != nil {
+		return helper.NotFound(c, "user not found")
+	}
+	if req.Effect == models.PermissionEffectDeny {
+		roleIDs, err := repository.GetUserRoleIDs(user.ID)
+		if err != nil {
+			return helper.InternalError(c, err.Error())
+		}
+		for _, roleID := range roleIDs {
+			role, err := repository.GetRoleNameByID(roleID)
+			if err != nil {
+				return helper.InternalError(c, err.Error())
+			}
+			if role == adminRoleName {
+				return helper.Conflict(c, "permissions cannot be denied to admin users")
+			}
+		}
+	}
+
+	up, err := repository.SetUserPermission(user.ID, permission, req.Effect,
+		optionalString(req.Reason), optionalString(helper.GetUserID(c)))
+	if err != nil {
+		return userPermissionErrorResponse(c, err)
+	}
+
+	changes := map[string]models.FieldChange{"effect": {To: req.Effect}}
+	if req.Reason != "" {
+		changes["reason"] = models.FieldChange{To: req.Reason}
+	}
+	recordRBACAudit(c, "user:assign-permission", models.RBACAuditEntry{
+		Action:       models.RBACAuditUserPermissionSet,
+		Permission:   optionalString(permission),
+		TargetUserID: optionalString(user.ID),
+		Changes:      changes,
+	})
+	return helper.APIResponse(c, fiber.StatusOK, "user permission saved", up)
+}
+
+// AdminDeleteUserPermission godoc
+// @Summary      Remove user permission override (admin)
+// @Description  Menghapus override sehingga permission user kembali mengikuti role-nya
+// @Tags         Admin - Users
+// @Produce      json
+// @Param        id          path   string  true  "User ID (UUID)"
+// @Param        permission  path   string  true  "Nama permission"
+// @Security     BearerAuth
+// @Success      200  {object}  map[string]interface{}  "envelope {status,message,data:null}"
+// @Failure      401  {object}  map[string]interface{}  "Unauthorized"
+// @Failure      403  {object}  map[string]interface{}  "Forbidden (not admin)"
+// @Failure      404  {object}  map[string]interface{}  "Override not found"
+// @Failure      500  {object}  map[string]interface{}  "error response"
+// @Router       /users/{id}/permissions/{permission} [delete]
+func AdminDeleteUserPermission(c *fiber.Ctx) error {
+	userID, permission := c.Params("id"), c.Params("permission")
+
+	deleted, err := repository.DeleteUserPermission(userID, permission)
+	if err != nil {
+		return helper.InternalError(c, err.Error())
+	}
+	if !deleted {
+		return helper.NotFound(c, "user has no override for permission "+permission)
+	}
+
+	recordRBACAudit(c, "user:assign-permission", models.RBACAuditEntry{
+		Action:       models.RBACAuditUserPermissionRemoved,
+		Permission:   optionalString(permission),
+		TargetUserID: optionalString(userID),
+	})
+	return helper.APIResponse(c, fiber.StatusOK, "user permission override removed", nil)
+}
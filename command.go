@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"UAS_GO/app/service"
+	"UAS_GO/config"
+	"UAS_GO/database"
+)
+
+const commandUsage = `Penggunaan:
+  go run . migrate up                 jalankan semua migrasi yang belum di-apply
+  go run . migrate down [N]           rollback N migrasi terakhir (default 1)
+  go run . migrate status             tampilkan status migrasi
+  go run . migrate baseline <versi>   tandai migrasi s/d <versi> sebagai applied (database lama)
+  go run . mongo bootstrap            pasang index & validator achievements di MongoDB
+  go run . mongo report [N]           tampilkan dokumen achievements yang melanggar schema (default 100)
+  go run . reconcile [--apply]        bandingkan MongoDB dengan achievement_references (tanpa --apply: dry run)
+  go run . types remap [--apply] [lama=kode ...]
+                                      ganti achievementType lama di MongoDB dengan kode katalog (tanpa --apply: dry run)`
+
+// runCommand menjalankan subcommand CLI (mis. "migrate up") lalu keluar.
+func runCommand(args []string) {
+	switch args[0] {
+	case "migrate":
+		runMigrateCommand(args[1:])
+	case "mongo":
+		runMongoCommand(args[1:])
+	case "reconcile":
+		runReconcileCommand(args[1:])
+	case "types":
+		runTypesCommand(args[1:])
+	default:
+		fmt.Println(commandUsage)
+		os.Exit(2)
+	}
+}
+
+func runMigrateCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Println(commandUsage)
+		os.Exit(2)
+	}
+
+	config.LoadEnv()
+	database.ConnectPostgres()
+
+	switch args[0] {
+	case "up":
+		done, err := database.MigrateUp(database.PSQL)
+		printMigrations("applied", done)
+		if err != nil {
+			log.Fatalf(" Migrasi gagal: %v", err)
+		}
+		if len(done) == 0 {
+			fmt.Println("Skema sudah up to date.")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil {
+				log.Fatalf(" Jumlah step tidak valid: %s", args[1])
+			}
+			steps = n
+		}
+		done, err := database.MigrateDown(database.PSQL, steps)
+		printMigrations("rolled back", done)
+		if err != nil {
+			log.Fatalf(" Rollback gagal: %v", err)
+		}
+
+	case "status":
+		statuses, err := database.MigrationStatuses(database.PSQL)
+		if err != nil {
+			log.Fatalf(" Gagal membaca status migrasi: %v", err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if s.Modified {
+				state += " (MODIFIED)"
+			}
+			fmt.Printf("%04d  %-30s %s\n", s.Version, s.Name, state)
+		}
+
+	case "baseline":
+		if len(args) < 2 {
+			log.Fatal(" Versi baseline wajib diisi")
+		}
+		version, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			log.Fatalf(" Versi tidak valid: %s", args[1])
+		}
+		done, err := database.MigrateBaseline(database.PSQL, version)
+		printMigrations("baselined", done)
+		if err != nil {
+			log.Fatalf(" Baseline gagal: %v", err)
+		}
+
+	default:
+		fmt.Println(commandUsage)
+		os.Exit(2)
+	}
+}
+
+func runMongoCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Println(commandUsage)
+		os.Exit(2)
+	}
+
+	config.LoadEnv()
+	database.ConnectMongoDB()
+
+	switch args[0] {
+	case "bootstrap":
+		report, err := database.BootstrapMongo(database.MongoDB)
+		if err != nil {
+			log.Fatalf(" Bootstrap MongoDB gagal: %v", err)
+		}
+		fmt.Printf("index: %v\n", report.Indexes)
+		fmt.Printf("validator: $jsonSchema (action=%s)\n", report.ValidatorAction)
+		printInvalidAchievements(report.InvalidCount, report.InvalidSamples)
+
+	case "report":
+		limit := int64(100)
+		if len(args) > 1 {
+			n, err := strconv.ParseInt(args[1], 10, 64)
+			if err != nil || n < 1 {
+				log.Fatalf(" Limit tidak valid: %s", args[1])
+			}
+			limit = n
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		count, samples, err := database.FindInvalidAchievements(ctx, database.MongoDB, limit)
+		if err != nil {
+			log.Fatalf(" Gagal memeriksa dokumen: %v", err)
+		}
+		printInvalidAchievements(count, samples)
+
+	default:
+		fmt.Println(commandUsage)
+		os.Exit(2)
+	}
+}
+
+func runReconcileCommand(args []string) {
+	dryRun := true
+	for _, a := range args {
+		if a != "--apply" {
+			fmt.Println(commandUsage)
+			os.Exit(2)
+		}
+		dryRun = false
+	}
+
+	config.LoadEnv()
+	database.ConnectPostgres()
+	database.ConnectMongoDB()
+
+	report, err := service.ReconcileAchievements(dryRun)
+	if err != nil {
+		log.Fatalf(" Reconcile gagal: %v", err)
+	}
+
+	fmt.Printf("dipindai: %d dokumen MongoDB, %d reference (%d prestasi baru dilewati)\n",
+		report.ScannedMongo, report.ScannedReferences, report.SkippedRecent)
+	for _, issue := range report.Issues {
+		state := "dry-run"
+		if issue.Error != "" {
+			state = "GAGAL: " + issue.Error
+		} else if issue.Repaired {
+			state = "diperbaiki"
+		} else if issue.Stale {
+			state = "sudah tidak ada saat dicek ulang"
+		} else if !dryRun {
+			state = "dilewati"
+		}
+		fmt.Printf("  %-26s mongo=%s ref=%s (mongo=%q ref=%q) -> %s [%s]\n",
+			issue.Category, issue.MongoID, issue.ReferenceID, issue.MongoStatus, issue.ReferenceStatus, issue.Action, state)
+	}
+	categories := make([]string, 0, len(report.Counts))
+	for category := range report.Counts {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		fmt.Printf("%s: %d\n", category, report.Counts[category])
+	}
+	if len(report.Issues) == 0 {
+		fmt.Println("Tidak ada selisih.")
+	}
+}
+
+func runTypesCommand(args []string) {
+	if len(args) == 0 || args[0] != "remap" {
+		fmt.Println(commandUsage)
+		os.Exit(2)
+	}
+
+	dryRun := true
+	mappings := map[string]string{}
+	for _, a := range args[1:] {
+		if a == "--apply" {
+			dryRun = false
+			continue
+		}
+		from, to, ok := strings.Cut(a, "=")
+		if !ok || strings.TrimSpace(to) == "" {
+			fmt.Println(commandUsage)
+			os.Exit(2)
+		}
+		mappings[from] = strings.TrimSpace(to)
+	}
+
+	config.LoadEnv()
+	database.ConnectPostgres()
+	database.ConnectMongoDB()
+
+	report, err := service.RemapAchievementTypes(mappings, dryRun)
+	if err != nil {
+		log.Fatalf(" Remap gagal: %v", err)
+	}
+
+	state := "diubah"
+	if dryRun {
+		state = "dry-run"
+	}
+	for _, e := range report.Remapped {
+		fmt.Printf("  %-20q -> %-16s %6d dokumen (%s) [%s]\n", e.From, e.To, e.Count, e.Source, state)
+	}
+	for _, e := range report.Unmapped {
+		fmt.Printf("  %-20q -> ?                %6d dokumen (tidak dikenali, tambahkan lama=kode)\n", e.From, e.Count)
+	}
+	if len(report.Remapped) == 0 && len(report.Unmapped) == 0 {
+		fmt.Println("Semua achievementType sudah sesuai katalog.")
+	}
+}
+
+func printInvalidAchievements(count int64, samples []database.InvalidAchievement) {
+	fmt.Printf("%d dokumen tidak sesuai schema\n", count)
+	for _, d := range samples {
+		fmt.Printf("  %s  studentId=%v  title=%v\n", d.ID.Hex(), d.StudentID, d.Title)
+	}
+}
+
+func printMigrations(action string, migrations []database.Migration) {
+	for _, m := range migrations {
+		fmt.Printf("%s %04d_%s\n", action, m.Version, m.Name)
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -21,3 +22,13 @@ func GetEnv(key, fallback string) string {
 	}
 	return val
 }
+
+// IsDevelopment: APP_ENV=development (atau dev / local). Selain itu dianggap produksi,
+// sehingga default yang hanya aman untuk lokal (secret JWT development, mailer log) ditolak.
+func IsDevelopment() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))) {
+	case "development", "dev", "local":
+		return true
+	}
+	return false
+}
@@ -31,11 +31,11 @@ func MigrateTesting(DB *sql.DB) {
 	dosenRoleID := uuid.New().String()
 
 	_, err := DB.Exec(`
-        INSERT INTO roles (id, name, description)
+        INSERT INTO roles (id, name, description, is_system)
         VALUES 
-        ($1, 'admin', 'Administrator Sistem'),
-        ($2, 'mahasiswa', 'User Mahasiswa'),
-        ($3, 'dosen_wali', 'Dosen Pembimbing Akademik')
+        ($1, 'admin', 'Administrator Sistem', TRUE),
+        ($2, 'mahasiswa', 'User Mahasiswa', TRUE),
+        ($3, 'dosen_wali', 'Dosen Pembimbing Akademik', TRUE)
     `, adminRoleID, mhsRoleID, dosenRoleID)
 
 	if err != nil {
@@ -58,6 +58,11 @@ func MigrateTesting(DB *sql.DB) {
 		{"user:delete", "user", "delete", "Delete user"},
 		{"user:assign-role", "user", "assign-role", "Assign role to user"},
 		{"user:manage", "user", "manage", "Full user management"},
+		{"user:assign-permission", "user", "assign-permission", "Grant or deny individual permissions to a user"},
+
+		// ROLES
+		{"role:read", "role", "read", "View roles, permissions and the RBAC audit log"},
+		{"role:manage", "role", "manage", "Create, update and delete roles; grant and revoke permissions"},
 
 		// ACHIEVEMENTS
 		{"achievement:create", "achievement", "create", "Create achievement"},
@@ -80,6 +85,11 @@ func MigrateTesting(DB *sql.DB) {
 		// REPORTS
 		{"report:statistics", "report", "statistics", "View achievement statistics"},
 		{"report:student", "report", "student", "View student-specific report"},
+
+		// MAINTENANCE (/admin/*)
+		{"maintenance:reconcile", "maintenance", "reconcile", "Run the PostgreSQL / MongoDB consistency check and repair"},
+		{"scoring:manage", "scoring", "manage", "Manage scoring rules and the scoring policy"},
+		{"achievement-type:manage", "achievement-type", "manage", "Manage achievement types, their schemas and type remapping"},
 	}
 
 	// map nama permission -> id (agar bisa diassign ke role)
@@ -0,0 +1,73 @@
+-- Skema inti: RBAC, user, mahasiswa, dosen, dan referensi prestasi.
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TABLE roles (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name        VARCHAR(50) NOT NULL UNIQUE,
+    description TEXT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE permissions (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name        VARCHAR(100) NOT NULL UNIQUE,
+    resource    VARCHAR(50) NOT NULL,
+    action      VARCHAR(50) NOT NULL,
+    description TEXT
+);
+
+CREATE TABLE role_permissions (
+    role_id       UUID NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    permission_id UUID NOT NULL REFERENCES permissions (id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE users (
+    id            UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    username      VARCHAR(50) NOT NULL UNIQUE,
+    email         VARCHAR(100) NOT NULL UNIQUE,
+    password_hash VARCHAR(255) NOT NULL,
+    full_name     VARCHAR(100) NOT NULL,
+    role_id       UUID REFERENCES roles (id),
+    is_active     BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE lecturers (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id     UUID NOT NULL UNIQUE REFERENCES users (id),
+    lecturer_id VARCHAR(20) NOT NULL UNIQUE,
+    department  VARCHAR(100),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE students (
+    id            UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id       UUID NOT NULL UNIQUE REFERENCES users (id),
+    student_id    VARCHAR(20) NOT NULL UNIQUE,
+    program_study VARCHAR(100),
+    academic_year VARCHAR(10),
+    advisor_id    UUID REFERENCES lecturers (id),
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_students_advisor_id ON students (advisor_id);
+
+CREATE TABLE achievement_references (
+    id                   UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    student_id           UUID NOT NULL REFERENCES students (id),
+    mongo_achievement_id VARCHAR(24) NOT NULL UNIQUE,
+    status               VARCHAR(20) NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'submitted', 'verified', 'rejected', 'deleted')),
+    submitted_at         TIMESTAMPTZ,
+    verified_at          TIMESTAMPTZ,
+    verified_by          UUID REFERENCES users (id),
+    rejection_note       TEXT,
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at           TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    deleted_at           TIMESTAMPTZ
+);
+
+CREATE INDEX idx_achievement_references_student_id ON achievement_references (student_id);
+CREATE INDEX idx_achievement_references_status ON achievement_references (status);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS achievement_events;
+DROP FUNCTION IF EXISTS achievement_events_append_only();
@@ -0,0 +1,47 @@
+-- Log audit per prestasi (append-only): setiap create/update/attachment/submit/verify/reject/delete.
+-- Tidak ada FK ke users / achievement_references agar riwayat tetap ada walau data asal dihapus.
+CREATE TABLE achievement_events (
+    id                   BIGSERIAL PRIMARY KEY,
+    mongo_achievement_id VARCHAR(24) NOT NULL,
+    event_type           VARCHAR(32) NOT NULL,
+    actor_user_id        UUID,
+    actor_role           VARCHAR(50),
+    from_status          VARCHAR(20),
+    to_status            VARCHAR(20),
+    changes              JSONB,
+    note                 TEXT,
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_achievement_events_mongo_id ON achievement_events (mongo_achievement_id, id);
+
+CREATE FUNCTION achievement_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'achievement_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER achievement_events_append_only
+    BEFORE UPDATE OR DELETE ON achievement_events
+    FOR EACH ROW EXECUTE FUNCTION achievement_events_append_only();
+
+-- Riwayat lama dibangun ulang dari kolom achievement_references (tanpa actor untuk create/submit).
+INSERT INTO achievement_events (mongo_achievement_id, event_type, to_status, note, created_at)
+SELECT mongo_achievement_id, 'created', 'draft', 'backfill', created_at
+FROM achievement_references;
+
+INSERT INTO achievement_events (mongo_achievement_id, event_type, from_status, to_status, note, created_at)
+SELECT mongo_achievement_id, 'submitted', 'draft', 'submitted', 'backfill', submitted_at
+FROM achievement_references
+WHERE submitted_at IS NOT NULL;
+
+INSERT INTO achievement_events (mongo_achievement_id, event_type, actor_user_id, from_status, to_status, note, created_at)
+SELECT mongo_achievement_id, status, verified_by, 'submitted', status,
+       COALESCE(rejection_note, 'backfill'), verified_at
+FROM achievement_references
+WHERE status IN ('verified', 'rejected') AND verified_at IS NOT NULL;
+
+INSERT INTO achievement_events (mongo_achievement_id, event_type, from_status, to_status, note, created_at)
+SELECT mongo_achievement_id, 'deleted', 'draft', 'deleted', 'backfill', COALESCE(deleted_at, updated_at)
+FROM achievement_references
+WHERE status = 'deleted';
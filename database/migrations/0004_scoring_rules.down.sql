@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS scoring_policy;
+DROP TABLE IF EXISTS scoring_rules;
@@ -0,0 +1,31 @@
+-- Rubrik poin prestasi. Kolom kriteria yang NULL berarti "semua nilai";
+-- saat penilaian dipakai aturan yang paling spesifik (kriteria terisi paling banyak).
+CREATE TABLE scoring_rules (
+    id                UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    achievement_type  VARCHAR(50) NOT NULL,
+    competition_level VARCHAR(50),
+    rank              INT CHECK (rank > 0),
+    participation     VARCHAR(20) CHECK (participation IN ('individual', 'team')),
+    points            INT NOT NULL CHECK (points > 0),
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_scoring_rules_criteria ON scoring_rules (
+    achievement_type,
+    COALESCE(competition_level, ''),
+    COALESCE(rank, 0),
+    COALESCE(participation, '')
+);
+
+-- Kebijakan penerapan rubrik (satu baris):
+--   enforce  = poin harus sama dengan rubrik
+--   override = dosen boleh menyimpang maks. max_override_percent dengan justifikasi
+CREATE TABLE scoring_policy (
+    id                   BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+    mode                 VARCHAR(20) NOT NULL DEFAULT 'override' CHECK (mode IN ('enforce', 'override')),
+    max_override_percent INT NOT NULL DEFAULT 20 CHECK (max_override_percent BETWEEN 0 AND 100),
+    updated_at           TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO scoring_policy DEFAULT VALUES;
@@ -0,0 +1,53 @@
+-- JSON Schema untuk field details per achievementType (subset yang didukung helper.ValidateJSONSchema).
+-- Tipe tanpa schema tidak divalidasi.
+CREATE TABLE achievement_type_schemas (
+    achievement_type VARCHAR(50) PRIMARY KEY,
+    schema           JSONB NOT NULL,
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO achievement_type_schemas (achievement_type, schema) VALUES
+('competition', '{
+    "type": "object",
+    "required": ["competitionName", "competitionLevel"],
+    "properties": {
+        "competitionName":  {"type": "string", "minLength": 1, "title": "Nama kompetisi"},
+        "competitionLevel": {"type": "string", "enum": ["international", "national", "regional", "local"], "title": "Tingkat"},
+        "rank":             {"type": "integer", "minimum": 1, "title": "Peringkat"},
+        "participation":    {"type": "string", "enum": ["individual", "team"], "title": "Individu / tim"},
+        "organizer":        {"type": "string", "title": "Penyelenggara"},
+        "eventDate":        {"type": "string", "format": "date", "title": "Tanggal"}
+    }
+}'),
+('publication', '{
+    "type": "object",
+    "required": ["publicationType", "publicationTitle", "publisher"],
+    "properties": {
+        "publicationType":  {"type": "string", "enum": ["journal", "conference", "book"], "title": "Jenis publikasi"},
+        "publicationTitle": {"type": "string", "minLength": 1, "title": "Judul publikasi"},
+        "authors":          {"type": "array", "items": {"type": "string"}, "minItems": 1, "title": "Penulis"},
+        "publisher":        {"type": "string", "minLength": 1, "title": "Penerbit"},
+        "issn":             {"type": "string", "pattern": "^[0-9]{4}-[0-9]{3}[0-9Xx]$", "title": "ISSN"},
+        "publishedAt":      {"type": "string", "format": "date", "title": "Tanggal terbit"}
+    }
+}'),
+('certification', '{
+    "type": "object",
+    "required": ["certificationName", "issuedBy"],
+    "properties": {
+        "certificationName":   {"type": "string", "minLength": 1, "title": "Nama sertifikasi"},
+        "issuedBy":            {"type": "string", "minLength": 1, "title": "Penerbit sertifikat"},
+        "certificationNumber": {"type": "string", "title": "Nomor sertifikat"},
+        "validUntil":          {"type": "string", "format": "date", "title": "Berlaku sampai"}
+    }
+}'),
+('organization', '{
+    "type": "object",
+    "required": ["organizationName", "position"],
+    "properties": {
+        "organizationName": {"type": "string", "minLength": 1, "title": "Nama organisasi"},
+        "position":         {"type": "string", "minLength": 1, "title": "Jabatan"},
+        "periodStart":      {"type": "string", "format": "date", "title": "Mulai"},
+        "periodEnd":        {"type": "string", "format": "date", "title": "Selesai"}
+    }
+}');
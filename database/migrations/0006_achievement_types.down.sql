@@ -0,0 +1,3 @@
+ALTER TABLE scoring_rules DROP CONSTRAINT IF EXISTS scoring_rules_type_fkey;
+ALTER TABLE achievement_type_schemas DROP CONSTRAINT IF EXISTS achievement_type_schemas_type_fkey;
+DROP TABLE IF EXISTS achievement_types;
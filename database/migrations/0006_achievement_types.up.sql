@@ -0,0 +1,31 @@
+-- Katalog achievementType: kode baku, label per bahasa, status aktif, poin default.
+-- Dokumen Mongo menyimpan kode ini di field achievementType.
+CREATE TABLE achievement_types (
+    code           VARCHAR(50) PRIMARY KEY,
+    labels         JSONB NOT NULL DEFAULT '{}',
+    is_active      BOOLEAN NOT NULL DEFAULT TRUE,
+    default_points INT CHECK (default_points > 0),
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO achievement_types (code, labels) VALUES
+('competition',   '{"id": "Kompetisi",   "en": "Competition"}'),
+('publication',   '{"id": "Publikasi",   "en": "Publication"}'),
+('certification', '{"id": "Sertifikasi", "en": "Certification"}'),
+('organization',  '{"id": "Organisasi",  "en": "Organization"}');
+
+-- tipe yang sudah dipakai schema / rubrik ikut masuk katalog agar foreign key bisa dipasang
+INSERT INTO achievement_types (code, labels)
+SELECT achievement_type, jsonb_build_object('en', achievement_type) FROM achievement_type_schemas
+UNION
+SELECT achievement_type, jsonb_build_object('en', achievement_type) FROM scoring_rules
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE achievement_type_schemas
+    ADD CONSTRAINT achievement_type_schemas_type_fkey
+    FOREIGN KEY (achievement_type) REFERENCES achievement_types (code) ON DELETE CASCADE;
+
+ALTER TABLE scoring_rules
+    ADD CONSTRAINT scoring_rules_type_fkey
+    FOREIGN KEY (achievement_type) REFERENCES achievement_types (code) ON DELETE CASCADE;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS rbac_audit_log;
+DROP FUNCTION IF EXISTS rbac_audit_log_append_only();
+DELETE FROM permissions WHERE name IN ('role:read', 'role:manage');
+ALTER TABLE roles DROP COLUMN IF EXISTS is_system;
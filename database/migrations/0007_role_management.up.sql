@@ -0,0 +1,42 @@
+-- Manajemen role & permission lewat API.
+-- Role bawaan ditandai is_system: namanya dipakai di kode sehingga tidak boleh diganti / dihapus.
+ALTER TABLE roles ADD COLUMN is_system BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE roles SET is_system = TRUE WHERE name IN ('admin', 'mahasiswa', 'dosen_wali');
+
+INSERT INTO permissions (name, resource, action, description) VALUES
+('role:read',   'role', 'read',   'View roles, permissions and the RBAC audit log'),
+('role:manage', 'role', 'manage', 'Create, update and delete roles; grant and revoke permissions')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'admin' AND p.name IN ('role:read', 'role:manage')
+ON CONFLICT DO NOTHING;
+
+-- Log audit perubahan RBAC (append-only). Tanpa FK agar riwayat tetap ada walau role / user dihapus.
+CREATE TABLE rbac_audit_log (
+    id             BIGSERIAL PRIMARY KEY,
+    action         VARCHAR(40) NOT NULL,
+    role_id        UUID,
+    role_name      VARCHAR(50),
+    permission     VARCHAR(100),
+    target_user_id UUID,
+    actor_user_id  UUID,
+    actor_role     VARCHAR(50),
+    changes        JSONB,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_rbac_audit_log_role_id ON rbac_audit_log (role_id, id);
+
+CREATE FUNCTION rbac_audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'rbac_audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER rbac_audit_log_append_only
+    BEFORE UPDATE OR DELETE ON rbac_audit_log
+    FOR EACH ROW EXECUTE FUNCTION rbac_audit_log_append_only();
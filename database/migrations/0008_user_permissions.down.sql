@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_permissions;
+DELETE FROM permissions WHERE name = 'user:assign-permission';
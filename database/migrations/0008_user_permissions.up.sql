@@ -0,0 +1,22 @@
+-- Override permission per user di atas permission role.
+-- Urutan resolusi: deny > grant user > permission role.
+CREATE TABLE user_permissions (
+    user_id       UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    permission_id UUID NOT NULL REFERENCES permissions (id) ON DELETE CASCADE,
+    effect        VARCHAR(5) NOT NULL CHECK (effect IN ('grant', 'deny')),
+    reason        TEXT,
+    granted_by    UUID,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, permission_id)
+);
+
+INSERT INTO permissions (name, resource, action, description) VALUES
+('user:assign-permission', 'user', 'assign-permission', 'Grant or deny individual permissions to a user')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'admin' AND p.name = 'user:assign-permission'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS users_sync_primary_role ON users;
+DROP FUNCTION IF EXISTS users_sync_primary_role();
+DROP TABLE IF EXISTS user_roles;
@@ -0,0 +1,32 @@
+-- Satu user bisa memegang beberapa role (mis. dosen wali yang juga admin fakultas).
+-- users.role_id tetap menjadi role utama dan selalu ikut tercatat di user_roles.
+CREATE TABLE user_roles (
+    user_id    UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role_id    UUID NOT NULL REFERENCES roles (id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, role_id)
+);
+
+CREATE INDEX idx_user_roles_role_id ON user_roles (role_id);
+
+INSERT INTO user_roles (user_id, role_id)
+SELECT id, role_id FROM users WHERE role_id IS NOT NULL
+ON CONFLICT DO NOTHING;
+
+-- Mengganti users.role_id (API lama) menukar role utama di user_roles; role tambahan tidak tersentuh.
+CREATE FUNCTION users_sync_primary_role() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND OLD.role_id IS DISTINCT FROM NEW.role_id AND OLD.role_id IS NOT NULL THEN
+        DELETE FROM user_roles WHERE user_id = NEW.id AND role_id = OLD.role_id;
+    END IF;
+    IF NEW.role_id IS NOT NULL THEN
+        INSERT INTO user_roles (user_id, role_id) VALUES (NEW.id, NEW.role_id)
+        ON CONFLICT DO NOTHING;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER users_sync_primary_role
+    AFTER INSERT OR UPDATE OF role_id ON users
+    FOR EACH ROW EXECUTE FUNCTION users_sync_primary_role();
@@ -0,0 +1,12 @@
+-- Pembatasan percobaan login gagal, per akun (identifier yang diketik) dan per IP.
+-- Identifier yang tidak terdaftar ikut dicatat supaya respons tidak membocorkan akun mana yang ada.
+CREATE TABLE login_throttles (
+    scope           VARCHAR(10)  NOT NULL CHECK (scope IN ('account', 'ip')),
+    key             VARCHAR(255) NOT NULL,
+    failures        INT          NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    locked_until    TIMESTAMPTZ,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idx_login_throttles_last_failure_at ON login_throttles (last_failure_at);
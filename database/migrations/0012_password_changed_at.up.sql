@@ -0,0 +1,2 @@
+-- Access token (JWT) yang diterbitkan sebelum password terakhir diganti / direset ditolak AuthRequired.
+ALTER TABLE users ADD COLUMN password_changed_at TIMESTAMPTZ;
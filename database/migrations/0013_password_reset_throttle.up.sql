@@ -0,0 +1,4 @@
+-- Permintaan reset password dibatasi lewat tabel yang sama, per identifier (reset) dan per IP (reset_ip).
+ALTER TABLE login_throttles DROP CONSTRAINT IF EXISTS login_throttles_scope_check;
+ALTER TABLE login_throttles ADD CONSTRAINT login_throttles_scope_check
+    CHECK (scope IN ('account', 'ip', 'reset', 'reset_ip'));
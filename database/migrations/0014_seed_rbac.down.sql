@@ -0,0 +1,3 @@
+-- Sengaja tidak menghapus data: role / permission ini mungkin sudah ada sebelum 0014
+-- (mis. dari MigrateTesting) dan dirujuk oleh users, user_roles dan user_permissions.
+SELECT 1;
@@ -0,0 +1,61 @@
+-- Data RBAC dasar: role sistem, permission yang dipakai route, dan pemberiannya ke role.
+-- 0001 hanya membuat tabel, sehingga database yang dibangun dengan `migrate up` belum punya role
+-- (UPDATE is_system di 0007 dan grant admin di 0007 / 0008 tidak mengenai baris apa pun).
+-- Idempoten: database yang sudah diisi (mis. lewat MigrateTesting) tidak berubah selain is_system.
+INSERT INTO roles (name, description, is_system) VALUES
+('admin',      'Administrator Sistem',      TRUE),
+('mahasiswa',  'User Mahasiswa',            TRUE),
+('dosen_wali', 'Dosen Pembimbing Akademik', TRUE)
+ON CONFLICT (name) DO UPDATE SET is_system = TRUE;
+
+INSERT INTO permissions (name, resource, action, description) VALUES
+('auth:profile',             'auth',        'profile',           'View logged-in user profile'),
+('user:create',              'user',        'create',            'Create a new user'),
+('user:read',                'user',        'read',              'View list or detail of users'),
+('user:update',              'user',        'update',            'Update user data'),
+('user:delete',              'user',        'delete',            'Delete user'),
+('user:assign-role',         'user',        'assign-role',       'Assign role to user'),
+('user:manage',              'user',        'manage',            'Full user management'),
+('user:assign-permission',   'user',        'assign-permission', 'Grant or deny individual permissions to a user'),
+('role:read',                'role',        'read',              'View roles, permissions and the RBAC audit log'),
+('role:manage',              'role',        'manage',            'Create, update and delete roles; grant and revoke permissions'),
+('achievement:create',       'achievement', 'create',            'Create achievement'),
+('achievement:read',         'achievement', 'read',              'Read achievements'),
+('achievement:update',       'achievement', 'update',            'Update achievement'),
+('achievement:delete',       'achievement', 'delete',            'Delete achievement'),
+('achievement:submit',       'achievement', 'submit',            'Submit for verification'),
+('achievement:view-advisee', 'achievement', 'view-advisee',      'View advisee achievements'),
+('achievement:verify',       'achievement', 'verify',            'Verify student achievement'),
+('achievement:reject',       'achievement', 'reject',            'Reject student achievement'),
+('student:read',             'student',     'read',              'View student list'),
+('student:update',           'student',     'update',            'Update student data'),
+('lecturer:read',            'lecturer',    'read',              'View lecturers'),
+('lecturer:advisee-list',    'lecturer',    'advisee-list',      'View lecturer advisee list'),
+('report:statistics',        'report',      'statistics',        'View achievement statistics'),
+('report:student',           'report',      'student',           'View student-specific report')
+ON CONFLICT (name) DO NOTHING;
+
+-- admin memegang semua permission
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'mahasiswa' AND p.name IN (
+    'achievement:create', 'achievement:read', 'achievement:update', 'achievement:delete',
+    'achievement:submit', 'report:student', 'auth:profile'
+)
+ON CONFLICT DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'dosen_wali' AND p.name IN (
+    'achievement:read', 'achievement:view-advisee', 'achievement:verify', 'achievement:reject',
+    'lecturer:advisee-list', 'report:statistics', 'auth:profile'
+)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1 @@
+DELETE FROM permissions WHERE name IN ('maintenance:reconcile', 'scoring:manage', 'achievement-type:manage');
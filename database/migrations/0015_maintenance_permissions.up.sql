@@ -0,0 +1,12 @@
+-- Endpoint /admin/* dijaga permission, bukan nama role, sehingga bisa didelegasikan ke role lain.
+INSERT INTO permissions (name, resource, action, description) VALUES
+('maintenance:reconcile',   'maintenance',      'reconcile', 'Run the PostgreSQL / MongoDB consistency check and repair'),
+('scoring:manage',          'scoring',          'manage',    'Manage scoring rules and the scoring policy'),
+('achievement-type:manage', 'achievement-type', 'manage',    'Manage achievement types, their schemas and type remapping')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r CROSS JOIN permissions p
+WHERE r.name = 'admin' AND p.name IN ('maintenance:reconcile', 'scoring:manage', 'achievement-type:manage')
+ON CONFLICT DO NOTHING;
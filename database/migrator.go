@@ -0,0 +1,302 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// File migrasi: migrations/<versi>_<nama>.up.sql dan .down.sql
+// Versi diurutkan secara numerik dan dicatat di tabel schema_migrations.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// key pg_advisory_lock agar hanya satu proses yang menjalankan migrasi
+const migrationLockKey = 72_616_001
+
+var migrationFileRe = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string // sha256 dari file .up.sql
+}
+
+type MigrationStatus struct {
+	Migration
+	Applied   bool
+	AppliedAt *time.Time
+	Modified  bool // file .up.sql berubah setelah di-apply
+}
+
+// LoadMigrations membaca semua file migrasi yang di-embed, terurut berdasarkan versi.
+func LoadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, e := range entries {
+		m := migrationFileRe.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("nama file migrasi tidak valid: %s", e.Name())
+		}
+
+		version, _ := strconv.ParseInt(m[1], 10, 64)
+		body, err := migrationFiles.ReadFile(path.Join("migrations", e.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		}
+		if mig.Name != m[2] {
+			return nil, fmt.Errorf("versi migrasi %d dipakai dua nama: %s dan %s", version, mig.Name, m[2])
+		}
+
+		if m[3] == "up" {
+			sum := sha256.Sum256(body)
+			mig.Up = string(body)
+			mig.Checksum = hex.EncodeToString(sum[:])
+		} else {
+			mig.Down = string(body)
+		}
+	}
+
+	out := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" || mig.Down == "" {
+			return nil, fmt.Errorf("migrasi %d_%s harus punya file .up.sql dan .down.sql", mig.Version, mig.Name)
+		}
+		out = append(out, *mig)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+type appliedMigration struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+// withMigrationLock menjalankan fn di satu koneksi yang memegang advisory lock.
+func withMigrationLock(DB *sql.DB, fn func(conn *sql.Conn) error) error {
+	ctx := context.Background()
+	conn, err := DB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("gagal mengambil lock migrasi: %w", err)
+	}
+	defer conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, migrationLockKey)
+
+	_, err = conn.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    BIGINT PRIMARY KEY,
+			name       TEXT NOT NULL,
+			checksum   TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("gagal membuat tabel schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+func loadApplied(ctx context.Context, conn *sql.Conn) (map[int64]appliedMigration, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]appliedMigration{}
+	for rows.Next() {
+		var version int64
+		var a appliedMigration
+		if err := rows.Scan(&version, &a.checksum, &a.appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = a
+	}
+	return applied, rows.Err()
+}
+
+// MigrateUp menjalankan semua migrasi yang belum di-apply, masing-masing dalam transaksi.
+// Gagal jika ada migrasi yang sudah di-apply tapi file-nya berubah (checksum beda).
+func MigrateUp(DB *sql.DB) ([]Migration, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	err = withMigrationLock(DB, func(conn *sql.Conn) error {
+		ctx := context.Background()
+		applied, err := loadApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			if a, ok := applied[m.Version]; ok {
+				if a.checksum != m.Checksum {
+					return fmt.Errorf("checksum migrasi %d_%s berbeda dengan yang sudah di-apply", m.Version, m.Name)
+				}
+				continue
+			}
+
+			err := runInTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx,
+					`INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)`,
+					m.Version, m.Name, m.Checksum)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migrasi %d_%s gagal: %w", m.Version, m.Name, err)
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// MigrateDown me-rollback `steps` migrasi terakhir yang sudah di-apply.
+func MigrateDown(DB *sql.DB, steps int) ([]Migration, error) {
+	if steps < 1 {
+		return nil, errors.New("steps minimal 1")
+	}
+
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	err = withMigrationLock(DB, func(conn *sql.Conn) error {
+		ctx := context.Background()
+		applied, err := loadApplied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			m := migrations[i]
+			if _, ok := applied[m.Version]; !ok {
+				continue
+			}
+
+			err := runInTx(ctx, conn, func(tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, m.Down); err != nil {
+					return err
+				}
+				_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, m.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("rollback %d_%s gagal: %w", m.Version, m.Name, err)
+			}
+			done = append(done, m)
+		}
+		return nil
+	})
+	return done, err
+}
+
+// MigrateBaseline menandai migrasi sampai `version` sebagai sudah di-apply tanpa menjalankannya.
+// Dipakai untuk database lama yang tabelnya sudah dibuat manual.
+func MigrateBaseline(DB *sql.DB, version int64) ([]Migration, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	err = withMigrationLock(DB, func(conn *sql.Conn) error {
+		ctx := context.Background()
+		for _, m := range migrations {
+			if m.Version > version {
+				break
+			}
+			res, err := conn.ExecContext(ctx, `
+				INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)
+				ON CONFLICT (version) DO NOTHING
+			`, m.Version, m.Name, m.Checksum)
+			if err != nil {
+				return err
+			}
+			if n, _ := res.RowsAffected(); n > 0 {
+				done = append(done, m)
+			}
+		}
+		return nil
+	})
+	return done, err
+}
+
+// MigrationStatuses mengembalikan status setiap migrasi (applied / pending / modified).
+func MigrationStatuses(DB *sql.DB) ([]MigrationStatus, error) {
+	migrations, err := LoadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var out []MigrationStatus
+	err = withMigrationLock(DB, func(conn *sql.Conn) error {
+		applied, err := loadApplied(context.Background(), conn)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range migrations {
+			st := MigrationStatus{Migration: m}
+			if a, ok := applied[m.Version]; ok {
+				at := a.appliedAt
+				st.Applied = true
+				st.AppliedAt = &at
+				st.Modified = a.checksum != m.Checksum
+			}
+			out = append(out, st)
+		}
+		return nil
+	})
+	return out, err
+}
+
+func runInTx(ctx context.Context, conn *sql.Conn, fn func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,208 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"UAS_GO/config"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const achievementsCollection = "achievements"
+
+// InvalidAchievement: dokumen achievements yang tidak lolos $jsonSchema
+type InvalidAchievement struct {
+	ID        primitive.ObjectID `bson:"_id" json:"id"`
+	StudentID any                `bson:"studentId" json:"studentId"`
+	Title     any                `bson:"title" json:"title"`
+}
+
+type MongoBootstrapReport struct {
+	Indexes         []string             `json:"indexes"`
+	ValidatorAction string               `json:"validatorAction"`
+	InvalidCount    int64                `json:"invalidCount"`
+	InvalidSamples  []InvalidAchievement `json:"invalidSamples"`
+}
+
+// AchievementIndexes: index yang dipakai query di repository
+// (list per mahasiswa dengan urutan createdAt/updatedAt/points, filter tipe & tag,
+// statistik per periode & tingkat kompetisi).
+func AchievementIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "studentId", Value: 1}, {Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("studentId_createdAt"),
+		},
+		{
+			Keys:    bson.D{{Key: "studentId", Value: 1}, {Key: "updatedAt", Value: -1}},
+			Options: options.Index().SetName("studentId_updatedAt"),
+		},
+		{
+			Keys:    bson.D{{Key: "studentId", Value: 1}, {Key: "points", Value: -1}},
+			Options: options.Index().SetName("studentId_points"),
+		},
+		{
+			Keys:    bson.D{{Key: "tags", Value: 1}},
+			Options: options.Index().SetName("tags"),
+		},
+		{
+			Keys:    bson.D{{Key: "studentId", Value: 1}, {Key: "achievementType", Value: 1}},
+			Options: options.Index().SetName("studentId_achievementType"),
+		},
+		{
+			Keys:    bson.D{{Key: "achievementType", Value: 1}},
+			Options: options.Index().SetName("achievementType"),
+		},
+		{
+			Keys:    bson.D{{Key: "createdAt", Value: -1}},
+			Options: options.Index().SetName("createdAt"),
+		},
+		{
+			Keys:    bson.D{{Key: "details.competitionLevel", Value: 1}},
+			Options: options.Index().SetName("details_competitionLevel"),
+		},
+	}
+}
+
+// AchievementJSONSchema: $jsonSchema yang mengikuti models.Achievement.
+// Field opsional boleh null karena slice/map nil di Go disimpan sebagai null.
+func AchievementJSONSchema() bson.M {
+	return bson.M{
+		"bsonType": "object",
+		"required": []string{"studentId", "title", "achievementType", "createdAt", "updatedAt"},
+		"properties": bson.M{
+			"_id":             bson.M{"bsonType": "objectId"},
+			"studentId":       bson.M{"bsonType": "string", "minLength": 1},
+			"title":           bson.M{"bsonType": "string", "minLength": 1},
+			"description":     bson.M{"bsonType": []string{"string", "null"}},
+			"achievementType": bson.M{"bsonType": "string"},
+			"details": bson.M{
+				"bsonType": []string{"object", "null"},
+				"properties": bson.M{
+					"competitionLevel": bson.M{"bsonType": []string{"string", "null"}},
+				},
+			},
+			"attachments": bson.M{
+				"bsonType": []string{"array", "null"},
+				"items": bson.M{
+					"bsonType": "object",
+					"required": []string{"fileName", "fileUrl"},
+					"properties": bson.M{
+						"fileName":   bson.M{"bsonType": "string"},
+						"fileUrl":    bson.M{"bsonType": "string"},
+						"fileType":   bson.M{"bsonType": "string"},
+						"uploadedAt": bson.M{"bsonType": "date"},
+					},
+				},
+			},
+			"tags": bson.M{
+				"bsonType": []string{"array", "null"},
+				"items":    bson.M{"bsonType": "string"},
+			},
+			"points":    bson.M{"bsonType": []string{"int", "long"}, "minimum": 0},
+			"createdAt": bson.M{"bsonType": "date"},
+			"updatedAt": bson.M{"bsonType": "date"},
+		},
+	}
+}
+
+// EnsureAchievementIndexes membuat index achievements (idempotent).
+func EnsureAchievementIndexes(ctx context.Context, db *mongo.Database) ([]string, error) {
+	return db.Collection(achievementsCollection).Indexes().CreateMany(ctx, AchievementIndexes())
+}
+
+// EnsureAchievementValidator memasang $jsonSchema validator di collection achievements.
+// validationLevel "moderate": dokumen lama yang sudah invalid tidak memblokir update,
+// tapi insert baru dan update dokumen valid harus lolos schema.
+func EnsureAchievementValidator(ctx context.Context, db *mongo.Database, action string) error {
+	validator := bson.M{"$jsonSchema": AchievementJSONSchema()}
+
+	names, err := db.ListCollectionNames(ctx, bson.M{"name": achievementsCollection})
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		opts := options.CreateCollection().
+			SetValidator(validator).
+			SetValidationLevel("moderate").
+			SetValidationAction(action)
+		return db.CreateCollection(ctx, achievementsCollection, opts)
+	}
+
+	return db.RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: achievementsCollection},
+		{Key: "validator", Value: validator},
+		{Key: "validationLevel", Value: "moderate"},
+		{Key: "validationAction", Value: action},
+	}).Err()
+}
+
+// FindInvalidAchievements mencari dokumen yang melanggar schema (maks `limit` contoh).
+func FindInvalidAchievements(ctx context.Context, db *mongo.Database, limit int64) (int64, []InvalidAchievement, error) {
+	coll := db.Collection(achievementsCollection)
+	filter := bson.M{"$nor": []bson.M{{"$jsonSchema": AchievementJSONSchema()}}}
+
+	count, err := coll.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	opts := options.Find().
+		SetLimit(limit).
+		SetProjection(bson.M{"_id": 1, "studentId": 1, "title": 1})
+	cursor, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	samples := []InvalidAchievement{}
+	if err := cursor.All(ctx, &samples); err != nil {
+		return 0, nil, err
+	}
+	return count, samples, nil
+}
+
+// BootstrapMongo memastikan index & validator achievements terpasang,
+// lalu melaporkan dokumen lama yang tidak sesuai schema.
+func BootstrapMongo(db *mongo.Database) (*MongoBootstrapReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// "error" = tolak dokumen invalid, "warn" = hanya dicatat di log MongoDB
+	action := config.GetEnv("MONGO_VALIDATION_ACTION", "error")
+	if action != "error" && action != "warn" {
+		return nil, fmt.Errorf("MONGO_VALIDATION_ACTION tidak valid: %s", action)
+	}
+
+	if err := EnsureAchievementValidator(ctx, db, action); err != nil {
+		return nil, fmt.Errorf("gagal memasang validator achievements: %w", err)
+	}
+
+	indexes, err := EnsureAchievementIndexes(ctx, db)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat index achievements: %w", err)
+	}
+
+	count, samples, err := FindInvalidAchievements(ctx, db, 20)
+	if err != nil {
+		return nil, fmt.Errorf("gagal memeriksa dokumen achievements: %w", err)
+	}
+
+	if count > 0 {
+		log.Printf("  %d dokumen achievements tidak sesuai schema (jalankan `go run . mongo report` untuk detail)\n", count)
+	}
+
+	return &MongoBootstrapReport{
+		Indexes:         indexes,
+		ValidatorAction: action,
+		InvalidCount:    count,
+		InvalidSamples:  samples,
+	}, nil
+}
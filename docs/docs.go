@@ -17,14 +17,77 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/achievements": {
+        "/.well-known/jwks.json": {
+            "get": {
+                "description": "Public key (RS256 / EdDSA) yang dipakai untuk menandatangani access token,\ntermasuk kunci lama yang masih berlaku selama rotasi. Kosong jika memakai HS256.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "JSON Web Key Set",
+                "responses": {
+                    "200": {
+                        "description": "{keys:[...]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/achievement-types": {
             "get": {
+                "description": "Katalog achievementType yang aktif (kode, label per bahasa, poin default) beserta JSON Schema field details, dipakai frontend untuk merender form",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Achievements"
+                ],
+                "summary": "Achievement types \u0026 details schema",
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data:[models.AchievementType]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Mengambil daftar prestasi. Bisa difilter studentId dan type.",
+                ]
+            }
+        },
+        "/achievements": {
+            "get": {
+                "description": "Mengambil daftar prestasi sesuai role: admin semua prestasi, dosen wali prestasi mahasiswa bimbingannya, mahasiswa prestasi sendiri. Mendukung filter, urutan, dan pagination (page/limit atau cursor).",
                 "consumes": [
                     "application/json"
                 ],
@@ -38,25 +101,89 @@ const docTemplate = `{
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Filter by student UUID",
+                        "description": "Filter mahasiswa (UUID), di dalam cakupan role",
                         "name": "studentId",
                         "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Filter by achievement type",
+                        "description": "Filter program studi (admin \u0026 dosen wali)",
+                        "name": "programStudy",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter achievementType (pisahkan dengan koma)",
                         "name": "type",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter status: draft, submitted, verified, rejected, deleted (pisahkan dengan koma)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Hanya prestasi yang punya semua tag ini (pisahkan dengan koma)",
+                        "name": "tags",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "createdAt mulai (YYYY-MM-DD atau RFC3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "createdAt sampai (YYYY-MM-DD inklusif atau RFC3339)",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "createdAt (default), updatedAt, points",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "desc (default) atau asc",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 10, max. 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "next_cursor dari halaman sebelumnya (page diabaikan)",
+                        "name": "cursor",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "envelope {status,message,data:{page,limit,total,next_cursor,results}}",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Achievement"
-                            }
+                            "$ref": "#/definitions/models.AchievementPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Query tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "401": {
@@ -66,6 +193,13 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
+                    "403": {
+                        "description": "Forbidden (profil tidak ditemukan / studentId di luar cakupan)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -73,14 +207,14 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
+                ]
+            },
+            "post": {
                 "description": "Menambahkan prestasi baru ke MongoDB \u0026 reference ke PostgreSQL",
                 "consumes": [
                     "application/json"
@@ -99,7 +233,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.Achievement"
+                            "$ref": "#/definitions/models.CreateAchievementRequest"
                         }
                     }
                 ],
@@ -124,18 +258,25 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "422": {
+                        "description": "achievementType tidak ada di katalog / details tidak sesuai schema (data.errors: [{field,message}])",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
-                }
-            }
-        },
-        "/achievements/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Mengambil detail prestasi berdasarkan ID Mongo",
+                ]
+            }
+        },
+        "/achievements/bulk/reject": {
+            "post": {
+                "description": "Dosen wali menolak banyak prestasi sekaligus (catatan per item). Hasil dikembalikan per item: ok / forbidden / wrong_status / not_found / invalid / error.",
                 "consumes": [
                     "application/json"
                 ],
@@ -145,21 +286,30 @@ const docTemplate = `{
                 "tags": [
                     "Achievements"
                 ],
-                "summary": "Get achievement detail",
+                "summary": "Bulk reject achievements",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Mongo Achievement ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Daftar id + note (maks. 500)",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkRejectRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.Achievement"
+                            "$ref": "#/definitions/models.BulkResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid JSON / jumlah item tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "401": {
@@ -169,22 +319,31 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Achievement not found",
+                    "403": {
+                        "description": "Forbidden (not a lecturer)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
-                }
-            },
-            "delete": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Menandai prestasi sebagai deleted di Mongo \u0026 PostgreSQL (soft delete)",
+                ]
+            }
+        },
+        "/achievements/bulk/verify": {
+            "post": {
+                "description": "Dosen wali memverifikasi banyak prestasi sekaligus. Poin per item mengikuti rubrik (points kosong = poin rubrik, override dengan justification). Hasil dikembalikan per item: ok / forbidden / wrong_status / not_found / invalid / error.",
                 "consumes": [
                     "application/json"
                 ],
@@ -194,26 +353,27 @@ const docTemplate = `{
                 "tags": [
                     "Achievements"
                 ],
-                "summary": "Delete (soft) achievement",
+                "summary": "Bulk verify achievements",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Mongo Achievement ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Daftar id + points/justification (maks. 500)",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BulkVerifyRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Achievement deleted (envelope)",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.BulkResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad request",
+                        "description": "Invalid JSON / jumlah item tidak valid",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -227,14 +387,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden (not owner / not draft)",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "404": {
-                        "description": "Achievement not found",
+                        "description": "Forbidden (not a lecturer)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -247,57 +400,46 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            },
-            "patch": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Mengupdate field tertentu pada achievement",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            }
+        },
+        "/achievements/review-queue": {
+            "get": {
+                "description": "Prestasi berstatus submitted milik mahasiswa bimbingan dosen yang login, urut dari yang paling lama menunggu, beserta poin saran rubrik (suggested_points).",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "Achievements"
                 ],
-                "summary": "Update achievement",
+                "summary": "Review queue dosen wali",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Mongo Achievement ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Filter achievement type",
+                        "name": "type",
+                        "in": "query"
                     },
                     {
-                        "description": "Fields to update",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
+                        "type": "string",
+                        "description": "Filter program studi mahasiswa",
+                        "name": "programStudy",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Achievement updated (envelope)",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "Bad request (invalid body / blocked fields)",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ReviewQueueItem"
+                            }
                         }
                     },
                     "401": {
@@ -308,14 +450,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden (not owner)",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "404": {
-                        "description": "Achievement not found",
+                        "description": "Forbidden (not a lecturer)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -328,19 +463,19 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/achievements/{id}/attachments": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Mengunggah file (sertifikat / bukti prestasi) ke achievement",
+                ]
+            }
+        },
+        "/achievements/{id}": {
+            "get": {
+                "description": "Mengambil detail prestasi berdasarkan ID Mongo. Hanya pemilik, dosen wali pemilik, atau admin.",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
@@ -348,7 +483,7 @@ const docTemplate = `{
                 "tags": [
                     "Achievements"
                 ],
-                "summary": "Upload achievement attachment",
+                "summary": "Get achievement detail",
                 "parameters": [
                     {
                         "type": "string",
@@ -356,28 +491,13 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "file",
-                        "description": "File attachment",
-                        "name": "file",
-                        "in": "formData",
-                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Attachment uploaded (envelope)",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "Bad request (no file / invalid form)",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.Achievement"
                         }
                     },
                     "401": {
@@ -388,7 +508,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden (not owner)",
+                        "description": "Bukan pemilik / dosen wali pemilik / admin",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -408,17 +528,15 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/achievements/{id}/history": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Mengambil reference, achievement (jika ada), dan riwayat event (created, submitted, verified, rejected, attachment_uploaded, last_updated).",
+                ]
+            },
+            "delete": {
+                "description": "Menandai prestasi sebagai deleted di Mongo \u0026 PostgreSQL (soft delete)",
                 "consumes": [
                     "application/json"
                 ],
@@ -428,11 +546,11 @@ const docTemplate = `{
                 "tags": [
                     "Achievements"
                 ],
-                "summary": "Get achievement history \u0026 timeline",
+                "summary": "Delete (soft) achievement",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Achievement Mongo ID",
+                        "description": "Mongo Achievement ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -440,21 +558,42 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "envelope {status,message,data:{reference,achievement,history}}",
+                        "description": "Achievement deleted (envelope)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Invalid achievement ID",
+                        "description": "Bad request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (not owner)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "404": {
-                        "description": "Achievement reference not found",
+                        "description": "Achievement not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Status tidak mengizinkan aksi ini (data: current_status, allowed_next_states)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -467,17 +606,15 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/achievements/{id}/reject": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Dosen menolak prestasi dan memberikan catatan",
+                ]
+            },
+            "patch": {
+                "description": "Mengupdate field tertentu pada achievement",
                 "consumes": [
                     "application/json"
                 ],
@@ -487,7 +624,7 @@ const docTemplate = `{
                 "tags": [
                     "Achievements"
                 ],
-                "summary": "Reject achievement",
+                "summary": "Update achievement",
                 "parameters": [
                     {
                         "type": "string",
@@ -497,25 +634,26 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Rejection data",
+                        "description": "Fields to update",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.RejectAchievementRequest"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Achievement rejected (envelope)",
+                        "description": "Achievement updated (envelope)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Bad request (invalid JSON / status bukan submitted)",
+                        "description": "Bad request (invalid body / blocked fields)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -529,14 +667,28 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden (not advisor)",
+                        "description": "Forbidden (not owner)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "404": {
-                        "description": "Achievement/reference not found",
+                        "description": "Achievement not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Status tidak mengizinkan aksi ini (data: current_status, allowed_next_states)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "achievementType tidak ada di katalog / details tidak sesuai schema (data.errors: [{field,message}])",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -549,19 +701,19 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/achievements/{id}/submit": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Mahasiswa mengirim prestasi agar diverifikasi dosen",
+                ]
+            }
+        },
+        "/achievements/{id}/attachments": {
+            "post": {
+                "description": "Mengunggah file (sertifikat / bukti prestasi) ke achievement",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
@@ -569,7 +721,7 @@ const docTemplate = `{
                 "tags": [
                     "Achievements"
                 ],
-                "summary": "Submit achievement",
+                "summary": "Upload achievement attachment",
                 "parameters": [
                     {
                         "type": "string",
@@ -577,18 +729,25 @@ const docTemplate = `{
                         "name": "id",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "File attachment",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Achievement submitted (envelope)",
+                    "201": {
+                        "description": "Attachment uploaded (envelope)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Bad request (invalid ID / already submitted)",
+                        "description": "Bad request (no file / invalid form)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -609,7 +768,14 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Achievement or reference not found",
+                        "description": "Achievement not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Status tidak mengizinkan aksi ini (data: current_status, allowed_next_states)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -622,17 +788,17 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/achievements/{id}/verify": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Dosen memverifikasi prestasi dan memberi poin",
+                ]
+            }
+        },
+        "/achievements/{id}/history": {
+            "get": {
+                "description": "Mengambil reference, achievement (jika ada), dan riwayat event dari log audit (created, updated, attachment_added, submitted, verified, rejected, deleted) beserta actor, role, perubahan field, dan catatan. Hanya pemilik, dosen wali pemilik, atau admin.",
                 "consumes": [
                     "application/json"
                 ],
@@ -642,56 +808,40 @@ const docTemplate = `{
                 "tags": [
                     "Achievements"
                 ],
-                "summary": "Verify achievement",
+                "summary": "Get achievement history \u0026 timeline",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Mongo Achievement ID",
+                        "description": "Achievement Mongo ID",
                         "name": "id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "description": "Verification data",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.VerifyAchievementRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Achievement verified (envelope)",
+                        "description": "envelope {status,message,data:{reference,achievement,history}}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Bad request (invalid JSON / points)",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized",
+                        "description": "Invalid achievement ID",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "403": {
-                        "description": "Forbidden (not advisor)",
+                        "description": "Bukan pemilik / dosen wali pemilik / admin",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "404": {
-                        "description": "Achievement/reference not found",
+                        "description": "Achievement not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -704,17 +854,17 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/admin/users": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Mengambil daftar semua user (khusus admin)",
+                ]
+            }
+        },
+        "/achievements/{id}/reject": {
+            "post": {
+                "description": "Dosen menolak prestasi dan memberikan catatan",
                 "consumes": [
                     "application/json"
                 ],
@@ -722,12 +872,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Admin - Users"
+                    "Achievements"
+                ],
+                "summary": "Reject achievement",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Mongo Achievement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Rejection data",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RejectAchievementRequest"
+                        }
+                    }
                 ],
-                "summary": "Get all users (admin)",
                 "responses": {
                     "200": {
-                        "description": "envelope {status,message,data:[users]}",
+                        "description": "Achievement rejected (envelope)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request (invalid JSON / note kosong)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -741,7 +916,21 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden (not admin)",
+                        "description": "Forbidden (not advisor)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Achievement/reference not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Status tidak mengizinkan aksi ini (data: current_status, allowed_next_states)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -754,60 +943,56 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            },
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Admin membuat user baru. Password akan di-hash sebelum disimpan.",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            }
+        },
+        "/achievements/{id}/score": {
+            "get": {
+                "description": "Poin yang disarankan rubrik untuk prestasi mahasiswa bimbingan, beserta rentang poin yang diizinkan kebijakan",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Admin - Users"
+                    "Achievements"
                 ],
-                "summary": "Create new user (admin)",
+                "summary": "Suggested points",
                 "parameters": [
                     {
-                        "description": "User payload",
-                        "name": "body",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateUserRequest"
-                        }
+                        "type": "string",
+                        "description": "Mongo Achievement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "envelope {status,message,data:user}",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.ScoreSuggestion"
                         }
                     },
-                    "400": {
-                        "description": "Validation error / invalid payload",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "403": {
+                        "description": "Forbidden (not advisor)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "403": {
-                        "description": "Forbidden (not admin)",
+                    "404": {
+                        "description": "Achievement not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -820,17 +1005,17 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/admin/users/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Mengambil detail user berdasarkan ID (UUID)",
+                ]
+            }
+        },
+        "/achievements/{id}/submit": {
+            "post": {
+                "description": "Mahasiswa mengirim prestasi agar diverifikasi dosen",
                 "consumes": [
                     "application/json"
                 ],
@@ -838,13 +1023,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Admin - Users"
+                    "Achievements"
                 ],
-                "summary": "Get user by ID (admin)",
+                "summary": "Submit achievement",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID (UUID)",
+                        "description": "Mongo Achievement ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -852,7 +1037,14 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "envelope {status,message,data:user}",
+                        "description": "Achievement submitted (envelope)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request (invalid ID)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -866,14 +1058,21 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden (not admin)",
+                        "description": "Forbidden (not owner)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "404": {
-                        "description": "User not found",
+                        "description": "Achievement or reference not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Status tidak mengizinkan aksi ini (data: current_status, allowed_next_states)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -886,15 +1085,17 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            },
-            "put": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Admin mengubah data user (email, username, full name, role, status aktif)",
+                ]
+            }
+        },
+        "/achievements/{id}/verify": {
+            "post": {
+                "description": "Dosen memverifikasi prestasi dan memberi poin. Poin kosong = poin rubrik; poin berbeda dari rubrik hanya boleh pada mode override, dalam batas kebijakan dan dengan justification.",
                 "consumes": [
                     "application/json"
                 ],
@@ -902,37 +1103,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Admin - Users"
+                    "Achievements"
                 ],
-                "summary": "Update user (admin)",
+                "summary": "Verify achievement",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID (UUID)",
+                        "description": "Mongo Achievement ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "User update payload",
+                        "description": "Verification data",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateUserRequest"
+                            "$ref": "#/definitions/models.VerifyAchievementRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "envelope {status,message,data:user}",
+                        "description": "Achievement verified (envelope)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Invalid payload / email already used / invalid role",
+                        "description": "Bad request (invalid JSON / points di luar rubrik / justification kosong)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -946,14 +1147,21 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden (not admin)",
+                        "description": "Forbidden (not advisor)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "404": {
-                        "description": "User not found",
+                        "description": "Achievement/reference not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Status tidak mengizinkan aksi ini (data: current_status, allowed_next_states)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -966,37 +1174,27 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            },
-            "delete": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Admin menghapus user berdasarkan ID.",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            }
+        },
+        "/admin/achievement-types": {
+            "get": {
+                "description": "Seluruh katalog achievementType termasuk yang tidak aktif",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Admin - Users"
-                ],
-                "summary": "Delete user (admin)",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "User ID (UUID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "Admin - Achievement Types"
                 ],
+                "summary": "List achievement types (admin)",
                 "responses": {
                     "200": {
-                        "description": "user deleted (envelope)",
+                        "description": "envelope {status,message,data:[models.AchievementType]}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1010,14 +1208,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden (not admin)",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "404": {
-                        "description": "user not found",
+                        "description": "Forbidden (butuh achievement-type:manage)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1030,17 +1221,15 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/admin/users/{id}/role": {
-            "put": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Admin mengubah role user (hanya role, tanpa mengubah field lain).",
+                ]
+            },
+            "post": {
+                "description": "Menambah kode baru ke katalog achievementType",
                 "consumes": [
                     "application/json"
                 ],
@@ -1048,37 +1237,29 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Admin - Users"
+                    "Admin - Achievement Types"
                 ],
-                "summary": "Update user role (admin)",
+                "summary": "Create achievement type (admin)",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User ID (UUID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "Role update payload",
+                        "description": "Achievement type",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateUserRoleRequest"
+                            "$ref": "#/definitions/models.AchievementTypeRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "user role updated (envelope)",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.AchievementType"
                         }
                     },
                     "400": {
-                        "description": "invalid request body",
+                        "description": "Validation error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1092,14 +1273,14 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden (not admin)",
+                        "description": "Forbidden (butuh achievement-type:manage)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "user not found",
+                    "409": {
+                        "description": "Kode sudah ada",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1112,12 +1293,17 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/auth/login": {
+        "/admin/achievement-types/remap": {
             "post": {
-                "description": "Autentikasi user menggunakan email+password atau NIM+password.\nMengembalikan JWT token, data user, dan permissions.",
+                "description": "Mengganti nilai achievementType di MongoDB yang tidak ada di katalog. mappings opsional (nilai lama -\u003e kode); sisanya dicocokkan otomatis dengan kode / label. Dengan dry_run=false perubahan ditulis.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1125,37 +1311,48 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Admin - Achievement Types"
                 ],
-                "summary": "Login user",
+                "summary": "Remap legacy achievement types (admin)",
                 "parameters": [
                     {
-                        "description": "Login payload (email+password atau NIM+password)",
+                        "type": "boolean",
+                        "description": "Hanya laporan (default true)",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "Mapping eksplisit",
                         "name": "body",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.LoginRequest"
+                            "$ref": "#/definitions/models.TypeRemapRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "envelope {status,message,data:LoginResponse}",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.TypeRemapReport"
                         }
                     },
                     "400": {
-                        "description": "Invalid request format / missing fields",
+                        "description": "Kode tujuan tidak ada di katalog",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "401": {
-                        "description": "Invalid credentials / inactive account",
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (butuh achievement-type:manage)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1168,17 +1365,17 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/auth/logout": {
-            "post": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Logout user yang sedang login (invalidate token / update state server).",
+                ]
+            }
+        },
+        "/admin/achievement-types/{code}": {
+            "put": {
+                "description": "Mengubah label, status aktif, dan poin default. Kode tidak bisa diubah (gunakan remap). Tipe tidak aktif tidak bisa dipakai untuk prestasi baru.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1186,19 +1383,57 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Admin - Achievement Types"
+                ],
+                "summary": "Update achievement type (admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement type code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Achievement type",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.AchievementTypeRequest"
+                        }
+                    }
                 ],
-                "summary": "Logout current user",
                 "responses": {
                     "200": {
-                        "description": "Logout successful (envelope)",
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.AchievementType"
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "401": {
-                        "description": "User not authenticated",
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (butuh achievement-type:manage)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Achievement type not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1211,62 +1446,85 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/auth/profile": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Mengambil profil user yang sedang login berdasarkan JWT.",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            },
+            "delete": {
+                "description": "Menghapus kode dari katalog beserta schema dan rubriknya. Ditolak jika masih dipakai prestasi (nonaktifkan atau remap terlebih dahulu).",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Admin - Achievement Types"
+                ],
+                "summary": "Delete achievement type (admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement type code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get current user profile",
                 "responses": {
                     "200": {
-                        "description": "envelope {status,message,data} berisi profil user",
+                        "description": "envelope {status,message,data:null}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "401": {
-                        "description": "User not authenticated",
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "User not found",
+                    "403": {
+                        "description": "Forbidden (butuh achievement-type:manage)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "error response",
+                    "404": {
+                        "description": "Achievement type not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Masih dipakai prestasi",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
-                }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
             }
         },
-        "/auth/refresh": {
-            "post": {
-                "description": "Menerima token lama (masih valid) dan mengembalikan token baru.",
+        "/admin/achievement-types/{type}/schema": {
+            "put": {
+                "description": "Membuat / mengganti JSON Schema details untuk satu achievementType. Keyword yang didukung: type, properties, required, additionalProperties, enum, minLength, maxLength, pattern, format (date, date-time, email, uri), minimum, maximum, items, minItems, maxItems; anotasi title/description/default/examples/x-* disimpan untuk frontend.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1274,37 +1532,57 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Auth"
+                    "Admin - Achievement Types"
                 ],
-                "summary": "Refresh JWT token",
+                "summary": "Set details schema (admin)",
                 "parameters": [
                     {
-                        "description": "Refresh token payload",
+                        "type": "string",
+                        "description": "Achievement type",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "JSON Schema",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.RefreshTokenRequest"
+                            "$ref": "#/definitions/models.AchievementTypeSchemaRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "envelope {status,message,data:LoginResponse}",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.AchievementTypeSchema"
                         }
                     },
                     "400": {
-                        "description": "Invalid request format / token empty",
+                        "description": "Schema tidak valid / keyword tidak didukung",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "401": {
-                        "description": "Invalid or expired token",
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (butuh achievement-type:manage)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Achievement type tidak ada di katalog",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1317,30 +1595,34 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/lecturers": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Mengambil daftar semua dosen.",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            },
+            "delete": {
+                "description": "Menghapus schema details; details untuk tipe tersebut tidak lagi divalidasi",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Lecturers"
+                    "Admin - Achievement Types"
+                ],
+                "summary": "Delete details schema (admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Achievement type",
+                        "name": "type",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all lecturers",
                 "responses": {
                     "200": {
-                        "description": "envelope {status,message,data} berisi array lecturers",
+                        "description": "envelope {status,message,data:null}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1354,7 +1636,14 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden",
+                        "description": "Forbidden (butuh achievement-type:manage)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Schema not found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1367,61 +1656,37 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/lecturers/{id}/advisees": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Mengambil daftar mahasiswa bimbingan seorang dosen beserta prestasinya (pagination).",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            }
+        },
+        "/admin/reconcile": {
+            "post": {
+                "description": "Membandingkan dokumen MongoDB dengan achievement_references dan melaporkan selisihnya per kategori. Dengan dry_run=false selisih diperbaiki\nsetelah kedua sisi dibaca ulang (selisih yang sudah hilang ditandai stale). Prestasi yang dibuat kurang dari satu menit sebelum scan tidak diperiksa.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Lecturers"
+                    "Admin - Maintenance"
                 ],
-                "summary": "Get lecturer's advisees and achievements",
+                "summary": "Reconcile achievements (admin)",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Lecturer ID (UUID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number (default 1)",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Items per page (default 10)",
-                        "name": "limit",
+                        "type": "boolean",
+                        "description": "Hanya laporan, tanpa perbaikan (default true)",
+                        "name": "dry_run",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "envelope {status,message,data:{page,limit,results}}",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid lecturer ID",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.ReconcileReport"
                         }
                     },
                     "401": {
@@ -1432,14 +1697,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden (not advisor)",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "404": {
-                        "description": "Lecturer not found",
+                        "description": "Forbidden (butuh maintenance:reconcile)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1452,44 +1710,40 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/statistics/global": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Mengambil statistik global prestasi berdasarkan role:\n- admin: melihat semua data\n- dosen_wali: hanya prestasi mahasiswa bimbingannya\n- mahasiswa: hanya prestasi milik sendiri",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            }
+        },
+        "/admin/scoring-policy": {
+            "get": {
+                "description": "Mode penerapan rubrik (enforce / override) dan batas override dalam persen",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Statistics"
+                    "Admin - Scoring"
                 ],
-                "summary": "Get global statistics (role-based)",
+                "summary": "Get scoring policy (admin)",
                 "responses": {
                     "200": {
-                        "description": "envelope {status,message,data} berisi statistik global",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.ScoringPolicy"
                         }
                     },
                     "401": {
-                        "description": "Unauthorized (role / user_id tidak tersedia)",
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "403": {
-                        "description": "Forbidden (profil dosen/mahasiswa tidak ditemukan)",
+                        "description": "Forbidden (butuh scoring:manage)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1502,17 +1756,15 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/statistics/students/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Mengambil statistik dan laporan prestasi untuk satu mahasiswa.\nBerdasarkan student ID (UUID dari PostgreSQL).",
+                ]
+            },
+            "put": {
+                "description": "enforce = poin harus sama dengan rubrik; override = dosen boleh menyimpang maks. max_override_percent dengan justifikasi",
                 "consumes": [
                     "application/json"
                 ],
@@ -1520,42 +1772,43 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Statistics"
+                    "Admin - Scoring"
                 ],
-                "summary": "Get student report",
+                "summary": "Update scoring policy (admin)",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Student ID (UUID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Scoring policy",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ScoringPolicyRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "envelope {status,message,data} berisi statistik student",
+                        "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.ScoringPolicy"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Validation error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "403": {
-                        "description": "Forbidden (akses bukan admin / dosen wali)",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Student not found",
+                    "403": {
+                        "description": "Forbidden (butuh scoring:manage)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1568,45 +1821,27 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/students": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Mengambil daftar mahasiswa.\nOptional filter by advisorId dan free-text query.",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            }
+        },
+        "/admin/scoring-rules": {
+            "get": {
+                "description": "Mengambil seluruh rubrik poin prestasi",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Students"
-                ],
-                "summary": "Get all students",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Filter by advisor UUID",
-                        "name": "advisorId",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "Free-text search (nama, NIM, dll)",
-                        "name": "q",
-                        "in": "query"
-                    }
+                    "Admin - Scoring"
                 ],
+                "summary": "List scoring rules (admin)",
                 "responses": {
                     "200": {
-                        "description": "envelope {status,message,data}",
+                        "description": "envelope {status,message,data:[rules]}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1620,7 +1855,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden",
+                        "description": "Forbidden (butuh scoring:manage)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1633,17 +1868,15 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/students/{id}": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Mengambil detail student berdasarkan ID (UUID).",
+                ]
+            },
+            "post": {
+                "description": "Menambah aturan rubrik. Kriteria kosong (competition_level, rank, participation) berlaku untuk semua nilai.",
                 "consumes": [
                     "application/json"
                 ],
@@ -1651,22 +1884,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Students"
+                    "Admin - Scoring"
                 ],
-                "summary": "Get student by ID",
+                "summary": "Create scoring rule (admin)",
                 "parameters": [
                     {
-                        "type": "string",
-                        "format": "uuid",
-                        "description": "Student ID (UUID)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Scoring rule",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ScoringRuleRequest"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "envelope {status,message,data}",
+                    "201": {
+                        "description": "envelope {status,message,data:rule}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1680,14 +1921,14 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden",
+                        "description": "Forbidden (butuh scoring:manage)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Student not found",
+                    "409": {
+                        "description": "Aturan dengan kriteria yang sama sudah ada",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1700,17 +1941,17 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/students/{id}/achievements": {
-            "get": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Mengambil achievement references milik student.\nJika reference memiliki mongoId, maka akan di-enrich dengan dokumen MongoDB.",
+                ]
+            }
+        },
+        "/admin/scoring-rules/{id}": {
+            "put": {
+                "description": "Mengganti kriteria dan poin satu aturan rubrik",
                 "consumes": [
                     "application/json"
                 ],
@@ -1718,35 +1959,40 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Students",
-                    "Achievements"
+                    "Admin - Scoring"
                 ],
-                "summary": "Get student's achievements",
+                "summary": "Update scoring rule (admin)",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Student ID (UUID)",
+                        "description": "Scoring rule ID (UUID)",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Optional status filter (draft, submitted, verified, rejected)",
-                        "name": "status",
-                        "in": "query"
+                        "description": "Scoring rule",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ScoringRuleRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "array of { reference: object, achievement: object|null }",
+                        "description": "envelope {status,message,data:rule}",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "object",
-                                "additionalProperties": true
-                            }
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Validation error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "401": {
@@ -1757,14 +2003,21 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden",
+                        "description": "Forbidden (butuh scoring:manage)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "404": {
-                        "description": "Student not found",
+                        "description": "Scoring rule not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Aturan dengan kriteria yang sama sudah ada",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1777,56 +2030,157 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        },
-        "/students/{id}/advisor": {
-            "put": {
+                },
                 "security": [
                     {
                         "BearerAuth": []
                     }
-                ],
-                "description": "Update advisorId milik student.\nBody JSON: { \"advisorId\": \"\u003cuuid|null\u003e\" }.",
-                "consumes": [
-                    "application/json"
-                ],
+                ]
+            },
+            "delete": {
+                "description": "Menghapus satu aturan rubrik. Prestasi yang sudah diverifikasi tidak berubah.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Students"
+                    "Admin - Scoring"
                 ],
-                "summary": "Update student's advisor",
+                "summary": "Delete scoring rule (admin)",
                 "parameters": [
                     {
                         "type": "string",
-                        "format": "uuid",
-                        "description": "Student ID (UUID)",
+                        "description": "Scoring rule ID (UUID)",
                         "name": "id",
                         "in": "path",
                         "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data:null}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (butuh scoring:manage)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Scoring rule not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
                     {
-                        "description": "Payload: advisorId (uuid or null)",
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/users": {
+            "get": {
+                "description": "Mengambil daftar semua user (khusus admin)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Users"
+                ],
+                "summary": "Get all users (admin)",
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data:[users]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (not admin)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Admin membuat user baru. Password akan di-hash sebelum disimpan.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Users"
+                ],
+                "summary": "Create new user (admin)",
+                "parameters": [
+                    {
+                        "description": "User payload",
                         "name": "body",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/models.UpdateStudentAdvisorRequest"
+                            "$ref": "#/definitions/models.CreateUserRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Student advisor updated (envelope)",
+                    "201": {
+                        "description": "envelope {status,message,data:user}",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
                     "400": {
-                        "description": "Bad request (invalid JSON/body validation)",
+                        "description": "Validation error / invalid payload / tidak memenuhi kebijakan password",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1840,7 +2194,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Forbidden",
+                        "description": "Forbidden (not admin)",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -1853,132 +2207,2801 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     }
-                }
-            }
-        }
-    },
-    "definitions": {
-        "models.Achievement": {
-            "type": "object",
-            "properties": {
-                "achievementType": {
-                    "type": "string"
-                },
-                "attachments": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/models.Attachment"
-                    }
-                },
-                "createdAt": {
-                    "type": "string"
-                },
-                "description": {
-                    "type": "string"
-                },
-                "details": {
-                    "type": "object",
-                    "additionalProperties": {}
-                },
-                "id": {
-                    "type": "string"
-                },
-                "points": {
-                    "type": "integer"
-                },
-                "studentID": {
-                    "type": "string"
                 },
-                "tags": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
+                "security": [
+                    {
+                        "BearerAuth": []
                     }
-                },
-                "title": {
-                    "type": "string"
-                },
-                "updatedAt": {
-                    "type": "string"
-                }
-            }
-        },
-        "models.Attachment": {
-            "type": "object",
-            "properties": {
-                "fileName": {
-                    "type": "string"
-                },
-                "fileType": {
-                    "type": "string"
-                },
-                "fileURL": {
-                    "type": "string"
-                },
-                "uploadedAt": {
-                    "type": "string"
-                }
+                ]
             }
         },
-        "models.CreateUserRequest": {
-            "type": "object",
-            "required": [
-                "email",
-                "full_name",
-                "is_active",
-                "password",
-                "role_id",
-                "username"
-            ],
-            "properties": {
-                "email": {
-                    "type": "string"
+        "/admin/users/{id}": {
+            "get": {
+                "description": "Mengambil detail user berdasarkan ID (UUID)",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Users"
+                ],
+                "summary": "Get user by ID (admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data:user}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (not admin)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "put": {
+                "description": "Admin mengubah data user (email, username, full name, role, status aktif)\nJika password diisi, semua sesi user dicabut dan token reset yang masih terbuka dibatalkan.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Users"
+                ],
+                "summary": "Update user (admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User update payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data:user}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid payload / email already used / invalid role / tidak memenuhi kebijakan password",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (not admin)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Admin aktif terakhir tidak boleh dihapus / diturunkan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Admin menghapus user berdasarkan ID.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Users"
+                ],
+                "summary": "Delete user (admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "user deleted (envelope)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (not admin)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Admin aktif terakhir tidak boleh dihapus / diturunkan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/admin/users/{id}/role": {
+            "put": {
+                "description": "Admin mengubah role user (hanya role, tanpa mengubah field lain).\nrole_id mengganti role utama (bentuk lama, tetap didukung); add_role_ids / remove_role_ids\nmenambah / melepas role tambahan. Permission user adalah gabungan permission semua rolenya.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Users"
+                ],
+                "summary": "Update user roles (admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role update payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateUserRoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "user role updated (envelope)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body / role tidak ada / role utama dilepas",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (not admin)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Admin aktif terakhir tidak boleh dihapus / diturunkan",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/auth/forgot": {
+            "post": {
+                "description": "Mengirim token reset password (sekali pakai, berlaku PASSWORD_RESET_TTL) ke email akun.\nRespons selalu sama walaupun email / NIM tidak terdaftar.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Request password reset",
+                "parameters": [
+                    {
+                        "description": "Email atau NIM",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ForgotPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "envelope (pesan seragam)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Email or NIM is required",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "429": {
+                        "description": "Terlalu banyak permintaan reset (identifier / IP); lihat header Retry-After",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "503": {
+                        "description": "Mailer belum dikonfigurasi (MAIL_DRIVER)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "Autentikasi user menggunakan email+password atau NIM+password.\nMengembalikan access token (JWT, berumur pendek), refresh token (opaque, per perangkat), data user, dan permissions.\nLogin gagal dihitung per akun dan per IP: jeda progresif lalu kunci sementara (429).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Login user",
+                "parameters": [
+                    {
+                        "description": "Login payload (email+password atau NIM+password)",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data:LoginResponse}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format / missing fields",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid credentials / inactive account",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "429": {
+                        "description": "Terlalu banyak login gagal (akun / IP); lihat header Retry-After",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/logout": {
+            "post": {
+                "description": "Logout user yang sedang login. Access token (jti) dimasukkan ke blacklist sampai expired\ndan refresh token milik sesi (perangkat) ini dicabut.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Logout current user",
+                "responses": {
+                    "200": {
+                        "description": "Logout successful (envelope)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/auth/password": {
+            "post": {
+                "description": "User yang sedang login mengganti password dengan menyertakan password lama.\nSesi (refresh token) di perangkat lain dicabut; sesi yang dipakai untuk request ini tetap berlaku.\nSemua access token yang sudah terbit ditolak; perangkat ini mengambil access token baru lewat /auth/refresh.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Change password",
+                "parameters": [
+                    {
+                        "description": "Password lama dan baru",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ChangePasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "password changed (envelope)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid payload / password lama salah / password baru sama / tidak memenuhi kebijakan password",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/auth/profile": {
+            "get": {
+                "description": "Mengambil profil user yang sedang login berdasarkan JWT.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Get current user profile",
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data} berisi profil user",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "User not authenticated",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/auth/refresh": {
+            "post": {
+                "description": "Menukar refresh token dengan access token + refresh token baru (rotasi).\nRefresh token lama langsung tidak berlaku; jika dipakai lagi, seluruh sesi perangkat tersebut dicabut.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Refresh access token",
+                "parameters": [
+                    {
+                        "description": "Refresh token payload",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RefreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data:LoginResponse}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format / token empty",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid, expired, revoked or reused refresh token",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/reset": {
+            "post": {
+                "description": "Mengganti password memakai token dari email. Token hanya bisa dipakai sekali;\nsemua sesi (refresh token dan access token yang sudah terbit) user dicabut dan kunci login akun dibuka.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Auth"
+                ],
+                "summary": "Reset password",
+                "parameters": [
+                    {
+                        "description": "Token dan password baru",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.ResetPasswordRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "password reset (envelope)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid payload / token tidak valid atau expired / tidak memenuhi kebijakan password",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/lecturers": {
+            "get": {
+                "description": "Mengambil daftar semua dosen.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Lecturers"
+                ],
+                "summary": "Get all lecturers",
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data} berisi array lecturers",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/lecturers/{id}/advisees": {
+            "get": {
+                "description": "Mengambil daftar mahasiswa bimbingan seorang dosen beserta prestasinya (pagination).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Lecturers"
+                ],
+                "summary": "Get lecturer's advisees and achievements",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Lecturer ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default 10)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data:{page,limit,results}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid lecturer ID",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (not advisor)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Lecturer not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/permissions": {
+            "get": {
+                "description": "Semua permission yang dikenal aplikasi (didefinisikan oleh route, tidak bisa dibuat lewat API)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Roles"
+                ],
+                "summary": "List permissions",
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data:[models.Permission]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (butuh role:read)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/roles": {
+            "get": {
+                "description": "Semua role beserta permission dan jumlah user-nya",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Roles"
+                ],
+                "summary": "List roles",
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data:[models.Role]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (butuh role:read)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "post": {
+                "description": "Membuat role baru (mis. kaprodi) sekaligus permission awalnya",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Roles"
+                ],
+                "summary": "Create role",
+                "parameters": [
+                    {
+                        "description": "Role",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Nama tidak valid / permission tidak dikenal",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (butuh role:manage)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Nama role sudah dipakai",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/roles/audit": {
+            "get": {
+                "description": "Riwayat perubahan role, permission, dan penugasan role user (terbaru dulu)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Roles"
+                ],
+                "summary": "RBAC audit log",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Filter per role (UUID)",
+                        "name": "role_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Jumlah entri (default 50, maks. 200)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data:[models.RBACAuditEntry]}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (butuh role:read)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/roles/{id}": {
+            "get": {
+                "description": "Detail satu role beserta permission dan jumlah user-nya",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Roles"
+                ],
+                "summary": "Get role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (butuh role:read)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Role not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "put": {
+                "description": "Mengubah nama dan deskripsi role. Role sistem (admin, mahasiswa, dosen_wali) tidak bisa diganti nama. Permission diatur lewat /roles/{id}/permissions.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Roles"
+                ],
+                "summary": "Update role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Role (permissions diabaikan)",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RoleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    },
+                    "400": {
+                        "description": "Nama tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (butuh role:manage)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Role not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Nama sudah dipakai / role sistem",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Menghapus role non-sistem yang tidak lagi dipakai user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Roles"
+                ],
+                "summary": "Delete role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data:null}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (butuh role:manage)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Role not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Role sistem / masih dipakai user",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/roles/{id}/permissions": {
+            "post": {
+                "description": "Menambah permission ke role (idempoten). Data berisi role terbaru dan permission yang baru ditambahkan.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Roles"
+                ],
+                "summary": "Grant permissions to role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Permissions",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.RolePermissionsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data:{role,granted}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Permission kosong / tidak dikenal",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (butuh role:manage)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Role not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/roles/{id}/permissions/{permission}": {
+            "delete": {
+                "description": "Mencabut satu permission dari role. Permission role admin tidak bisa dicabut agar admin tidak terkunci dari sistem.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Roles"
+                ],
+                "summary": "Revoke permission from role",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Role ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Nama permission, mis. achievement:verify",
+                        "name": "permission",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Role"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (butuh role:manage)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Role not found / role tidak memiliki permission tsb",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Role admin",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/statistics/global": {
+            "get": {
+                "description": "Mengambil statistik global prestasi berdasarkan role:\n- admin: melihat semua data\n- dosen_wali: hanya prestasi mahasiswa bimbingannya\n- mahasiswa: hanya prestasi milik sendiri",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Statistics"
+                ],
+                "summary": "Get global statistics (role-based)",
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data} berisi statistik global",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized (role / user_id tidak tersedia)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (profil dosen/mahasiswa tidak ditemukan)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/statistics/students/{id}": {
+            "get": {
+                "description": "Mengambil statistik dan laporan prestasi untuk satu mahasiswa.\nBerdasarkan student ID (UUID dari PostgreSQL).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Statistics"
+                ],
+                "summary": "Get student report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Student ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data} berisi statistik student",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (akses bukan admin / dosen wali)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Student not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/students": {
+            "get": {
+                "description": "Mengambil daftar mahasiswa.\nOptional filter by advisorId dan free-text query.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Students"
+                ],
+                "summary": "Get all students",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Filter by advisor UUID",
+                        "name": "advisorId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Free-text search (nama, NIM, dll)",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/students/{id}": {
+            "get": {
+                "description": "Mengambil detail student berdasarkan ID (UUID).",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Students"
+                ],
+                "summary": "Get student by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Student ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Student not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/students/{id}/achievements": {
+            "get": {
+                "description": "Mengambil achievement references milik student.\nJika reference memiliki mongoId, maka akan di-enrich dengan dokumen MongoDB.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Students",
+                    "Achievements"
+                ],
+                "summary": "Get student's achievements",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Student ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional status filter (draft, submitted, verified, rejected)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "array of { reference: object, achievement: object|null }",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "type": "object",
+                                "additionalProperties": true
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Student not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/students/{id}/advisor": {
+            "put": {
+                "description": "Update advisorId milik student.\nBody JSON: { \"advisorId\": \"\u003cuuid|null\u003e\" }.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Students"
+                ],
+                "summary": "Update student's advisor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Student ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Payload: advisorId (uuid or null)",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateStudentAdvisorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Student advisor updated (envelope)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad request (invalid JSON/body validation)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/users/{id}/permissions": {
+            "get": {
+                "description": "Permission semua role user, override per user (grant / deny), dan permission efektifnya. Urutan resolusi: deny user \u003e grant user \u003e role.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Users"
+                ],
+                "summary": "Get user permissions (admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserPermissionsView"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (not admin)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/users/{id}/permissions/{permission}": {
+            "put": {
+                "description": "Membuat atau mengganti override satu permission untuk user, mis. memberi satu dosen report:statistics atau mencabut achievement:create dari mahasiswa yang diskors. Deny tidak bisa diberikan ke user ber-role admin.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Users"
+                ],
+                "summary": "Grant / deny permission for user (admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Nama permission, mis. report:statistics",
+                        "name": "permission",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Effect (grant / deny) dan alasan",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserPermissionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserPermission"
+                        }
+                    },
+                    "400": {
+                        "description": "Effect tidak valid",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (not admin)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "User / permission not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Deny untuk admin",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            },
+            "delete": {
+                "description": "Menghapus override sehingga permission user kembali mengikuti role-nya",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Users"
+                ],
+                "summary": "Remove user permission override (admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Nama permission",
+                        "name": "permission",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data:null}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (not admin)",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Override not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        },
+        "/users/{id}/unlock": {
+            "post": {
+                "description": "Menghapus hitungan login gagal dan kunci sementara untuk semua identifier user (email dan NIM).\nKunci per IP tidak ikut dihapus.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Admin - Users"
+                ],
+                "summary": "Unlock user login (admin)",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID (UUID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "envelope {status,message,data:{cleared}}",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                },
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ]
+            }
+        }
+    },
+    "definitions": {
+        "models.Achievement": {
+            "type": "object",
+            "properties": {
+                "achievementType": {
+                    "type": "string"
+                },
+                "attachments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Attachment"
+                    }
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "details": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "id": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "studentID": {
+                    "type": "string"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AchievementPage": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "integer"
+                },
+                "next_cursor": {
+                    "type": "string"
+                },
+                "page": {
+                    "description": "kosong pada mode cursor",
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Achievement"
+                    }
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.AchievementType": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "default_points": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "labels": {
+                    "description": "per bahasa, mis. {\"id\": \"Kompetisi\", \"en\": \"Competition\"}",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "schema": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AchievementTypeRequest": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "default_points": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "labels": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.AchievementTypeSchema": {
+            "type": "object",
+            "properties": {
+                "achievement_type": {
+                    "type": "string"
+                },
+                "schema": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.AchievementTypeSchemaRequest": {
+            "type": "object",
+            "properties": {
+                "schema": {
+                    "type": "object",
+                    "additionalProperties": {}
+                }
+            }
+        },
+        "models.Attachment": {
+            "type": "object",
+            "properties": {
+                "fileName": {
+                    "type": "string"
+                },
+                "fileType": {
+                    "type": "string"
+                },
+                "fileURL": {
+                    "type": "string"
+                },
+                "uploadedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BulkItemResult": {
+            "type": "object",
+            "properties": {
+                "allowed_next_states": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "current_status": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "result": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BulkRejectItem": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.BulkRejectRequest": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BulkRejectItem"
+                    }
+                }
+            }
+        },
+        "models.BulkResponse": {
+            "type": "object",
+            "properties": {
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BulkItemResult"
+                    }
+                },
+                "summary": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.BulkVerifyItem": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "justification": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BulkVerifyRequest": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BulkVerifyItem"
+                    }
+                }
+            }
+        },
+        "models.ChangePasswordRequest": {
+            "type": "object",
+            "required": [
+                "current_password",
+                "new_password"
+            ],
+            "properties": {
+                "current_password": {
+                    "type": "string"
+                },
+                "new_password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateAchievementRequest": {
+            "type": "object",
+            "properties": {
+                "achievementType": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "details": {
+                    "description": "divalidasi dengan schema achievementType (GET /achievement-types)",
+                    "type": "object",
+                    "additionalProperties": {}
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateUserRequest": {
+            "type": "object",
+            "required": [
+                "email",
+                "full_name",
+                "is_active",
+                "password",
+                "role_id",
+                "username"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "password": {
+                    "description": "aturan lain: helper.ValidatePassword",
+                    "type": "string"
+                },
+                "role_id": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ForgotPasswordRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "nim": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LoginRequest": {
+            "type": "object",
+            "properties": {
+                "device": {
+                    "description": "nama perangkat (opsional, default User-Agent)",
+                    "type": "string"
+                },
+                "email": {
+                    "type": "string"
+                },
+                "nim": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ReconcileIssue": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "mongo_id": {
+                    "type": "string"
+                },
+                "mongo_status": {
+                    "type": "string"
+                },
+                "reference_id": {
+                    "type": "string"
+                },
+                "reference_status": {
+                    "type": "string"
+                },
+                "repaired": {
+                    "type": "boolean"
                 },
-                "full_name": {
+                "stale": {
+                    "description": "selisih sudah tidak ada saat dicek ulang sebelum diperbaiki",
+                    "type": "boolean"
+                },
+                "student_id": {
                     "type": "string"
+                }
+            }
+        },
+        "models.ReconcileReport": {
+            "type": "object",
+            "properties": {
+                "counts": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
                 },
-                "is_active": {
+                "dry_run": {
                     "type": "boolean"
                 },
-                "password": {
-                    "type": "string",
-                    "minLength": 6
+                "issues": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReconcileIssue"
+                    }
                 },
-                "role_id": {
+                "scanned_mongo": {
+                    "type": "integer"
+                },
+                "scanned_references": {
+                    "type": "integer"
+                },
+                "skipped_recent": {
+                    "description": "prestasi yang baru dibuat saat scan, tidak diperiksa",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.RefreshTokenRequest": {
+            "type": "object",
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RejectAchievementRequest": {
+            "type": "object",
+            "properties": {
+                "note": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ResetPasswordRequest": {
+            "type": "object",
+            "required": [
+                "new_password",
+                "token"
+            ],
+            "properties": {
+                "new_password": {
                     "type": "string"
                 },
-                "username": {
+                "token": {
                     "type": "string"
                 }
             }
         },
-        "models.LoginRequest": {
+        "models.ReviewQueueItem": {
             "type": "object",
             "properties": {
-                "email": {
+                "achievement_type": {
+                    "type": "string"
+                },
+                "age_seconds": {
+                    "description": "lama menunggu di antrian",
+                    "type": "integer"
+                },
+                "attachment_count": {
+                    "type": "integer"
+                },
+                "mongo_achievement_id": {
                     "type": "string"
                 },
                 "nim": {
                     "type": "string"
                 },
-                "password": {
+                "program_study": {
+                    "type": "string"
+                },
+                "reference_id": {
+                    "type": "string"
+                },
+                "student_id": {
+                    "type": "string"
+                },
+                "student_name": {
+                    "type": "string"
+                },
+                "submitted_at": {
+                    "type": "string"
+                },
+                "suggested_points": {
+                    "description": "poin rubrik, null jika tidak ada aturan yang cocok",
+                    "type": "integer"
+                },
+                "title": {
                     "type": "string"
                 }
             }
         },
-        "models.RefreshTokenRequest": {
+        "models.Role": {
             "type": "object",
             "properties": {
-                "token": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
                     "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "is_system": {
+                    "description": "role bawaan: tidak bisa diganti nama / dihapus",
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_count": {
+                    "type": "integer"
                 }
             }
         },
-        "models.RejectAchievementRequest": {
+        "models.RolePermissionsRequest": {
             "type": "object",
             "properties": {
-                "note": {
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.RoleRequest": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "permissions": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.ScoreSuggestion": {
+            "type": "object",
+            "properties": {
+                "max_points": {
+                    "type": "integer"
+                },
+                "min_points": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "rule_id": {
+                    "type": "string"
+                },
+                "suggested_points": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ScoringPolicy": {
+            "type": "object",
+            "properties": {
+                "max_override_percent": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ScoringPolicyRequest": {
+            "type": "object",
+            "properties": {
+                "max_override_percent": {
+                    "type": "integer"
+                },
+                "mode": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ScoringRuleRequest": {
+            "type": "object",
+            "properties": {
+                "achievement_type": {
+                    "type": "string"
+                },
+                "competition_level": {
+                    "type": "string"
+                },
+                "participation": {
+                    "type": "string"
+                },
+                "points": {
+                    "type": "integer"
+                },
+                "rank": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.TypeRemapEntry": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "from": {
                     "type": "string"
+                },
+                "source": {
+                    "description": "\"explicit\" atau \"auto\"",
+                    "type": "string"
+                },
+                "to": {
+                    "description": "kosong = tidak ditemukan padanannya",
+                    "type": "string"
+                }
+            }
+        },
+        "models.TypeRemapReport": {
+            "type": "object",
+            "properties": {
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "remapped": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TypeRemapEntry"
+                    }
+                },
+                "unmapped": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TypeRemapEntry"
+                    }
+                }
+            }
+        },
+        "models.TypeRemapRequest": {
+            "type": "object",
+            "properties": {
+                "mappings": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
                 }
             }
         },
@@ -2015,18 +5038,108 @@ const docTemplate = `{
         },
         "models.UpdateUserRoleRequest": {
             "type": "object",
-            "required": [
-                "role_id"
-            ],
             "properties": {
+                "add_role_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "remove_role_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "role_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserPermission": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "effect": {
+                    "type": "string"
+                },
+                "granted_by": {
+                    "type": "string"
+                },
+                "permission": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserPermissionRequest": {
+            "type": "object",
+            "properties": {
+                "effect": {
+                    "description": "\"grant\" atau \"deny\"",
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.UserPermissionsView": {
+            "type": "object",
+            "properties": {
+                "effective": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "overrides": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserPermission"
+                    }
+                },
+                "role": {
+                    "type": "string"
+                },
                 "role_id": {
                     "type": "string"
+                },
+                "role_permissions": {
+                    "description": "gabungan permission semua role",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "roles": {
+                    "description": "semua role (utama + tambahan)",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "user_id": {
+                    "type": "string"
                 }
             }
         },
         "models.VerifyAchievementRequest": {
             "type": "object",
             "properties": {
+                "justification": {
+                    "type": "string"
+                },
                 "points": {
                     "type": "integer"
                 }
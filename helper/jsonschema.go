@@ -0,0 +1,327 @@
+package helper
+
+import (
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// FieldError: satu pelanggaran schema pada field tertentu (mis. "details.rank")
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Subset JSON Schema yang didukung. Keyword anotasi (title, description, default, examples,
+// $schema, $id, x-*) diabaikan saat validasi tapi tetap disimpan untuk frontend.
+var jsonSchemaKeywords = map[string]bool{
+	"type": true, "properties": true, "required": true, "additionalProperties": true,
+	"enum": true, "minLength": true, "maxLength": true, "pattern": true, "format": true,
+	"minimum": true, "maximum": true, "items": true, "minItems": true, "maxItems": true,
+	"title": true, "description": true, "default": true, "examples": true, "$schema": true, "$id": true,
+}
+
+var jsonSchemaTypes = map[string]bool{
+	"object": true, "array": true, "string": true, "number": true, "integer": true, "boolean": true, "null": true,
+}
+
+var jsonSchemaFormats = map[string]bool{"date": true, "date-time": true, "email": true, "uri": true}
+
+// CheckJSONSchema memastikan schema hanya memakai keyword yang didukung ValidateJSONSchema,
+// sehingga schema dari admin tidak diam-diam diabaikan sebagian.
+func CheckJSONSchema(schema map[string]any) error {
+	return checkSchemaNode(schema, "schema")
+}
+
+func checkSchemaNode(schema map[string]any, path string) error {
+	for key, val := range schema {
+		if !jsonSchemaKeywords[key] && !strings.HasPrefix(key, "x-") {
+			return fmt.Errorf("%s: keyword %q is not supported", path, key)
+		}
+
+		switch key {
+		case "type":
+			types, ok := schemaTypes(val)
+			if !ok {
+				return fmt.Errorf("%s.type must be a string or an array of strings", path)
+			}
+			for _, t := range types {
+				if !jsonSchemaTypes[t] {
+					return fmt.Errorf("%s.type: unknown type %q", path, t)
+				}
+			}
+		case "properties":
+			props, ok := val.(map[string]any)
+			if !ok {
+				return fmt.Errorf("%s.properties must be an object", path)
+			}
+			for name, p := range props {
+				sub, ok := p.(map[string]any)
+				if !ok {
+					return fmt.Errorf("%s.properties.%s must be an object", path, name)
+				}
+				if err := checkSchemaNode(sub, path+".properties."+name); err != nil {
+					return err
+				}
+			}
+		case "items":
+			sub, ok := val.(map[string]any)
+			if !ok {
+				return fmt.Errorf("%s.items must be an object", path)
+			}
+			if err := checkSchemaNode(sub, path+".items"); err != nil {
+				return err
+			}
+		case "required":
+			if _, ok := stringList(val); !ok {
+				return fmt.Errorf("%s.required must be an array of strings", path)
+			}
+		case "additionalProperties":
+			if _, ok := val.(bool); !ok {
+				return fmt.Errorf("%s.additionalProperties must be a boolean", path)
+			}
+		case "enum":
+			if list, ok := val.([]any); !ok || len(list) == 0 {
+				return fmt.Errorf("%s.enum must be a non-empty array", path)
+			}
+		case "minLength", "maxLength", "minItems", "maxItems":
+			if n, ok := toFloat(val); !ok || n < 0 || n != math.Trunc(n) {
+				return fmt.Errorf("%s.%s must be a non-negative integer", path, key)
+			}
+		case "minimum", "maximum":
+			if _, ok := toFloat(val); !ok {
+				return fmt.Errorf("%s.%s must be a number", path, key)
+			}
+		case "pattern":
+			s, ok := val.(string)
+			if !ok {
+				return fmt.Errorf("%s.pattern must be a string", path)
+			}
+			if _, err := regexp.Compile(s); err != nil {
+				return fmt.Errorf("%s.pattern is not a valid regular expression", path)
+			}
+		case "format":
+			if s, ok := val.(string); !ok || !jsonSchemaFormats[s] {
+				return fmt.Errorf("%s.format must be one of date, date-time, email, uri", path)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateJSONSchema memvalidasi value terhadap schema dan mengembalikan semua pelanggaran,
+// diurutkan berdasarkan field. field adalah nama root (mis. "details").
+// Schema diasumsikan sudah lolos CheckJSONSchema.
+func ValidateJSONSchema(schema map[string]any, value any, field string) []FieldError {
+	var errs []FieldError
+	validateSchemaNode(schema, value, field, &errs)
+	sort.SliceStable(errs, func(i, j int) bool { return errs[i].Field < errs[j].Field })
+	return errs
+}
+
+func validateSchemaNode(schema map[string]any, value any, field string, errs *[]FieldError) {
+	add := func(format string, args ...any) {
+		*errs = append(*errs, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if types, ok := schemaTypes(schema["type"]); ok && len(types) > 0 {
+		matched := false
+		for _, t := range types {
+			if matchesType(t, value) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			add("must be of type %s", strings.Join(types, " or "))
+			return
+		}
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if jsonEqual(e, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			allowed := make([]string, len(enum))
+			for i, e := range enum {
+				allowed[i] = fmt.Sprint(e)
+			}
+			add("must be one of: %s", strings.Join(allowed, ", "))
+		}
+	}
+
+	switch v := value.(type) {
+	case string:
+		length := len([]rune(v))
+		if n, ok := toFloat(schema["minLength"]); ok && float64(length) < n {
+			add("must be at least %d characters", int(n))
+		}
+		if n, ok := toFloat(schema["maxLength"]); ok && float64(length) > n {
+			add("must be at most %d characters", int(n))
+		}
+		if p, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(p); err == nil && !re.MatchString(v) {
+				add("does not match pattern %s", p)
+			}
+		}
+		if f, ok := schema["format"].(string); ok && !matchesFormat(f, v) {
+			add("must be a valid %s", f)
+		}
+
+	case map[string]any:
+		required, _ := stringList(schema["required"])
+		for _, name := range required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, FieldError{Field: field + "." + name, Message: "is required"})
+			}
+		}
+		props, _ := schema["properties"].(map[string]any)
+		for name, sub := range props {
+			if pv, ok := v[name]; ok {
+				if subSchema, ok := sub.(map[string]any); ok {
+					validateSchemaNode(subSchema, pv, field+"."+name, errs)
+				}
+			}
+		}
+		if allowed, ok := schema["additionalProperties"].(bool); ok && !allowed {
+			for name := range v {
+				if _, known := props[name]; !known {
+					*errs = append(*errs, FieldError{Field: field + "." + name, Message: "is not allowed"})
+				}
+			}
+		}
+
+	case []any:
+		if n, ok := toFloat(schema["minItems"]); ok && float64(len(v)) < n {
+			add("must contain at least %d items", int(n))
+		}
+		if n, ok := toFloat(schema["maxItems"]); ok && float64(len(v)) > n {
+			add("must contain at most %d items", int(n))
+		}
+		if sub, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				validateSchemaNode(sub, item, fmt.Sprintf("%s[%d]", field, i), errs)
+			}
+		}
+
+	default:
+		if n, ok := toFloat(value); ok {
+			if min, ok := toFloat(schema["minimum"]); ok && n < min {
+				add("must be >= %v", min)
+			}
+			if max, ok := toFloat(schema["maximum"]); ok && n > max {
+				add("must be <= %v", max)
+			}
+		}
+	}
+}
+
+func matchesType(t string, value any) bool {
+	switch t {
+	case "null":
+		return value == nil
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	case "integer":
+		n, ok := toFloat(value)
+		return ok && n == math.Trunc(n)
+	}
+	return false
+}
+
+func matchesFormat(format, s string) bool {
+	switch format {
+	case "date":
+		_, err := time.Parse("2006-01-02", s)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, s)
+		return err == nil
+	case "email":
+		_, err := mail.ParseAddress(s)
+		return err == nil
+	case "uri":
+		u, err := url.ParseRequestURI(s)
+		return err == nil && u.Scheme != ""
+	}
+	return true
+}
+
+// toFloat menerima angka dari JSON (float64) maupun dari Mongo (int32/int64).
+func toFloat(v any) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func jsonEqual(a, b any) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func schemaTypes(v any) ([]string, bool) {
+	switch t := v.(type) {
+	case nil:
+		return nil, true
+	case string:
+		return []string{t}, true
+	}
+	return stringList(v)
+}
+
+func stringList(v any) ([]string, bool) {
+	switch list := v.(type) {
+	case nil:
+		return nil, true
+	case []string:
+		return list, true
+	case []any:
+		out := make([]string, 0, len(list))
+		for _, item := range list {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			out = append(out, s)
+		}
+		return out, true
+	}
+	return nil, false
+}
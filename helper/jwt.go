@@ -1,34 +1,88 @@
 package helper
 
 import (
+	"strconv"
 	"time"
 	"UAS_GO/app/models"
+	"UAS_GO/config"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
-var jwtSecret = []byte("your-secret-key-min-32-characters-long")
+// AccessTokenTTL: umur access token (env ACCESS_TOKEN_TTL, default 15 menit)
+func AccessTokenTTL() time.Duration {
+	return parseDurationEnv("ACCESS_TOKEN_TTL", 15*time.Minute)
+}
+
+// RefreshTokenTTL: umur refresh token (env REFRESH_TOKEN_TTL, default 7 hari)
+func RefreshTokenTTL() time.Duration {
+	return parseDurationEnv("REFRESH_TOKEN_TTL", 7*24*time.Hour)
+}
+
+func parseDurationEnv(key string, fallback time.Duration) time.Duration {
+	d, err := time.ParseDuration(config.GetEnv(key, ""))
+	if err != nil || d <= 0 {
+		return fallback
+	}
+	return d
+}
+
+// EmbedPermissionsInToken: env JWT_EMBED_PERMISSIONS=true menyalin permission role ke claim "perms"
+// sehingga middleware tidak perlu mengecek role_permissions. Permission yang dicabut tetap
+// berlaku di token lama sampai expired (ACCESS_TOKEN_TTL).
+func EmbedPermissionsInToken() bool {
+	embed, _ := strconv.ParseBool(config.GetEnv("JWT_EMBED_PERMISSIONS", "false"))
+	return embed
+}
+
+// GenerateAccessToken membuat access token berumur pendek.
+// sessionID = family id refresh token milik perangkat yang login.
+func GenerateAccessToken(user models.User, sessionID string) (string, time.Time, error) {
+	return GenerateAccessTokenWithPermissions(user, sessionID, nil)
+}
+
+// GenerateAccessTokenWithPermissions: seperti GenerateAccessToken, dengan claim "perms".
+func GenerateAccessTokenWithPermissions(user models.User, sessionID string, permissions []string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(AccessTokenTTL())
 
-func GenerateToken(user models.User) (string, error) {
 	claims := models.JWTClaims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   user.RoleID,
+		UserID:      user.ID,
+		Email:       user.Email,
+		Role:        user.RoleID,
+		Roles:       user.RoleIDs,
+		SessionID:   sessionID,
+		Permissions: permissions,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			// jti dipakai untuk mencabut token saat logout
+			ID:        uuid.New().String(),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	ks, err := jwtKeys()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	token := jwt.NewWithClaims(ks.active.method, claims)
+	token.Header["kid"] = ks.active.kid
+	signed, err := token.SignedString(ks.active.signKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expiresAt, nil
 }
 
 // Validasi token JWT
 func ValidateToken(tokenString string) (*models.JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &models.JWTClaims{},
-		func(token *jwt.Token) (interface{}, error) {
-			return jwtSecret, nil
-		})
+	ks, err := jwtKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.ParseWithClaims(tokenString, &models.JWTClaims{}, ks.lookup)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,276 @@
+package helper
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"UAS_GO/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Konfigurasi kunci JWT (semua lewat env):
+//
+//	JWT_ALG              HS256 (default) | RS256 | EdDSA
+//	JWT_KEY_ID           kid untuk kunci aktif (default "default")
+//	JWT_SECRET           secret HS256 (min 32 karakter); wajib kecuali APP_ENV=development
+//	JWT_PRIVATE_KEY_FILE path PEM private key untuk RS256 / EdDSA
+//	JWT_VERIFY_KEYS      kunci lama yang masih diterima, format "kid=path.pem,kid2=path2.pem"
+//	                     (public atau private key PEM, algoritma dideteksi dari tipe kunci)
+//	JWT_PREVIOUS_SECRETS secret HS256 lama, format "kid=secret,kid2=secret2" (masing-masing min 32 karakter)
+//
+// Rotasi: buat kunci baru, pindahkan kunci lama ke JWT_VERIFY_KEYS / JWT_PREVIOUS_SECRETS,
+// lalu ganti JWT_KEY_ID. Token lama tetap valid sampai expired.
+
+// devJWTSecret publik di repo: hanya dipakai jika APP_ENV=development
+const devJWTSecret = "your-secret-key-min-32-characters-long"
+
+const minJWTSecretLength = 32
+
+type jwtKey struct {
+	kid       string
+	method    jwt.SigningMethod
+	signKey   any // nil untuk kunci yang hanya dipakai verifikasi
+	verifyKey any
+}
+
+type jwtKeySet struct {
+	active *jwtKey
+	byKID  map[string]*jwtKey
+}
+
+var (
+	keySet     *jwtKeySet
+	keySetErr  error
+	keySetOnce sync.Once
+)
+
+// InitJWTKeys memuat kunci JWT dari env. Dipanggil saat startup agar
+// konfigurasi yang salah langsung ketahuan; jika tidak dipanggil, kunci
+// dimuat otomatis saat token pertama kali dibuat / divalidasi.
+func InitJWTKeys() error {
+	_, err := jwtKeys()
+	return err
+}
+
+func jwtKeys() (*jwtKeySet, error) {
+	keySetOnce.Do(func() {
+		keySet, keySetErr = loadJWTKeySet()
+	})
+	return keySet, keySetErr
+}
+
+func loadJWTKeySet() (*jwtKeySet, error) {
+	ks := &jwtKeySet{byKID: map[string]*jwtKey{}}
+	kid := config.GetEnv("JWT_KEY_ID", "default")
+
+	switch alg := config.GetEnv("JWT_ALG", "HS256"); alg {
+	case "HS256":
+		secret := config.GetEnv("JWT_SECRET", "")
+		if secret == "" {
+			if !config.IsDevelopment() {
+				return nil, errors.New("JWT_SECRET wajib di-set (secret development hanya dipakai jika APP_ENV=development)")
+			}
+			log.Println("  JWT_SECRET tidak di-set, memakai secret development (APP_ENV=development)")
+			secret = devJWTSecret
+		}
+		if len(secret) < minJWTSecretLength {
+			return nil, fmt.Errorf("JWT_SECRET minimal %d karakter", minJWTSecretLength)
+		}
+		ks.active = &jwtKey{kid: kid, method: jwt.SigningMethodHS256, signKey: []byte(secret), verifyKey: []byte(secret)}
+
+	case "RS256", "EdDSA":
+		path := config.GetEnv("JWT_PRIVATE_KEY_FILE", "")
+		if path == "" {
+			return nil, fmt.Errorf("JWT_PRIVATE_KEY_FILE wajib di-set untuk %s", alg)
+		}
+		key, err := loadPEMKey(kid, path)
+		if err != nil {
+			return nil, err
+		}
+		if key.signKey == nil {
+			return nil, fmt.Errorf("%s bukan private key", path)
+		}
+		if key.method.Alg() != alg {
+			return nil, fmt.Errorf("%s berisi kunci %s, bukan %s", path, key.method.Alg(), alg)
+		}
+		ks.active = key
+
+	default:
+		return nil, fmt.Errorf("JWT_ALG tidak didukung: %s", alg)
+	}
+	ks.byKID[kid] = ks.active
+
+	for vkid, path := range parseKeyValueList(config.GetEnv("JWT_VERIFY_KEYS", "")) {
+		key, err := loadPEMKey(vkid, path)
+		if err != nil {
+			return nil, err
+		}
+		// kunci lama hanya untuk verifikasi
+		key.signKey = nil
+		if err := ks.add(key); err != nil {
+			return nil, err
+		}
+	}
+
+	for vkid, secret := range parseKeyValueList(config.GetEnv("JWT_PREVIOUS_SECRETS", "")) {
+		if len(secret) < minJWTSecretLength {
+			return nil, fmt.Errorf("JWT_PREVIOUS_SECRETS %s minimal %d karakter", vkid, minJWTSecretLength)
+		}
+		key := &jwtKey{kid: vkid, method: jwt.SigningMethodHS256, verifyKey: []byte(secret)}
+		if err := ks.add(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return ks, nil
+}
+
+func (ks *jwtKeySet) add(key *jwtKey) error {
+	if _, exists := ks.byKID[key.kid]; exists {
+		return fmt.Errorf("kid JWT duplikat: %s", key.kid)
+	}
+	ks.byKID[key.kid] = key
+	return nil
+}
+
+// lookup mencari kunci verifikasi berdasarkan header kid.
+// Token tanpa kid (diterbitkan sebelum rotasi didukung) dicek dengan kunci aktif.
+func (ks *jwtKeySet) lookup(token *jwt.Token) (any, error) {
+	key := ks.active
+	if kid, ok := token.Header["kid"].(string); ok && kid != "" {
+		key = ks.byKID[kid]
+		if key == nil {
+			return nil, fmt.Errorf("unknown kid: %s", kid)
+		}
+	}
+
+	// cegah algorithm confusion: alg token harus sama dengan alg kunci
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %s", token.Method.Alg())
+	}
+	return key.verifyKey, nil
+}
+
+// parseKeyValueList mem-parse "a=1,b=2" menjadi map.
+func parseKeyValueList(raw string) map[string]string {
+	out := map[string]string{}
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		out[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return out
+}
+
+// loadPEMKey membaca RSA / Ed25519 key (private atau public) dari file PEM.
+func loadPEMKey(kid, path string) (*jwtKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca kunci JWT %s: %w", path, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s bukan file PEM", path)
+	}
+
+	var parsed any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: tipe PEM tidak didukung (%s)", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	key := &jwtKey{kid: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.signKey, key.verifyKey = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.method, key.verifyKey = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.method, key.signKey, key.verifyKey = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.method, key.verifyKey = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("%s: tipe kunci tidak didukung (%T)", path, parsed)
+	}
+	return key, nil
+}
+
+// JWKS mengembalikan public key (RS256 / EdDSA) dalam format JSON Web Key Set
+// agar service lain bisa memverifikasi token. Secret HS256 tidak pernah dipublikasikan.
+func JWKS() (map[string]any, error) {
+	ks, err := jwtKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	kids := make([]string, 0, len(ks.byKID))
+	for kid := range ks.byKID {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	keys := []map[string]any{}
+	for _, kid := range kids {
+		if jwk := toJWK(ks.byKID[kid]); jwk != nil {
+			keys = append(keys, jwk)
+		}
+	}
+	return map[string]any{"keys": keys}, nil
+}
+
+func toJWK(key *jwtKey) map[string]any {
+	b64 := base64.RawURLEncoding.EncodeToString
+
+	switch pub := key.verifyKey.(type) {
+	case *rsa.PublicKey:
+		return map[string]any{
+			"kty": "RSA",
+			"use": "sig",
+			"alg": key.method.Alg(),
+			"kid": key.kid,
+			"n":   b64(pub.N.Bytes()),
+			"e":   b64(big.NewInt(int64(pub.E)).Bytes()),
+		}
+	case ed25519.PublicKey:
+		return map[string]any{
+			"kty": "OKP",
+			"crv": "Ed25519",
+			"use": "sig",
+			"alg": key.method.Alg(),
+			"kid": key.kid,
+			"x":   b64(pub),
+		}
+	}
+	// secret HS256 tidak dipublikasikan
+	return nil
+}
@@ -0,0 +1,120 @@
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"UAS_GO/config"
+
+	"github.com/google/uuid"
+)
+
+// Konfigurasi pengiriman email (semua lewat env):
+//
+//	MAIL_DRIVER log | file (kosong: log jika APP_ENV development, selain itu tidak ada mailer)
+//	MAIL_DIR    folder tujuan driver file (default "logs/mail")
+//	MAIL_FROM   alamat pengirim (default "no-reply@localhost")
+//
+// Keduanya pengganti untuk lokal / development dan ikut menulis isi email (termasuk token reset),
+// jadi di luar development harus dipilih secara eksplisit. Server SMTP cukup mengimplementasikan
+// Mailer lalu dipasang dengan SetMailer saat startup.
+
+type Mail struct {
+	From    string
+	To      string
+	Subject string
+	Body    string
+}
+
+type Mailer interface {
+	Send(m Mail) error
+}
+
+// LogMailer menulis email ke log aplikasi.
+type LogMailer struct{}
+
+func (LogMailer) Send(m Mail) error {
+	log.Printf("MAIL: from=%s to=%s subject=%q\n%s\n", m.From, m.To, m.Subject, m.Body)
+	return nil
+}
+
+// FileMailer menyimpan tiap email sebagai file .eml di Dir.
+type FileMailer struct {
+	Dir string
+}
+
+func (f FileMailer) Send(m Mail) error {
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return err
+	}
+	name := time.Now().UTC().Format("20060102T150405") + "-" + uuid.NewString() + ".eml"
+	content := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\n\r\n%s\r\n",
+		m.From, m.To, m.Subject, time.Now().Format(time.RFC1123Z), m.Body)
+	return os.WriteFile(filepath.Join(f.Dir, name), []byte(content), 0o600)
+}
+
+var (
+	mailerMu sync.RWMutex
+	mailer   Mailer
+)
+
+// SetMailer mengganti pengirim email (mis. SMTP di produksi, stub di test).
+func SetMailer(m Mailer) {
+	mailerMu.Lock()
+	defer mailerMu.Unlock()
+	mailer = m
+}
+
+// ErrMailerNotConfigured: tidak ada Mailer dari SetMailer dan MAIL_DRIVER kosong di luar development.
+var ErrMailerNotConfigured = errors.New("no mailer configured (set MAIL_DRIVER)")
+
+func getMailer() (Mailer, error) {
+	mailerMu.RLock()
+	m := mailer
+	mailerMu.RUnlock()
+	if m != nil {
+		return m, nil
+	}
+
+	driver := strings.ToLower(strings.TrimSpace(config.GetEnv("MAIL_DRIVER", "")))
+	if driver == "" {
+		if !config.IsDevelopment() {
+			return nil, ErrMailerNotConfigured
+		}
+		driver = "log"
+	}
+	switch driver {
+	case "log":
+		m = LogMailer{}
+	case "file":
+		m = FileMailer{Dir: config.GetEnv("MAIL_DIR", "logs/mail")}
+	default:
+		return nil, fmt.Errorf("MAIL_DRIVER %q tidak dikenal", driver)
+	}
+	SetMailer(m)
+	return m, nil
+}
+
+// CheckMailer: nil jika email bisa dikirim (dipanggil saat startup dan sebelum membuat token reset).
+func CheckMailer() error {
+	_, err := getMailer()
+	return err
+}
+
+// SendMail mengirim email lewat Mailer aktif; From diisi MAIL_FROM jika kosong.
+func SendMail(m Mail) error {
+	mailer, err := getMailer()
+	if err != nil {
+		return err
+	}
+	if m.From == "" {
+		m.From = config.GetEnv("MAIL_FROM", "no-reply@localhost")
+	}
+	return mailer.Send(m)
+}
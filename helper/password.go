@@ -2,9 +2,9 @@ package helper
 
 import "golang.org/x/crypto/bcrypt"
 
-// Hash password plaintext jadi bcrypt hash
+// Hash password plaintext jadi bcrypt hash (cost dari PASSWORD_BCRYPT_COST)
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), BcryptCost())
 	return string(bytes), err
 }
 
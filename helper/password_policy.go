@@ -0,0 +1,166 @@
+package helper
+
+import (
+	"bufio"
+	_ "embed"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"UAS_GO/config"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Kebijakan password (semua lewat env):
+//
+//	PASSWORD_MIN_LENGTH  panjang minimum (default 8)
+//	PASSWORD_MIN_CLASSES jumlah minimum jenis karakter dari huruf kecil, huruf besar, angka, simbol (default 3)
+//	PASSWORD_BCRYPT_COST cost bcrypt untuk hash baru (default bcrypt.DefaultCost)
+//
+// Selain itu password tidak boleh ada di daftar password umum (common_passwords.txt)
+// dan tidak boleh sama dengan username / email / NIM pemiliknya.
+
+const (
+	defaultPasswordMinLength  = 8
+	defaultPasswordMinClasses = 3
+	// bcrypt hanya memakai 72 byte pertama
+	passwordMaxBytes = 72
+)
+
+//go:embed common_passwords.txt
+var commonPasswordsFile string
+
+var (
+	commonPasswordsOnce sync.Once
+	commonPasswords     map[string]bool
+)
+
+func isCommonPassword(password string) bool {
+	commonPasswordsOnce.Do(func() {
+		commonPasswords = map[string]bool{}
+		sc := bufio.NewScanner(strings.NewReader(commonPasswordsFile))
+		for sc.Scan() {
+			line := strings.TrimSpace(sc.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			commonPasswords[strings.ToLower(line)] = true
+		}
+	})
+	return commonPasswords[strings.ToLower(password)]
+}
+
+type PasswordPolicy struct {
+	MinLength  int
+	MinClasses int
+}
+
+// PasswordPolicyError berisi semua aturan yang dilanggar.
+type PasswordPolicyError struct {
+	Problems []string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "password does not meet the policy: " + strings.Join(e.Problems, "; ")
+}
+
+func LoadPasswordPolicy() PasswordPolicy {
+	p := PasswordPolicy{
+		MinLength:  parseIntEnv("PASSWORD_MIN_LENGTH", defaultPasswordMinLength),
+		MinClasses: parseIntEnv("PASSWORD_MIN_CLASSES", defaultPasswordMinClasses),
+	}
+	if p.MinLength < 1 {
+		p.MinLength = defaultPasswordMinLength
+	}
+	if p.MinClasses < 0 || p.MinClasses > 4 {
+		p.MinClasses = defaultPasswordMinClasses
+	}
+	return p
+}
+
+func parseIntEnv(key string, fallback int) int {
+	n, err := strconv.Atoi(strings.TrimSpace(config.GetEnv(key, "")))
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+// Validate memeriksa password terhadap kebijakan. identifiers: username, email, NIM pemilik
+// (nilai kosong diabaikan); bagian sebelum @ pada email ikut dibandingkan.
+func (p PasswordPolicy) Validate(password string, identifiers ...string) error {
+	var problems []string
+
+	if n := len([]rune(password)); n < p.MinLength {
+		problems = append(problems, "must be at least "+strconv.Itoa(p.MinLength)+" characters")
+	}
+	if len(password) > passwordMaxBytes {
+		problems = append(problems, "must be at most "+strconv.Itoa(passwordMaxBytes)+" bytes")
+	}
+
+	var lower, upper, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+	classes := 0
+	for _, ok := range []bool{lower, upper, digit, symbol} {
+		if ok {
+			classes++
+		}
+	}
+	if classes < p.MinClasses {
+		problems = append(problems, "must contain at least "+strconv.Itoa(p.MinClasses)+
+			" of: lowercase letter, uppercase letter, digit, symbol")
+	}
+
+	if isCommonPassword(password) {
+		problems = append(problems, "is too common")
+	}
+	for _, id := range identifiers {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		local, _, _ := strings.Cut(id, "@")
+		if strings.EqualFold(password, id) || strings.EqualFold(password, local) {
+			problems = append(problems, "must not be the same as the username, email or NIM")
+			break
+		}
+	}
+
+	if len(problems) > 0 {
+		return &PasswordPolicyError{Problems: problems}
+	}
+	return nil
+}
+
+// ValidatePassword: Validate dengan kebijakan dari env.
+func ValidatePassword(password string, identifiers ...string) error {
+	return LoadPasswordPolicy().Validate(password, identifiers...)
+}
+
+// BcryptCost: cost untuk hash baru (env PASSWORD_BCRYPT_COST, di luar rentang bcrypt memakai default).
+func BcryptCost() int {
+	cost := parseIntEnv("PASSWORD_BCRYPT_COST", bcrypt.DefaultCost)
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return bcrypt.DefaultCost
+	}
+	return cost
+}
+
+// PasswordNeedsRehash: hash dibuat dengan cost di bawah BcryptCost (mis. sebelum cost dinaikkan).
+func PasswordNeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+	return err == nil && cost < BcryptCost()
+}
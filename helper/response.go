@@ -40,6 +40,14 @@ func Unprocessable(c *fiber.Ctx, msg string) error {
 	return APIResponse(c, fiber.StatusUnprocessableEntity, msg, nil)
 }
 
+func TooManyRequests(c *fiber.Ctx, msg string) error {
+	return APIResponse(c, fiber.StatusTooManyRequests, msg, nil)
+}
+
+func ServiceUnavailable(c *fiber.Ctx, msg string) error {
+	return APIResponse(c, fiber.StatusServiceUnavailable, msg, nil)
+}
+
 func InternalError(c *fiber.Ctx, msg string) error {
 	return APIResponse(c, fiber.StatusInternalServerError, msg, nil)
 }
@@ -71,3 +79,26 @@ func GetUserID(c *fiber.Ctx) string {
 
     return ""
 }
+
+// GetRoles: nama semua role user (utama + tambahan) dari JWT context; token lama hanya membawa role utama.
+func GetRoles(c *fiber.Ctx) []string {
+	if roles, ok := c.Locals("roles").([]string); ok && len(roles) > 0 {
+		return roles
+	}
+	if role, ok := c.Locals("role").(string); ok && role != "" {
+		return []string{role}
+	}
+	return nil
+}
+
+// HasRole: true jika user memegang salah satu role yang disebut.
+func HasRole(c *fiber.Ctx, roles ...string) bool {
+	for _, held := range GetRoles(c) {
+		for _, r := range roles {
+			if held == r {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package helper
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+)
+
+// GenerateOpaqueToken membuat token acak (256-bit) yang aman untuk URL.
+// Dipakai untuk refresh token, bukan JWT.
+func GenerateOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken mengembalikan SHA-256 (hex) dari token opaque.
+// Hanya hash yang disimpan di database.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// PasswordResetTTL: umur token reset password (env PASSWORD_RESET_TTL, default 30 menit)
+func PasswordResetTTL() time.Duration {
+	return parseDurationEnv("PASSWORD_RESET_TTL", 30*time.Minute)
+}
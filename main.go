@@ -1,9 +1,15 @@
 package main
 
 import (
+	"log"
+	"os"
+	"time"
+
+	"UAS_GO/app/service"
 	"UAS_GO/config"
 	"UAS_GO/database"
 	_ "UAS_GO/docs" // <- wajib: package yang dibuat swag
+	"UAS_GO/helper"
 	"UAS_GO/route"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	fiberSwagger "github.com/swaggo/fiber-swagger"
@@ -20,13 +26,38 @@ import (
 // @in header
 // @name Authorization
 func main() {
+	// subcommand CLI, mis. `go run . migrate up`
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1:])
+		return
+	}
+
 	config.LoadEnv()
+	if err := helper.InitJWTKeys(); err != nil {
+		log.Fatalf(" Konfigurasi kunci JWT tidak valid: %v", err)
+	}
+	if err := helper.CheckMailer(); err != nil {
+		log.Printf("  Email tidak bisa dikirim, POST /auth/forgot akan ditolak: %v", err)
+	}
 
 	database.ConnectPostgres()
 	database.ConnectMongoDB()
-	// database.AutoMigrate()
+	// skema dikelola lewat `go run . migrate up`; set DB_AUTO_MIGRATE=true untuk menjalankannya saat startup
+	if config.GetEnv("DB_AUTO_MIGRATE", "false") == "true" {
+		if _, err := database.MigrateUp(database.PSQL); err != nil {
+			log.Fatalf(" Migrasi gagal: %v", err)
+		}
+	}
 	// database.MigrateTesting(database.PSQL) // uncomment jika perlu
 
+	// index & $jsonSchema validator collection achievements (idempotent)
+	if _, err := database.BootstrapMongo(database.MongoDB); err != nil {
+		log.Printf("  Bootstrap MongoDB gagal: %v", err)
+	}
+
+	// bersihkan blacklist token & refresh token yang sudah expired setiap jam
+	service.StartTokenCleanup(time.Hour)
+
 	app := config.NewApp()
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*", // atau contoh: "http://localhost:3000,http://localhost:8080"
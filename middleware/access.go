@@ -0,0 +1,89 @@
+package middleware
+
+import (
+	"UAS_GO/app/repository"
+	"UAS_GO/helper"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// accessError: alasan akses ditolak, beserta status HTTP-nya
+type accessError struct {
+	status  int
+	message string
+}
+
+func (e *accessError) Error() string { return e.message }
+
+func respondAccessError(c *fiber.Ctx, err error) error {
+	var ae *accessError
+	if errors.As(err, &ae) {
+		return helper.APIResponse(c, ae.status, ae.message, nil)
+	}
+	return helper.InternalError(c, err.Error())
+}
+
+// checkStudentAccess: admin boleh semua, mahasiswa hanya datanya sendiri,
+// dosen wali hanya mahasiswa bimbingannya. User dengan beberapa role cukup lolos
+// lewat salah satunya. nil berarti boleh.
+func checkStudentAccess(c *fiber.Ctx, studentID string) error {
+	// admin always allowed
+	if helper.HasRole(c, "admin") {
+		return nil
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	if userID == "" {
+		return &accessError{fiber.StatusUnauthorized, "Unauthorized"}
+	}
+
+	// others not allowed
+	var denied error = &accessError{fiber.StatusForbidden, "Access denied"}
+
+	// if mahasiswa -> ensure it's their own resource
+	if helper.HasRole(c, "mahasiswa") {
+		if denied = checkStudentSelf(userID, studentID); denied == nil {
+			return nil
+		}
+	}
+
+	// if lecturer/dosen_wali -> check advisor relation
+	if helper.HasRole(c, "dosen_wali", "lecturer") {
+		if denied = checkStudentAdvisor(userID, studentID); denied == nil {
+			return nil
+		}
+	}
+	return denied
+}
+
+func checkStudentSelf(userID, studentID string) error {
+	sid, err := repository.GetStudentIDByUserID(userID)
+	if err != nil {
+		return &accessError{fiber.StatusForbidden, "Student profile not found"}
+	}
+	if sid != studentID {
+		return &accessError{fiber.StatusForbidden, "You are not allowed to access this student's data"}
+	}
+	return nil
+}
+
+func checkStudentAdvisor(userID, studentID string) error {
+	lecturerID, err := repository.GetLecturerIDByUserID(userID)
+	if err != nil {
+		return &accessError{fiber.StatusForbidden, "Lecturer profile not found"}
+	}
+	isAdvisor, err := repository.IsLecturerAdvisorOfStudent(lecturerID, studentID)
+	if err != nil {
+		return errors.New("Error checking advisor relation")
+	}
+	if !isAdvisor {
+		return &accessError{fiber.StatusForbidden, "You are not the academic advisor for this student"}
+	}
+	return nil
+}
+
+// AchievementOwnerOrAdvisorOrAdmin: :id adalah Mongo ID prestasi; lihat AchievementOwnerOrAdvisor.
+func AchievementOwnerOrAdvisorOrAdmin() fiber.Handler {
+	return Authorize(Policy{Rule: AchievementOwnerOrAdvisor})
+}
@@ -3,7 +3,10 @@ package middleware
 import (
 	"UAS_GO/app/repository"
 	"UAS_GO/helper"
+	"database/sql"
+	"errors"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -26,16 +29,68 @@ func AuthRequired() fiber.Handler {
             return helper.Unauthorized(c, "Token tidak valid atau expired")
         }
 
-        // claims.Role diasumsikan adalah role ID (UUID). Ambil nama role untuk convenience.
-        roleName, err := repository.GetRoleNameByID(claims.Role)
+        // token tanpa jti tidak bisa dicabut -> tolak
+        if claims.ID == "" {
+            return helper.Unauthorized(c, "Token tidak valid atau expired")
+        }
+
+        revoked, err := repository.IsTokenRevoked(claims.ID)
+        if err != nil {
+            return helper.InternalError(c, "Error checking token status")
+        }
+        if revoked {
+            return helper.Unauthorized(c, "Token sudah dicabut, silakan login ulang")
+        }
+
+        // token yang terbit sebelum password diganti / direset tidak berlaku lagi.
+        // iat hanya presisi detik, jadi waktu ganti password ikut dibulatkan ke bawah.
+        changedAt, err := repository.GetPasswordChangedAt(claims.UserID)
+        if errors.Is(err, sql.ErrNoRows) {
+            return helper.Unauthorized(c, "Token tidak valid atau expired")
+        }
+        if err != nil {
+            return helper.InternalError(c, "Error checking token status")
+        }
+        if !changedAt.IsZero() && (claims.IssuedAt == nil || claims.IssuedAt.Time.Before(changedAt.Truncate(time.Second))) {
+            return helper.Unauthorized(c, "Password telah diganti, silakan login ulang")
+        }
+
+        // claims.Role diasumsikan adalah role ID (UUID). Ambil nama role untuk convenience (lewat cache).
+        roleName, err := repository.CachedRoleName(claims.Role)
         if err != nil {
             return helper.Unauthorized(c, "Role tidak ditemukan")
         }
 
+        // claims.Roles: semua role user (token lama hanya punya role utama).
+        // Role tambahan yang sudah tidak ada dilewati saja.
+        roleIDs, roleNames := []string{claims.Role}, []string{roleName}
+        for _, id := range claims.Roles {
+            if id == claims.Role {
+                continue
+            }
+            name, err := repository.CachedRoleName(id)
+            if err != nil {
+                continue
+            }
+            roleIDs = append(roleIDs, id)
+            roleNames = append(roleNames, name)
+        }
+
         c.Locals("user_id", claims.UserID)
         c.Locals("email", claims.Email)
         c.Locals("role", roleName)
         c.Locals("role_id", claims.Role) // <<-- simpan role id juga
+        c.Locals("roles", roleNames)
+        c.Locals("role_ids", roleIDs)
+        c.Locals("jti", claims.ID)
+        c.Locals("session_id", claims.SessionID)
+        if claims.ExpiresAt != nil {
+            c.Locals("token_exp", claims.ExpiresAt.Time)
+        }
+        // fast path checkPermission (hanya ada jika JWT_EMBED_PERMISSIONS aktif saat token dibuat)
+        if len(claims.Permissions) > 0 {
+            c.Locals("permissions", claims.Permissions)
+        }
 
 
 
@@ -43,43 +98,12 @@ func AuthRequired() fiber.Handler {
     }
 }
 func PermissionRequired(permission string) fiber.Handler {
-    return func(c *fiber.Ctx) error {
-        // 1) Fast path: cek apakah permissions ada di Locals (mis. dari JWT claims)
-        if permsVal := c.Locals("permissions"); permsVal != nil {
-            if permsSlice, ok := permsVal.([]string); ok {
-                for _, p := range permsSlice {
-                    if p == permission {
-                        return c.Next()
-                    }
-                }
-            }
-        }
-
-        // 2) Ambil role_id dari locals
-        roleIDVal := c.Locals("role_id")
-        roleID, _ := roleIDVal.(string)
-        if roleID == "" {
-            // jika role_id kosong, kemungkinan AuthRequired belum dipanggil
-            return helper.Unauthorized(c, "Unauthorized")
-        }
-
-        // 3) Cek lewat repository (DB)
-        has, err := repository.RoleHasPermission(roleID, permission)
-        if err != nil {
-            return helper.InternalError(c, "Error checking permissions")
-        }
-        if !has {
-            return helper.Forbidden(c, "Akses ditolak. Permission diperlukan: "+permission)
-        }
-
-        return c.Next()
-    }
+	return Authorize(Policy{Permission: permission})
 }
 
 func AdminOnly() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		role := c.Locals("role") 
-		if roleStr, ok := role.(string); ok && roleStr == "admin" {
+		if helper.HasRole(c, "admin") {
 			return c.Next()
 		}
 		
@@ -89,8 +113,7 @@ func AdminOnly() fiber.Handler {
 
 func DosenWaliOnly() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		role := c.Locals("role")
-		if roleStr, ok := role.(string); ok && roleStr == "dosen_wali" {
+		if helper.HasRole(c, "dosen_wali") {
 			return c.Next()
 		}		
 		return helper.Forbidden(c, "Akses ditolak. Hanya dosen wali yang diizinkan.")
@@ -99,163 +122,24 @@ func DosenWaliOnly() fiber.Handler {
 
 func MahasiswaOnly() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		role := c.Locals("role")	
-		if roleStr, ok := role.(string); ok && roleStr == "mahasiswa" {
+		if helper.HasRole(c, "mahasiswa") {
 			return c.Next()
 		}		
 		return helper.Forbidden(c, "Akses ditolak. Hanya mahasiswa yang diizinkan.")
 	}	
 }
 
+// OwnerOrAdvisorOrAdmin: :id adalah students.id; lihat StudentOwnerOrAdvisor.
 func OwnerOrAdvisorOrAdmin() fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		roleVal := c.Locals("role")
-		role, _ := roleVal.(string)
-
-		// admin always allowed
-		if role == "admin" {
-			return c.Next()
-		}
-
-		// get student id from path
-		studentID := c.Params("id")
-		if studentID == "" {
-			return helper.BadRequest(c, "Student id is required")
-		}
-
-		// if mahasiswa -> ensure it's their own resource
-		if role == "mahasiswa" {
-			userIDVal := c.Locals("user_id")
-			userID, _ := userIDVal.(string)
-			if userID == "" {
-				return helper.Unauthorized(c, "Unauthorized")
-			}
-			// convert user -> student.id
-			sid, err := repository.GetStudentIDByUserID(userID)
-			if err != nil {
-				return helper.Forbidden(c, "Student profile not found")
-			}
-			if sid == studentID {
-				return c.Next()
-			}
-			return helper.Forbidden(c, "You are not allowed to access this student's data")
-		}
-
-		// if lecturer/dosen_wali -> check advisor relation
-		if role == "dosen_wali" || role == "lecturer" {
-			userIDVal := c.Locals("user_id")
-			userID, _ := userIDVal.(string)
-			if userID == "" {
-				return helper.Unauthorized(c, "Unauthorized")
-			}
-			lecturerID, err := repository.GetLecturerIDByUserID(userID)
-			if err != nil {
-				return helper.Forbidden(c, "Lecturer profile not found")
-			}
-			isAdvisor, err := repository.IsLecturerAdvisorOfStudent(lecturerID, studentID)
-			if err != nil {
-				return helper.InternalError(c, "Error checking advisor relation")
-			}
-			if isAdvisor {
-				return c.Next()
-			}
-			return helper.Forbidden(c, "You are not the academic advisor for this student")
-		}
-
-		// others not allowed
-		return helper.Forbidden(c, "Access denied")
-	}
+	return Authorize(Policy{Rule: StudentOwnerOrAdvisor})
 }
 
+// LecturerOrAdminForLecturerResource: :id adalah lecturers.id; lihat LecturerSelf.
 func LecturerOrAdminForLecturerResource() fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		roleVal := c.Locals("role")
-		role, _ := roleVal.(string)
-
-		// admin allowed
-		if role == "admin" {
-			return c.Next()
-		}
-
-		if role != "dosen_wali" {
-			return helper.Forbidden(c, "Akses ditolak. Hanya admin atau dosen yang diizinkan.")
-		}
-
-		paramLecturerID := c.Params("id")
-		if paramLecturerID == "" {
-			return helper.BadRequest(c, "Lecturer id required")
-		}
-
-		// get lecturer id by current user
-		userIDVal := c.Locals("user_id")
-		userID, _ := userIDVal.(string)
-		if userID == "" {
-			return helper.Unauthorized(c, "Unauthorized")
-		}
-
-		lecturerID, err := repository.GetLecturerIDByUserID(userID)
-		if err != nil {
-			return helper.Forbidden(c, "Lecturer profile not found")
-		}
-
-		if lecturerID != paramLecturerID {
-			return helper.Forbidden(c, "You are not allowed to access other lecturer's resources")
-		}
-
-		return c.Next()
-	}
+	return Authorize(Policy{Rule: LecturerSelf})
 }
 
+// AdminOrLecturerOrOwnerStudent sama dengan OwnerOrAdvisorOrAdmin.
 func AdminOrLecturerOrOwnerStudent() fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		role := c.Locals("role").(string)
-		userID := c.Locals("user_id").(string)
-		studentID := c.Params("id")
-
-		if studentID == "" {
-			return helper.BadRequest(c, "student id is required")
-		}
-
-		// 1. Admin boleh semua
-		if role == "admin" {
-			return c.Next()
-		}
-
-		// 2. Mahasiswa hanya boleh akses dirinya sendiri
-		if role == "mahasiswa" {
-			myStudentID, err := repository.GetStudentIDByUserID(userID)
-			if err != nil {
-				return helper.Forbidden(c, "Student profile not found")
-			}
-
-			if myStudentID != studentID {
-				return helper.Forbidden(c, "You are not allowed to access other student's data")
-			}
-
-			return c.Next()
-		}
-
-		// 3. Dosen Wali boleh akses mahasiswa bimbingannya
-		if role == "dosen_wali" {
-			lecturerID, err := repository.GetLecturerIDByUserID(userID)
-			if err != nil {
-				return helper.Forbidden(c, "Lecturer profile not found")
-			}
-
-			// cek apakah student ini adalah bimbingan dosen tersebut
-			isAdvisee, err := repository.IsStudentAdviseeOfLecturer(studentID, lecturerID)
-			if err != nil {
-				return helper.InternalError(c, err.Error())
-			}
-
-			if !isAdvisee {
-				return helper.Forbidden(c, "You are not allowed to access this student's data")
-			}
-
-			return c.Next()
-		}
-
-		// 4. Role lain ditolak
-		return helper.Forbidden(c, "Access denied")
-	}
-}
\ No newline at end of file
+	return OwnerOrAdvisorOrAdmin()
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"UAS_GO/app/models"
+	"UAS_GO/app/repository"
+	"UAS_GO/helper"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Rule: syarat hubungan pemanggil dengan resource di path (:id). nil berarti boleh.
+// Semua rule di bawah meloloskan admin.
+type Rule func(c *fiber.Ctx) error
+
+// Policy: aturan akses satu route secara deklaratif.
+//
+//	r.Get("/:id", middleware.Authorize(middleware.Policy{
+//		Permission: "student:read",
+//		Rule:       middleware.StudentOwnerOrAdvisor,
+//	}), service.GetStudentByID)
+type Policy struct {
+	Permission string   // permission role yang wajib dimiliki (kosong = cukup login)
+	Roles      []string // jika diisi, hanya role ini yang boleh
+	Rule       Rule     // relasi dengan resource (nil = tanpa syarat relasi)
+}
+
+// Authorize memeriksa permission, role, lalu rule relasi sesuai policy.
+func Authorize(p Policy) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if err := p.check(c); err != nil {
+			return respondAccessError(c, err)
+		}
+		return c.Next()
+	}
+}
+
+func (p Policy) check(c *fiber.Ctx) error {
+	if p.Permission != "" {
+		if err := checkPermission(c, p.Permission); err != nil {
+			return err
+		}
+	}
+	if len(p.Roles) > 0 {
+		// cukup salah satu role user (utama atau tambahan)
+		if !helper.HasRole(c, p.Roles...) {
+			return &accessError{fiber.StatusForbidden, "Akses ditolak. Role yang diizinkan: " + strings.Join(p.Roles, ", ")}
+		}
+	}
+	if p.Rule != nil {
+		return p.Rule(c)
+	}
+	return nil
+}
+
+// checkPermission: urutan resolusi deny user > grant user > permission salah satu role user.
+// Permission role diambil dari Locals (claims) atau dari role_permissions (lewat cache);
+// yang tidak ada di claims tetap dicek ke cache, jadi grant baru langsung berlaku.
+func checkPermission(c *fiber.Ctx, permission string) error {
+	// 1) Override per user (user_permissions); deny berlaku walau permission ada di token
+	if userID, _ := c.Locals("user_id").(string); userID != "" {
+		effect, err := repository.CachedUserPermissionEffect(userID, permission)
+		if err != nil {
+			return errors.New("Error checking permissions")
+		}
+		switch effect {
+		case models.PermissionEffectDeny:
+			return &accessError{fiber.StatusForbidden, "Akses ditolak. Permission dicabut untuk user ini: " + permission}
+		case models.PermissionEffectGrant:
+			return nil
+		}
+	}
+
+	// 2) Fast path: cek apakah permissions ada di Locals (mis. dari JWT claims)
+	if perms, ok := c.Locals("permissions").([]string); ok {
+		for _, p := range perms {
+			if p == permission {
+				return nil
+			}
+		}
+	}
+
+	// 3) Ambil role dari locals; kosong berarti AuthRequired belum dipanggil
+	roleIDs, _ := c.Locals("role_ids").([]string)
+	if len(roleIDs) == 0 {
+		roleID, _ := c.Locals("role_id").(string)
+		if roleID == "" {
+			return &accessError{fiber.StatusUnauthorized, "Unauthorized"}
+		}
+		roleIDs = []string{roleID}
+	}
+
+	// 4) Gabungan permission semua role, lewat cache role (DB hanya saat entri belum ada / expired)
+	for _, roleID := range roleIDs {
+		has, err := repository.CachedRoleHasPermission(roleID, permission)
+		if err != nil {
+			return errors.New("Error checking permissions")
+		}
+		if has {
+			return nil
+		}
+	}
+	return &accessError{fiber.StatusForbidden, "Akses ditolak. Permission diperlukan: " + permission}
+}
+
+// StudentOwnerOrAdvisor: :id adalah students.id milik pemanggil atau mahasiswa bimbingannya.
+func StudentOwnerOrAdvisor(c *fiber.Ctx) error {
+	studentID := c.Params("id")
+	if studentID == "" && !helper.HasRole(c, "admin") {
+		return &accessError{fiber.StatusBadRequest, "Student id is required"}
+	}
+	return checkStudentAccess(c, studentID)
+}
+
+// AchievementOwnerOrAdvisor: :id adalah Mongo ID prestasi milik pemanggil atau mahasiswa bimbingannya.
+// ID tidak valid / tidak ada → 404; ada tetapi di luar cakupan pemanggil → 403.
+func AchievementOwnerOrAdvisor(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if !primitive.IsValidObjectID(id) {
+		return &accessError{fiber.StatusNotFound, "Achievement not found"}
+	}
+
+	ref, err := repository.GetAchievementReferenceByMongoID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &accessError{fiber.StatusNotFound, "Achievement not found"}
+	}
+	if err != nil {
+		return errors.New("Error loading achievement reference")
+	}
+	return checkStudentAccess(c, ref.StudentID)
+}
+
+// LecturerSelf: :id adalah lecturers.id milik pemanggil (dosen wali).
+func LecturerSelf(c *fiber.Ctx) error {
+	// admin allowed
+	if helper.HasRole(c, "admin") {
+		return nil
+	}
+	if !helper.HasRole(c, "dosen_wali") {
+		return &accessError{fiber.StatusForbidden, "Akses ditolak. Hanya admin atau dosen yang diizinkan."}
+	}
+
+	paramLecturerID := c.Params("id")
+	if paramLecturerID == "" {
+		return &accessError{fiber.StatusBadRequest, "Lecturer id required"}
+	}
+
+	userID, _ := c.Locals("user_id").(string)
+	if userID == "" {
+		return &accessError{fiber.StatusUnauthorized, "Unauthorized"}
+	}
+	lecturerID, err := repository.GetLecturerIDByUserID(userID)
+	if err != nil {
+		return &accessError{fiber.StatusForbidden, "Lecturer profile not found"}
+	}
+	if lecturerID != paramLecturerID {
+		return &accessError{fiber.StatusForbidden, "You are not allowed to access other lecturer's resources"}
+	}
+	return nil
+}
@@ -7,17 +7,31 @@ import (
 )
 
 func registerAchivementRoutes(api fiber.Router) {
+	api.Get("/achievement-types", middleware.AuthRequired(), service.GetAchievementTypes)
+
 	r := api.Group("/achievements", middleware.AuthRequired())
 
-	r.Get("/",middleware.PermissionRequired("achievement:read"),service.GetAllAchievements)
-	r.Get("/:id",middleware.PermissionRequired("achievement:read"),service.GetAchievementById)
-	r.Post("/",middleware.PermissionRequired("achievement:create"),service.CreateAchievement)
-	r.Put("/:id",middleware.PermissionRequired("achievement:update"),service.UpdateAchievement)
-	r.Delete("/:id",middleware.PermissionRequired("achievement:delete"),service.DeleteAchievement)
-	r.Post("/:id/submit",middleware.PermissionRequired("achievement:submit"),service.SubmitAchievement)
-	r.Post("/:id/verify",middleware.PermissionRequired("achievement:verify"),service.VerifyAchievement)
-	r.Post("/:id/reject",middleware.PermissionRequired("achievement:reject"),service.RejectAchievement)
-	r.Get("/:id/history",middleware.PermissionRequired("achievement:read"),service.GetAchievementHistory)
-	r.Post("/:id/attachments",middleware.PermissionRequired("achievement:update"),service.UploadAchievementFile)
+	// route dengan :id hanya untuk pemilik prestasi, dosen walinya, atau admin
+	owned := func(permission string) fiber.Handler {
+		return middleware.Authorize(middleware.Policy{Permission: permission, Rule: middleware.AchievementOwnerOrAdvisor})
+	}
+	lecturer := func(permission string) fiber.Handler {
+		return middleware.Authorize(middleware.Policy{Permission: permission, Roles: []string{"dosen_wali"}})
+	}
+
+	r.Get("/", middleware.Authorize(middleware.Policy{Permission: "achievement:read"}), service.GetAllAchievements)
+	r.Get("/review-queue", lecturer("achievement:view-advisee"), service.GetReviewQueue)
+	r.Post("/bulk/verify", lecturer("achievement:verify"), service.BulkVerifyAchievements)
+	r.Post("/bulk/reject", lecturer("achievement:reject"), service.BulkRejectAchievements)
+	r.Get("/:id", owned("achievement:read"), service.GetAchievementById)
+	r.Post("/", middleware.Authorize(middleware.Policy{Permission: "achievement:create"}), service.CreateAchievement)
+	r.Put("/:id", owned("achievement:update"), service.UpdateAchievement)
+	r.Delete("/:id", owned("achievement:delete"), service.DeleteAchievement)
+	r.Post("/:id/submit", owned("achievement:submit"), service.SubmitAchievement)
+	r.Get("/:id/score", owned("achievement:verify"), service.GetAchievementScore)
+	r.Post("/:id/verify", owned("achievement:verify"), service.VerifyAchievement)
+	r.Post("/:id/reject", owned("achievement:reject"), service.RejectAchievement)
+	r.Get("/:id/history", owned("achievement:read"), service.GetAchievementHistory)
+	r.Post("/:id/attachments", owned("achievement:update"), service.UploadAchievementFile)
 
 }
@@ -7,12 +7,40 @@ import (
 )
 
 func registerAdminRoutes(api fiber.Router) {
-	admin := api.Group("/users", middleware.AuthRequired(), middleware.PermissionRequired("user:manage"))
+	admin := api.Group("/users", middleware.AuthRequired(), middleware.Authorize(middleware.Policy{Permission: "user:manage"}))
 
-	admin.Get("/", middleware.PermissionRequired("user:read"), service.AdminGetAllUsers)
-	admin.Get("/:id", middleware.PermissionRequired("user:read"), service.AdminGetUserByID)
-	admin.Post("/", middleware.PermissionRequired("user:create"), service.AdminCreateUser)
-	admin.Put("/:id", middleware.PermissionRequired("user:update"), service.AdminUpdateUser)
-	admin.Delete("/:id", middleware.PermissionRequired("user:delete"), service.AdminDeleteUser)
-	admin.Put("/:id/role", middleware.PermissionRequired("user:assign-role"), service.AdminUpdateUserRole)
+	admin.Get("/", middleware.Authorize(middleware.Policy{Permission: "user:read"}), service.AdminGetAllUsers)
+	admin.Get("/:id", middleware.Authorize(middleware.Policy{Permission: "user:read"}), service.AdminGetUserByID)
+	admin.Post("/", middleware.Authorize(middleware.Policy{Permission: "user:create"}), service.AdminCreateUser)
+	admin.Put("/:id", middleware.Authorize(middleware.Policy{Permission: "user:update"}), service.AdminUpdateUser)
+	admin.Delete("/:id", middleware.Authorize(middleware.Policy{Permission: "user:delete"}), service.AdminDeleteUser)
+	admin.Post("/:id/unlock", middleware.Authorize(middleware.Policy{Permission: "user:update"}), service.AdminUnlockUser)
+	admin.Put("/:id/role", middleware.Authorize(middleware.Policy{Permission: "user:assign-role"}), service.AdminUpdateUserRole)
+	admin.Get("/:id/permissions", middleware.Authorize(middleware.Policy{Permission: "user:read"}), service.AdminGetUserPermissions)
+	admin.Put("/:id/permissions/:permission", middleware.Authorize(middleware.Policy{Permission: "user:assign-permission"}), service.AdminSetUserPermission)
+	admin.Delete("/:id/permissions/:permission", middleware.Authorize(middleware.Policy{Permission: "user:assign-permission"}), service.AdminDeleteUserPermission)
+}
+
+func registerMaintenanceRoutes(api fiber.Router) {
+	m := api.Group("/admin", middleware.AuthRequired())
+	reconcile := middleware.Authorize(middleware.Policy{Permission: "maintenance:reconcile"})
+	scoring := middleware.Authorize(middleware.Policy{Permission: "scoring:manage"})
+	types := middleware.Authorize(middleware.Policy{Permission: "achievement-type:manage"})
+
+	m.Post("/reconcile", reconcile, service.AdminReconcileAchievements)
+
+	m.Get("/scoring-rules", scoring, service.AdminListScoringRules)
+	m.Post("/scoring-rules", scoring, service.AdminCreateScoringRule)
+	m.Put("/scoring-rules/:id", scoring, service.AdminUpdateScoringRule)
+	m.Delete("/scoring-rules/:id", scoring, service.AdminDeleteScoringRule)
+	m.Get("/scoring-policy", scoring, service.AdminGetScoringPolicy)
+	m.Put("/scoring-policy", scoring, service.AdminUpdateScoringPolicy)
+
+	m.Get("/achievement-types", types, service.AdminListAchievementTypes)
+	m.Post("/achievement-types", types, service.AdminCreateAchievementType)
+	m.Post("/achievement-types/remap", types, service.AdminRemapAchievementTypes)
+	m.Put("/achievement-types/:code", types, service.AdminUpdateAchievementType)
+	m.Delete("/achievement-types/:code", types, service.AdminDeleteAchievementType)
+	m.Put("/achievement-types/:type/schema", types, service.AdminPutAchievementTypeSchema)
+	m.Delete("/achievement-types/:type/schema", types, service.AdminDeleteAchievementTypeSchema)
 }
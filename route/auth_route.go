@@ -10,10 +10,14 @@ func registerAuthRoutes(api fiber.Router) {
 	auth := api.Group("/auth")
 
 	auth.Post("/login", service.AuthLogin)
+	// refresh memakai refresh token (bukan access token yang mungkin sudah expired)
+	auth.Post("/refresh", service.AuthRefreshToken)
+	auth.Post("/forgot", service.AuthForgotPassword)
+	auth.Post("/reset", service.AuthResetPassword)
 
 	protected := auth.Use(middleware.AuthRequired())
 
-	protected.Get("/profile", middleware.PermissionRequired("auth:profile"), service.AuthGetProfile)
+	protected.Get("/profile", middleware.Authorize(middleware.Policy{Permission: "auth:profile"}), service.AuthGetProfile)
 	protected.Post("/logout", service.AuthLogout)
-	protected.Post("/refresh", service.AuthRefreshToken)
+	protected.Post("/password", service.AuthChangePassword)
 }
@@ -10,6 +10,6 @@ import (
 func registerlecturerRoutes(api fiber.Router) {
 	r := api.Group("/lecturers", middleware.AuthRequired())
 
-	r.Get("/", middleware.PermissionRequired("lecturer:read"), service.GetAllLecturers)
-	r.Get("/:id/advisees", middleware.PermissionRequired("lecturer:advisee-list"), service.GetLecturerAdvisees)
+	r.Get("/", middleware.Authorize(middleware.Policy{Permission: "lecturer:read"}), service.GetAllLecturers)
+	r.Get("/:id/advisees", middleware.Authorize(middleware.Policy{Permission: "lecturer:advisee-list", Rule: middleware.LecturerSelf}), service.GetLecturerAdvisees)
 }
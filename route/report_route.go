@@ -9,6 +9,6 @@ import (
 func registerReportRoutes(api fiber.Router) {
 	r := api.Group("/reports", middleware.AuthRequired())
 
-	r.Get("/statistics", middleware.PermissionRequired("report:statistics"), service.GetGlobalStatistics)
-	r.Get("/student/:id", middleware.PermissionRequired("report:student"), service.GetStudentReport)
+	r.Get("/statistics", middleware.Authorize(middleware.Policy{Permission: "report:statistics"}), service.GetGlobalStatistics)
+	r.Get("/student/:id", middleware.Authorize(middleware.Policy{Permission: "report:student", Rule: middleware.StudentOwnerOrAdvisor}), service.GetStudentReport)
 }
@@ -0,0 +1,26 @@
+package route
+
+import (
+	"UAS_GO/app/service"
+	"UAS_GO/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func registerRoleRoutes(api fiber.Router) {
+	read := middleware.Authorize(middleware.Policy{Permission: "role:read"})
+	manage := middleware.Authorize(middleware.Policy{Permission: "role:manage"})
+
+	api.Get("/permissions", middleware.AuthRequired(), read, service.AdminListPermissions)
+
+	r := api.Group("/roles", middleware.AuthRequired())
+
+	r.Get("/", read, service.AdminListRoles)
+	r.Get("/audit", read, service.AdminGetRBACAudit)
+	r.Get("/:id", read, service.AdminGetRole)
+	r.Post("/", manage, service.AdminCreateRole)
+	r.Put("/:id", manage, service.AdminUpdateRole)
+	r.Delete("/:id", manage, service.AdminDeleteRole)
+	r.Post("/:id/permissions", manage, service.AdminGrantRolePermissions)
+	r.Delete("/:id/permissions/:permission", manage, service.AdminRevokeRolePermission)
+}
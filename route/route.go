@@ -1,11 +1,19 @@
 package route
 
-import "github.com/gofiber/fiber/v2"
+import (
+	"UAS_GO/app/service"
+	"github.com/gofiber/fiber/v2"
+)
 
 func RegisterRoutes(app *fiber.App) {
+	// public key untuk verifikasi token oleh service lain
+	app.Get("/.well-known/jwks.json", service.GetJWKS)
+
 	api := app.Group("/api/v1")
 	registerAuthRoutes(api)
 	registerAdminRoutes(api)
+	registerMaintenanceRoutes(api)
+	registerRoleRoutes(api)
 	registerAchivementRoutes(api)
 	registerStudentRoutes(api)
 	registerlecturerRoutes(api)
@@ -9,8 +9,11 @@ import (
 func registerStudentRoutes(api fiber.Router) {
 	r := api.Group("/students", middleware.AuthRequired())
 
-	r.Get("/", middleware.PermissionRequired("student:read"), service.GetAllStudents)
-	r.Get("/:id", middleware.PermissionRequired("student:read"), service.GetStudentByID)
-	r.Get("/:id/achievements", middleware.PermissionRequired("student:read"), service.GetStudentAchievements)
-	r.Put("/:id/advisor", middleware.PermissionRequired("student:update"), service.UpdateStudentAdvisor)
+	// data satu mahasiswa hanya untuk dirinya, dosen walinya, atau admin
+	own := middleware.Authorize(middleware.Policy{Permission: "student:read", Rule: middleware.StudentOwnerOrAdvisor})
+
+	r.Get("/", middleware.Authorize(middleware.Policy{Permission: "student:read"}), service.GetAllStudents)
+	r.Get("/:id", own, service.GetStudentByID)
+	r.Get("/:id/achievements", own, service.GetStudentAchievements)
+	r.Put("/:id/advisor", middleware.Authorize(middleware.Policy{Permission: "student:update", Roles: []string{"admin"}}), service.UpdateStudentAdvisor)
 }